@@ -0,0 +1,45 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+// This plugin imports jwtauthserver to register the JWT implementation of AuthServer.
+
+import (
+	"time"
+
+	"vitess.io/vitess/go/mysql/jwtauthserver"
+	"vitess.io/vitess/go/vt/vtgate"
+)
+
+var jwtAuthConfig = jwtauthserver.Config{
+	ReloadInterval: 30 * time.Second,
+	UsernameClaim:  "sub",
+	GroupsClaim:    "groups",
+	ClockSkew:      30 * time.Second,
+}
+
+func init() {
+	Main.Flags().StringVar(&jwtAuthConfig.JWKSFile, "mysql-auth-jwt-jwks-file", jwtAuthConfig.JWKSFile, "Path to the JSON Web Key Set (JWKS) whose keys verify the signatures of the JWTs sent as passwords with mysql_clear_password.")
+	Main.Flags().DurationVar(&jwtAuthConfig.ReloadInterval, "mysql-auth-jwt-jwks-reload-interval", jwtAuthConfig.ReloadInterval, "Interval at which the JWKS file is reloaded if it changed. It is reloaded on SIGHUP too.")
+	Main.Flags().StringVar(&jwtAuthConfig.Issuer, "mysql-auth-jwt-issuer", jwtAuthConfig.Issuer, "Issuer (iss claim) of the JWTs.")
+	Main.Flags().StringVar(&jwtAuthConfig.Audience, "mysql-auth-jwt-audience", jwtAuthConfig.Audience, "Audience the JWTs must be issued for (aud claim).")
+	Main.Flags().StringVar(&jwtAuthConfig.UsernameClaim, "mysql-auth-jwt-username-claim", jwtAuthConfig.UsernameClaim, "Claim of the JWTs holding the username. Nested claims are separated with dots.")
+	Main.Flags().StringVar(&jwtAuthConfig.GroupsClaim, "mysql-auth-jwt-groups-claim", jwtAuthConfig.GroupsClaim, "Claim of the JWTs holding the groups of the user, used by table ACLs. Nested claims are separated with dots.")
+	Main.Flags().DurationVar(&jwtAuthConfig.ClockSkew, "mysql-auth-jwt-clock-skew", jwtAuthConfig.ClockSkew, "Leeway given to clock differences when checking the expiry and start of validity of the JWTs.")
+
+	vtgate.RegisterPluginInitializer(func() { jwtauthserver.Init(jwtAuthConfig) })
+}
@@ -174,7 +174,7 @@ func Connect(ctx context.Context, params *ConnParams) (*Conn, error) {
 // Ping implements mysql ping command.
 func (c *Conn) Ping() error {
 	// This is a new command, need to reset the sequence.
-	c.sequence = 0
+	c.resetSequence()
 	data, pos := c.startEphemeralPacketWithHeader(1)
 	data[pos] = ComPing
 
@@ -195,6 +195,51 @@ func (c *Conn) Ping() error {
 	return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected packet type: %d", data[0])
 }
 
+// ChangeUser implements the mysql change user command. It authenticates
+// params.Uname and switches to params.DbName, resetting the connection.
+// Returns a SQLError.
+func (c *Conn) ChangeUser(params *ConnParams) error {
+	// This is a new command, need to reset the sequence.
+	c.resetSequence()
+
+	var scrambledPassword []byte
+	if c.authPluginName == CachingSha2Password {
+		scrambledPassword = ScrambleCachingSha2Password(c.salt, []byte(params.Pass))
+	} else {
+		scrambledPassword = ScrambleMysqlNativePassword(c.salt, []byte(params.Pass))
+	}
+
+	// Keep the character set of the connection, unless asked otherwise.
+	charset := params.Charset
+	if charset == collations.Unknown {
+		charset = c.CharacterSet
+	}
+
+	length := 1 + // ComChangeUser
+		lenNullString(params.Uname) +
+		1 + len(scrambledPassword) +
+		lenNullString(params.DbName) +
+		2 + // character set
+		lenNullString(string(c.authPluginName))
+	data, pos := c.startEphemeralPacketWithHeader(length)
+	pos = writeByte(data, pos, ComChangeUser)
+	pos = writeNullString(data, pos, params.Uname)
+	pos = writeByte(data, pos, byte(len(scrambledPassword)))
+	pos += copy(data[pos:], scrambledPassword)
+	pos = writeNullString(data, pos, params.DbName)
+	pos = writeUint16(data, pos, uint16(charset))
+	_ = writeNullString(data, pos, string(c.authPluginName))
+	if err := c.writeEphemeralPacket(); err != nil {
+		return sqlerror.NewSQLErrorf(sqlerror.CRServerGone, sqlerror.SSUnknownSQLState, "%v", err)
+	}
+
+	if err := c.handleAuthResponse(params); err != nil {
+		return err
+	}
+	c.schemaName = params.DbName
+	return nil
+}
+
 // clientHandshake handles the client side of the handshake.
 // Note the connection can be closed while this is running.
 // Returns a SQLError.
@@ -306,6 +351,10 @@ func (c *Conn) clientHandshake(params *ConnParams) error {
 		return err
 	}
 
+	// The packets that follow the authentication are compressed, if
+	// the server supports the algorithm we asked for.
+	c.startCompression()
+
 	// If the server didn't support DbName in its handshake, set
 	// it now. This is what the 'mysql' client does.
 	if capabilities&CapabilityClientConnectWithDB == 0 && params.DbName != "" {
@@ -539,6 +588,15 @@ func (c *Conn) writeHandshakeResponse41(capabilities uint32, scrambledPassword [
 		// CapabilityClientSessionTrack, we also support it.
 		c.Capabilities&CapabilityClientSessionTrack
 
+	// Compress the protocol if the server supports the algorithm.
+	// zstd needs the compression level at the end of the packet.
+	c.compression = ""
+	if params.Compression != "" && capabilities&params.Compression.capability() != 0 {
+		c.compression = params.Compression
+		c.compressionLevel = DefaultZstdCompressionLevel
+		capabilityFlags |= params.Compression.capability()
+	}
+
 	// FIXME(alainjobart) add multi statement.
 
 	length :=
@@ -564,6 +622,10 @@ func (c *Conn) writeHandshakeResponse41(capabilities uint32, scrambledPassword [
 		length++
 	}
 
+	if c.compression == CompressionZstd {
+		length++
+	}
+
 	data, pos := c.startEphemeralPacketWithHeader(length)
 
 	// Client capability flags.
@@ -600,6 +662,11 @@ func (c *Conn) writeHandshakeResponse41(capabilities uint32, scrambledPassword [
 	// Assume native client during response
 	pos = writeNullString(data, pos, string(c.authPluginName))
 
+	// zstd compression level.
+	if c.compression == CompressionZstd {
+		pos = writeByte(data, pos, byte(c.compressionLevel))
+	}
+
 	// Sanity-check the length.
 	if pos != len(data) {
 		return sqlerror.NewSQLErrorf(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "writeHandshakeResponse41: only packed %v bytes, out of %v allocated", pos, len(data))
@@ -0,0 +1,385 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// This file contains the compressed packet framing of the protocol.
+// See https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_basic_compression.html
+//
+// Once negotiated in the handshake, all packets after the OK packet that
+// ends the authentication are sent in compressed packets. A compressed
+// packet has a 7 bytes header:
+//   - 3 bytes: the length of the (compressed) payload.
+//   - 1 byte: the compressed sequence, which is reset with the sequence.
+//   - 3 bytes: the length of the uncompressed payload, or 0 if the payload
+//     is not compressed.
+//
+// The payload is a chunk of the stream of regular packets, headers
+// included. Each payload is compressed on its own, as a zlib stream or a
+// zstd frame.
+
+// CompressionAlgorithm is an algorithm that compresses the protocol.
+type CompressionAlgorithm string
+
+const (
+	// CompressionZlib compresses the protocol with zlib (CLIENT_COMPRESS).
+	CompressionZlib = CompressionAlgorithm("zlib")
+
+	// CompressionZstd compresses the protocol with zstd
+	// (CLIENT_ZSTD_COMPRESSION_ALGORITHM).
+	CompressionZstd = CompressionAlgorithm("zstd")
+)
+
+const (
+	compressedPacketHeaderSize = 7
+
+	// minCompressLength is the size under which payloads are not worth
+	// compressing, as in MySQL.
+	minCompressLength = 50
+
+	// DefaultZstdCompressionLevel is the zstd level used when the client
+	// doesn't ask for one, as in MySQL.
+	DefaultZstdCompressionLevel = 3
+)
+
+var (
+	compressionBytesSaved = stats.NewCountersWithSingleLabel("MysqlCompressionBytesSaved", "Bytes saved on the network by the compression of the MySQL protocol", "algorithm")
+
+	// zstdDecoder decompresses the zstd payloads of all the connections,
+	// which are never larger than MaxPacketSize.
+	zstdDecoder     *zstd.Decoder
+	zstdDecoderOnce sync.Once
+
+	// zstdEncoders compress the zstd payloads of all the connections,
+	// by level.
+	zstdEncoders   = make(map[zstd.EncoderLevel]*zstd.Encoder)
+	zstdEncodersMu sync.Mutex
+)
+
+// ParseCompressionAlgorithm returns the CompressionAlgorithm of the given name.
+func ParseCompressionAlgorithm(name string) (CompressionAlgorithm, error) {
+	switch algorithm := CompressionAlgorithm(name); algorithm {
+	case CompressionZlib, CompressionZstd:
+		return algorithm, nil
+	}
+	return "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unknown compression algorithm %q, it should be one of zlib or zstd", name)
+}
+
+// capability returns the capability flag that negotiates the algorithm.
+func (algorithm CompressionAlgorithm) capability() uint32 {
+	switch algorithm {
+	case CompressionZlib:
+		return CapabilityClientCompress
+	case CompressionZstd:
+		return CapabilityClientZstdCompressionAlgorithm
+	}
+	return 0
+}
+
+// compressionCapabilities returns the capability flags of the algorithms.
+func compressionCapabilities(algorithms []CompressionAlgorithm) uint32 {
+	var capabilities uint32
+	for _, algorithm := range algorithms {
+		capabilities |= algorithm.capability()
+	}
+	return capabilities
+}
+
+// negotiateCompression returns the algorithm to use given the capabilities
+// both sides support. zlib is preferred, as in MySQL.
+func negotiateCompression(capabilities uint32) CompressionAlgorithm {
+	switch {
+	case capabilities&CapabilityClientCompress != 0:
+		return CompressionZlib
+	case capabilities&CapabilityClientZstdCompressionAlgorithm != 0:
+		return CompressionZstd
+	}
+	return ""
+}
+
+func getZstdDecoder() (*zstd.Decoder, error) {
+	var err error
+	zstdDecoderOnce.Do(func() {
+		zstdDecoder, err = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0), zstd.WithDecoderMaxMemory(MaxPacketSize))
+	})
+	if zstdDecoder == nil {
+		return nil, vterrors.Wrapf(err, "cannot create zstd decoder")
+	}
+	return zstdDecoder, nil
+}
+
+func getZstdEncoder(level int) (*zstd.Encoder, error) {
+	encoderLevel := zstd.EncoderLevelFromZstd(level)
+
+	zstdEncodersMu.Lock()
+	defer zstdEncodersMu.Unlock()
+	if encoder, ok := zstdEncoders[encoderLevel]; ok {
+		return encoder, nil
+	}
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(encoderLevel))
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "cannot create zstd encoder")
+	}
+	zstdEncoders[encoderLevel] = encoder
+	return encoder, nil
+}
+
+// compressedConn reads and writes the compressed packets of a Conn. It
+// reads from and writes to the underlying reader and writer of the Conn,
+// and is used in their place.
+type compressedConn struct {
+	c         *Conn
+	algorithm CompressionAlgorithm
+	level     int
+
+	r io.Reader
+	w io.Writer
+
+	// header is the header of the compressed packet being read.
+	header [compressedPacketHeaderSize]byte
+
+	// readBuf holds the uncompressed payload being read, from readPos.
+	readBuf []byte
+	readPos int
+	// compressedBuf holds the compressed payload being read.
+	compressedBuf []byte
+
+	// writeBuf holds the compressed packet being written.
+	writeBuf bytes.Buffer
+	// zlibWriter and zlibReader are reused for all the zlib payloads.
+	zlibWriter *zlib.Writer
+	zlibReader io.ReadCloser
+}
+
+// Read reads the uncompressed stream of packets, reading the next
+// compressed packet when needed.
+func (cc *compressedConn) Read(p []byte) (int, error) {
+	if cc.readPos == len(cc.readBuf) {
+		if err := cc.readCompressedPacket(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, cc.readBuf[cc.readPos:])
+	cc.readPos += n
+	return n, nil
+}
+
+func (cc *compressedConn) readCompressedPacket() error {
+	cc.readBuf = cc.readBuf[:0]
+	cc.readPos = 0
+
+	if _, err := io.ReadFull(cc.r, cc.header[:]); err != nil {
+		// As in readHeaderFrom, io.EOF is returned as is so that the
+		// server can recognize a client that disconnects.
+		if err == io.EOF {
+			return err
+		}
+		return vterrors.Wrapf(err, "io.ReadFull(compressed header size) failed")
+	}
+
+	length := int(uint32(cc.header[0]) | uint32(cc.header[1])<<8 | uint32(cc.header[2])<<16)
+	sequence := cc.header[3]
+	uncompressedLength := int(uint32(cc.header[4]) | uint32(cc.header[5])<<8 | uint32(cc.header[6])<<16)
+	if sequence != cc.c.compressedSequence {
+		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "invalid compressed sequence, expected %v got %v", cc.c.compressedSequence, sequence)
+	}
+	cc.c.compressedSequence++
+
+	if uncompressedLength == 0 {
+		// The payload was sent uncompressed.
+		cc.readBuf = growBuffer(cc.readBuf, length)
+		if _, err := io.ReadFull(cc.r, cc.readBuf); err != nil {
+			return vterrors.Wrapf(err, "io.ReadFull(compressed packet body of length %v) failed", length)
+		}
+		return nil
+	}
+
+	cc.compressedBuf = growBuffer(cc.compressedBuf, length)
+	if _, err := io.ReadFull(cc.r, cc.compressedBuf); err != nil {
+		return vterrors.Wrapf(err, "io.ReadFull(compressed packet body of length %v) failed", length)
+	}
+	if err := cc.decompress(uncompressedLength); err != nil {
+		return err
+	}
+	compressionBytesSaved.Add(string(cc.algorithm), int64(uncompressedLength-length))
+	return nil
+}
+
+// decompress decompresses compressedBuf into readBuf.
+func (cc *compressedConn) decompress(uncompressedLength int) error {
+	cc.readBuf = growBuffer(cc.readBuf, uncompressedLength)
+	switch cc.algorithm {
+	case CompressionZlib:
+		var err error
+		if cc.zlibReader == nil {
+			cc.zlibReader, err = zlib.NewReader(bytes.NewReader(cc.compressedBuf))
+		} else {
+			err = cc.zlibReader.(zlib.Resetter).Reset(bytes.NewReader(cc.compressedBuf), nil)
+		}
+		if err != nil {
+			return vterrors.Wrapf(err, "cannot decompress zlib packet")
+		}
+		if _, err := io.ReadFull(cc.zlibReader, cc.readBuf); err != nil {
+			return vterrors.Wrapf(err, "cannot decompress zlib packet")
+		}
+	case CompressionZstd:
+		decoder, err := getZstdDecoder()
+		if err != nil {
+			return err
+		}
+		decompressed, err := decoder.DecodeAll(cc.compressedBuf, cc.readBuf[:0])
+		if err != nil {
+			return vterrors.Wrapf(err, "cannot decompress zstd packet")
+		}
+		if len(decompressed) != uncompressedLength {
+			return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "zstd packet decompressed to %v bytes, expected %v", len(decompressed), uncompressedLength)
+		}
+		cc.readBuf = decompressed
+	}
+	return nil
+}
+
+// Write writes the stream of packets in compressed packets.
+func (cc *compressedConn) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		// The length of compressed packets is capped to MaxPacketSize too.
+		chunk := p[written:]
+		if len(chunk) > MaxPacketSize {
+			chunk = chunk[:MaxPacketSize]
+		}
+		if err := cc.writeCompressedPacket(chunk); err != nil {
+			return written, err
+		}
+		written += len(chunk)
+	}
+	return written, nil
+}
+
+func (cc *compressedConn) writeCompressedPacket(payload []byte) error {
+	// The header is filled in once the length of the payload is known.
+	var header [compressedPacketHeaderSize]byte
+	cc.writeBuf.Reset()
+	cc.writeBuf.Write(header[:])
+
+	uncompressedLength := 0
+	if len(payload) >= minCompressLength {
+		if err := cc.compress(payload); err != nil {
+			return err
+		}
+		compressedLength := cc.writeBuf.Len() - compressedPacketHeaderSize
+		if compressedLength < len(payload) {
+			uncompressedLength = len(payload)
+			compressionBytesSaved.Add(string(cc.algorithm), int64(len(payload)-compressedLength))
+		} else {
+			// Compressing didn't help, send the payload as is.
+			cc.writeBuf.Truncate(compressedPacketHeaderSize)
+		}
+	}
+	if uncompressedLength == 0 {
+		cc.writeBuf.Write(payload)
+	}
+
+	length := cc.writeBuf.Len() - compressedPacketHeaderSize
+	data := cc.writeBuf.Bytes()
+	data[0] = byte(length)
+	data[1] = byte(length >> 8)
+	data[2] = byte(length >> 16)
+	data[3] = cc.c.compressedSequence
+	data[4] = byte(uncompressedLength)
+	data[5] = byte(uncompressedLength >> 8)
+	data[6] = byte(uncompressedLength >> 16)
+
+	if n, err := cc.w.Write(data); err != nil {
+		return vterrors.Wrapf(err, "Write(compressed packet) failed")
+	} else if n != len(data) {
+		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "Write(compressed packet) returned a short write: %v < %v", n, len(data))
+	}
+	cc.c.compressedSequence++
+	return nil
+}
+
+// compress appends the compressed payload to writeBuf.
+func (cc *compressedConn) compress(payload []byte) error {
+	switch cc.algorithm {
+	case CompressionZlib:
+		if cc.zlibWriter == nil {
+			cc.zlibWriter = zlib.NewWriter(&cc.writeBuf)
+		} else {
+			cc.zlibWriter.Reset(&cc.writeBuf)
+		}
+		if _, err := cc.zlibWriter.Write(payload); err != nil {
+			return vterrors.Wrapf(err, "cannot compress zlib packet")
+		}
+		if err := cc.zlibWriter.Close(); err != nil {
+			return vterrors.Wrapf(err, "cannot compress zlib packet")
+		}
+	case CompressionZstd:
+		encoder, err := getZstdEncoder(cc.level)
+		if err != nil {
+			return err
+		}
+		buf := cc.writeBuf.AvailableBuffer()
+		cc.writeBuf.Write(encoder.EncodeAll(payload, buf))
+	}
+	return nil
+}
+
+// growBuffer returns a buffer of the given length, reusing buf if it's
+// large enough.
+func growBuffer(buf []byte, length int) []byte {
+	if cap(buf) < length {
+		return make([]byte, length)
+	}
+	return buf[:length]
+}
+
+// startCompression makes the connection read and write compressed
+// packets from now on, if a compression algorithm was negotiated in the
+// handshake. It must be called once the handshake is over.
+func (c *Conn) startCompression() {
+	if c.compression == "" {
+		return
+	}
+	c.compressedConn = &compressedConn{
+		c:         c,
+		algorithm: c.compression,
+		level:     c.compressionLevel,
+		r:         c.getReader(),
+		w:         c.conn,
+	}
+	c.compressedSequence = 0
+}
+
+// Compression returns the algorithm that compresses the protocol on
+// this connection, or an empty string if it's not compressed.
+func (c *Conn) Compression() CompressionAlgorithm {
+	return c.compression
+}
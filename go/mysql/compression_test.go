@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestParseCompressionAlgorithm(t *testing.T) {
+	algorithm, err := ParseCompressionAlgorithm("zstd")
+	require.NoError(t, err)
+	assert.Equal(t, CompressionZstd, algorithm)
+
+	_, err = ParseCompressionAlgorithm("lz4")
+	require.EqualError(t, err, `unknown compression algorithm "lz4", it should be one of zlib or zstd`)
+}
+
+func TestCompression(t *testing.T) {
+	result := &sqltypes.Result{
+		Fields: []*querypb.Field{{
+			Name:    "name",
+			Type:    querypb.Type_VARCHAR,
+			Charset: uint32(collations.CollationUtf8mb4ID),
+		}},
+	}
+	for i := 0; i < 1000; i++ {
+		result.Rows = append(result.Rows, []sqltypes.Value{sqltypes.NewVarChar(fmt.Sprintf("a rather compressible name %d", i))})
+	}
+	// A row larger than a packet, and than a compressed packet.
+	result.Rows = append(result.Rows, []sqltypes.Value{sqltypes.NewVarChar(strings.Repeat("x", MaxPacketSize+100))})
+	th := &testHandler{result: result}
+
+	l, err := NewListener("tcp", "127.0.0.1:", NewAuthServerNone(), th, 0, 0, false, false, 0, 0)
+	require.NoError(t, err)
+	defer l.Close()
+	l.CompressionAlgorithms = []CompressionAlgorithm{CompressionZlib, CompressionZstd}
+	go l.Accept()
+
+	host, port := getHostPort(t, l.Addr())
+	query := "select name from t where name like '" + strings.Repeat("a", 100) + "'"
+
+	for _, algorithm := range []CompressionAlgorithm{CompressionZlib, CompressionZstd} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			saved := compressionBytesSaved.Counts()[string(algorithm)]
+
+			c, err := Connect(context.Background(), &ConnParams{Host: host, Port: port, Compression: algorithm})
+			require.NoError(t, err)
+			defer c.Close()
+			assert.Equal(t, algorithm, c.Compression())
+			assert.Equal(t, algorithm, th.LastConn().Compression())
+
+			// Run a few commands, the first large enough to be compressed.
+			q := query
+			for i := 0; i < 3; i++ {
+				qr, err := c.ExecuteFetch(q, 10000, false)
+				require.NoError(t, err)
+				assert.Equal(t, result.Rows, qr.Rows)
+				require.NoError(t, c.Ping())
+				q = "select 1"
+			}
+
+			assert.Greater(t, compressionBytesSaved.Counts()[string(algorithm)], saved)
+		})
+	}
+
+	t.Run("not offered", func(t *testing.T) {
+		l, err := NewListener("tcp", "127.0.0.1:", NewAuthServerNone(), th, 0, 0, false, false, 0, 0)
+		require.NoError(t, err)
+		defer l.Close()
+		l.CompressionAlgorithms = []CompressionAlgorithm{CompressionZlib}
+		go l.Accept()
+
+		host, port := getHostPort(t, l.Addr())
+		c, err := Connect(context.Background(), &ConnParams{Host: host, Port: port, Compression: CompressionZstd})
+		require.NoError(t, err)
+		defer c.Close()
+		assert.Empty(t, c.Compression())
+		assert.Empty(t, th.LastConn().Compression())
+
+		qr, err := c.ExecuteFetch(query, 10000, false)
+		require.NoError(t, err)
+		assert.Equal(t, result.Rows, qr.Rows)
+	})
+}
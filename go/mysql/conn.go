@@ -159,10 +159,16 @@ type Conn struct {
 	// the client and the server, and currently in use.
 	// It is set during the initial handshake.
 	//
-	// It is only used for CapabilityClientDeprecateEOF
-	// and CapabilityClientFoundRows.
+	// It is only used for CapabilityClientDeprecateEOF,
+	// CapabilityClientFoundRows, CapabilityClientMultiStatements
+	// and CapabilityClientQueryAttributes.
 	Capabilities uint32
 
+	// QueryAttributes are the query attributes the client sent along
+	// with the command being handled, if any. NULL attributes are left out.
+	// It is only used by the server, with CapabilityClientQueryAttributes.
+	QueryAttributes map[string]string
+
 	// closed is set to true when Close() is called on the connection.
 	closed atomic.Bool
 
@@ -194,6 +200,17 @@ type Conn struct {
 	// Packet encoding variables.
 	sequence uint8
 
+	// compression is the algorithm negotiated in the handshake to
+	// compress the protocol, and compressionLevel its level for zstd.
+	// The protocol is compressed once compressedConn is set, at the
+	// end of the handshake.
+	compression      CompressionAlgorithm
+	compressionLevel int
+	compressedConn   *compressedConn
+	// compressedSequence is the sequence of compressed packets. It is
+	// reset with sequence.
+	compressedSequence uint8
+
 	// ExpectSemiSyncIndicator is applicable when the connection is used for replication (ComBinlogDump).
 	// When 'true', events are assumed to be padded with 2-byte semi-sync information
 	// See https://dev.mysql.com/doc/internals/en/semi-sync-binlog-event.html
@@ -212,6 +229,11 @@ type Conn struct {
 	// cancel keep the cancel function for the current executing query.
 	// this is used by `kill [query|connection] ID` command from other connection.
 	cancel context.CancelFunc
+	// cursorCancels keep the cancel functions of the executions of the open
+	// cursors, which outlive the command that opened them. They are used by
+	// `kill [query|connection] ID` as well.
+	cursorCancels    map[int]context.CancelFunc
+	lastCursorCancel int
 	// this is used to mark the connection to be closed so that the command phase for the connection can be stopped and
 	// the connection gets closed.
 	closing bool
@@ -225,8 +247,23 @@ type PrepareData struct {
 	ColumnNames []string
 	PrepareStmt string
 	BindVars    map[string]*querypb.BindVariable
+	// cursor is the cursor opened by the last execution, if still open.
+	cursor      *cursor
 	StatementID uint32
 	ParamsCount uint16
+	// CursorType holds the cursor type flags of the current execution,
+	// CursorTypeReadOnly if the rows are fetched with COM_STMT_FETCH.
+	CursorType byte
+	// attributes are the names and types of the query attributes bound
+	// by the last execution, sent after the parameters.
+	attributes []queryAttribute
+}
+
+// queryAttribute is the name and type of a query attribute bound to
+// a prepared statement.
+type queryAttribute struct {
+	name string
+	typ  querypb.Type
 }
 
 // execResult is an enum signifying the result of executing a query
@@ -323,7 +360,7 @@ func (c *Conn) startWriterBuffering() {
 	defer c.bufMu.Unlock()
 
 	c.bufferedWriter = writersPool.Get().(*bufio.Writer)
-	c.bufferedWriter.Reset(c.conn)
+	c.bufferedWriter.Reset(c.getWriter())
 }
 
 // endWriterBuffering must be called to terminate startWriteBuffering.
@@ -371,14 +408,34 @@ func (c *Conn) startFlushTimer() {
 }
 
 // getReader returns reader for connection. It can be *bufio.Reader or net.Conn
-// depending on which buffer size was passed to newServerConn.
+// depending on which buffer size was passed to newServerConn, or the
+// compressedConn reading from them once compression started.
 func (c *Conn) getReader() io.Reader {
+	if c.compressedConn != nil {
+		return c.compressedConn
+	}
 	if c.bufferedReader != nil {
 		return c.bufferedReader
 	}
 	return c.conn
 }
 
+// getWriter returns the unbuffered writer for connection. It is the
+// net.Conn, or the compressedConn writing to it once compression started.
+func (c *Conn) getWriter() io.Writer {
+	if c.compressedConn != nil {
+		return c.compressedConn
+	}
+	return c.conn
+}
+
+// resetSequence resets the sequence, and the compressed sequence, for
+// a new command.
+func (c *Conn) resetSequence() {
+	c.sequence = 0
+	c.compressedSequence = 0
+}
+
 func (c *Conn) readHeaderFrom(r io.Reader) (int, error) {
 	// Note io.ReadFull will return two different types of errors:
 	// 1. if the socket is already closed, and the go runtime knows it,
@@ -400,7 +457,11 @@ func (c *Conn) readHeaderFrom(r io.Reader) (int, error) {
 	}
 
 	sequence := uint8(c.header[3])
-	if sequence != c.sequence {
+	if c.compressedConn != nil {
+		// The sequence of packets within compressed packets is not
+		// checked, as in MySQL: the compressed sequence is.
+		c.sequence = sequence
+	} else if sequence != c.sequence {
 		return 0, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "invalid sequence, expected %v got %v", c.sequence, sequence)
 	}
 
@@ -611,7 +672,7 @@ func (c *Conn) writePacket(data []byte) error {
 		}()
 	} else {
 		c.bufMu.Unlock()
-		w = c.conn
+		w = c.getWriter()
 	}
 
 	var header [packetHeaderSize]byte
@@ -715,7 +776,7 @@ func (c *Conn) recycleWritePacket() {
 // Returns SQLError(CRServerGone) if it can't.
 func (c *Conn) writeComQuit() error {
 	// This is a new command, need to reset the sequence.
-	c.sequence = 0
+	c.resetSequence()
 
 	data, pos := c.startEphemeralPacketWithHeader(1)
 	data[pos] = ComQuit
@@ -888,7 +949,7 @@ func (c *Conn) writeEOFPacket(flags uint16, warnings uint16) error {
 // handleNextCommand is called in the server loop to process
 // incoming packets.
 func (c *Conn) handleNextCommand(handler Handler) bool {
-	c.sequence = 0
+	c.resetSequence()
 	data, err := c.readEphemeralPacket()
 	if err != nil {
 		// Don't log EOF errors. They cause too much spam.
@@ -905,6 +966,9 @@ func (c *Conn) handleNextCommand(handler Handler) bool {
 		return false
 	}
 
+	// Query attributes only apply to the command they are sent with.
+	c.QueryAttributes = nil
+
 	switch data[0] {
 	case ComQuit:
 		c.recycleReadPacket()
@@ -924,12 +988,15 @@ func (c *Conn) handleNextCommand(handler Handler) bool {
 		return c.handleComPrepare(handler, data)
 	case ComStmtExecute:
 		return c.handleComStmtExecute(handler, data)
+	case ComStmtFetch:
+		return c.handleComStmtFetch(handler, data)
 	case ComStmtSendLongData:
 		return c.handleComStmtSendLongData(data)
 	case ComStmtClose:
 		stmtID, ok := c.parseComStmtClose(data)
 		c.recycleReadPacket()
-		if ok {
+		if prepare, found := c.PrepareData[stmtID]; ok && found {
+			prepare.closeCursor()
 			delete(c.PrepareData, stmtID)
 		}
 	case ComStmtReset:
@@ -937,6 +1004,8 @@ func (c *Conn) handleNextCommand(handler Handler) bool {
 	case ComResetConnection:
 		c.handleComResetConnection(handler)
 		return true
+	case ComChangeUser:
+		return c.handleComChangeUser(handler, data)
 	case ComFieldList:
 		c.recycleReadPacket()
 		if !c.writeErrorAndLog(sqlerror.ERUnknownComError, sqlerror.SSNetError, "command handling not implemented yet: %v", data[0]) {
@@ -1028,6 +1097,7 @@ func (c *Conn) handleComBinlogDumpGTID(handler Handler, data []byte) (kontinue b
 func (c *Conn) handleComResetConnection(handler Handler) {
 	// Clean up and reset the connection
 	c.recycleReadPacket()
+	c.closeCursors()
 	handler.ComResetConnection(c)
 	// Reset prepared statements
 	c.PrepareData = make(map[uint32]*PrepareData)
@@ -1037,6 +1107,59 @@ func (c *Conn) handleComResetConnection(handler Handler) {
 	}
 }
 
+// handleComChangeUser switches the connection to the user of the
+// COM_CHANGE_USER once authenticated by the AuthServer, and resets it.
+// As in MySQL, the connection is left untouched if the authentication
+// fails: the previous user, session and prepared statements stay.
+func (c *Conn) handleComChangeUser(handler Handler, data []byte) (kontinue bool) {
+	user, authMethod, _, schemaName, characterSet, ok := c.parseComChangeUser(data)
+	c.recycleReadPacket()
+	if !ok {
+		log.Errorf("Got malformed ComChangeUser packet from client %v, returning error", c.ConnectionID)
+		return c.writeErrorAndLog(sqlerror.ERUnknownComError, sqlerror.SSNetError, "error handling ComChangeUser packet")
+	}
+
+	// The client computed its auth response with the salt of the initial
+	// handshake, or of an auth switch since then, so we switch to a new salt
+	// by ignoring it.
+	userData, ok := c.listener.authenticate(c, user, authMethod, nil, nil)
+	if !ok {
+		return true
+	}
+
+	c.closeCursors()
+	handler.ComChangeUser(c)
+	c.PrepareData = make(map[uint32]*PrepareData)
+	if characterSet != 0 {
+		c.CharacterSet = characterSet
+	}
+
+	if c.User != "" {
+		connCountPerUser.Add(c.User, -1)
+	}
+	c.User = user
+	c.UserData = userData
+	if c.User != "" {
+		connCountPerUser.Add(c.User, 1)
+	}
+
+	c.schemaName = schemaName
+	if schemaName != "" {
+		err := handler.ComQuery(c, "use "+sqlescape.EscapeID(schemaName), func(*sqltypes.Result) error {
+			return nil
+		})
+		if err != nil {
+			return c.writeErrorPacketFromErrorAndLog(err)
+		}
+	}
+
+	if err := c.writeOKPacket(&PacketOK{statusFlags: c.StatusFlags}); err != nil {
+		log.Errorf("Error writing ComChangeUser OK packet to %s: %v", c, err)
+		return false
+	}
+	return true
+}
+
 func (c *Conn) handleComStmtReset(data []byte) bool {
 	stmtID, ok := c.parseComStmtReset(data)
 	c.recycleReadPacket()
@@ -1055,6 +1178,7 @@ func (c *Conn) handleComStmtReset(data []byte) bool {
 		}
 	}
 
+	prepare.closeCursor()
 	if prepare.BindVars != nil {
 		for k := range prepare.BindVars {
 			prepare.BindVars[k] = nil
@@ -1107,7 +1231,7 @@ func (c *Conn) handleComStmtExecute(handler Handler, data []byte) (kontinue bool
 		}
 	}()
 	queryStart := time.Now()
-	stmtID, _, err := c.parseComStmtExecute(c.PrepareData, data)
+	stmtID, cursorType, err := c.parseComStmtExecute(c.PrepareData, data)
 	c.recycleReadPacket()
 
 	if stmtID != uint32(0) {
@@ -1122,10 +1246,21 @@ func (c *Conn) handleComStmtExecute(handler Handler, data []byte) (kontinue bool
 		return c.writeErrorPacketFromErrorAndLog(err)
 	}
 
+	// Executing the statement again closes its previous cursor.
+	prepare := c.PrepareData[stmtID]
+	prepare.closeCursor()
+	prepare.CursorType = cursorType
+	if cursorType&CursorTypeReadOnly != 0 {
+		if !c.executeWithCursor(handler, prepare) {
+			return false
+		}
+		timings.Record(queryTimingKey, queryStart)
+		return true
+	}
+
 	fieldSent := false
 	// sendFinished is set if the response should just be an OK packet.
 	sendFinished := false
-	prepare := c.PrepareData[stmtID]
 	err = handler.ComStmtExecute(c, prepare, func(qr *sqltypes.Result) error {
 		if sendFinished {
 			// Failsafe: Unreachable if server is well-behaved.
@@ -1324,11 +1459,14 @@ func (c *Conn) handleComQuery(handler Handler, data []byte) (kontinue bool) {
 	}()
 
 	queryStart := time.Now()
-	query := c.parseComQuery(data)
+	query, err := c.parseComQuery(data)
 	c.recycleReadPacket()
+	if err != nil {
+		log.Errorf("Conn %v: Error parsing query: %v", c, err)
+		return c.writeErrorPacketFromErrorAndLog(err)
+	}
 
 	var queries []string
-	var err error
 	if c.Capabilities&CapabilityClientMultiStatements != 0 {
 		queries, err = handler.Env().Parser().SplitStatementToPieces(query)
 		if err != nil {
@@ -1641,13 +1779,35 @@ func (c *Conn) GetRawConn() net.Conn {
 	return c.conn
 }
 
-// CancelCtx aborts an existing running query
+// CancelCtx aborts an existing running query, and the executions of the open cursors
 func (c *Conn) CancelCtx() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if c.cancel != nil {
 		c.cancel()
 	}
+	for _, cancel := range c.cursorCancels {
+		cancel()
+	}
+}
+
+// AddCursorCancelCtx adds the cancel function of the execution of a cursor, which
+// outlives the command that opened it. CancelCtx calls it along with the cancel
+// function of the running query, until the returned function is called.
+func (c *Conn) AddCursorCancelCtx(cancel context.CancelFunc) (remove func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cursorCancels == nil {
+		c.cursorCancels = make(map[int]context.CancelFunc)
+	}
+	c.lastCursorCancel++
+	id := c.lastCursorCancel
+	c.cursorCancels[id] = cancel
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.cursorCancels, id)
+	}
 }
 
 // UpdateCancelCtx updates the cancel function on the connection.
@@ -64,6 +64,10 @@ type ConnParams struct {
 	// FlushDelay is the delay after which buffered response will be flushed to the client.
 	FlushDelay time.Duration
 
+	// Compression is the algorithm to compress the protocol with, if the
+	// server supports it. The protocol is not compressed if it is empty.
+	Compression CompressionAlgorithm
+
 	TruncateErrLen int
 }
 
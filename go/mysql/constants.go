@@ -76,8 +76,10 @@ const (
 	// CLIENT_NO_SCHEMA 1 << 4
 	// Do not permit database.table.column. We do permit it.
 
-	// CLIENT_COMPRESS 1 << 5
-	// We do not support compression. CPU is usually our bottleneck.
+	// CapabilityClientCompress is CLIENT_COMPRESS.
+	// Can use zlib compression of the protocol. Only offered when
+	// enabled, as CPU is usually our bottleneck.
+	CapabilityClientCompress = 1 << 5
 
 	// CLIENT_ODBC 1 << 6
 	// No special behavior since 3.22.
@@ -148,6 +150,18 @@ const (
 	// CapabilityClientDeprecateEOF is CLIENT_DEPRECATE_EOF
 	// Expects an OK (instead of EOF) after the resultset rows of a Text Resultset.
 	CapabilityClientDeprecateEOF = 1 << 24
+
+	// CLIENT_OPTIONAL_RESULTSET_METADATA 1 << 25
+	// Not supported.
+
+	// CapabilityClientZstdCompressionAlgorithm is CLIENT_ZSTD_COMPRESSION_ALGORITHM.
+	// Can use zstd compression of the protocol. The compression level
+	// is sent at the end of Protocol::HandshakeResponse41.
+	CapabilityClientZstdCompressionAlgorithm = 1 << 26
+
+	// CapabilityClientQueryAttributes is CLIENT_QUERY_ATTRIBUTES.
+	// Can send query attributes with COM_QUERY and COM_STMT_EXECUTE.
+	CapabilityClientQueryAttributes = 1 << 27
 )
 
 // Status flags. They are returned by the server in a few cases.
@@ -179,6 +193,25 @@ const (
 	ServerSessionStateChanged uint16 = 0x4000
 )
 
+// Cursor type flags of COM_STMT_EXECUTE.
+// Originally found in include/mysql/mysql_com.h
+const (
+	// CursorTypeNoCursor is CURSOR_TYPE_NO_CURSOR.
+	CursorTypeNoCursor byte = 0x00
+
+	// CursorTypeReadOnly is CURSOR_TYPE_READ_ONLY. The rows of the statement
+	// are then fetched with COM_STMT_FETCH.
+	CursorTypeReadOnly byte = 0x01
+
+	// CURSOR_TYPE_FOR_UPDATE 0x02 and CURSOR_TYPE_SCROLLABLE 0x04
+	// are not supported by MySQL either.
+
+	// ParameterCountAvailable is PARAMETER_COUNT_AVAILABLE. The parameter
+	// count is sent even if the statement has no parameters, to send query
+	// attributes.
+	ParameterCountAvailable byte = 0x08
+)
+
 // State Change Information
 const (
 	// one or more system variables changed.
@@ -209,6 +242,9 @@ const (
 	// ComPing is COM_PING.
 	ComPing = 0x0e
 
+	// ComChangeUser is COM_CHANGE_USER.
+	ComChangeUser = 0x11
+
 	// ComBinlogDump is COM_BINLOG_DUMP.
 	ComBinlogDump = 0x12
 
@@ -230,7 +266,7 @@ const (
 	// ComStmtReset is COM_STMT_RESET
 	ComStmtReset = 0x1a
 
-	//ComStmtFetch is COM_STMT_FETCH
+	// ComStmtFetch is COM_STMT_FETCH
 	ComStmtFetch = 0x1c
 
 	// ComSetOption is COM_SET_OPTION
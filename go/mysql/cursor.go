@@ -0,0 +1,227 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"errors"
+	"io"
+	"iter"
+
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/log"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// cursor is a read-only cursor opened by a COM_STMT_EXECUTE, whose rows
+// are returned in chunks by COM_STMT_FETCH.
+//
+// The execution of the statement by the Handler is pulled as an iterator:
+// it only runs while the connection waits for more rows, and is suspended
+// in the callback in between. So it never runs concurrently with the other
+// commands of the connection, and only buffers the rows of one result.
+type cursor struct {
+	next func() (*sqltypes.Result, error, bool)
+	stop func()
+
+	fields []*querypb.Field
+	rows   [][]sqltypes.Value
+	done   bool
+}
+
+// newCursor starts the execution of the prepared statement by the handler.
+// Nothing runs until the first result is pulled.
+func newCursor(c *Conn, handler Handler, prepare *PrepareData) *cursor {
+	next, stop := iter.Pull2(func(yield func(*sqltypes.Result, error) bool) {
+		err := handler.ComStmtExecute(c, prepare, func(qr *sqltypes.Result) error {
+			if !yield(qr, nil) {
+				// The cursor was closed, abort the execution.
+				return io.EOF
+			}
+			return nil
+		})
+		if err != nil {
+			yield(nil, err)
+		}
+	})
+	return &cursor{next: next, stop: stop}
+}
+
+// nextResult resumes the execution until the next result, and returns nil
+// once the execution is over.
+func (cur *cursor) nextResult() (*sqltypes.Result, error) {
+	if cur.done {
+		return nil, nil
+	}
+	qr, err, ok := cur.next()
+	if !ok || err != nil {
+		cur.done = true
+		return nil, err
+	}
+	return qr, nil
+}
+
+// fetch returns up to numRows rows, and whether they are the last ones.
+func (cur *cursor) fetch(numRows int) ([][]sqltypes.Value, bool, error) {
+	for len(cur.rows) < numRows && !cur.done {
+		qr, err := cur.nextResult()
+		if err != nil {
+			return nil, false, err
+		}
+		if qr != nil {
+			cur.rows = append(cur.rows, qr.Rows...)
+		}
+	}
+	n := min(numRows, len(cur.rows))
+	rows := cur.rows[:n:n]
+	cur.rows = cur.rows[n:]
+	return rows, cur.done && len(cur.rows) == 0, nil
+}
+
+// close aborts the execution if it is still running, and waits for the
+// handler to return.
+func (cur *cursor) close() {
+	cur.stop()
+	cur.done = true
+	cur.rows = nil
+}
+
+// closeCursor closes the cursor of the prepared statement, if any.
+func (prepare *PrepareData) closeCursor() {
+	if prepare.cursor != nil {
+		prepare.cursor.close()
+		prepare.cursor = nil
+	}
+}
+
+// closeCursors closes the cursors of all the prepared statements, before
+// they are forgotten, or the connection is closed.
+func (c *Conn) closeCursors() {
+	for _, prepare := range c.PrepareData {
+		prepare.closeCursor()
+	}
+}
+
+// executeWithCursor executes the prepared statement, and keeps a cursor open
+// on its rows. Only the fields are sent, with the ServerStatusCursorExists
+// status flag. Statements without fields are answered as usual, with an OK
+// packet.
+func (c *Conn) executeWithCursor(handler Handler, prepare *PrepareData) (kontinue bool) {
+	cur := newCursor(c, handler, prepare)
+	qr, err := cur.nextResult()
+	if err == nil && qr == nil {
+		// This is just a failsafe. Should never happen.
+		err = sqlerror.NewSQLErrorFromError(errors.New("unexpected: query ended without no results and no error"))
+	}
+	if err != nil {
+		cur.close()
+		return c.writeErrorPacketFromErrorAndLog(err)
+	}
+
+	if len(qr.Fields) == 0 {
+		// Let the handler finish before sending the OK packet, the
+		// status flags may still change.
+		for !cur.done {
+			if _, err := cur.nextResult(); err != nil {
+				return c.writeErrorPacketFromErrorAndLog(err)
+			}
+		}
+		ok := PacketOK{
+			affectedRows:     qr.RowsAffected,
+			lastInsertID:     qr.InsertID,
+			statusFlags:      c.StatusFlags,
+			sessionStateData: qr.SessionStateChanges,
+		}
+		if err := c.writeOKPacket(&ok); err != nil {
+			log.Errorf("Error writing result to %s: %v", c, err)
+			return false
+		}
+		return true
+	}
+
+	cur.fields = qr.Fields
+	cur.rows = qr.Rows
+	prepare.cursor = cur
+
+	if err := c.sendColumnCount(uint64(len(qr.Fields))); err != nil {
+		log.Errorf("Error writing fields to %s: %v", c, err)
+		return false
+	}
+	for _, field := range qr.Fields {
+		if err := c.writeColumnDefinition(field); err != nil {
+			log.Errorf("Error writing fields to %s: %v", c, err)
+			return false
+		}
+	}
+	if err := c.writeEndResultWithFlags(c.StatusFlags|ServerStatusCursorExists, 0, 0, 0); err != nil {
+		log.Errorf("Error writing fields to %s: %v", c, err)
+		return false
+	}
+	return true
+}
+
+// handleComStmtFetch sends the next rows of the cursor of a prepared
+// statement. The cursor is closed once its last row was sent.
+func (c *Conn) handleComStmtFetch(handler Handler, data []byte) (kontinue bool) {
+	c.startWriterBuffering()
+	defer func() {
+		if err := c.endWriterBuffering(); err != nil {
+			log.Errorf("conn %v: flush() failed: %v", c.ID(), err)
+			kontinue = false
+		}
+	}()
+
+	stmtID, numRows, ok := c.parseComStmtFetch(data)
+	c.recycleReadPacket()
+	if !ok {
+		log.Errorf("Got malformed ComStmtFetch packet from client %v, returning error", c.ConnectionID)
+		return c.writeErrorAndLog(sqlerror.ERUnknownComError, sqlerror.SSNetError, "error handling ComStmtFetch packet")
+	}
+
+	prepare, ok := c.PrepareData[stmtID]
+	if !ok {
+		return c.writeErrorAndLog(sqlerror.ERUnknownStmtHandler, sqlerror.SSUnknownSQLState, "Unknown prepared statement handler (%d) given to mysqld_stmt_fetch", stmtID)
+	}
+	if prepare.cursor == nil {
+		return c.writeErrorAndLog(sqlerror.ERStmtHasNoOpenCursor, sqlerror.SSUnknownSQLState, "The statement (%d) has no open cursor.", stmtID)
+	}
+
+	cur := prepare.cursor
+	rows, last, err := cur.fetch(int(numRows))
+	if err != nil {
+		prepare.closeCursor()
+		return c.writeErrorPacketFromErrorAndLog(err)
+	}
+	for _, row := range rows {
+		if err := c.writeBinaryRow(cur.fields, row); err != nil {
+			log.Errorf("Error writing row to %s: %v", c, err)
+			return false
+		}
+	}
+
+	flags := c.StatusFlags | ServerStatusCursorExists
+	if last {
+		flags |= ServerStatusLastRowSent
+		prepare.closeCursor()
+	}
+	if err := c.writeEndResultWithFlags(flags, 0, 0, handler.WarningCount(c)); err != nil {
+		log.Errorf("Error writing result to %s: %v", c, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/sqltypes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// cursorHandler streams its results, one callback each, and records how
+// far the execution went.
+type cursorHandler struct {
+	testRun
+	results  []*sqltypes.Result
+	streamed int
+	finished bool
+	aborted  bool
+}
+
+func (h *cursorHandler) ComStmtExecute(c *Conn, prepare *PrepareData, callback func(*sqltypes.Result) error) error {
+	h.streamed, h.finished, h.aborted = 0, false, false
+	for _, qr := range h.results {
+		if err := callback(qr); err != nil {
+			h.aborted = true
+			return err
+		}
+		h.streamed++
+	}
+	h.finished = true
+	return nil
+}
+
+func createStmtExecutePacket(stmtID uint32, cursorType byte) []byte {
+	packet := []byte{0, 0, 0, 0, ComStmtExecute}
+	packet = binary.LittleEndian.AppendUint32(packet, stmtID)
+	packet = append(packet, cursorType)
+	packet = binary.LittleEndian.AppendUint32(packet, 1) // iteration count
+	return packet
+}
+
+func createStmtFetchPacket(stmtID uint32, numRows uint32) []byte {
+	packet := []byte{0, 0, 0, 0, ComStmtFetch}
+	packet = binary.LittleEndian.AppendUint32(packet, stmtID)
+	packet = binary.LittleEndian.AppendUint32(packet, numRows)
+	return packet
+}
+
+// readFetchedRows reads binary rows up to the EOF packet, and returns their
+// count, and the status flags of the EOF packet.
+func readFetchedRows(t *testing.T, cConn *Conn) (int, uint16) {
+	rows := 0
+	for {
+		data, err := cConn.ReadPacket()
+		require.NoError(t, err)
+		if cConn.isEOFPacket(data) {
+			_, flags, err := parseEOFPacket(data)
+			require.NoError(t, err)
+			return rows, flags
+		}
+		require.EqualValues(t, OKPacket, data[0], "binary row header")
+		rows++
+	}
+}
+
+func TestCursor(t *testing.T) {
+	listener, sConn, cConn := createSocketPair(t)
+	defer func() {
+		listener.Close()
+		sConn.Close()
+		cConn.Close()
+	}()
+
+	fields := []*querypb.Field{{Name: "id", Type: querypb.Type_INT64}}
+	row := []sqltypes.Value{sqltypes.NewInt64(1)}
+	handler := &cursorHandler{
+		testRun: testRun{t: t},
+		results: []*sqltypes.Result{
+			{Fields: fields},
+			{Rows: [][]sqltypes.Value{row, row, row}},
+			{Rows: [][]sqltypes.Value{row, row, row}},
+		},
+	}
+	sConn.PrepareData[1] = &PrepareData{StatementID: 1, PrepareStmt: "select id from t"}
+
+	fetch := func(numRows uint32) (int, uint16) {
+		cConn.resetSequence()
+		require.NoError(t, cConn.writePacket(createStmtFetchPacket(1, numRows)))
+		require.True(t, sConn.handleNextCommand(handler))
+		return readFetchedRows(t, cConn)
+	}
+
+	execute := func() {
+		cConn.resetSequence()
+		require.NoError(t, cConn.writePacket(createStmtExecutePacket(1, CursorTypeReadOnly)))
+		require.True(t, sConn.handleNextCommand(handler))
+
+		// Only the fields are sent.
+		data, err := cConn.ReadPacket()
+		require.NoError(t, err)
+		require.Equal(t, []byte{1}, data)
+		field := &querypb.Field{}
+		require.NoError(t, cConn.readColumnDefinition(field, 0))
+		assert.Equal(t, "id", field.Name)
+		rows, flags := readFetchedRows(t, cConn)
+		assert.Zero(t, rows)
+		assert.NotZero(t, flags&ServerStatusCursorExists)
+	}
+
+	// The execution is suspended once the fields are sent.
+	execute()
+	assert.Equal(t, 0, handler.streamed)
+
+	// Rows are returned in fetch size chunks, across results. The
+	// execution is suspended in the callback of the last result pulled.
+	rows, flags := fetch(2)
+	assert.Equal(t, 2, rows)
+	assert.Zero(t, flags&ServerStatusLastRowSent)
+	assert.Equal(t, 1, handler.streamed)
+
+	rows, flags = fetch(2)
+	assert.Equal(t, 2, rows)
+	assert.Zero(t, flags&ServerStatusLastRowSent)
+	assert.Equal(t, 2, handler.streamed)
+
+	rows, flags = fetch(10)
+	assert.Equal(t, 2, rows)
+	assert.NotZero(t, flags&ServerStatusLastRowSent)
+	assert.True(t, handler.finished)
+
+	// The cursor is closed after its last row.
+	cConn.resetSequence()
+	require.NoError(t, cConn.writePacket(createStmtFetchPacket(1, 1)))
+	require.True(t, sConn.handleNextCommand(handler))
+	data, err := cConn.ReadPacket()
+	require.NoError(t, err)
+	assert.Equal(t, sqlerror.ERStmtHasNoOpenCursor, ParseErrorPacket(data).(*sqlerror.SQLError).Number())
+
+	// Closing the statement aborts the execution.
+	execute()
+	fetch(1)
+	assert.False(t, handler.aborted)
+	cConn.resetSequence()
+	require.NoError(t, cConn.writePacket([]byte{0, 0, 0, 0, ComStmtClose, 1, 0, 0, 0}))
+	require.True(t, sConn.handleNextCommand(handler))
+	assert.True(t, handler.aborted)
+	assert.Empty(t, sConn.PrepareData)
+}
+
+func TestCursorWithoutFields(t *testing.T) {
+	listener, sConn, cConn := createSocketPair(t)
+	defer func() {
+		listener.Close()
+		sConn.Close()
+		cConn.Close()
+	}()
+
+	handler := &cursorHandler{
+		testRun: testRun{t: t},
+		results: []*sqltypes.Result{{RowsAffected: 3}},
+	}
+	sConn.PrepareData[1] = &PrepareData{StatementID: 1, PrepareStmt: "delete from t"}
+
+	require.NoError(t, cConn.writePacket(createStmtExecutePacket(1, CursorTypeReadOnly)))
+	require.True(t, sConn.handleNextCommand(handler))
+	qr, _, _, err := cConn.ReadQueryResult(0, false)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, qr.RowsAffected)
+	assert.True(t, handler.finished)
+	assert.Nil(t, sConn.PrepareData[1].cursor)
+}
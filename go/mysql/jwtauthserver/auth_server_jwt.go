@@ -0,0 +1,271 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jwtauthserver
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/vt/log"
+)
+
+// Config is the configuration of AuthServerJwt.
+type Config struct {
+	// JWKSFile is the path to the JSON Web Key Set of the identity
+	// provider, whose keys verify the signatures of the tokens.
+	JWKSFile string
+	// ReloadInterval is the interval at which JWKSFile is checked for
+	// changes. It is only reloaded on SIGHUP if zero.
+	ReloadInterval time.Duration
+	// Issuer must be the iss claim of the tokens.
+	Issuer string
+	// Audience must be in the aud claim of the tokens.
+	Audience string
+	// UsernameClaim is the claim holding the Vitess username. Nested
+	// claims are separated with dots.
+	UsernameClaim string
+	// GroupsClaim is the claim holding the groups of the user, used by
+	// table ACLs. Nested claims are separated with dots.
+	GroupsClaim string
+	// ClockSkew is the leeway given when checking the expiry and the
+	// start of validity of the tokens.
+	ClockSkew time.Duration
+}
+
+// AuthServerJwt implements AuthServer with JSON Web Tokens, like the ID and
+// access tokens issued by OIDC providers. The token is sent as the password,
+// with the mysql_clear_password method, which the server only accepts over
+// TLS. It authenticates the connection until it is closed, even once the
+// token expires.
+type AuthServerJwt struct {
+	methods []mysql.AuthMethod
+	config  Config
+	now     func() time.Time
+
+	mu   sync.Mutex
+	keys []*key
+	// jwks is the content of the JWKS file when it was last loaded.
+	jwks []byte
+
+	sigChan chan os.Signal
+	ticker  *time.Ticker
+}
+
+// Init is public so it can be called from plugin_auth_jwt.go (go/cmd/vtgate)
+func Init(config Config) {
+	if config.JWKSFile == "" {
+		log.Infof("Not configuring AuthServerJwt, as --mysql-auth-jwt-jwks-file is empty.")
+		return
+	}
+	if config.Issuer == "" || config.Audience == "" {
+		log.Exitf("If using JWT auth server, --mysql-auth-jwt-issuer and --mysql-auth-jwt-audience are required.")
+	}
+
+	authServerJwt, err := newAuthServerJwt(config)
+	if err != nil {
+		log.Exitf("Error initializing JWT auth server: %v", err)
+	}
+	mysql.RegisterAuthServer("jwt", authServerJwt)
+}
+
+func newAuthServerJwt(config Config) (*AuthServerJwt, error) {
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = "sub"
+	}
+	a := &AuthServerJwt{
+		config: config,
+		now:    time.Now,
+	}
+	a.methods = []mysql.AuthMethod{mysql.NewMysqlClearAuthMethod(a, a)}
+
+	if err := a.reload(); err != nil {
+		return nil, err
+	}
+	a.installSignalHandlers()
+	return a, nil
+}
+
+// AuthMethods returns the list of registered auth methods
+// implemented by this auth server.
+func (a *AuthServerJwt) AuthMethods() []mysql.AuthMethod {
+	return a.methods
+}
+
+// DefaultAuthMethodDescription returns MysqlNativePassword as the default
+// authentication method for the auth server implementation. The client is
+// then switched to mysql_clear_password.
+func (a *AuthServerJwt) DefaultAuthMethodDescription() mysql.AuthMethodDescription {
+	return mysql.MysqlNativePassword
+}
+
+// HandleUser is part of the UserValidator interface. We
+// handle any user here since the token tells who it is.
+func (a *AuthServerJwt) HandleUser(user string) bool {
+	return true
+}
+
+// UserEntryWithPassword is part of the PlainTextStorage interface
+// and called after the token is sent by the client as the password.
+func (a *AuthServerJwt) UserEntryWithPassword(conn *mysql.Conn, user string, password string, remoteAddr net.Addr) (mysql.Getter, error) {
+	userData, err := a.validate(user, password)
+	if err != nil {
+		log.Warningf("Rejected JWT of user '%v' from %v: %v", user, remoteAddr, err)
+		return &mysql.StaticUserData{}, sqlerror.NewSQLErrorf(sqlerror.ERAccessDeniedError, sqlerror.SSAccessDeniedError, "Access denied for user '%v'", user)
+	}
+	return userData, nil
+}
+
+// validate verifies the token, and checks its claims. The username claim
+// must match the user the client connects as, if any.
+func (a *AuthServerJwt) validate(user, token string) (*mysql.StaticUserData, error) {
+	a.mu.Lock()
+	keys := a.keys
+	a.mu.Unlock()
+
+	c, err := parseToken(token, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	if iss, _ := c.getString("iss"); iss != a.config.Issuer {
+		return nil, fmt.Errorf("issuer %q is not %q", iss, a.config.Issuer)
+	}
+	aud, err := c.getStrings("aud")
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(aud, a.config.Audience) {
+		return nil, fmt.Errorf("audience %q does not contain %q", aud, a.config.Audience)
+	}
+
+	now := a.now()
+	exp, ok, err := c.getTime("exp")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("token has no expiry")
+	}
+	if now.After(exp.Add(a.config.ClockSkew)) {
+		return nil, fmt.Errorf("token expired at %v", exp)
+	}
+	nbf, ok, err := c.getTime("nbf")
+	if err != nil {
+		return nil, err
+	}
+	if ok && now.Before(nbf.Add(-a.config.ClockSkew)) {
+		return nil, fmt.Errorf("token is not valid before %v", nbf)
+	}
+
+	username, _ := c.getString(a.config.UsernameClaim)
+	if username == "" {
+		return nil, fmt.Errorf("token has no %s claim", a.config.UsernameClaim)
+	}
+	if user != "" && user != username {
+		return nil, fmt.Errorf("token is for user '%v'", username)
+	}
+	var groups []string
+	if a.config.GroupsClaim != "" {
+		if groups, err = c.getStrings(a.config.GroupsClaim); err != nil {
+			return nil, err
+		}
+	}
+	return &mysql.StaticUserData{Username: username, Groups: groups}, nil
+}
+
+// reload loads the JWKS file. The previous keys are kept if it fails.
+func (a *AuthServerJwt) reload() error {
+	data, err := os.ReadFile(a.config.JWKSFile)
+	if err != nil {
+		return fmt.Errorf("failed to read --mysql-auth-jwt-jwks-file: %w", err)
+	}
+	return a.load(data)
+}
+
+func (a *AuthServerJwt) load(data []byte) error {
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.keys = keys
+	a.jwks = data
+	a.mu.Unlock()
+	return nil
+}
+
+// reloadIfChanged reloads the JWKS file if its content changed since
+// it was last loaded.
+func (a *AuthServerJwt) reloadIfChanged() {
+	data, err := os.ReadFile(a.config.JWKSFile)
+	if err != nil {
+		log.Errorf("Failed to read --mysql-auth-jwt-jwks-file: %v", err)
+		return
+	}
+	a.mu.Lock()
+	changed := !bytes.Equal(data, a.jwks)
+	a.mu.Unlock()
+	if !changed {
+		return
+	}
+	if err := a.load(data); err != nil {
+		log.Errorf("Error reloading JWKS, keeping the previous keys: %v", err)
+		return
+	}
+	log.Infof("Reloaded JWKS from %s", a.config.JWKSFile)
+}
+
+func (a *AuthServerJwt) installSignalHandlers() {
+	a.sigChan = make(chan os.Signal, 1)
+	signal.Notify(a.sigChan, syscall.SIGHUP)
+	go func() {
+		for range a.sigChan {
+			if err := a.reload(); err != nil {
+				log.Errorf("Error reloading JWKS, keeping the previous keys: %v", err)
+			}
+		}
+	}()
+
+	// If duration is set, it will check the file for changes every interval
+	if a.config.ReloadInterval > 0 {
+		a.ticker = time.NewTicker(a.config.ReloadInterval)
+		go func() {
+			for range a.ticker.C {
+				a.reloadIfChanged()
+			}
+		}()
+	}
+}
+
+func (a *AuthServerJwt) close() {
+	if a.ticker != nil {
+		a.ticker.Stop()
+	}
+	if a.sigChan != nil {
+		signal.Stop(a.sigChan)
+	}
+}
@@ -0,0 +1,331 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jwtauthserver
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/mysql/sqlerror"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+var b64 = base64.RawURLEncoding
+
+func encodeSegment(t *testing.T, v any) string {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return b64.EncodeToString(data)
+}
+
+// signToken returns a token with the claims, signed with the private key.
+func signToken(t *testing.T, alg, kid string, privateKey crypto.Signer, claims map[string]any) string {
+	signingInput := encodeSegment(t, map[string]string{"alg": alg, "kid": kid, "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	var err error
+	switch alg {
+	case "RS256":
+		signature, err = privateKey.Sign(rand.Reader, digest[:], crypto.SHA256)
+	case "PS256":
+		signature, err = privateKey.Sign(rand.Reader, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256})
+	case "ES256":
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, privateKey.(*ecdsa.PrivateKey), digest[:])
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case "EdDSA":
+		signature, err = privateKey.Sign(rand.Reader, []byte(signingInput), crypto.Hash(0))
+	}
+	require.NoError(t, err)
+	return signingInput + "." + b64.EncodeToString(signature)
+}
+
+type testKeys struct {
+	rsa     *rsa.PrivateKey
+	ecdsa   *ecdsa.PrivateKey
+	ed25519 ed25519.PrivateKey
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return &testKeys{rsa: rsaKey, ecdsa: ecdsaKey, ed25519: ed25519Key}
+}
+
+func (k *testKeys) jwks() map[string]any {
+	rsaKey := &k.rsa.PublicKey
+	ecdsaKey := &k.ecdsa.PublicKey
+	return map[string]any{"keys": []map[string]string{{
+		"kty": "RSA", "kid": "rsa", "use": "sig",
+		"n": b64.EncodeToString(rsaKey.N.Bytes()),
+		"e": b64.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+	}, {
+		"kty": "EC", "kid": "ec", "alg": "ES256", "crv": "P-256",
+		"x": b64.EncodeToString(ecdsaKey.X.FillBytes(make([]byte, 32))),
+		"y": b64.EncodeToString(ecdsaKey.Y.FillBytes(make([]byte, 32))),
+	}, {
+		"kty": "OKP", "kid": "ed", "crv": "Ed25519",
+		"x": b64.EncodeToString(k.ed25519.Public().(ed25519.PublicKey)),
+	}, {
+		"kty": "oct", "use": "enc", "k": "c2VjcmV0",
+	}}}
+}
+
+func writeJWKS(t *testing.T, path string, jwks any) {
+	data, err := json.Marshal(jwks)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func TestAuthServerJwt(t *testing.T) {
+	keys := newTestKeys(t)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, jwksFile, keys.jwks())
+
+	a, err := newAuthServerJwt(Config{
+		JWKSFile:    jwksFile,
+		Issuer:      "https://issuer.example.com",
+		Audience:    "vtgate",
+		GroupsClaim: "realm_access.roles",
+		ClockSkew:   time.Minute,
+	})
+	require.NoError(t, err)
+	defer a.close()
+	now := time.Unix(1700000000, 0)
+	a.now = func() time.Time { return now }
+
+	validClaims := func() map[string]any {
+		return map[string]any{
+			"iss":          "https://issuer.example.com",
+			"aud":          []string{"vtgate", "other"},
+			"sub":          "alice",
+			"exp":          now.Add(time.Hour).Unix(),
+			"realm_access": map[string]any{"roles": []string{"readers", "writers"}},
+		}
+	}
+
+	for _, signer := range []struct {
+		alg, kid string
+		key      crypto.Signer
+	}{
+		{"RS256", "rsa", keys.rsa},
+		{"PS256", "rsa", keys.rsa},
+		{"ES256", "ec", keys.ecdsa},
+		{"EdDSA", "ed", keys.ed25519},
+		{"EdDSA", "", keys.ed25519},
+	} {
+		token := signToken(t, signer.alg, signer.kid, signer.key, validClaims())
+		getter, err := a.UserEntryWithPassword(nil, "alice", token, nil)
+		require.NoError(t, err, signer.alg)
+		assert.Equal(t, &querypb.VTGateCallerID{Username: "alice", Groups: []string{"readers", "writers"}}, getter.Get(), signer.alg)
+	}
+
+	// The user is taken from the token if the client sends none.
+	token := signToken(t, "RS256", "rsa", keys.rsa, validClaims())
+	getter, err := a.UserEntryWithPassword(nil, "", token, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", getter.Get().Username)
+
+	tcases := []struct {
+		name  string
+		user  string
+		token func() string
+		err   string
+	}{{
+		name:  "other user",
+		user:  "bob",
+		token: func() string { return signToken(t, "RS256", "rsa", keys.rsa, validClaims()) },
+		err:   "token is for user 'alice'",
+	}, {
+		name: "wrong issuer",
+		token: func() string {
+			claims := validClaims()
+			claims["iss"] = "https://other.example.com"
+			return signToken(t, "RS256", "rsa", keys.rsa, claims)
+		},
+		err: "issuer",
+	}, {
+		name: "wrong audience",
+		token: func() string {
+			claims := validClaims()
+			claims["aud"] = "other"
+			return signToken(t, "RS256", "rsa", keys.rsa, claims)
+		},
+		err: "audience",
+	}, {
+		name: "expired",
+		token: func() string {
+			claims := validClaims()
+			claims["exp"] = now.Add(-2 * time.Minute).Unix()
+			return signToken(t, "RS256", "rsa", keys.rsa, claims)
+		},
+		err: "token expired",
+	}, {
+		name: "no expiry",
+		token: func() string {
+			claims := validClaims()
+			delete(claims, "exp")
+			return signToken(t, "RS256", "rsa", keys.rsa, claims)
+		},
+		err: "token has no expiry",
+	}, {
+		name: "not yet valid",
+		token: func() string {
+			claims := validClaims()
+			claims["nbf"] = now.Add(2 * time.Minute).Unix()
+			return signToken(t, "RS256", "rsa", keys.rsa, claims)
+		},
+		err: "token is not valid before",
+	}, {
+		name: "no username",
+		token: func() string {
+			claims := validClaims()
+			delete(claims, "sub")
+			return signToken(t, "RS256", "rsa", keys.rsa, claims)
+		},
+		err: "token has no sub claim",
+	}, {
+		name: "invalid groups",
+		token: func() string {
+			claims := validClaims()
+			claims["realm_access"] = map[string]any{"roles": []int{1}}
+			return signToken(t, "RS256", "rsa", keys.rsa, claims)
+		},
+		err: "claim realm_access.roles is not a list of strings",
+	}, {
+		name: "unknown key",
+		token: func() string {
+			other := newTestKeys(t)
+			return signToken(t, "RS256", "rsa", other.rsa, validClaims())
+		},
+		err: "token signature is not verified",
+	}, {
+		name:  "algorithm of the key",
+		token: func() string { return signToken(t, "RS256", "ec", keys.rsa, validClaims()) },
+		err:   "token signature is not verified",
+	}, {
+		name: "tampered claims",
+		token: func() string {
+			token := signToken(t, "RS256", "rsa", keys.rsa, validClaims())
+			claims := validClaims()
+			claims["sub"] = "bob"
+			parts := strings.Split(token, ".")
+			return parts[0] + "." + encodeSegment(t, claims) + "." + parts[2]
+		},
+		user: "bob",
+		err:  "token signature is not verified",
+	}, {
+		name: "none algorithm",
+		token: func() string {
+			return encodeSegment(t, map[string]string{"alg": "none"}) + "." + encodeSegment(t, validClaims()) + "."
+		},
+		err: `unsupported signing algorithm "none"`,
+	}, {
+		name:  "not a token",
+		token: func() string { return "password" },
+		err:   "token is not a JWS",
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			token := tcase.token()
+			_, err := a.validate(tcase.user, token)
+			assert.ErrorContains(t, err, tcase.err)
+
+			_, err = a.UserEntryWithPassword(nil, tcase.user, token, nil)
+			assert.Equal(t, sqlerror.ERAccessDeniedError, err.(*sqlerror.SQLError).Number())
+		})
+	}
+}
+
+func TestAuthServerJwtReload(t *testing.T) {
+	keys := newTestKeys(t)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, jwksFile, keys.jwks())
+
+	a, err := newAuthServerJwt(Config{
+		JWKSFile:       jwksFile,
+		ReloadInterval: 10 * time.Millisecond,
+		Issuer:         "issuer",
+		Audience:       "vtgate",
+	})
+	require.NoError(t, err)
+	defer a.close()
+	assert.Equal(t, []mysql.AuthMethod{mysql.NewMysqlClearAuthMethod(a, a)}, a.AuthMethods())
+
+	claims := map[string]any{"iss": "issuer", "aud": "vtgate", "sub": "alice", "exp": time.Now().Add(time.Hour).Unix()}
+	oldToken := signToken(t, "RS256", "rsa", keys.rsa, claims)
+	_, err = a.validate("alice", oldToken)
+	require.NoError(t, err)
+
+	// The keys are rotated, the changed file is reloaded.
+	newKeys := newTestKeys(t)
+	writeJWKS(t, jwksFile, newKeys.jwks())
+	newToken := signToken(t, "RS256", "rsa", newKeys.rsa, claims)
+	assert.Eventually(t, func() bool {
+		_, err := a.validate("alice", newToken)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	_, err = a.validate("alice", oldToken)
+	assert.ErrorContains(t, err, "token signature is not verified")
+
+	// An invalid file is ignored.
+	require.NoError(t, os.WriteFile(jwksFile, []byte("{"), 0o600))
+	a.reloadIfChanged()
+	_, err = a.validate("alice", newToken)
+	assert.NoError(t, err)
+}
+
+func TestParseJWKS(t *testing.T) {
+	tcases := []struct {
+		jwks string
+		err  string
+	}{
+		{jwks: `{`, err: "error parsing JWKS"},
+		{jwks: `{"keys": []}`, err: "JWKS has no signing key"},
+		{jwks: `{"keys": [{"kty": "oct", "k": "c2VjcmV0"}]}`, err: `unsupported key type "oct"`},
+		{jwks: `{"keys": [{"kty": "RSA", "n": "", "e": "AQAB"}]}`, err: "invalid n"},
+		{jwks: `{"keys": [{"kty": "EC", "crv": "P-256", "x": "AQ", "y": "AQ"}]}`, err: "point is not on curve P-256"},
+		{jwks: `{"keys": [{"kty": "EC", "crv": "secp256k1", "x": "AQ", "y": "AQ"}]}`, err: `unsupported curve "secp256k1"`},
+		{jwks: `{"keys": [{"kty": "OKP", "crv": "Ed25519", "x": "AQ"}]}`, err: "invalid x size 1"},
+	}
+	for _, tcase := range tcases {
+		_, err := parseJWKS([]byte(tcase.jwks))
+		assert.ErrorContains(t, err, tcase.err, tcase.jwks)
+	}
+}
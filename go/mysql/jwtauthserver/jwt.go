@@ -0,0 +1,230 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jwtauthserver
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// verifier verifies the signature of a token with a public key.
+type verifier func(publicKey crypto.PublicKey, signingInput, signature []byte) error
+
+// verifiers are the supported signing algorithms of RFC 7518 and RFC 8037.
+// "none" and the HMAC algorithms are left out, as tokens are signed by the
+// private keys of the identity provider.
+var verifiers = map[string]verifier{
+	"RS256": verifyRSA(crypto.SHA256, false),
+	"RS384": verifyRSA(crypto.SHA384, false),
+	"RS512": verifyRSA(crypto.SHA512, false),
+	"PS256": verifyRSA(crypto.SHA256, true),
+	"PS384": verifyRSA(crypto.SHA384, true),
+	"PS512": verifyRSA(crypto.SHA512, true),
+	"ES256": verifyECDSA(crypto.SHA256, elliptic.P256()),
+	"ES384": verifyECDSA(crypto.SHA384, elliptic.P384()),
+	"ES512": verifyECDSA(crypto.SHA512, elliptic.P521()),
+	"EdDSA": verifyEdDSA,
+}
+
+var errKeyType = errors.New("key type does not match the algorithm")
+
+func verifyRSA(hash crypto.Hash, pss bool) verifier {
+	return func(publicKey crypto.PublicKey, signingInput, signature []byte) error {
+		pub, ok := publicKey.(*rsa.PublicKey)
+		if !ok {
+			return errKeyType
+		}
+		h := hash.New()
+		h.Write(signingInput)
+		if pss {
+			return rsa.VerifyPSS(pub, hash, h.Sum(nil), signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		return rsa.VerifyPKCS1v15(pub, hash, h.Sum(nil), signature)
+	}
+}
+
+func verifyECDSA(hash crypto.Hash, curve elliptic.Curve) verifier {
+	return func(publicKey crypto.PublicKey, signingInput, signature []byte) error {
+		pub, ok := publicKey.(*ecdsa.PublicKey)
+		if !ok || pub.Curve != curve {
+			return errKeyType
+		}
+		// The signature is the concatenation of r and s, of the size of the curve.
+		size := (curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("invalid signature size")
+		}
+		h := hash.New()
+		h.Write(signingInput)
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, h.Sum(nil), r, s) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+}
+
+func verifyEdDSA(publicKey crypto.PublicKey, signingInput, signature []byte) error {
+	pub, ok := publicKey.(ed25519.PublicKey)
+	if !ok {
+		return errKeyType
+	}
+	if !ed25519.Verify(pub, signingInput, signature) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// claims are the claims of a verified token.
+type claims map[string]any
+
+// parseToken verifies the signature of the token, a JWS in compact
+// serialization, with the keys, and returns its claims. They still
+// need to be validated.
+func parseToken(token string, keys []*key) (claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("token is not a JWS in compact serialization")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("invalid token header: %w", err)
+	}
+	verify, ok := verifiers[header.Alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token signature: %w", err)
+	}
+
+	// Without a key ID, any key of the JWKS may have signed the token.
+	signingInput := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, k := range keys {
+		if (header.Kid != "" && k.kid != header.Kid) || (k.alg != "" && k.alg != header.Alg) {
+			continue
+		}
+		if verify(k.publicKey, signingInput, signature) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, fmt.Errorf("token signature is not verified by the JWKS (kid %q, alg %s)", header.Kid, header.Alg)
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return nil, fmt.Errorf("invalid token claims: %w", err)
+	}
+	return c, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// lookup returns the claim, following the dots of the name through
+// nested objects, like "realm_access.roles".
+func (c claims) lookup(name string) (any, bool) {
+	var value any = map[string]any(c)
+	for _, part := range strings.Split(name, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[part]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// getString returns the claim if it is a string.
+func (c claims) getString(name string) (string, bool) {
+	value, _ := c.lookup(name)
+	s, ok := value.(string)
+	return s, ok
+}
+
+// getStrings returns the claim if it is a string, or a list of strings.
+func (c claims) getStrings(name string) ([]string, error) {
+	value, ok := c.lookup(name)
+	if !ok {
+		return nil, nil
+	}
+	switch value := value.(type) {
+	case string:
+		return []string{value}, nil
+	case []any:
+		strs := make([]string, 0, len(value))
+		for _, v := range value {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("claim %s is not a list of strings", name)
+			}
+			strs = append(strs, s)
+		}
+		return strs, nil
+	default:
+		return nil, fmt.Errorf("claim %s is not a string or a list of strings", name)
+	}
+}
+
+// getTime returns the claim if it is a NumericDate, the number of seconds
+// since the epoch.
+func (c claims) getTime(name string) (time.Time, bool, error) {
+	value, ok := c.lookup(name)
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	n, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, false, fmt.Errorf("claim %s is not a number", name)
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("claim %s is not a number: %w", name, err)
+	}
+	return time.UnixMilli(int64(f * 1000)), true, nil
+}
@@ -55,7 +55,7 @@ const (
 // Returns SQLError(CRServerGone) if it can't.
 func (c *Conn) WriteComQuery(query string) error {
 	// This is a new command, need to reset the sequence.
-	c.sequence = 0
+	c.resetSequence()
 
 	data, pos := c.startEphemeralPacketWithHeader(len(query) + 1)
 	data[pos] = ComQuery
@@ -574,8 +574,86 @@ func (c *Conn) readComQueryResponse(packetOk *PacketOK) (int, error) {
 // Server side methods.
 //
 
-func (c *Conn) parseComQuery(data []byte) string {
-	return string(data[1:])
+func (c *Conn) parseComQuery(data []byte) (string, error) {
+	if c.Capabilities&CapabilityClientQueryAttributes == 0 {
+		return string(data[1:]), nil
+	}
+
+	payload := data[1:]
+	paramsCount, pos, ok := readLenEncInt(payload, 0)
+	if !ok {
+		return "", sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter count failed")
+	}
+	// The parameter set count is always 1.
+	_, pos, ok = readLenEncInt(payload, pos)
+	if !ok {
+		return "", sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter set count failed")
+	}
+	if paramsCount == 0 {
+		return string(payload[pos:]), nil
+	}
+
+	bitMap, pos, ok := readBytes(payload, pos, int((paramsCount+7)/8))
+	if !ok {
+		return "", sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading NULL-bitmap failed")
+	}
+	// The new params bound flag is always set.
+	_, pos, ok = readByte(payload, pos)
+	if !ok {
+		return "", sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading new params bound flag failed")
+	}
+
+	var attributes []queryAttribute
+	for i := uint64(0); i < paramsCount; i++ {
+		var attr queryAttribute
+		attr.typ, attr.name, pos, ok = c.parseStmtParamType(payload, pos)
+		if !ok {
+			return "", sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter type failed")
+		}
+		attributes = append(attributes, attr)
+	}
+
+	c.QueryAttributes = make(map[string]string, len(attributes))
+	for i, attr := range attributes {
+		if bitMap[i/8]&(1<<uint(i%8)) > 0 {
+			continue
+		}
+		var val sqltypes.Value
+		val, pos, ok = c.parseStmtArgs(payload, attr.typ, pos)
+		if !ok {
+			return "", sqlerror.NewSQLErrorf(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "decoding query attribute value failed: %v", attr.typ)
+		}
+		c.QueryAttributes[attr.name] = val.ToString()
+	}
+
+	return string(payload[pos:]), nil
+}
+
+// parseStmtParamType parses the type of a parameter, followed by its name
+// if the client sends query attributes.
+func (c *Conn) parseStmtParamType(data []byte, pos int) (querypb.Type, string, int, bool) {
+	mysqlType, pos, ok := readByte(data, pos)
+	if !ok {
+		return 0, "", 0, false
+	}
+	flags, pos, ok := readByte(data, pos)
+	if !ok {
+		return 0, "", 0, false
+	}
+	// convert MySQL type to internal type.
+	typ, err := sqltypes.MySQLToType(mysqlType, int64(flags))
+	if err != nil {
+		return 0, "", 0, false
+	}
+
+	var name string
+	if c.Capabilities&CapabilityClientQueryAttributes != 0 {
+		name, pos, ok = readLenEncString(data, pos)
+		if !ok {
+			return 0, "", 0, false
+		}
+	}
+	return typ, name, pos, true
 }
 
 func (c *Conn) parseComSetOption(data []byte) (uint16, bool) {
@@ -617,8 +695,21 @@ func (c *Conn) parseComStmtExecute(prepareData map[uint32]*PrepareData, data []b
 		return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "iteration count is not equal to 1")
 	}
 
-	if prepare.ParamsCount > 0 {
-		bitMap, pos, ok = readBytes(payload, pos, (int(prepare.ParamsCount)+7)/8)
+	// With query attributes, the parameter count is sent, and the
+	// attributes come after the parameters of the statement.
+	paramsCount := uint64(prepare.ParamsCount)
+	if c.Capabilities&CapabilityClientQueryAttributes != 0 && (prepare.ParamsCount > 0 || cursorType&ParameterCountAvailable != 0) {
+		paramsCount, pos, ok = readLenEncInt(payload, pos)
+		if !ok {
+			return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter count failed")
+		}
+		if paramsCount < uint64(prepare.ParamsCount) {
+			return stmtID, 0, sqlerror.NewSQLErrorf(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "parameter count %d is lower than the statement's %d", paramsCount, prepare.ParamsCount)
+		}
+	}
+
+	if paramsCount > 0 {
+		bitMap, pos, ok = readBytes(payload, pos, int((paramsCount+7)/8))
 		if !ok {
 			return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading NULL-bitmap failed")
 		}
@@ -626,27 +717,23 @@ func (c *Conn) parseComStmtExecute(prepareData map[uint32]*PrepareData, data []b
 
 	newParamsBoundFlag, pos, ok := readByte(payload, pos)
 	if ok && newParamsBoundFlag == 0x01 {
-		var mysqlType, flags byte
-		for i := uint16(0); i < prepare.ParamsCount; i++ {
-			mysqlType, pos, ok = readByte(payload, pos)
+		prepare.attributes = nil
+		for i := uint64(0); i < paramsCount; i++ {
+			var attr queryAttribute
+			attr.typ, attr.name, pos, ok = c.parseStmtParamType(payload, pos)
 			if !ok {
 				return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter type failed")
 			}
-
-			flags, pos, ok = readByte(payload, pos)
-			if !ok {
-				return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter flags failed")
-			}
-
-			// convert MySQL type to internal type.
-			valType, err := sqltypes.MySQLToType(mysqlType, int64(flags))
-			if err != nil {
-				return stmtID, 0, sqlerror.NewSQLErrorf(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "MySQLToType(%v,%v) failed: %v", mysqlType, flags, err)
+			if i < uint64(prepare.ParamsCount) {
+				prepare.ParamsType[i] = int32(attr.typ)
+			} else {
+				prepare.attributes = append(prepare.attributes, attr)
 			}
-
-			prepare.ParamsType[i] = int32(valType)
 		}
 	}
+	if uint64(len(prepare.attributes)) != paramsCount-uint64(prepare.ParamsCount) {
+		return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "query attribute types were not bound")
+	}
 
 	for i := 0; i < len(prepare.ParamsType); i++ {
 		var val sqltypes.Value
@@ -669,6 +756,22 @@ func (c *Conn) parseComStmtExecute(prepareData map[uint32]*PrepareData, data []b
 		prepare.BindVars[parameterID] = sqltypes.ValueBindVariable(val)
 	}
 
+	if len(prepare.attributes) > 0 {
+		c.QueryAttributes = make(map[string]string, len(prepare.attributes))
+	}
+	for i, attr := range prepare.attributes {
+		j := int(prepare.ParamsCount) + i
+		if bitMap[j/8]&(1<<uint(j%8)) > 0 {
+			continue
+		}
+		var val sqltypes.Value
+		val, pos, ok = c.parseStmtArgs(payload, attr.typ, pos)
+		if !ok {
+			return stmtID, 0, sqlerror.NewSQLErrorf(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "decoding query attribute value failed: %v", attr.typ)
+		}
+		c.QueryAttributes[attr.name] = val.ToString()
+	}
+
 	return stmtID, cursorType, nil
 }
 
@@ -928,6 +1031,15 @@ func (c *Conn) parseComStmtReset(data []byte) (uint32, bool) {
 	return val, ok
 }
 
+func (c *Conn) parseComStmtFetch(data []byte) (uint32, uint32, bool) {
+	stmtID, pos, ok := readUint32(data, 1)
+	if !ok {
+		return 0, 0, false
+	}
+	numRows, _, ok := readUint32(data, pos)
+	return stmtID, numRows, ok
+}
+
 func (c *Conn) parseComInitDB(data []byte) string {
 	return string(data[1:])
 }
@@ -1054,6 +1166,12 @@ func (c *Conn) writeEndResult(more bool, affectedRows, lastInsertID uint64, warn
 	if more {
 		flags |= ServerMoreResultsExists
 	}
+	return c.writeEndResultWithFlags(flags, affectedRows, lastInsertID, warnings)
+}
+
+// writeEndResultWithFlags concludes the sending of a Result, or of its
+// fields, with the given status flags.
+func (c *Conn) writeEndResultWithFlags(flags uint16, affectedRows, lastInsertID uint64, warnings uint16) error {
 	if c.Capabilities&CapabilityClientDeprecateEOF == 0 {
 		if err := c.writeEOFPacket(flags, warnings); err != nil {
 			return err
@@ -328,6 +328,96 @@ func TestComStmtExecuteUpdStmt(t *testing.T) {
 	assert.EqualValues(t, querypb.Type_CHAR, prepData.ParamsType[28], "got: %s", querypb.Type(prepData.ParamsType[28]))
 }
 
+func TestComQueryWithAttributes(t *testing.T) {
+	sConn := &Conn{Capabilities: CapabilityClientQueryAttributes}
+
+	// Without attributes.
+	data := append([]byte{ComQuery, 0x00, 0x01}, "select 1"...)
+	query, err := sConn.parseComQuery(data)
+	require.NoError(t, err)
+	assert.Equal(t, "select 1", query)
+	assert.Nil(t, sConn.QueryAttributes)
+
+	// A string attribute, and a NULL one.
+	data = []byte{ComQuery, 0x02, 0x01, 0x02, 0x01}
+	data = append(data, 0xfe, 0x00, 0x0b)
+	data = append(data, "vt_workload"...)
+	data = append(data, 0xfe, 0x00, 0x01, 'n')
+	data = append(data, 0x04)
+	data = append(data, "olap"...)
+	data = append(data, "select 1"...)
+	query, err = sConn.parseComQuery(data)
+	require.NoError(t, err)
+	assert.Equal(t, "select 1", query)
+	assert.Equal(t, map[string]string{"vt_workload": "olap"}, sConn.QueryAttributes)
+
+	// Truncated packet.
+	_, err = sConn.parseComQuery(data[:8])
+	require.Error(t, err)
+
+	// Without the capability, the packet is the query.
+	sConn.Capabilities = 0
+	query, err = sConn.parseComQuery(append([]byte{ComQuery}, "select 1"...))
+	require.NoError(t, err)
+	assert.Equal(t, "select 1", query)
+}
+
+func TestComStmtExecuteWithAttributes(t *testing.T) {
+	sConn := &Conn{Capabilities: CapabilityClientQueryAttributes}
+	prepareDataMap := map[uint32]*PrepareData{
+		1: {
+			StatementID: 1,
+			ParamsCount: 1,
+			ParamsType:  make([]int32, 1),
+			BindVars:    map[string]*querypb.BindVariable{},
+		},
+		2: {
+			StatementID: 2,
+			BindVars:    map[string]*querypb.BindVariable{},
+		},
+	}
+
+	// One parameter, and one attribute, with their types.
+	data := []byte{ComStmtExecute, 0x01, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00}
+	data = append(data, 0x02, 0x00, 0x01)
+	data = append(data, 0x08, 0x00, 0x00)
+	data = append(data, 0xfe, 0x00, 0x01, 'a')
+	data = append(data, 0x2a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+	data = append(data, 0x01, 'b')
+	stmtID, _, err := sConn.parseComStmtExecute(prepareDataMap, data)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, stmtID)
+	assert.Equal(t, sqltypes.Int64BindVariable(42), prepareDataMap[1].BindVars["v1"])
+	assert.Equal(t, map[string]string{"a": "b"}, sConn.QueryAttributes)
+
+	// The types are not sent again.
+	prepareDataMap[1].BindVars = map[string]*querypb.BindVariable{}
+	data = []byte{ComStmtExecute, 0x01, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00}
+	data = append(data, 0x02, 0x00, 0x00)
+	data = append(data, 0x07, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+	data = append(data, 0x01, 'c')
+	_, _, err = sConn.parseComStmtExecute(prepareDataMap, data)
+	require.NoError(t, err)
+	assert.Equal(t, sqltypes.Int64BindVariable(7), prepareDataMap[1].BindVars["v1"])
+	assert.Equal(t, map[string]string{"a": "c"}, sConn.QueryAttributes)
+
+	// A statement without parameters sends the parameter count with
+	// ParameterCountAvailable.
+	sConn.QueryAttributes = nil
+	data = []byte{ComStmtExecute, 0x02, 0x00, 0x00, 0x00, ParameterCountAvailable, 0x01, 0x00, 0x00, 0x00}
+	data = append(data, 0x01, 0x00, 0x01)
+	data = append(data, 0xfe, 0x00, 0x01, 'a')
+	data = append(data, 0x01, 'd')
+	_, _, err = sConn.parseComStmtExecute(prepareDataMap, data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "d"}, sConn.QueryAttributes)
+
+	// The parameter count can't be lower than the statement's.
+	data = []byte{ComStmtExecute, 0x01, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00}
+	_, _, err = sConn.parseComStmtExecute(prepareDataMap, data)
+	require.Error(t, err)
+}
+
 func TestComStmtClose(t *testing.T) {
 	listener, sConn, cConn := createSocketPair(t)
 	defer func() {
@@ -112,7 +112,11 @@ type Handler interface {
 	ComPrepare(c *Conn, query string, bindVars map[string]*querypb.BindVariable) ([]*querypb.Field, error)
 
 	// ComStmtExecute is called when a connection receives a statement
-	// execute query.
+	// execute query. If prepare.CursorType asks for a cursor, the
+	// execution is suspended in the callback until the client fetches
+	// more rows, and it should rather stream the rows. Other commands
+	// of the connection may run meanwhile. The callback returns an
+	// error if the cursor is closed before the last row.
 	ComStmtExecute(c *Conn, prepare *PrepareData, callback func(*sqltypes.Result) error) error
 
 	// ComRegisterReplica is called when a connection receives a ComRegisterReplica request
@@ -133,6 +137,12 @@ type Handler interface {
 
 	ComResetConnection(c *Conn)
 
+	// ComChangeUser is called when a connection receives a COM_CHANGE_USER,
+	// after the new user has been authenticated, but before c.User and
+	// c.UserData are switched to it. The handler should reset the state of
+	// the connection as for a new connection.
+	ComChangeUser(c *Conn)
+
 	Env() *vtenv.Environment
 }
 
@@ -146,6 +156,7 @@ func (UnimplementedHandler) NewConnection(*Conn)      {}
 func (UnimplementedHandler) ConnectionReady(*Conn)    {}
 func (UnimplementedHandler) ConnectionClosed(*Conn)   {}
 func (UnimplementedHandler) ComResetConnection(*Conn) {}
+func (UnimplementedHandler) ComChangeUser(*Conn)      {}
 
 // Listener is the MySQL server protocol listener.
 type Listener struct {
@@ -182,6 +193,15 @@ type Listener struct {
 	// beyond which a warning is logged to identify the slow connection
 	SlowConnectWarnThreshold atomic.Int64
 
+	// CompressionAlgorithms are the algorithms the server offers to
+	// compress the protocol with. The protocol is never compressed
+	// if it is empty.
+	CompressionAlgorithms []CompressionAlgorithm
+
+	// EnableQueryAttributes makes the server accept query attributes
+	// from the clients. They are then parsed into Conn.QueryAttributes.
+	EnableQueryAttributes bool
+
 	// The following parameters are changed by the Accept routine.
 
 	// Incrementing ID for connection id.
@@ -391,7 +411,7 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32, acceptTime time.Ti
 	defer connCount.Add(-1)
 
 	// First build and send the server handshake packet.
-	serverAuthPluginData, err := c.writeHandshakeV10(l.ServerVersion, l.authServer, uint8(l.charset), l.TLSConfig.Load() != nil)
+	serverAuthPluginData, err := c.writeHandshakeV10(l.ServerVersion, l.authServer, uint8(l.charset), l.TLSConfig.Load() != nil, l.optionalCapabilities())
 	if err != nil {
 		if err != io.EOF {
 			log.Errorf("Cannot send HandshakeV10 packet to %s: %v", c, err)
@@ -450,6 +470,78 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32, acceptTime time.Ti
 		defer connCountByTLSVer.Add(versionNoTLS, -1)
 	}
 
+	userData, ok := l.authenticate(c, user, clientAuthMethod, clientAuthResponse, serverAuthPluginData)
+	if !ok {
+		return
+	}
+
+	c.User = user
+	c.UserData = userData
+
+	if c.User != "" {
+		connCountPerUser.Add(c.User, 1)
+	}
+	// COM_CHANGE_USER may change the user of the connection.
+	defer func() {
+		if c.User != "" {
+			connCountPerUser.Add(c.User, -1)
+		}
+	}()
+
+	// Set initial db name.
+	if c.schemaName != "" {
+		err = l.handler.ComQuery(c, "use "+sqlescape.EscapeID(c.schemaName), func(result *sqltypes.Result) error {
+			return nil
+		})
+		if err != nil {
+			c.writeErrorPacketFromError(err)
+			return
+		}
+	}
+
+	// Negotiation worked, send OK packet.
+	if err := c.writeOKPacket(&PacketOK{statusFlags: c.StatusFlags}); err != nil {
+		log.Errorf("Cannot write OK packet to %s: %v", c, err)
+		return
+	}
+
+	// The packets that follow the OK packet are compressed, if the
+	// client asked for it.
+	c.startCompression()
+
+	// Record how long we took to establish the connection
+	timings.Record(connectTimingKey, acceptTime)
+
+	// Log a warning if it took too long to connect
+	connectTime := time.Since(acceptTime).Nanoseconds()
+	if threshold := l.SlowConnectWarnThreshold.Load(); threshold != 0 && connectTime > threshold {
+		connSlow.Add(1)
+		log.Warningf("Slow connection from %s: %v", c, connectTime)
+	}
+
+	// Tell our handler that we're finished handshake and are ready to
+	// process commands.
+	l.handler.ConnectionReady(c)
+
+	// Open cursors are closed before the handler is told about the
+	// connection closing.
+	defer c.closeCursors()
+
+	for {
+		kontinue := c.handleNextCommand(l.handler)
+		// before going for next command check if the connection should be closed or not.
+		if !kontinue || c.IsMarkedForClose() {
+			return
+		}
+	}
+}
+
+// authenticate authenticates the user with the auth method the client asked
+// for, or switches to another auth method the AuthServer allows for the user.
+// serverAuthPluginData is the salt the client computed clientAuthResponse
+// with. It writes the error packet, and returns false, if the user could not
+// be authenticated.
+func (l *Listener) authenticate(c *Conn, user string, clientAuthMethod AuthMethodDescription, clientAuthResponse, serverAuthPluginData []byte) (Getter, bool) {
 	// See what auth method the AuthServer wants to use for that user.
 	negotiatedAuthMethod, err := negotiateAuthMethod(c, l.authServer, user, clientAuthMethod)
 
@@ -475,86 +567,40 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32, acceptTime time.Ti
 
 		if negotiatedAuthMethod == nil {
 			c.writeErrorPacket(sqlerror.CRServerHandshakeErr, sqlerror.SSUnknownSQLState, "No authentication methods available for authentication.")
-			return
+			return nil, false
 		}
 
 		if !l.AllowClearTextWithoutTLS.Load() && !c.TLSEnabled() && !negotiatedAuthMethod.AllowClearTextWithoutTLS() {
 			c.writeErrorPacket(sqlerror.CRServerHandshakeErr, sqlerror.SSUnknownSQLState, "Cannot use clear text authentication over non-SSL connections.")
-			return
+			return nil, false
 		}
 
 		serverAuthPluginData, err = negotiatedAuthMethod.AuthPluginData()
 		if err != nil {
 			log.Errorf("Error generating auth switch packet for %s: %v", c, err)
-			return
+			return nil, false
 		}
 
 		if err := c.writeAuthSwitchRequest(string(negotiatedAuthMethod.Name()), serverAuthPluginData); err != nil {
 			log.Errorf("Error writing auth switch packet for %s: %v", c, err)
-			return
+			return nil, false
 		}
 
 		clientAuthResponse, err = c.readEphemeralPacket()
 		if err != nil {
 			log.Errorf("Error reading auth switch response for %s: %v", c, err)
-			return
+			return nil, false
 		}
 		c.recycleReadPacket()
 	}
 
-	userData, err := negotiatedAuthMethod.HandleAuthPluginData(c, user, serverAuthPluginData, clientAuthResponse, conn.RemoteAddr())
+	userData, err := negotiatedAuthMethod.HandleAuthPluginData(c, user, serverAuthPluginData, clientAuthResponse, c.RemoteAddr())
 	if err != nil {
 		log.Warningf("Error authenticating user %s using: %s", user, negotiatedAuthMethod.Name())
 		c.writeErrorPacketFromError(err)
-		return
-	}
-
-	c.User = user
-	c.UserData = userData
-
-	if c.User != "" {
-		connCountPerUser.Add(c.User, 1)
-		defer connCountPerUser.Add(c.User, -1)
-	}
-
-	// Set initial db name.
-	if c.schemaName != "" {
-		err = l.handler.ComQuery(c, "use "+sqlescape.EscapeID(c.schemaName), func(result *sqltypes.Result) error {
-			return nil
-		})
-		if err != nil {
-			c.writeErrorPacketFromError(err)
-			return
-		}
-	}
-
-	// Negotiation worked, send OK packet.
-	if err := c.writeOKPacket(&PacketOK{statusFlags: c.StatusFlags}); err != nil {
-		log.Errorf("Cannot write OK packet to %s: %v", c, err)
-		return
-	}
-
-	// Record how long we took to establish the connection
-	timings.Record(connectTimingKey, acceptTime)
-
-	// Log a warning if it took too long to connect
-	connectTime := time.Since(acceptTime).Nanoseconds()
-	if threshold := l.SlowConnectWarnThreshold.Load(); threshold != 0 && connectTime > threshold {
-		connSlow.Add(1)
-		log.Warningf("Slow connection from %s: %v", c, connectTime)
-	}
-
-	// Tell our handler that we're finished handshake and are ready to
-	// process commands.
-	l.handler.ConnectionReady(c)
-
-	for {
-		kontinue := c.handleNextCommand(l.handler)
-		// before going for next command check if the connection should be closed or not.
-		if !kontinue || c.IsMarkedForClose() {
-			return
-		}
+		return nil, false
 	}
+	return userData, true
 }
 
 // Close stops the listener, which prevents accept of any new connections. Existing connections won't be closed.
@@ -570,9 +616,20 @@ func (l *Listener) Shutdown() {
 	}
 }
 
+// optionalCapabilities returns the capability flags the server only
+// offers if configured to: the compression algorithms, and query attributes.
+func (l *Listener) optionalCapabilities() uint32 {
+	capabilities := compressionCapabilities(l.CompressionAlgorithms)
+	if l.EnableQueryAttributes {
+		capabilities |= CapabilityClientQueryAttributes
+	}
+	return capabilities
+}
+
 // writeHandshakeV10 writes the Initial Handshake Packet, server side.
-// It returns the salt data.
-func (c *Conn) writeHandshakeV10(serverVersion string, authServer AuthServer, charset uint8, enableTLS bool) ([]byte, error) {
+// optional holds the capability flags of the optional features the
+// server offers. It returns the salt data.
+func (c *Conn) writeHandshakeV10(serverVersion string, authServer AuthServer, charset uint8, enableTLS bool, optional uint32) ([]byte, error) {
 	capabilities := CapabilityClientLongPassword |
 		CapabilityClientFoundRows |
 		CapabilityClientLongFlag |
@@ -589,6 +646,7 @@ func (c *Conn) writeHandshakeV10(serverVersion string, authServer AuthServer, ch
 	if enableTLS {
 		capabilities |= CapabilityClientSSL
 	}
+	capabilities |= int(optional)
 
 	// Grab the default auth method. This can only be either
 	// mysql_native_password or caching_sha2_password. Both
@@ -714,6 +772,11 @@ func (l *Listener) parseClientHandshakePacket(c *Conn, firstTime bool, data []by
 		c.Capabilities |= CapabilityClientMultiStatements
 	}
 
+	// Query attributes are then sent with COM_QUERY and COM_STMT_EXECUTE.
+	if l.EnableQueryAttributes && clientFlags&CapabilityClientQueryAttributes != 0 {
+		c.Capabilities |= CapabilityClientQueryAttributes
+	}
+
 	// Max packet size. Don't do anything with this now.
 	// See doc.go for more information.
 	_, pos, ok = readUint32(data, pos)
@@ -806,14 +869,68 @@ func (l *Listener) parseClientHandshakePacket(c *Conn, firstTime bool, data []by
 
 	// Decode connection attributes send by the client
 	if clientFlags&CapabilityClientConnAttr != 0 {
-		if _, _, err := parseConnAttrs(data, pos); err != nil {
+		var err error
+		if _, pos, err = parseConnAttrs(data, pos); err != nil {
 			log.Warningf("Decode connection attributes send by the client: %v", err)
 		}
 	}
 
+	// Negotiate the compression of the protocol, among the algorithms
+	// we offered. The zstd compression level follows the attributes, if
+	// they could be decoded.
+	c.compression = negotiateCompression(clientFlags & compressionCapabilities(l.CompressionAlgorithms))
+	c.compressionLevel = DefaultZstdCompressionLevel
+	if c.compression == CompressionZstd && pos > 0 {
+		if level, _, ok := readByte(data, pos); ok && level > 0 {
+			c.compressionLevel = int(level)
+		}
+	}
+
 	return username, AuthMethodDescription(authMethod), authResponse, nil
 }
 
+// parseComChangeUser parses a COM_CHANGE_USER packet.
+// Returns the username, auth method, auth data, schema name, character
+// set (0 if not sent), and false if the packet is malformed. The original
+// data is not pointed at, and can be freed.
+func (c *Conn) parseComChangeUser(data []byte) (string, AuthMethodDescription, []byte, string, collations.ID, bool) {
+	// Skip the command byte.
+	pos := 1
+
+	username, pos, ok := readNullString(data, pos)
+	if !ok {
+		return "", "", nil, "", 0, false
+	}
+
+	// We always advertise CapabilityClientSecureConnection, so the
+	// auth-response has a length byte.
+	l, pos, ok := readByte(data, pos)
+	if !ok {
+		return "", "", nil, "", 0, false
+	}
+	authResponse, pos, ok := readBytesCopy(data, pos, int(l))
+	if !ok {
+		return "", "", nil, "", 0, false
+	}
+
+	schemaName, pos, ok := readNullString(data, pos)
+	if !ok {
+		return "", "", nil, "", 0, false
+	}
+
+	// Character set and auth method are optional.
+	authMethod := MysqlNativePassword
+	var characterSet collations.ID
+	if cs, npos, ok := readUint16(data, pos); ok {
+		characterSet = collations.ID(cs)
+		if authMethodStr, _, ok := readNullString(data, npos); ok && authMethodStr != "" {
+			authMethod = AuthMethodDescription(authMethodStr)
+		}
+	}
+
+	return username, authMethod, authResponse, schemaName, characterSet, true
+}
+
 func parseConnAttrs(data []byte, pos int) (map[string]string, int, error) {
 	var attrLen uint64
 
@@ -19,6 +19,7 @@ package mysql
 import (
 	"context"
 	"crypto/tls"
+	"encoding/binary"
 	"fmt"
 	"net"
 	"os"
@@ -80,6 +81,8 @@ type testHandler struct {
 	result   *sqltypes.Result
 	err      error
 	warnings uint16
+	// changeUsers counts the calls to ComChangeUser.
+	changeUsers int
 }
 
 func (th *testHandler) LastConn() *Conn {
@@ -112,6 +115,12 @@ func (th *testHandler) SetWarnings(count uint16) {
 	th.warnings = count
 }
 
+func (th *testHandler) ChangeUsers() int {
+	th.mu.Lock()
+	defer th.mu.Unlock()
+	return th.changeUsers
+}
+
 func (th *testHandler) NewConnection(c *Conn) {
 	th.mu.Lock()
 	defer th.mu.Unlock()
@@ -231,12 +240,18 @@ func (th *testHandler) ComQuery(c *Conn, query string, callback func(*sqltypes.R
 	return nil
 }
 
+func (th *testHandler) ComChangeUser(c *Conn) {
+	th.mu.Lock()
+	defer th.mu.Unlock()
+	th.changeUsers++
+}
+
 func (th *testHandler) ComPrepare(c *Conn, query string, bindVars map[string]*querypb.BindVariable) ([]*querypb.Field, error) {
 	return nil, nil
 }
 
 func (th *testHandler) ComStmtExecute(c *Conn, prepare *PrepareData, callback func(*sqltypes.Result) error) error {
-	return nil
+	return callback(&sqltypes.Result{})
 }
 
 func (th *testHandler) ComRegisterReplica(c *Conn, replicaHost string, replicaPort uint16, replicaUser string, replicaPassword string) error {
@@ -471,6 +486,96 @@ func TestClientFoundRows(t *testing.T) {
 	c.Close()
 }
 
+func TestChangeUser(t *testing.T) {
+	th := &testHandler{}
+
+	authServer := NewAuthServerStatic("", "", 0)
+	authServer.entries["user1"] = []*AuthServerStaticEntry{{
+		Password: "password1",
+		UserData: "userData1",
+	}}
+	authServer.entries["user2"] = []*AuthServerStaticEntry{{
+		Password: "password2",
+		UserData: "userData2",
+	}}
+	defer authServer.close()
+	l, err := NewListener("tcp", "127.0.0.1:", authServer, th, 0, 0, false, false, 0, 0)
+	require.NoError(t, err, "NewListener failed")
+	defer l.Close()
+	go l.Accept()
+
+	host, port := getHostPort(t, l.Addr())
+	params := &ConnParams{
+		Host:  host,
+		Port:  port,
+		Uname: "user1",
+		Pass:  "password1",
+	}
+
+	c, err := Connect(context.Background(), params)
+	require.NoError(t, err, "Connect failed")
+	defer c.Close()
+
+	userDataEcho := func(user, userData string) {
+		t.Helper()
+		qr, err := c.ExecuteFetch("userData echo", 1, false)
+		require.NoError(t, err)
+		assert.Equal(t, user, qr.Rows[0][0].ToString())
+		assert.Equal(t, userData, qr.Rows[0][1].ToString())
+	}
+	userDataEcho("user1", "userData1")
+
+	// prepare prepares a statement without columns nor parameters, and
+	// returns its ID.
+	prepare := func() uint32 {
+		t.Helper()
+		c.resetSequence()
+		require.NoError(t, c.writePacket(append([]byte{0, 0, 0, 0, ComPrepare}, "select 1 from dual"...)))
+		data, err := c.ReadPacket()
+		require.NoError(t, err)
+		require.EqualValues(t, OKPacket, data[0])
+		return binary.LittleEndian.Uint32(data[1:5])
+	}
+	// execute executes the prepared statement, and returns the error.
+	execute := func(stmtID uint32) error {
+		t.Helper()
+		c.resetSequence()
+		require.NoError(t, c.writePacket(createStmtExecutePacket(stmtID, CursorTypeNoCursor)))
+		data, err := c.ReadPacket()
+		require.NoError(t, err)
+		if isErrorPacket(data) {
+			return ParseErrorPacket(data)
+		}
+		return nil
+	}
+
+	stmtID := prepare()
+	require.NoError(t, execute(stmtID))
+
+	// The connection is left untouched if the authentication fails.
+	err = c.ChangeUser(&ConnParams{Uname: "user2", Pass: "bad", DbName: "db2"})
+	require.ErrorContains(t, err, "Access denied for user 'user2'")
+	assert.Zero(t, th.ChangeUsers(), "the session of the handler was reset")
+	userDataEcho("user1", "userData1")
+	qr, err := c.ExecuteFetch("schema echo", 1, false)
+	require.NoError(t, err)
+	assert.Equal(t, "", qr.Rows[0][0].ToString())
+	require.NoError(t, execute(stmtID))
+
+	err = c.ChangeUser(&ConnParams{Uname: "user2", Pass: "password2", DbName: "db2"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, th.ChangeUsers())
+	userDataEcho("user2", "userData2")
+	qr, err = c.ExecuteFetch("schema echo", 1, false)
+	require.NoError(t, err)
+	assert.Equal(t, "db2", qr.Rows[0][0].ToString())
+	assert.EqualValues(t, 1, connCountPerUser.Counts()["user2"])
+	assert.EqualValues(t, 0, connCountPerUser.Counts()["user1"])
+
+	// The prepared statements of the previous user are closed.
+	require.ErrorContains(t, execute(stmtID), "statement ID is not found")
+}
+
 func TestConnCounts(t *testing.T) {
 	th := &testHandler{}
 
@@ -219,3 +219,34 @@ func TestExtractTables(t *testing.T) {
 		})
 	}
 }
+
+// TestContainsWindowFunction verifies that window functions and aggregations with OVER clauses are detected.
+func TestContainsWindowFunction(t *testing.T) {
+	tcases := []struct {
+		expr     string
+		expected bool
+	}{{
+		expr:     "row_number() over (partition by a order by b)",
+		expected: true,
+	}, {
+		expr:     "sum(a) over (order by b)",
+		expected: true,
+	}, {
+		expr:     "lag(a, 2) over (order by b) + 1",
+		expected: true,
+	}, {
+		expr:     "sum(a)",
+		expected: false,
+	}, {
+		expr:     "a + (select row_number() over () from t)",
+		expected: false,
+	}}
+	parser := NewTestParser()
+	for _, tcase := range tcases {
+		t.Run(tcase.expr, func(t *testing.T) {
+			expr, err := parser.ParseExpr(tcase.expr)
+			require.NoError(t, err)
+			require.Equal(t, tcase.expected, ContainsWindowFunction(expr))
+		})
+	}
+}
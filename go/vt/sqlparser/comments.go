@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
@@ -58,6 +59,8 @@ const (
 	// DirectivePriority specifies the priority of a workload. It should be an integer between 0 and MaxPriorityValue,
 	// where 0 is the highest priority, and MaxPriorityValue is the lowest one.
 	DirectivePriority = "PRIORITY"
+	// DirectiveResultCacheTTL lets vtgate cache the result of a select for the given number of milliseconds.
+	DirectiveResultCacheTTL = "RESULT_CACHE_TTL_MS"
 
 	// MaxPriorityValue specifies the maximum value allowed for the priority query directive. Valid priority values are
 	// between zero and MaxPriorityValue.
@@ -629,6 +632,21 @@ func Consolidator(stmt Statement) querypb.ExecuteOptions_Consolidator {
 	return querypb.ExecuteOptions_CONSOLIDATOR_UNSPECIFIED
 }
 
+// ResultCacheTTL returns for how long vtgate can cache the result of the statement,
+// as set by the RESULT_CACHE_TTL_MS directive. It returns 0 if the result should not be cached.
+func ResultCacheTTL(stmt Statement) time.Duration {
+	sel, ok := stmt.(*Select)
+	if !ok || sel.Comments == nil {
+		return 0
+	}
+	val, _ := sel.Comments.Directives().GetString(DirectiveResultCacheTTL, "0")
+	ttl, err := strconv.Atoi(val)
+	if err != nil || ttl <= 0 {
+		return 0
+	}
+	return time.Duration(ttl) * time.Millisecond
+}
+
 // GetWorkloadNameFromStatement gets the workload name from the provided Statement, using workloadLabel as the name of
 // the query directive that specifies it.
 func GetWorkloadNameFromStatement(statement Statement) string {
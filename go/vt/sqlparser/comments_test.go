@@ -19,6 +19,7 @@ package sqlparser
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -480,6 +481,28 @@ func TestConsolidator(t *testing.T) {
 	}
 }
 
+func TestResultCacheTTL(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected time.Duration
+	}{
+		{"select * from users", 0},
+		{"select /*vt+ RESULT_CACHE_TTL_MS=1500 */ * from users", 1500 * time.Millisecond},
+		{"select /*vt+ RESULT_CACHE_TTL_MS=soon */ * from users", 0},
+		{"select /*vt+ RESULT_CACHE_TTL_MS=-1 */ * from users", 0},
+		{"update /*vt+ RESULT_CACHE_TTL_MS=1500 */ users set name=1", 0},
+	}
+
+	parser := NewTestParser()
+	for _, test := range testCases {
+		t.Run(test.query, func(t *testing.T) {
+			stmt, err := parser.Parse(test.query)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, ResultCacheTTL(stmt))
+		})
+	}
+}
+
 func TestGetPriorityFromStatement(t *testing.T) {
 	testCases := []struct {
 		query            string
@@ -150,6 +150,9 @@ func (nz *normalizer) walkUpSelect(cursor *Cursor) bool {
 	switch parent.(type) {
 	case *Order, *GroupBy:
 		return true
+	case *NtileExpr, *LagLeadExpr, *NTHValueExpr:
+		// the offsets used by window functions have to be constants
+		return true
 	case *Limit:
 		nz.convertLiteral(node, cursor)
 	default:
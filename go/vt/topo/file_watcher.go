@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+)
+
+// FileWatcher watches a file of a cell in the background, and hands every
+// version of it to its apply function. The watch is restarted after a
+// delay when it fails, or when apply returns an error, until Stop is called.
+// It is used by the query rules of vttablet and vtgate stored in the topo.
+type FileWatcher struct {
+	// conn is the topo connection. Set at construction time.
+	conn Conn
+
+	// filePath is the file to read from.
+	filePath string
+
+	// retryDelay is how long to sleep before retrying in case of error.
+	retryDelay time.Duration
+
+	// apply is called with every version of the file.
+	apply func(*WatchData) error
+
+	// mu protects the following variables.
+	mu sync.Mutex
+
+	// cancel is the function to call to cancel the current watch, if any.
+	cancel func()
+
+	// stopped is set when Stop() is called. It is a protection for race conditions.
+	stopped bool
+}
+
+// NewFileWatcher creates a watcher of the file of the connection.
+func NewFileWatcher(conn Conn, filePath string, retryDelay time.Duration, apply func(*WatchData) error) *FileWatcher {
+	return &FileWatcher{
+		conn:       conn,
+		filePath:   filePath,
+		retryDelay: retryDelay,
+		apply:      apply,
+	}
+}
+
+// Start watches the file in the background, until Stop is called.
+func (fw *FileWatcher) Start() {
+	go func() {
+		for {
+			if err := fw.oneWatch(); err != nil {
+				log.Warningf("Background watch of %v failed: %v", fw.filePath, err)
+			}
+
+			fw.mu.Lock()
+			stopped := fw.stopped
+			fw.mu.Unlock()
+
+			if stopped {
+				log.Warningf("Watch of %v was terminated", fw.filePath)
+				return
+			}
+
+			log.Warningf("Sleeping for %v before trying again", fw.retryDelay)
+			time.Sleep(fw.retryDelay)
+		}
+	}()
+}
+
+// Stop stops watching the file.
+func (fw *FileWatcher) Stop() {
+	fw.mu.Lock()
+	if fw.cancel != nil {
+		fw.cancel()
+	}
+	fw.stopped = true
+	fw.mu.Unlock()
+}
+
+func (fw *FileWatcher) oneWatch() error {
+	defer func() {
+		// Whatever happens, cancel() won't be valid after this function exits.
+		fw.mu.Lock()
+		fw.cancel = nil
+		fw.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	current, wdChannel, err := fw.conn.Watch(ctx, fw.filePath)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	fw.mu.Lock()
+	if fw.stopped {
+		// We're not interested in the result any more.
+		fw.mu.Unlock()
+		cancel()
+		for range wdChannel {
+		}
+		return NewError(Interrupted, "watch")
+	}
+	fw.cancel = cancel
+	fw.mu.Unlock()
+
+	if err := fw.apply(current); err != nil {
+		// Cancel the watch, drain channel.
+		cancel()
+		for range wdChannel {
+		}
+		return err
+	}
+
+	for wd := range wdChannel {
+		if wd.Err != nil {
+			// Last error value, we're done.
+			// wdChannel will be closed right after
+			// this, no need to do anything.
+			return wd.Err
+		}
+
+		if err := fw.apply(wd); err != nil {
+			// Cancel the watch, drain channel.
+			cancel()
+			for range wdChannel {
+			}
+			return err
+		}
+	}
+
+	return fmt.Errorf("watch terminated with no error")
+}
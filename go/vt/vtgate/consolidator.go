@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/streamlog"
+	"vitess.io/vitess/go/sync2"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+var consolidatedReads = stats.NewCounter("ConsolidatedReads", "Number of reads that waited for the result of an identical read instead of being executed")
+
+// EnableConsolidator makes the executor consolidate identical reads from replica and rdonly
+// tablets: while a read is executing, identical reads wait for its result instead of being executed.
+func (e *Executor) EnableConsolidator() {
+	e.consolidator = sync2.NewConsolidator()
+	e.consolidations = sync2.NewConsolidatorCache(1000)
+}
+
+// shouldConsolidate returns whether identical executions of the query can share their result
+func (e *Executor) shouldConsolidate(safeSession *SafeSession, plan *engine.Plan, vcursor *vcursorImpl) bool {
+	if e.consolidator == nil || plan.Type != sqlparser.StmtSelect || safeSession.InTransaction() {
+		return false
+	}
+	if safeSession.GetOptions().GetConsolidator() == querypb.ExecuteOptions_CONSOLIDATOR_DISABLED {
+		return false
+	}
+	// reads from the primary must see the writes that happened before them
+	return vcursor.tabletType == topodatapb.TabletType_REPLICA || vcursor.tabletType == topodatapb.TabletType_RDONLY
+}
+
+// executeConsolidated executes the plan, or waits for the result of an identical execution of it
+// if one is already running
+func (e *Executor) executeConsolidated(
+	ctx context.Context,
+	safeSession *SafeSession,
+	plan *engine.Plan,
+	vcursor *vcursorImpl,
+	bindVars map[string]*querypb.BindVariable,
+) (*sqltypes.Result, error) {
+	if !e.shouldConsolidate(safeSession, plan, vcursor) {
+		return vcursor.ExecutePrimitive(ctx, plan.Instructions, bindVars, true)
+	}
+
+	key := queryResultKey(ctx, vcursor, plan.Original, bindVars)
+	q, original := e.consolidator.Create(hex.EncodeToString(key[:]))
+	if original {
+		defer q.Broadcast()
+		qr, err := vcursor.ExecutePrimitive(ctx, plan.Instructions, bindVars, true)
+		if err == nil {
+			// the caller is free to modify its result
+			q.SetResult(qr.Copy())
+		}
+		q.SetErr(err)
+		return qr, err
+	}
+
+	consolidatedReads.Add(1)
+	e.consolidations.Record(plan.Original)
+	q.Wait()
+	if q.Err() != nil {
+		return nil, q.Err()
+	}
+	return q.Result().Copy(), nil
+}
+
+// writeConsolidations shows how many times the recent queries waited for an identical query
+func (e *Executor) writeConsolidations(response http.ResponseWriter) {
+	response.Header().Set("Content-Type", "text/plain")
+	if e.consolidations == nil {
+		_, _ = response.Write([]byte("empty\n"))
+		return
+	}
+	items := e.consolidations.Items()
+	if len(items) == 0 {
+		_, _ = response.Write([]byte("empty\n"))
+		return
+	}
+	_, _ = response.Write([]byte(fmt.Sprintf("Length: %d\n", len(items))))
+	for _, v := range items {
+		query := v.Query
+		if streamlog.GetRedactDebugUIQueries() {
+			query, _ = e.env.Parser().RedactSQLQuery(v.Query)
+		}
+		_, _ = response.Write([]byte(fmt.Sprintf("%v: %s\n", v.Count, query)))
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/sync2"
+
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// recordingConsolidator remembers the keys of the reads it consolidates
+type recordingConsolidator struct {
+	sync2.Consolidator
+
+	mu   sync.Mutex
+	keys []string
+}
+
+func (rc *recordingConsolidator) Create(key string) (sync2.PendingResult, bool) {
+	rc.mu.Lock()
+	rc.keys = append(rc.keys, key)
+	rc.mu.Unlock()
+	return rc.Consolidator.Create(key)
+}
+
+func TestConsolidator(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	executor, primary, replica := createExecutorEnvWithPrimaryReplicaConn(t, ctx, 0)
+	executor.EnableConsolidator()
+	consolidator := &recordingConsolidator{Consolidator: executor.consolidator}
+	executor.consolidator = consolidator
+
+	query := "select id from user where id = 1"
+	session := &vtgatepb.Session{TargetString: KsTestUnsharded + "@replica", Autocommit: true}
+	_, err := executorExec(ctx, executor, session, query, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, replica.ExecCount.Load())
+	require.Len(t, consolidator.keys, 1)
+
+	// while the read is running, identical reads wait for its result
+	q, original := consolidator.Consolidator.Create(consolidator.keys[0])
+	require.True(t, original)
+	const waiters = 5
+	var wg sync.WaitGroup
+	for range waiters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			qr, err := executorExec(ctx, executor, &vtgatepb.Session{TargetString: KsTestUnsharded + "@replica", Autocommit: true}, query, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "42").Rows, qr.Rows)
+		}()
+	}
+	require.Eventually(t, func() bool {
+		items := executor.consolidations.Items()
+		return len(items) == 1 && items[0].Count == waiters
+	}, 5*time.Second, time.Millisecond)
+	q.SetResult(sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "42"))
+	q.Broadcast()
+	wg.Wait()
+	assert.EqualValues(t, 1, replica.ExecCount.Load())
+
+	recorder := httptest.NewRecorder()
+	executor.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, pathConsolidations, nil))
+	assert.Equal(t, "Length: 1\n5: select id from `user` where id = 1\n", recorder.Body.String())
+
+	// reads from the primary, or with the consolidator disabled, are not consolidated
+	keys := len(consolidator.keys)
+	_, err = executorExec(ctx, executor, &vtgatepb.Session{TargetString: KsTestUnsharded + "@primary", Autocommit: true}, query, nil)
+	require.NoError(t, err)
+	_, err = executorExec(ctx, executor, session, "select /*vt+ CONSOLIDATOR=disabled */ id from user where id = 1", nil)
+	require.NoError(t, err)
+	assert.Len(t, consolidator.keys, keys)
+	assert.EqualValues(t, 1, primary.ExecCount.Load())
+	assert.EqualValues(t, 2, replica.ExecCount.Load())
+}
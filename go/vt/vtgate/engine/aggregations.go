@@ -18,7 +18,10 @@ package engine
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 
 	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/slice"
@@ -42,6 +45,18 @@ type AggregateParams struct {
 	WCol   int
 	Type   evalengine.Type
 
+	// DistinctCols is set when the input is not ordered by the distinct expression,
+	// which happens when there are DISTINCT aggregations on different expressions
+	// or when a DISTINCT aggregation has multiple arguments. The values seen in the
+	// current group are then tracked using a hash set over these columns.
+	DistinctCols []CheckCol
+
+	// These are used only for GROUP_CONCAT evaluated at the vtgate level.
+	// ConcatCols holds the columns after the first one when there are multiple
+	// arguments, and OrderBy is the ordering of the values within each group.
+	ConcatCols []int
+	OrderBy    evalengine.Comparison
+
 	Alias    string
 	Func     sqlparser.AggrFunc
 	Original *sqlparser.AliasedExpr
@@ -79,10 +94,24 @@ func (ap *AggregateParams) String() string {
 	if sqltypes.IsText(ap.Type.Type()) && ap.CollationEnv.IsSupported(ap.Type.Collation()) {
 		keyCol += " COLLATE " + ap.CollationEnv.LookupName(ap.Type.Collation())
 	}
+	if len(ap.DistinctCols) > 0 {
+		// the distinct columns already include all the arguments
+		keyCol = strings.Join(slice.Map(ap.DistinctCols, CheckCol.String), ", ")
+	} else {
+		for _, col := range ap.ConcatCols {
+			keyCol += ", " + strconv.Itoa(col)
+		}
+	}
+	if len(ap.OrderBy) > 0 {
+		keyCol += " ORDER BY " + GenericJoin(ap.OrderBy, orderByParamsToString)
+	}
 	dispOrigOp := ""
 	if ap.OrigOpcode != AggregateUnassigned && ap.OrigOpcode != ap.Opcode {
 		dispOrigOp = "_" + ap.OrigOpcode.String()
 	}
+	if len(ap.DistinctCols) > 0 {
+		dispOrigOp += "_hash"
+	}
 	if ap.Alias != "" {
 		return fmt.Sprintf("%s%s(%s) AS %s", ap.Opcode.String(), dispOrigOp, keyCol, ap.Alias)
 	}
@@ -108,9 +137,25 @@ type aggregatorDistinct struct {
 	coll         collations.ID
 	collationEnv *collations.Environment
 	values       *evalengine.EnumSetValues
+
+	// seen is used instead of comparing against the last value when the input
+	// is not ordered by the distinct columns
+	seen *probeTable
 }
 
 func (a *aggregatorDistinct) shouldReturn(row []sqltypes.Value) (bool, error) {
+	if a.seen != nil {
+		for _, col := range a.seen.checkCols {
+			if row[col.Col].IsNull() {
+				return true, nil
+			}
+		}
+		newRow, err := a.seen.exists(row)
+		if err != nil {
+			return true, err
+		}
+		return newRow == nil, nil
+	}
 	if a.column >= 0 {
 		last := a.last
 		next := row[a.column]
@@ -132,6 +177,9 @@ func (a *aggregatorDistinct) shouldReturn(row []sqltypes.Value) (bool, error) {
 
 func (a *aggregatorDistinct) reset() {
 	a.last = sqltypes.NULL
+	if a.seen != nil {
+		clear(a.seen.seenRows)
+	}
 }
 
 type aggregatorCount struct {
@@ -256,8 +304,14 @@ func (a *aggregatorScalar) reset() {
 
 type aggregatorGroupConcat struct {
 	from      int
+	cols      []int
 	type_     sqltypes.Type
 	separator []byte
+	maxLen    int
+
+	distinct aggregatorDistinct
+	orderBy  evalengine.Comparison
+	rows     []sqltypes.Row
 
 	concat []byte
 	n      int
@@ -267,15 +321,56 @@ func (a *aggregatorGroupConcat) add(row []sqltypes.Value) error {
 	if row[a.from].IsNull() {
 		return nil
 	}
+	for _, col := range a.cols {
+		if row[col].IsNull() {
+			return nil
+		}
+	}
+	if ret, err := a.distinct.shouldReturn(row); ret {
+		return err
+	}
+	if a.orderBy != nil {
+		return a.addSorted(row)
+	}
+	a.appendRow(row)
+	return nil
+}
+
+// addSorted keeps the rows of the group sorted, so they can be concatenated in the right order once the group is done
+func (a *aggregatorGroupConcat) addSorted(row []sqltypes.Value) (err error) {
+	defer evalengine.PanicHandler(&err)
+	idx := sort.Search(len(a.rows), func(i int) bool {
+		return a.orderBy.Compare(a.rows[i], row) > 0
+	})
+	a.rows = slices.Insert(a.rows, idx, row)
+	return nil
+}
+
+func (a *aggregatorGroupConcat) appendRow(row []sqltypes.Value) {
+	if a.maxLen > 0 && len(a.concat) >= a.maxLen {
+		return
+	}
 	if a.n > 0 {
 		a.concat = append(a.concat, a.separator...)
 	}
 	a.concat = append(a.concat, row[a.from].Raw()...)
+	for _, col := range a.cols {
+		a.concat = append(a.concat, row[col].Raw()...)
+	}
 	a.n++
-	return nil
+	if a.maxLen > 0 && len(a.concat) > a.maxLen {
+		a.concat = a.concat[:a.maxLen]
+	}
 }
 
 func (a *aggregatorGroupConcat) finish() sqltypes.Value {
+	if a.orderBy != nil {
+		a.n = 0
+		a.concat = nil
+		for _, row := range a.rows {
+			a.appendRow(row)
+		}
+	}
 	if a.n == 0 {
 		return sqltypes.NULL
 	}
@@ -285,6 +380,8 @@ func (a *aggregatorGroupConcat) finish() sqltypes.Value {
 func (a *aggregatorGroupConcat) reset() {
 	a.n = 0
 	a.concat = nil // not safe to reuse this byte slice as it's returned as MakeTrusted
+	a.rows = nil
+	a.distinct.reset()
 }
 
 type aggregatorGtid struct {
@@ -354,8 +451,28 @@ func isComparable(typ sqltypes.Type) bool {
 	return false
 }
 
-func newAggregation(fields []*querypb.Field, aggregates []*AggregateParams) (aggregationState, []*querypb.Field, error) {
+// groupConcatMaxLen returns the group_concat_max_len set on the session, or 0 if it has not been set
+func groupConcatMaxLen(vcursor VCursor, aggregates []*AggregateParams) int {
+	if vcursor == nil || !slices.ContainsFunc(aggregates, func(aggr *AggregateParams) bool {
+		return aggr.Opcode == AggregateGroupConcat
+	}) {
+		return 0
+	}
+	var maxLen int
+	vcursor.Session().GetSystemVariables(func(k string, v string) {
+		if k != "group_concat_max_len" {
+			return
+		}
+		if val, err := strconv.Atoi(v); err == nil {
+			maxLen = val
+		}
+	})
+	return maxLen
+}
+
+func newAggregation(vcursor VCursor, fields []*querypb.Field, aggregates []*AggregateParams) (aggregationState, []*querypb.Field, error) {
 	fields = slice.Map(fields, func(from *querypb.Field) *querypb.Field { return from.CloneVT() })
+	groupConcatMaxLen := groupConcatMaxLen(vcursor, aggregates)
 
 	agstate := make([]aggregator, len(fields))
 	for _, aggr := range aggregates {
@@ -365,11 +482,16 @@ func newAggregation(fields []*querypb.Field, aggregates []*AggregateParams) (agg
 		var ag aggregator
 		var distinct = -1
 
+		var seen *probeTable
+
 		if aggr.Opcode.IsDistinct() {
 			distinct = aggr.KeyCol
 			if aggr.WAssigned() && !isComparable(sourceType) {
 				distinct = aggr.WCol
 			}
+			if len(aggr.DistinctCols) > 0 {
+				seen = newProbeTable(aggr.DistinctCols, aggr.CollationEnv)
+			}
 		}
 
 		if aggr.Opcode == AggregateMin || aggr.Opcode == AggregateMax {
@@ -390,6 +512,7 @@ func newAggregation(fields []*querypb.Field, aggregates []*AggregateParams) (agg
 					coll:         aggr.Type.Collation(),
 					collationEnv: aggr.CollationEnv,
 					values:       aggr.Type.Values(),
+					seen:         seen,
 				},
 			}
 
@@ -410,6 +533,7 @@ func newAggregation(fields []*querypb.Field, aggregates []*AggregateParams) (agg
 					coll:         aggr.Type.Collation(),
 					collationEnv: aggr.CollationEnv,
 					values:       aggr.Type.Values(),
+					seen:         seen,
 				},
 			}
 
@@ -438,10 +562,18 @@ func newAggregation(fields []*querypb.Field, aggregates []*AggregateParams) (agg
 		case AggregateGroupConcat:
 			gcFunc := aggr.Func.(*sqlparser.GroupConcatExpr)
 			separator := []byte(gcFunc.Separator)
+			if len(aggr.DistinctCols) > 0 {
+				seen = newProbeTable(aggr.DistinctCols, aggr.CollationEnv)
+			}
 			ag = &aggregatorGroupConcat{
 				from:      aggr.Col,
+				cols:      aggr.ConcatCols,
 				type_:     targetType,
 				separator: separator,
+				maxLen:    groupConcatMaxLen,
+				distinct:  aggregatorDistinct{column: -1, seen: seen},
+				// the comparison can switch to the weight string columns, so we need our own copy
+				orderBy: slices.Clone(aggr.OrderBy),
 			}
 
 		default:
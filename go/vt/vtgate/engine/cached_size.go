@@ -35,10 +35,28 @@ func (cached *AggregateParams) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(112)
+		size += int64(192)
 	}
 	// field Type vitess.io/vitess/go/vt/vtgate/evalengine.Type
 	size += cached.Type.CachedSize(false)
+	// field DistinctCols []vitess.io/vitess/go/vt/vtgate/engine.CheckCol
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.DistinctCols)) * int64(48))
+		for _, elem := range cached.DistinctCols {
+			size += elem.CachedSize(false)
+		}
+	}
+	// field ConcatCols []int
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.ConcatCols)) * int64(8))
+	}
+	// field OrderBy vitess.io/vitess/go/vt/vtgate/evalengine.Comparison
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.OrderBy)) * int64(56))
+		for _, elem := range cached.OrderBy {
+			size += elem.CachedSize(false)
+		}
+	}
 	// field Alias string
 	size += hack.RuntimeAllocSize(int64(len(cached.Alias)))
 	// field Func vitess.io/vitess/go/vt/sqlparser.AggrFunc
@@ -113,6 +131,56 @@ func (cached *Concatenate) CachedSize(alloc bool) int64 {
 	}
 	return size
 }
+
+//go:nocheckptr
+func (cached *CorrelatedSubquery) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(144)
+	}
+	// field SubqueryResult string
+	size += hack.RuntimeAllocSize(int64(len(cached.SubqueryResult)))
+	// field HasValues string
+	size += hack.RuntimeAllocSize(int64(len(cached.HasValues)))
+	// field Vars map[string]int
+	if cached.Vars != nil {
+		size += int64(48)
+		hmap := reflect.ValueOf(cached.Vars)
+		numBuckets := int(math.Pow(2, float64((*(*uint8)(unsafe.Pointer(hmap.Pointer() + uintptr(9)))))))
+		numOldBuckets := (*(*uint16)(unsafe.Pointer(hmap.Pointer() + uintptr(10))))
+		size += hack.RuntimeAllocSize(int64(numOldBuckets * 208))
+		if len(cached.Vars) > 0 || numBuckets > 1 {
+			size += hack.RuntimeAllocSize(int64(numBuckets * 208))
+		}
+		for k := range cached.Vars {
+			size += hack.RuntimeAllocSize(int64(len(k)))
+		}
+	}
+	// field Filter vitess.io/vitess/go/vt/vtgate/evalengine.Expr
+	if cc, ok := cached.Filter.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field ASTFilter vitess.io/vitess/go/vt/sqlparser.Expr
+	if cc, ok := cached.ASTFilter.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field Cols []int
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.Cols)) * int64(8))
+	}
+	// field Outer vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Outer.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field Subquery vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Subquery.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	return size
+}
 func (cached *DBDDL) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -425,7 +493,7 @@ func (cached *HashJoin) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(128)
+		size += int64(160)
 	}
 	// field Left vitess.io/vitess/go/vt/vtgate/engine.Primitive
 	if cc, ok := cached.Left.(cachedObject); ok {
@@ -439,12 +507,41 @@ func (cached *HashJoin) CachedSize(alloc bool) int64 {
 	{
 		size += hack.RuntimeAllocSize(int64(cap(cached.Cols)) * int64(8))
 	}
+	// field Keys []vitess.io/vitess/go/vt/vtgate/engine.HashJoinKey
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.Keys)) * int64(40))
+		for _, elem := range cached.Keys {
+			size += elem.CachedSize(false)
+		}
+	}
 	// field ASTPred vitess.io/vitess/go/vt/sqlparser.Expr
 	if cc, ok := cached.ASTPred.(cachedObject); ok {
 		size += cc.CachedSize(true)
 	}
+	// field Filter vitess.io/vitess/go/vt/vtgate/evalengine.Expr
+	if cc, ok := cached.Filter.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field FilterCols []int
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.FilterCols)) * int64(8))
+	}
 	// field CollationEnv *vitess.io/vitess/go/mysql/collations.Environment
 	size += cached.CollationEnv.CachedSize(true)
+	// field Estimate *vitess.io/vitess/go/vt/vtgate/engine.CostEstimate
+	if cached.Estimate != nil {
+		size += hack.RuntimeAllocSize(int64(16))
+	}
+	return size
+}
+func (cached *HashJoinKey) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(48)
+	}
 	// field Values *vitess.io/vitess/go/vt/vtgate/evalengine.EnumSetValues
 	if cached.Values != nil {
 		size += int64(24)
@@ -542,7 +639,7 @@ func (cached *InsertSelect) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(176)
+		size += int64(224)
 	}
 	// field InsertCommon vitess.io/vitess/go/vt/vtgate/engine.InsertCommon
 	size += cached.InsertCommon.CachedSize(false)
@@ -559,6 +656,80 @@ func (cached *InsertSelect) CachedSize(alloc bool) int64 {
 			}
 		}
 	}
+	// field Delete vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Delete.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field PKOffsets []int
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.PKOffsets)) * int64(8))
+	}
+	return size
+}
+func (cached *JSONTable) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(80)
+	}
+	// field Doc vitess.io/vitess/go/vt/vtgate/evalengine.Expr
+	if cc, ok := cached.Doc.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field Path string
+	size += hack.RuntimeAllocSize(int64(len(cached.Path)))
+	// field Columns []*vitess.io/vitess/go/vt/vtgate/engine.JSONTableColumn
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.Columns)) * int64(8))
+		for _, elem := range cached.Columns {
+			size += elem.CachedSize(true)
+		}
+	}
+	// field Cols []int
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.Cols)) * int64(8))
+	}
+	return size
+}
+func (cached *JSONTableColumn) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(112)
+	}
+	// field Name string
+	size += hack.RuntimeAllocSize(int64(len(cached.Name)))
+	// field Type vitess.io/vitess/go/vt/vtgate/evalengine.Type
+	size += cached.Type.CachedSize(false)
+	// field Path string
+	size += hack.RuntimeAllocSize(int64(len(cached.Path)))
+	// field OnEmpty *vitess.io/vitess/go/vt/vtgate/engine.JSONTableOnResponse
+	size += cached.OnEmpty.CachedSize(true)
+	// field OnError *vitess.io/vitess/go/vt/vtgate/engine.JSONTableOnResponse
+	size += cached.OnError.CachedSize(true)
+	// field Nested []*vitess.io/vitess/go/vt/vtgate/engine.JSONTableColumn
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.Nested)) * int64(8))
+		for _, elem := range cached.Nested {
+			size += elem.CachedSize(true)
+		}
+	}
+	return size
+}
+func (cached *JSONTableOnResponse) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(48)
+	}
+	// field Default vitess.io/vitess/go/sqltypes.Value
+	size += cached.Default.CachedSize(false)
 	return size
 }
 
@@ -597,6 +768,10 @@ func (cached *Join) CachedSize(alloc bool) int64 {
 			size += hack.RuntimeAllocSize(int64(len(k)))
 		}
 	}
+	// field Estimate *vitess.io/vitess/go/vt/vtgate/engine.CostEstimate
+	if cached.Estimate != nil {
+		size += hack.RuntimeAllocSize(int64(16))
+	}
 	return size
 }
 func (cached *Limit) CachedSize(alloc bool) int64 {
@@ -950,6 +1125,18 @@ func (cached *RevertMigration) CachedSize(alloc bool) int64 {
 	}
 	return size
 }
+func (cached *RollupAggregate) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(80)
+	}
+	// field OrderedAggregate vitess.io/vitess/go/vt/vtgate/engine.OrderedAggregate
+	size += cached.OrderedAggregate.CachedSize(false)
+	return size
+}
 func (cached *Route) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -973,6 +1160,10 @@ func (cached *Route) CachedSize(alloc bool) int64 {
 	}
 	// field RoutingParameters *vitess.io/vitess/go/vt/vtgate/engine.RoutingParameters
 	size += cached.RoutingParameters.CachedSize(true)
+	// field Estimate *vitess.io/vitess/go/vt/vtgate/engine.CostEstimate
+	if cached.Estimate != nil {
+		size += hack.RuntimeAllocSize(int64(16))
+	}
 	return size
 }
 
@@ -1597,6 +1788,65 @@ func (cached *VitessMetadata) CachedSize(alloc bool) int64 {
 	size += hack.RuntimeAllocSize(int64(len(cached.Value)))
 	return size
 }
+func (cached *Window) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(112)
+	}
+	// field Cols []int
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.Cols)) * int64(8))
+	}
+	// field Functions []*vitess.io/vitess/go/vt/vtgate/engine.WindowFunc
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.Functions)) * int64(8))
+		for _, elem := range cached.Functions {
+			size += elem.CachedSize(true)
+		}
+	}
+	// field PartitionBy []*vitess.io/vitess/go/vt/vtgate/engine.GroupByParams
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.PartitionBy)) * int64(8))
+		for _, elem := range cached.PartitionBy {
+			size += elem.CachedSize(true)
+		}
+	}
+	// field OrderBy vitess.io/vitess/go/vt/vtgate/evalengine.Comparison
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.OrderBy)) * int64(56))
+		for _, elem := range cached.OrderBy {
+			size += elem.CachedSize(false)
+		}
+	}
+	// field Input vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Input.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	return size
+}
+func (cached *WindowFunc) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(96)
+	}
+	// field Frame *vitess.io/vitess/go/vt/vtgate/engine.WindowFrame
+	if cached.Frame != nil {
+		size += hack.RuntimeAllocSize(int64(40))
+	}
+	// field Alias string
+	size += hack.RuntimeAllocSize(int64(len(cached.Alias)))
+	// field Type vitess.io/vitess/go/vt/vtgate/evalengine.Type
+	size += cached.Type.CachedSize(false)
+	// field CollationEnv *vitess.io/vitess/go/mysql/collations.Environment
+	size += cached.CollationEnv.CachedSize(true)
+	return size
+}
 
 //go:nocheckptr
 func (cached *shardRoute) CachedSize(alloc bool) int64 {
@@ -0,0 +1,284 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"sync"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
+	. "vitess.io/vitess/go/vt/vtgate/engine/opcode"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+var _ Primitive = (*CorrelatedSubquery)(nil)
+
+// CorrelatedSubquery executes a subquery once for every row of the outer side,
+// with the columns of the outer row bound as arguments to the subquery.
+// The result of the subquery is bound the same way as UncorrelatedSubquery does,
+// and it is then used either to filter the outer row, or to produce a column.
+type CorrelatedSubquery struct {
+	Opcode PulloutOpcode
+
+	// SubqueryResult and HasValues are the names of the bind variables the subquery result is bound to
+	SubqueryResult string
+	HasValues      string
+
+	// Vars defines the columns of the outer row that are bound as arguments for the subquery
+	Vars map[string]int
+
+	// Filter, when set, is evaluated against every outer row with the subquery result bound,
+	// and only the rows it evaluates to true for are returned
+	Filter    evalengine.Expr
+	ASTFilter sqlparser.Expr
+
+	// Cols, when set, defines the columns produced by this primitive.
+	// Non-negative values are offsets in the outer row, and -1 is the value of the subquery
+	Cols []int
+
+	Outer    Primitive
+	Subquery Primitive
+}
+
+// Inputs returns the input primitives for this correlated subquery
+func (cs *CorrelatedSubquery) Inputs() ([]Primitive, []map[string]any) {
+	return []Primitive{cs.Outer, cs.Subquery}, []map[string]any{{
+		inputName: "Outer",
+	}, {
+		inputName: "SubQuery",
+	}}
+}
+
+// RouteType returns a description of the query routing type used by the primitive
+func (cs *CorrelatedSubquery) RouteType() string {
+	return cs.Opcode.String()
+}
+
+// GetKeyspaceName specifies the Keyspace that this primitive routes to.
+func (cs *CorrelatedSubquery) GetKeyspaceName() string {
+	return cs.Outer.GetKeyspaceName()
+}
+
+// GetTableName specifies the table that this primitive routes to.
+func (cs *CorrelatedSubquery) GetTableName() string {
+	return cs.Outer.GetTableName()
+}
+
+// NeedsTransaction implements the Primitive interface
+func (cs *CorrelatedSubquery) NeedsTransaction() bool {
+	return cs.Subquery.NeedsTransaction() || cs.Outer.NeedsTransaction()
+}
+
+// TryExecute satisfies the Primitive interface.
+func (cs *CorrelatedSubquery) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	outer, err := vcursor.ExecutePrimitive(ctx, cs.Outer, bindVars, wantfields)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &sqltypes.Result{}
+	if wantfields {
+		result.Fields, err = cs.fields(ctx, vcursor, bindVars, outer.Fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+	result.Rows, err = cs.applyRows(ctx, vcursor, bindVars, outer.Rows)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TryStreamExecute performs a streaming exec.
+func (cs *CorrelatedSubquery) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	var mu sync.Mutex
+	fieldsSent := !wantfields
+	return vcursor.StreamExecutePrimitive(ctx, cs.Outer, bindVars, wantfields, func(outer *sqltypes.Result) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		result := &sqltypes.Result{}
+		if !fieldsSent && len(outer.Fields) > 0 {
+			fields, err := cs.fields(ctx, vcursor, bindVars, outer.Fields)
+			if err != nil {
+				return err
+			}
+			result.Fields = fields
+			fieldsSent = true
+		}
+		rows, err := cs.applyRows(ctx, vcursor, bindVars, outer.Rows)
+		if err != nil {
+			return err
+		}
+		result.Rows = rows
+		return callback(result)
+	})
+}
+
+// GetFields fetches the field info.
+func (cs *CorrelatedSubquery) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	outer, err := cs.Outer.GetFields(ctx, vcursor, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := cs.fields(ctx, vcursor, bindVars, outer.Fields)
+	if err != nil {
+		return nil, err
+	}
+	return &sqltypes.Result{Fields: fields}, nil
+}
+
+func (cs *CorrelatedSubquery) fields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, outerFields []*querypb.Field) ([]*querypb.Field, error) {
+	if cs.Cols == nil {
+		return outerFields, nil
+	}
+
+	var valueField *querypb.Field
+	fields := make([]*querypb.Field, 0, len(cs.Cols))
+	for _, col := range cs.Cols {
+		if col >= 0 {
+			fields = append(fields, outerFields[col])
+			continue
+		}
+		if valueField == nil {
+			var err error
+			valueField, err = cs.valueField(ctx, vcursor, bindVars)
+			if err != nil {
+				return nil, err
+			}
+		}
+		fields = append(fields, valueField)
+	}
+	return fields, nil
+}
+
+func (cs *CorrelatedSubquery) valueField(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*querypb.Field, error) {
+	if cs.Opcode == PulloutExists {
+		return &querypb.Field{Name: cs.HasValues, Type: sqltypes.Int64}, nil
+	}
+	joinVars := make(map[string]*querypb.BindVariable, len(cs.Vars))
+	for k := range cs.Vars {
+		joinVars[k] = sqltypes.NullBindVariable
+	}
+	result, err := cs.Subquery.GetFields(ctx, vcursor, combineVars(bindVars, joinVars))
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Fields) != 1 {
+		return nil, errSqColumn
+	}
+	return result.Fields[0], nil
+}
+
+// applyRows runs the subquery for every row of the outer side and returns the rows to produce
+func (cs *CorrelatedSubquery) applyRows(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, outerRows []sqltypes.Row) ([]sqltypes.Row, error) {
+	rows := make([]sqltypes.Row, 0, len(outerRows))
+	for _, row := range outerRows {
+		combinedVars, err := cs.execSubquery(ctx, vcursor, bindVars, row)
+		if err != nil {
+			return nil, err
+		}
+
+		if cs.Filter != nil {
+			env := evalengine.NewExpressionEnv(ctx, combinedVars, vcursor)
+			env.Row = row
+			evalResult, err := env.Evaluate(cs.Filter)
+			if err != nil {
+				return nil, err
+			}
+			if !evalResult.ToBoolean() {
+				continue
+			}
+		}
+
+		if cs.Cols == nil {
+			rows = append(rows, row)
+			continue
+		}
+		out := make(sqltypes.Row, len(cs.Cols))
+		for i, col := range cs.Cols {
+			if col >= 0 {
+				out[i] = row[col]
+				continue
+			}
+			out[i], err = cs.subqueryValue(combinedVars)
+			if err != nil {
+				return nil, err
+			}
+		}
+		rows = append(rows, out)
+	}
+	return rows, nil
+}
+
+func (cs *CorrelatedSubquery) execSubquery(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, row sqltypes.Row) (map[string]*querypb.BindVariable, error) {
+	combinedVars := make(map[string]*querypb.BindVariable, len(bindVars)+len(cs.Vars)+2)
+	for k, v := range bindVars {
+		combinedVars[k] = v
+	}
+	for k, col := range cs.Vars {
+		combinedVars[k] = sqltypes.ValueBindVariable(row[col])
+	}
+	result, err := vcursor.ExecutePrimitive(ctx, cs.Subquery, combinedVars, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := bindSubqueryResult(cs.Opcode, result, cs.SubqueryResult, cs.HasValues, combinedVars); err != nil {
+		return nil, err
+	}
+	return combinedVars, nil
+}
+
+// subqueryValue returns the value produced by the subquery for the column it adds to the outer row
+func (cs *CorrelatedSubquery) subqueryValue(bindVars map[string]*querypb.BindVariable) (sqltypes.Value, error) {
+	name := cs.SubqueryResult
+	if cs.Opcode == PulloutExists {
+		name = cs.HasValues
+	}
+	return sqltypes.BindVariableToValue(bindVars[name])
+}
+
+func (cs *CorrelatedSubquery) description() PrimitiveDescription {
+	other := map[string]any{}
+	var pulloutVars []string
+	if cs.HasValues != "" {
+		pulloutVars = append(pulloutVars, cs.HasValues)
+	}
+	if cs.SubqueryResult != "" {
+		pulloutVars = append(pulloutVars, cs.SubqueryResult)
+	}
+	if len(pulloutVars) > 0 {
+		other["PulloutVars"] = pulloutVars
+	}
+	if len(cs.Vars) > 0 {
+		other["JoinVars"] = orderedStringIntMap(cs.Vars)
+	}
+	if cs.Filter != nil {
+		other["Predicate"] = sqlparser.String(cs.ASTFilter)
+	}
+	if len(cs.Cols) > 0 {
+		other["Columns"] = cs.Cols
+	}
+	return PrimitiveDescription{
+		OperatorType: "CorrelatedSubquery",
+		Variant:      cs.Opcode.String(),
+		Other:        other,
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtenv"
+	. "vitess.io/vitess/go/vt/vtgate/engine/opcode"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+func correlatedOuterInput() *fakePrimitive {
+	return &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields(
+				"id|col",
+				"int64|int64",
+			),
+			"1|10",
+			"2|20",
+			"3|30",
+		)},
+	}
+}
+
+func TestCorrelatedSubqueryFilter(t *testing.T) {
+	// col = :__sq1
+	predicate, err := evalengine.Translate(&sqlparser.ComparisonExpr{
+		Operator: sqlparser.EqualOp,
+		Left:     sqlparser.NewOffset(1, nil),
+		Right:    sqlparser.NewArgument("__sq1"),
+	}, &evalengine.Config{
+		Collation:   collations.MySQL8().DefaultConnectionCharset(),
+		Environment: vtenv.NewTestEnv(),
+	})
+	require.NoError(t, err)
+
+	subqueryFields := sqltypes.MakeTestFields("max(col)", "int64")
+	outer := correlatedOuterInput()
+	subquery := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(subqueryFields, "10"),
+			sqltypes.MakeTestResult(subqueryFields, "5"),
+			sqltypes.MakeTestResult(subqueryFields, "30"),
+		},
+	}
+
+	cs := &CorrelatedSubquery{
+		Opcode:         PulloutValue,
+		SubqueryResult: "__sq1",
+		Vars:           map[string]int{"id": 0},
+		Filter:         predicate,
+		Outer:          outer,
+		Subquery:       subquery,
+	}
+
+	r, err := cs.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, true)
+	require.NoError(t, err)
+	subquery.ExpectLog(t, []string{
+		`Execute id: type:INT64 value:"1" false`,
+		`Execute id: type:INT64 value:"2" false`,
+		`Execute id: type:INT64 value:"3" false`,
+	})
+	utils.MustMatch(t, sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"id|col",
+			"int64|int64",
+		),
+		"1|10",
+		"3|30",
+	), r)
+}
+
+func TestCorrelatedSubqueryValue(t *testing.T) {
+	subqueryFields := sqltypes.MakeTestFields("max(col)", "int64")
+	outer := correlatedOuterInput()
+	subquery := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(subqueryFields, "10"),
+			sqltypes.MakeTestResult(subqueryFields),
+			sqltypes.MakeTestResult(subqueryFields, "30"),
+		},
+	}
+
+	cs := &CorrelatedSubquery{
+		Opcode:         PulloutValue,
+		SubqueryResult: "__sq1",
+		Vars:           map[string]int{"col": 1},
+		Cols:           []int{0, -1},
+		Outer:          outer,
+		Subquery:       subquery,
+	}
+
+	var results []*sqltypes.Result
+	err := cs.TryStreamExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, false, func(qr *sqltypes.Result) error {
+		results = append(results, qr)
+		return nil
+	})
+	require.NoError(t, err)
+	subquery.ExpectLog(t, []string{
+		`Execute col: type:INT64 value:"10" false`,
+		`Execute col: type:INT64 value:"20" false`,
+		`Execute col: type:INT64 value:"30" false`,
+	})
+	var rows []sqltypes.Row
+	for _, qr := range results {
+		rows = append(rows, qr.Rows...)
+	}
+	utils.MustMatch(t, []sqltypes.Row{
+		{sqltypes.NewInt64(1), sqltypes.NewInt64(10)},
+		{sqltypes.NewInt64(2), sqltypes.NULL},
+		{sqltypes.NewInt64(3), sqltypes.NewInt64(30)},
+	}, rows)
+}
+
+func TestCorrelatedSubqueryTooManyRows(t *testing.T) {
+	subqueryFields := sqltypes.MakeTestFields("col", "int64")
+	cs := &CorrelatedSubquery{
+		Opcode:         PulloutValue,
+		SubqueryResult: "__sq1",
+		Vars:           map[string]int{"col": 1},
+		Cols:           []int{-1},
+		Outer:          correlatedOuterInput(),
+		Subquery: &fakePrimitive{
+			results: []*sqltypes.Result{sqltypes.MakeTestResult(subqueryFields, "1", "2")},
+		},
+	}
+
+	_, err := cs.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+	require.EqualError(t, err, "subquery returned more than one row")
+}
+
+func TestCorrelatedSubqueryExistsFields(t *testing.T) {
+	cs := &CorrelatedSubquery{
+		Opcode:    PulloutExists,
+		HasValues: "__sq1",
+		Vars:      map[string]int{"col": 1},
+		Cols:      []int{0, -1},
+		Outer:     correlatedOuterInput(),
+		Subquery:  &fakePrimitive{},
+	}
+
+	r, err := cs.GetFields(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{})
+	require.NoError(t, err)
+	utils.MustMatch(t, []*querypb.Field{
+		{Name: "id", Type: sqltypes.Int64},
+		{Name: "__sq1", Type: sqltypes.Int64},
+	}, r.Fields)
+}
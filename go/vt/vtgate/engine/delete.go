@@ -21,7 +21,6 @@ import (
 	"fmt"
 
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
-	"vitess.io/vitess/go/vt/sqlparser"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/srvtopo"
@@ -57,7 +56,7 @@ func (del *Delete) TryExecute(ctx context.Context, vcursor VCursor, bindVars map
 	switch del.Opcode {
 	case Unsharded:
 		return del.execUnsharded(ctx, del, vcursor, bindVars, rss)
-	case Equal, IN, Scatter, ByDestination, SubShard, EqualUnique, MultiEqual:
+	case Equal, IN, Scatter, ByDestination, SubShard, EqualUnique, MultiEqual, Range:
 		return del.execMultiDestination(ctx, del, vcursor, bindVars, rss, del.deleteVindexEntries, bvs)
 	default:
 		// Unreachable.
@@ -153,10 +152,6 @@ func addFieldsIfNotEmpty(dml *DML, other map[string]any) {
 		other["KsidLength"] = dml.KsidLength
 	}
 	if len(dml.Values) > 0 {
-		s := []string{}
-		for _, value := range dml.Values {
-			s = append(s, sqlparser.String(value))
-		}
-		other["Values"] = s
+		other["Values"] = dml.formatValues()
 	}
 }
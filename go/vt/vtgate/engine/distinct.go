@@ -67,6 +67,18 @@ func (pt *probeTable) exists(inputRow sqltypes.Row) (sqltypes.Row, error) {
 	return inputRow, nil
 }
 
+// spillIfNotSeen writes the row to the partition for its hash code, unless it has already been seen
+func (pt *probeTable) spillIfNotSeen(partitions spillPartitions, inputRow sqltypes.Row) error {
+	code, err := pt.hashCodeForRow(inputRow)
+	if err != nil {
+		return err
+	}
+	if _, found := pt.seenRows[code]; found {
+		return nil
+	}
+	return partitions.write(code, inputRow)
+}
+
 func (pt *probeTable) hashCodeForRow(inputRow sqltypes.Row) (vthash.Hash, error) {
 	hasher := vthash.New()
 	for i, checkCol := range pt.checkCols {
@@ -102,6 +114,9 @@ func newProbeTable(checkCols []CheckCol, collationEnv *collations.Environment) *
 
 // TryExecute implements the Primitive interface
 func (d *Distinct) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	if vcursor.SpillToDisk() != nil {
+		return executeStreaming(ctx, vcursor, d, bindVars, wantfields)
+	}
 	input, err := vcursor.ExecutePrimitive(ctx, d.Source, bindVars, wantfields)
 	if err != nil {
 		return nil, err
@@ -134,6 +149,13 @@ func (d *Distinct) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVa
 	var mu sync.Mutex
 
 	pt := newProbeTable(d.CheckCols, vcursor.Environment().CollationEnv())
+	// once we have seen too many rows to keep in memory, the rows we have not seen yet are
+	// partitioned to disk by their hash code, and deduplicated one partition at a time at the end
+	var partitions spillPartitions
+	defer func() {
+		partitions.close()
+	}()
+
 	err := vcursor.StreamExecutePrimitive(ctx, d.Source, bindVars, wantfields, func(input *sqltypes.Result) error {
 		result := &sqltypes.Result{
 			Fields:   input.Fields,
@@ -142,6 +164,12 @@ func (d *Distinct) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVa
 		mu.Lock()
 		defer mu.Unlock()
 		for _, row := range input.Rows {
+			if partitions != nil {
+				if err := pt.spillIfNotSeen(partitions, row); err != nil {
+					return err
+				}
+				continue
+			}
 			appendRow, err := pt.exists(row)
 			if err != nil {
 				return err
@@ -150,10 +178,39 @@ func (d *Distinct) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVa
 				result.Rows = append(result.Rows, appendRow)
 			}
 		}
+		if partitions == nil && vcursor.ExceedsMaxMemoryRows(len(pt.seenRows)) {
+			if spiller := vcursor.SpillToDisk(); spiller != nil {
+				var err error
+				if partitions, err = spiller.newPartitions("Distinct"); err != nil {
+					return err
+				}
+			}
+		}
 		return callback(result.Truncate(len(d.CheckCols)))
 	})
+	if err != nil || partitions == nil {
+		return err
+	}
 
-	return err
+	return sendInBatches(func(send func(row sqltypes.Row) error) error {
+		for _, partition := range partitions {
+			seen := newProbeTable(d.CheckCols, vcursor.Environment().CollationEnv())
+			err := partition.forEach(func(row sqltypes.Row) error {
+				appendRow, err := seen.exists(row)
+				if err != nil || appendRow == nil {
+					return err
+				}
+				return send(appendRow)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}, func(rows []sqltypes.Row) error {
+		result := &sqltypes.Result{Rows: rows}
+		return callback(result.Truncate(len(d.CheckCols)))
+	})
 }
 
 // RouteType implements the Primitive interface
@@ -184,6 +184,41 @@ func TestDistinctStreamAsync(t *testing.T) {
 [VARCHAR("a") INT64(1) INT64(1) VARCHAR("t")]]`, qr.Rows))
 }
 
+func TestDistinctStreamSpillToDisk(t *testing.T) {
+	withSpillToDisk(t, 2, SpillConfig{})
+
+	fields := sqltypes.MakeTestFields("myid|id", "varchar|int64")
+	distinct := &Distinct{
+		Source: &fakePrimitive{
+			results: []*sqltypes.Result{sqltypes.MakeTestResult(fields,
+				"a|1",
+				"b|1",
+				"a|1",
+				"c|1",
+				"b|1",
+				"d|2",
+				"c|1",
+				"d|2",
+				"a|2",
+			)},
+		},
+		CheckCols: []CheckCol{
+			{Col: 0, Type: evalengine.NewType(sqltypes.VarChar, collations.CollationUtf8mb4ID)},
+			{Col: 1, Type: evalengine.NewType(sqltypes.Int64, collations.CollationBinaryID)},
+		},
+	}
+
+	result, err := wrapStreamExecute(distinct, &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	expectResultAnyOrder(t, result, sqltypes.MakeTestResult(fields,
+		"a|1",
+		"b|1",
+		"c|1",
+		"d|2",
+		"a|2",
+	))
+}
+
 func TestWeightStringFallBack(t *testing.T) {
 	offsetOne := 1
 	checkCols := []CheckCol{{
@@ -217,3 +252,41 @@ func TestWeightStringFallBack(t *testing.T) {
 		Type:  evalengine.NewType(sqltypes.VarBinary, collations.CollationBinaryID),
 	}}, distinct.CheckCols, "checkCols should not be updated")
 }
+
+func TestDistinctExecuteSpillToDisk(t *testing.T) {
+	withSpillToDisk(t, 5, SpillConfig{})
+
+	fields := sqltypes.MakeTestFields("myid|id", "varchar|int64")
+	source := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(fields,
+			"a|1",
+			"b|1",
+			"a|1",
+			"c|1",
+			"b|1",
+			"d|2",
+			"c|1",
+			"d|2",
+			"a|2",
+		)},
+	}
+	distinct := &Distinct{
+		Source: source,
+		CheckCols: []CheckCol{
+			{Col: 0, Type: evalengine.NewType(sqltypes.VarChar, collations.CollationUtf8mb4ID)},
+			{Col: 1, Type: evalengine.NewType(sqltypes.Int64, collations.CollationBinaryID)},
+		},
+	}
+
+	// the input has more rows than max_memory_rows, but it is streamed
+	result, err := distinct.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	expectResultAnyOrder(t, result, sqltypes.MakeTestResult(fields,
+		"a|1",
+		"b|1",
+		"c|1",
+		"d|2",
+		"a|2",
+	))
+	require.Equal(t, []string{"StreamExecute  true"}, source.log)
+}
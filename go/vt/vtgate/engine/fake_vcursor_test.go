@@ -48,6 +48,7 @@ import (
 
 var testMaxMemoryRows = 100
 var testIgnoreMaxMemoryRows = false
+var testSpiller *Spiller
 
 var _ VCursor = (*noopVCursor)(nil)
 var _ SessionActions = (*noopVCursor)(nil)
@@ -171,9 +172,7 @@ func (t *noopVCursor) HasSystemVariables() bool {
 	panic("implement me")
 }
 
-func (t *noopVCursor) GetSystemVariables(func(k string, v string)) {
-	panic("implement me")
-}
+func (t *noopVCursor) GetSystemVariables(func(k string, v string)) {}
 
 func (t *noopVCursor) GetWarnings() []*querypb.QueryWarning {
 	panic("implement me")
@@ -239,6 +238,10 @@ func (t *noopVCursor) HasCreatedTempTable() {
 	panic("implement me")
 }
 
+func (t *noopVCursor) AfterTransaction(f func()) {
+	panic("implement me")
+}
+
 func (t *noopVCursor) LookupRowLockShardSession() vtgatepb.CommitOrder {
 	panic("implement me")
 }
@@ -345,6 +348,10 @@ func (t *noopVCursor) ExceedsMaxMemoryRows(numRows int) bool {
 	return !testIgnoreMaxMemoryRows && numRows > testMaxMemoryRows
 }
 
+func (t *noopVCursor) SpillToDisk() *Spiller {
+	return testSpiller
+}
+
 func (t *noopVCursor) GetKeyspace() string {
 	return "test_ks"
 }
@@ -474,8 +481,10 @@ func (f *loggingVCursor) HasSystemVariables() bool {
 	return len(f.systemVariables) > 0
 }
 
-func (f *loggingVCursor) GetSystemVariables(func(k string, v string)) {
-	panic("implement me")
+func (f *loggingVCursor) GetSystemVariables(fn func(k string, v string)) {
+	for k, v := range f.systemVariables {
+		fn(k, v)
+	}
 }
 
 func (f *loggingVCursor) SetFoundRows(u uint64) {
@@ -486,6 +495,10 @@ func (f *loggingVCursor) InTransactionAndIsDML() bool {
 	return false
 }
 
+func (f *loggingVCursor) AfterTransaction(fn func()) {
+	fn()
+}
+
 func (f *loggingVCursor) LookupRowLockShardSession() vtgatepb.CommitOrder {
 	panic("implement me")
 }
@@ -36,9 +36,9 @@ var _ Primitive = (*HashJoin)(nil)
 type (
 	// HashJoin specifies the parameters for a join primitive
 	// Hash joins work by fetch all the input from the LHS, and building a hash map, known as the probe table, for this input.
-	// The key to the map is the hashcode of the value for column that we are joining by.
+	// The key to the map is the hashcode of the values for the columns that we are joining by.
 	// Then the RHS is fetched, and we can check if the rows from the RHS matches any from the LHS.
-	// When they match by hash code, we double-check that we are not working with a false positive by comparing the values.
+	// The rows that match by hash code are then checked against the rest of the join condition, if there is any.
 	HashJoin struct {
 		Opcode JoinOpcode
 
@@ -55,33 +55,53 @@ type (
 		// the returned result will be {Left0, Left1, Right0, Right1}.
 		Cols []int
 
-		// The keys correspond to the column offset in the inputs where
-		// the join columns can be found
-		LHSKey, RHSKey int
+		// Keys are the columns from both sides that are compared for equality.
+		// The values of all the keys are hashed together to build and probe the hash table
+		Keys []HashJoinKey
 
 		// The join condition. Used for plan descriptions
 		ASTPred sqlparser.Expr
 
-		// collation and type are used to hash the incoming values correctly
-		Collation      collations.ID
-		ComparisonType querypb.Type
+		// Filter is the part of the join condition that can't be solved by comparing the keys,
+		// such as non-equality comparisons. It is evaluated for every pair of rows with matching keys,
+		// over the row built from FilterCols. FilterCols use the same encoding as Cols.
+		Filter     evalengine.Expr
+		FilterCols []int
 
 		CollationEnv *collations.Environment
 
+		// Estimate is the planner's estimate of the cost of this join, if table statistics were available
+		Estimate *CostEstimate
+	}
+
+	// HashJoinKey is a pair of columns, one from each side of the join, that must be equal for the rows to match
+	HashJoinKey struct {
+		// LHS and RHS are the column offsets in the inputs where the join columns can be found
+		LHS, RHS int
+
+		// collation and type are used to hash the incoming values correctly
+		Collation collations.ID
+		Type      querypb.Type
+
 		// Values for enum and set types
 		Values *evalengine.EnumSetValues
+
+		// NullSafe is set when the columns are compared using <=>, and NULL values match each other
+		NullSafe bool
 	}
 
 	hashJoinProbeTable struct {
 		innerMap map[vthash.Hash]*probeTableEntry
 
-		coll           collations.ID
-		typ            querypb.Type
-		lhsKey, rhsKey int
-		cols           []int
-		hasher         vthash.Hasher
-		sqlmode        evalengine.SQLMode
-		values         *evalengine.EnumSetValues
+		hj      *HashJoin
+		env     *evalengine.ExpressionEnv
+		hasher  vthash.Hasher
+		sqlmode evalengine.SQLMode
+		rows    int
+
+		// when the LHS has too many rows to keep in memory, the rows from both sides are
+		// partitioned to disk by the hash of the join key, and joined one partition at a time
+		lhsPartitions, rhsPartitions spillPartitions
 	}
 
 	probeTableEntry struct {
@@ -93,12 +113,15 @@ type (
 
 // TryExecute implements the Primitive interface
 func (hj *HashJoin) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	if vcursor.SpillToDisk() != nil {
+		return executeStreaming(ctx, vcursor, hj, bindVars, wantfields)
+	}
 	lresult, err := vcursor.ExecutePrimitive(ctx, hj.Left, bindVars, wantfields)
 	if err != nil {
 		return nil, err
 	}
 
-	pt := newHashJoinProbeTable(hj.Collation, hj.ComparisonType, hj.LHSKey, hj.RHSKey, hj.Cols, hj.Values)
+	pt := newHashJoinProbeTable(hj, evalengine.NewExpressionEnv(ctx, bindVars, vcursor))
 	// build the probe table from the LHS result
 	for _, row := range lresult.Rows {
 		err := pt.addLeftRow(row)
@@ -134,7 +157,8 @@ func (hj *HashJoin) TryExecute(ctx context.Context, vcursor VCursor, bindVars ma
 // TryStreamExecute implements the Primitive interface
 func (hj *HashJoin) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
 	// build the probe table from the LHS result
-	pt := newHashJoinProbeTable(hj.Collation, hj.ComparisonType, hj.LHSKey, hj.RHSKey, hj.Cols, hj.Values)
+	pt := newHashJoinProbeTable(hj, evalengine.NewExpressionEnv(ctx, bindVars, vcursor))
+	defer pt.close()
 	var lfields []*querypb.Field
 	var mu sync.Mutex
 	err := vcursor.StreamExecutePrimitive(ctx, hj.Left, bindVars, wantfields, func(result *sqltypes.Result) error {
@@ -149,6 +173,11 @@ func (hj *HashJoin) TryStreamExecute(ctx context.Context, vcursor VCursor, bindV
 				return err
 			}
 		}
+		if !pt.spilled() && vcursor.ExceedsMaxMemoryRows(pt.rows) {
+			if spiller := vcursor.SpillToDisk(); spiller != nil {
+				return pt.spill(spiller)
+			}
+		}
 		return nil
 	})
 	if err != nil {
@@ -182,23 +211,39 @@ func (hj *HashJoin) TryStreamExecute(ctx context.Context, vcursor VCursor, bindV
 		return err
 	}
 
-	if hj.Opcode == LeftJoin {
-		res := &sqltypes.Result{}
-		if sendFields.CompareAndSwap(true, false) {
-			// If we still have not sent the fields, we need to fetch
-			// the fields from the RHS to be able to build the result fields
-			rres, err := hj.Right.GetFields(ctx, vcursor, bindVars)
-			if err != nil {
-				return err
-			}
-			res.Fields = joinFields(lfields, rres.Fields, hj.Cols)
+	if hj.Opcode != LeftJoin && !pt.spilled() {
+		return nil
+	}
+
+	res := &sqltypes.Result{}
+	if hj.Opcode == LeftJoin && sendFields.CompareAndSwap(true, false) {
+		// If we still have not sent the fields, we need to fetch
+		// the fields from the RHS to be able to build the result fields
+		rres, err := hj.Right.GetFields(ctx, vcursor, bindVars)
+		if err != nil {
+			return err
 		}
-		// this will only be called when all the concurrent access to the pt has
-		// ceased, so we don't need to lock it here
+		res.Fields = joinFields(lfields, rres.Fields, hj.Cols)
+	}
+	// this will only be called when all the concurrent access to the pt has
+	// ceased, so we don't need to lock it here
+	if !pt.spilled() {
 		res.Rows = pt.notFetched()
 		return callback(res)
 	}
-	return nil
+
+	err = sendInBatches(func(send func(row sqltypes.Row) error) error {
+		return pt.joinPartitions(hj.Opcode == LeftJoin, send)
+	}, func(rows []sqltypes.Row) error {
+		res.Rows = rows
+		err := callback(res)
+		res = &sqltypes.Result{}
+		return err
+	})
+	if err != nil || len(res.Fields) == 0 {
+		return err
+	}
+	return callback(res)
 }
 
 // RouteType implements the Primitive interface
@@ -251,12 +296,21 @@ func (hj *HashJoin) description() PrimitiveDescription {
 		"TableName":         hj.GetTableName(),
 		"JoinColumnIndexes": strings.Trim(strings.Join(strings.Fields(fmt.Sprint(hj.Cols)), ","), "[]"),
 		"Predicate":         sqlparser.String(hj.ASTPred),
-		"ComparisonType":    hj.ComparisonType.String(),
 	}
-	coll := hj.Collation
-	if coll != collations.Unknown {
-		other["Collation"] = hj.CollationEnv.LookupName(coll)
+	var types, colls []string
+	for _, key := range hj.Keys {
+		types = append(types, key.Type.String())
+		if key.Collation != collations.Unknown {
+			colls = append(colls, hj.CollationEnv.LookupName(key.Collation))
+		}
+	}
+	if len(types) > 0 {
+		other["ComparisonType"] = strings.Join(types, ", ")
+	}
+	if len(colls) > 0 {
+		other["Collation"] = strings.Join(colls, ", ")
 	}
+	hj.Estimate.addToDescription(other)
 	return PrimitiveDescription{
 		OperatorType: "Join",
 		Variant:      "Hash" + hj.Opcode.String(),
@@ -264,67 +318,161 @@ func (hj *HashJoin) description() PrimitiveDescription {
 	}
 }
 
-func newHashJoinProbeTable(coll collations.ID, typ querypb.Type, lhsKey, rhsKey int, cols []int, values *evalengine.EnumSetValues) *hashJoinProbeTable {
+func newHashJoinProbeTable(hj *HashJoin, env *evalengine.ExpressionEnv) *hashJoinProbeTable {
 	return &hashJoinProbeTable{
 		innerMap: map[vthash.Hash]*probeTableEntry{},
-		coll:     coll,
-		typ:      typ,
-		lhsKey:   lhsKey,
-		rhsKey:   rhsKey,
-		cols:     cols,
+		hj:       hj,
+		env:      env,
 		hasher:   vthash.New(),
-		values:   values,
 	}
 }
 
 func (pt *hashJoinProbeTable) addLeftRow(r sqltypes.Row) error {
-	hash, err := pt.hash(r[pt.lhsKey])
+	// rows with NULL keys are kept even if they can't match anything, since they are needed for outer joins
+	hash, _, err := pt.hash(r, true)
 	if err != nil {
 		return err
 	}
+	if pt.spilled() {
+		return pt.lhsPartitions.write(hash, r)
+	}
 	pt.innerMap[hash] = &probeTableEntry{
 		row:  r,
 		next: pt.innerMap[hash],
 	}
+	pt.rows++
 
 	return nil
 }
 
-func (pt *hashJoinProbeTable) hash(val sqltypes.Value) (vthash.Hash, error) {
-	err := evalengine.NullsafeHashcode128(&pt.hasher, val, pt.coll, pt.typ, pt.sqlmode, pt.values)
-	if err != nil {
-		return vthash.Hash{}, err
+func (pt *hashJoinProbeTable) spilled() bool {
+	return pt.lhsPartitions != nil
+}
+
+// spill moves the LHS rows to disk. From then on, the rows from both sides are written
+// to disk as well, and are joined by joinPartitions once the inputs have been consumed
+func (pt *hashJoinProbeTable) spill(spiller *Spiller) (err error) {
+	if pt.lhsPartitions, err = spiller.newPartitions("HashJoin"); err != nil {
+		return err
 	}
+	if pt.rhsPartitions, err = spiller.newPartitions("HashJoin"); err != nil {
+		return err
+	}
+	for hash, e := range pt.innerMap {
+		for ; e != nil; e = e.next {
+			if err := pt.lhsPartitions.write(hash, e.row); err != nil {
+				return err
+			}
+		}
+	}
+	clear(pt.innerMap)
+	pt.rows = 0
+	return nil
+}
 
-	res := pt.hasher.Sum128()
-	pt.hasher.Reset()
-	return res, nil
+// joinPartitions joins the rows that were spilled to disk, one partition at a time
+func (pt *hashJoinProbeTable) joinPartitions(leftJoin bool, send func(row sqltypes.Row) error) error {
+	for i := range pt.lhsPartitions {
+		partition := newHashJoinProbeTable(pt.hj, pt.env)
+		partition.sqlmode = pt.sqlmode
+		if err := pt.lhsPartitions[i].forEach(partition.addLeftRow); err != nil {
+			return err
+		}
+		err := pt.rhsPartitions[i].forEach(func(row sqltypes.Row) error {
+			matches, err := partition.get(row)
+			if err != nil {
+				return err
+			}
+			for _, match := range matches {
+				if err := send(match); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if !leftJoin {
+			continue
+		}
+		for _, row := range partition.notFetched() {
+			if err := send(row); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
-func (pt *hashJoinProbeTable) get(rrow sqltypes.Row) (result []sqltypes.Row, err error) {
-	val := rrow[pt.rhsKey]
-	if val.IsNull() {
-		return
+func (pt *hashJoinProbeTable) close() {
+	pt.lhsPartitions.close()
+	pt.rhsPartitions.close()
+}
+
+// hash returns the hash code of the key columns of a row coming from the LHS or the RHS.
+// canMatch is false when one of the keys is NULL, and the row can't match any row from the other side
+func (pt *hashJoinProbeTable) hash(row sqltypes.Row, lhs bool) (hash vthash.Hash, canMatch bool, err error) {
+	defer pt.hasher.Reset()
+	canMatch = true
+	for _, key := range pt.hj.Keys {
+		val := row[key.RHS]
+		if lhs {
+			val = row[key.LHS]
+		}
+		if val.IsNull() && !key.NullSafe {
+			canMatch = false
+		}
+		err = evalengine.NullsafeHashcode128(&pt.hasher, val, key.Collation, key.Type, pt.sqlmode, key.Values)
+		if err != nil {
+			return vthash.Hash{}, false, err
+		}
 	}
+	return pt.hasher.Sum128(), canMatch, nil
+}
 
-	hash, err := pt.hash(val)
-	if err != nil {
+func (pt *hashJoinProbeTable) get(rrow sqltypes.Row) (result []sqltypes.Row, err error) {
+	hash, canMatch, err := pt.hash(rrow, false)
+	if err != nil || !canMatch {
 		return nil, err
 	}
+	if pt.spilled() {
+		return nil, pt.rhsPartitions.write(hash, rrow)
+	}
 
 	for e := pt.innerMap[hash]; e != nil; e = e.next {
+		match, err := pt.matchesFilter(e.row, rrow)
+		if err != nil {
+			return nil, err
+		}
+		if !match {
+			continue
+		}
 		e.seen = true
-		result = append(result, joinRows(e.row, rrow, pt.cols))
+		result = append(result, joinRows(e.row, rrow, pt.hj.Cols))
 	}
 
 	return
 }
 
+// matchesFilter checks the rows with matching keys against the rest of the join condition
+func (pt *hashJoinProbeTable) matchesFilter(lrow, rrow sqltypes.Row) (bool, error) {
+	if pt.hj.Filter == nil {
+		return true, nil
+	}
+	pt.env.Row = joinRows(lrow, rrow, pt.hj.FilterCols)
+	res, err := pt.env.Evaluate(pt.hj.Filter)
+	if err != nil {
+		return false, err
+	}
+	return res.ToBoolean(), nil
+}
+
 func (pt *hashJoinProbeTable) notFetched() (rows []sqltypes.Row) {
 	for _, e := range pt.innerMap {
 		for ; e != nil; e = e.next {
 			if !e.seen {
-				rows = append(rows, joinRows(e.row, nil, pt.cols))
+				rows = append(rows, joinRows(e.row, nil, pt.hj.Cols))
 			}
 		}
 	}
@@ -25,6 +25,8 @@ import (
 	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/sqltypes"
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
 )
 
@@ -136,13 +138,15 @@ func TestHashJoinVariations(t *testing.T) {
 		require.NoError(t, err)
 
 		jn := &HashJoin{
-			Opcode:         tc.typ,
-			Cols:           []int{-1, -2, 1, 2},
-			LHSKey:         tc.lhs,
-			RHSKey:         tc.rhs,
-			Collation:      typ.Collation(),
-			ComparisonType: typ.Type(),
-			CollationEnv:   collations.MySQL8(),
+			Opcode: tc.typ,
+			Cols:   []int{-1, -2, 1, 2},
+			Keys: []HashJoinKey{{
+				LHS:       tc.lhs,
+				RHS:       tc.rhs,
+				Collation: typ.Collation(),
+				Type:      typ.Type(),
+			}},
+			CollationEnv: collations.MySQL8(),
 		}
 
 		t.Run(tc.name, func(t *testing.T) {
@@ -159,6 +163,23 @@ func TestHashJoinVariations(t *testing.T) {
 			require.NoError(t, err)
 			expectResultAnyOrder(t, r, expected)
 		})
+		t.Run("Spilling "+tc.name, func(t *testing.T) {
+			withSpillToDisk(t, 1, SpillConfig{})
+			jn.Left = first()
+			jn.Right = last()
+			r, err := wrapStreamExecute(jn, &noopVCursor{}, map[string]*querypb.BindVariable{}, true)
+			require.NoError(t, err)
+			expectResultAnyOrder(t, r, expected)
+		})
+		t.Run("Spilling Execute "+tc.name, func(t *testing.T) {
+			// the result is still held in memory, so it has to fit in max_memory_rows
+			withSpillToDisk(t, len(tc.expected), SpillConfig{})
+			jn.Left = first()
+			jn.Right = last()
+			r, err := jn.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, true)
+			require.NoError(t, err)
+			expectResultAnyOrder(t, r, expected)
+		})
 	}
 }
 
@@ -172,3 +193,99 @@ func typeForOffset(i int) evalengine.Type {
 		panic(i)
 	}
 }
+
+func TestHashJoinMultipleKeysAndFilter(t *testing.T) {
+	// the rows are joined on a = d and b <=> e, and only kept if c > f
+	lhs := func() Primitive {
+		return &fakePrimitive{
+			results: []*sqltypes.Result{
+				sqltypes.MakeTestResult(
+					sqltypes.MakeTestFields("a|b|c", "int64|varchar|int64"),
+					"1|x|10",
+					"1|y|20",
+					"2|x|30",
+					"2|null|40",
+					"null|x|50",
+				),
+			},
+		}
+	}
+	rhs := func() Primitive {
+		return &fakePrimitive{
+			results: []*sqltypes.Result{
+				sqltypes.MakeTestResult(
+					sqltypes.MakeTestFields("d|e|f", "int64|varchar|int64"),
+					"1|x|5",
+					"1|x|15",
+					"1|y|25",
+					"2|x|0",
+					"2|null|0",
+					"null|x|0",
+				),
+			},
+		}
+	}
+
+	filterFields := sqltypes.MakeTestFields("c|f", "int64|int64")
+	filter, err := evalengine.Translate(&sqlparser.ComparisonExpr{
+		Operator: sqlparser.GreaterThanOp,
+		Left:     sqlparser.NewColName("c"),
+		Right:    sqlparser.NewColName("f"),
+	}, &evalengine.Config{
+		Collation:     collations.CollationBinaryID,
+		ResolveColumn: evalengine.FieldResolver(filterFields).Column,
+		Environment:   vtenv.NewTestEnv(),
+	})
+	require.NoError(t, err)
+
+	fields := sqltypes.MakeTestFields("a|b|c|f", "int64|varchar|int64|int64")
+	tests := []struct {
+		name     string
+		typ      JoinOpcode
+		expected []string
+	}{{
+		name:     "inner join",
+		typ:      InnerJoin,
+		expected: []string{"1|x|10|5", "2|x|30|0", "2|null|40|0"},
+	}, {
+		name:     "left join",
+		typ:      LeftJoin,
+		expected: []string{"1|x|10|5", "2|x|30|0", "2|null|40|0", "1|y|20|null", "null|x|50|null"},
+	}}
+
+	for _, tc := range tests {
+		jn := &HashJoin{
+			Opcode: tc.typ,
+			Cols:   []int{-1, -2, -3, 3},
+			Keys: []HashJoinKey{{
+				LHS:  0,
+				RHS:  0,
+				Type: sqltypes.Int64,
+			}, {
+				LHS:       1,
+				RHS:       1,
+				Collation: collations.MySQL8().DefaultConnectionCharset(),
+				Type:      sqltypes.VarChar,
+				NullSafe:  true,
+			}},
+			Filter:       filter,
+			FilterCols:   []int{-3, 3},
+			CollationEnv: collations.MySQL8(),
+		}
+		expected := sqltypes.MakeTestResult(fields, tc.expected...)
+
+		t.Run(tc.name, func(t *testing.T) {
+			jn.Left, jn.Right = lhs(), rhs()
+			r, err := jn.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, true)
+			require.NoError(t, err)
+			expectResultAnyOrder(t, r, expected)
+		})
+		t.Run("Spilling "+tc.name, func(t *testing.T) {
+			withSpillToDisk(t, 1, SpillConfig{})
+			jn.Left, jn.Right = lhs(), rhs()
+			r, err := wrapStreamExecute(jn, &noopVCursor{}, map[string]*querypb.BindVariable{}, true)
+			require.NoError(t, err)
+			expectResultAnyOrder(t, r, expected)
+		})
+	}
+}
@@ -47,6 +47,14 @@ type (
 		// VindexValueOffset stores the offset for each column in the ColumnVindex
 		// that will appear in the result set of the select query.
 		VindexValueOffset [][]int
+
+		// Delete is used by REPLACE INTO ... SELECT to remove the rows clashing with the selected rows
+		// before they are inserted. Deleting through vtgate keeps the owned vindexes up to date.
+		// It is executed with the primary key values of the selected rows bound to DmlVals.
+		Delete Primitive
+
+		// PKOffsets stores the offset of the primary key columns in the result set of the select query.
+		PKOffsets []int
 	}
 )
 
@@ -83,7 +91,14 @@ func newInsertSelect(
 }
 
 func (ins *InsertSelect) Inputs() ([]Primitive, []map[string]any) {
-	return []Primitive{ins.Input}, nil
+	if ins.Delete == nil {
+		return []Primitive{ins.Input}, nil
+	}
+	return []Primitive{ins.Input, ins.Delete}, []map[string]any{{
+		inputName: "Select",
+	}, {
+		inputName: "Delete",
+	}}
 }
 
 // RouteType returns a description of the query routing type used by the primitive
@@ -157,8 +172,38 @@ func (ins *InsertSelect) execInsertUnsharded(ctx context.Context, vcursor VCurso
 }
 
 func (ins *InsertSelect) insertIntoUnshardedTable(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, irr insertRowsResult) (*sqltypes.Result, error) {
+	deleted, err := ins.deleteClashingRows(ctx, vcursor, bindVars, irr.rows)
+	if err != nil {
+		return nil, err
+	}
 	query := ins.getInsertUnshardedQuery(irr.rows, bindVars)
-	return ins.executeUnshardedTableQuery(ctx, vcursor, ins, bindVars, query, irr.insertID)
+	qr, err := ins.executeUnshardedTableQuery(ctx, vcursor, ins, bindVars, query, irr.insertID)
+	if err != nil {
+		return nil, err
+	}
+	qr.RowsAffected += deleted
+	return qr, nil
+}
+
+// deleteClashingRows deletes the rows having the same primary key as the rows about to be inserted.
+// It returns the number of deleted rows, which MySQL reports as affected by a REPLACE.
+func (ins *InsertSelect) deleteClashingRows(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, rows []sqltypes.Row) (uint64, error) {
+	if ins.Delete == nil {
+		return 0, nil
+	}
+	var bv *querypb.BindVariable
+	if len(ins.PKOffsets) == 1 {
+		bv = getBVSingle(rows, ins.PKOffsets[0])
+	} else {
+		bv = getBVMulti(rows, ins.PKOffsets)
+	}
+	bvs := sqltypes.CopyBindVariables(bindVars)
+	bvs[DmlVals] = bv
+	qr, err := vcursor.ExecutePrimitive(ctx, ins.Delete, bvs, false)
+	if err != nil {
+		return 0, err
+	}
+	return qr.RowsAffected, nil
 }
 
 func (ins *InsertSelect) getInsertUnshardedQuery(rows []sqltypes.Row, bindVars map[string]*querypb.BindVariable) string {
@@ -181,6 +226,11 @@ func (ins *InsertSelect) insertIntoShardedTable(
 	bindVars map[string]*querypb.BindVariable,
 	irr insertRowsResult,
 ) (*sqltypes.Result, error) {
+	deleted, err := ins.deleteClashingRows(ctx, vcursor, bindVars, irr.rows)
+	if err != nil {
+		return nil, err
+	}
+
 	rss, queries, err := ins.getInsertShardedQueries(ctx, vcursor, bindVars, irr.rows)
 	if err != nil {
 		return nil, err
@@ -191,6 +241,7 @@ func (ins *InsertSelect) insertIntoShardedTable(
 		return nil, err
 	}
 	qr.InsertID = uint64(irr.insertID)
+	qr.RowsAffected += deleted
 	return qr, nil
 }
 
@@ -333,6 +384,9 @@ func (ins *InsertSelect) description() PrimitiveDescription {
 		}
 		other["VindexOffsetFromSelect"] = valuesOffsets
 	}
+	if len(ins.PKOffsets) > 0 {
+		other["PKOffsetFromSelect"] = ins.PKOffsets
+	}
 
 	return PrimitiveDescription{
 		OperatorType:     "Insert",
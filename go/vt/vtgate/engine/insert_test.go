@@ -2219,6 +2219,64 @@ func TestStreamingInsertSelectGenerateNotProvided(t *testing.T) {
 	expectResult(t, output, &sqltypes.Result{InsertID: 10})
 }
 
+func TestInsertSelectReplace(t *testing.T) {
+	invschema := &vschemapb.SrvVSchema{
+		Keyspaces: map[string]*vschemapb.Keyspace{
+			"sharded": {
+				Sharded: true,
+				Vindexes: map[string]*vschemapb.Vindex{
+					"hash": {Type: "hash"}},
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{
+							Name:    "hash",
+							Columns: []string{"id"}}}}}}}}
+
+	vs := vindexes.BuildVSchema(invschema, sqlparser.NewTestParser())
+	ks := vs.Keyspaces["sharded"]
+
+	rb := &Route{
+		Query:      "dummy_select",
+		FieldQuery: "dummy_field_query",
+		RoutingParameters: &RoutingParameters{
+			Opcode:   Scatter,
+			Keyspace: ks.Keyspace}}
+	del := &fakePrimitive{
+		results: []*sqltypes.Result{{RowsAffected: 1}},
+	}
+	ins := newInsertSelect(false, ks.Keyspace, ks.Tables["t1"], "prefix ", nil, [][]int{{1}}, rb)
+	ins.Delete = del
+	ins.PKOffsets = []int{1}
+
+	vc := newDMLTestVCursor("-20", "20-")
+	vc.shardForKsid = []string{"20-", "-20"}
+	vc.results = []*sqltypes.Result{
+		sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields(
+				"name|id",
+				"varchar|int64"),
+			"a|1",
+			"b|3"),
+		{RowsAffected: 2}}
+
+	qr, err := ins.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	// the deleted row is counted in the rows affected, like MySQL does for REPLACE.
+	require.EqualValues(t, 3, qr.RowsAffected)
+	del.ExpectLog(t, []string{
+		`Execute dml_vals: type:TUPLE values:{type:INT64 value:"1"} values:{type:INT64 value:"3"} false`,
+	})
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations sharded [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard sharded.-20: dummy_select {} sharded.20-: dummy_select {} false false`,
+		`ResolveDestinations sharded [value:"0" value:"1"] Destinations:DestinationKeyspaceID(166b40b44aba4bd6),DestinationKeyspaceID(4eb190c9a2fa169c)`,
+		`ExecuteMultiShard ` +
+			`sharded.20-: prefix values (:_c0_0, :_c0_1) ` +
+			`{_c0_0: type:VARCHAR value:"a" _c0_1: type:INT64 value:"1"} ` +
+			`sharded.-20: prefix values (:_c1_0, :_c1_1) ` +
+			`{_c1_0: type:VARCHAR value:"b" _c1_1: type:INT64 value:"3"} true false`})
+}
+
 func TestInsertSelectUnowned(t *testing.T) {
 	invschema := &vschemapb.SrvVSchema{
 		Keyspaces: map[string]*vschemapb.Keyspace{
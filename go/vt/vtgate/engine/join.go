@@ -50,6 +50,9 @@ type Join struct {
 	// be built from the LHS result before invoking
 	// the RHS subqquery.
 	Vars map[string]int
+
+	// Estimate is the planner's estimate of the cost of this join, if table statistics were available
+	Estimate *CostEstimate
 }
 
 // TryExecute performs a non-streaming exec.
@@ -308,6 +311,7 @@ func (jn *Join) description() PrimitiveDescription {
 	if len(jn.Vars) > 0 {
 		other["JoinVars"] = orderedStringIntMap(jn.Vars)
 	}
+	jn.Estimate.addToDescription(other)
 	return PrimitiveDescription{
 		OperatorType: "Join",
 		Variant:      jn.Opcode.String(),
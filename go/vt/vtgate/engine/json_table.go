@@ -0,0 +1,351 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"vitess.io/vitess/go/mysql/json"
+	"vitess.io/vitess/go/slice"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+var _ Primitive = (*JSONTable)(nil)
+
+// JSONTable is a primitive that evaluates a JSON_TABLE expression on the vtgate.
+// It produces a row for every value matched by the row path in the JSON document.
+type JSONTable struct {
+	// JSONTable does not take inputs
+	noInputs
+
+	// JSONTable does not need to work inside a tx
+	noTxNeeded
+
+	// Doc is the expression producing the JSON document
+	Doc evalengine.Expr
+	// Path is the row path that is matched against the JSON document
+	Path string
+	// Columns are the column definitions of the JSON_TABLE
+	Columns []*JSONTableColumn
+	// Cols contains the offsets into the flattened column definitions that will be returned
+	Cols []int
+}
+
+// JSONTableColumn is a column definition of a JSON_TABLE.
+// A column is either an ordinality column, a path column, or a nested path with its own columns.
+type JSONTableColumn struct {
+	Name       string
+	Type       evalengine.Type
+	Ordinality bool
+	Exists     bool
+	Path       string
+	OnEmpty    *JSONTableOnResponse
+	OnError    *JSONTableOnResponse
+
+	// Nested contains the columns of a NESTED PATH column definition
+	Nested []*JSONTableColumn
+}
+
+// JSONTableOnResponse specifies what to do when a path column is empty or fails.
+// When it is nil, NULL is used.
+type JSONTableOnResponse struct {
+	Error   bool
+	Default sqltypes.Value
+}
+
+// RouteType returns a description of the query routing type used by the primitive
+func (jt *JSONTable) RouteType() string {
+	return "JSONTable"
+}
+
+// GetKeyspaceName specifies the Keyspace that this primitive routes to.
+func (jt *JSONTable) GetKeyspaceName() string {
+	return ""
+}
+
+// GetTableName specifies the table that this primitive routes to.
+func (jt *JSONTable) GetTableName() string {
+	return ""
+}
+
+// TryExecute performs a non-streaming exec.
+func (jt *JSONTable) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, _ bool) (*sqltypes.Result, error) {
+	return jt.evaluate(ctx, vcursor, bindVars)
+}
+
+// TryStreamExecute performs a streaming exec.
+func (jt *JSONTable) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	res, err := jt.TryExecute(ctx, vcursor, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	return callback(res)
+}
+
+// GetFields fetches the field info.
+func (jt *JSONTable) GetFields(context.Context, VCursor, map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return &sqltypes.Result{Fields: jt.fields()}, nil
+}
+
+func (jt *JSONTable) fields() []*querypb.Field {
+	columns := flattenJSONTableColumns(jt.Columns)
+	return slice.Map(jt.Cols, func(offset int) *querypb.Field {
+		col := columns[offset]
+		return col.Type.ToField(col.Name)
+	})
+}
+
+func (jt *JSONTable) evaluate(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
+	res, err := env.Evaluate(jt.Doc)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &sqltypes.Result{Fields: jt.fields()}
+	docValue := res.Value(vcursor.ConnCollation())
+	if docValue.IsNull() {
+		return result, nil
+	}
+
+	var p json.Parser
+	doc, err := p.ParseBytes(docValue.Raw())
+	if err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Invalid JSON text in argument 1 to function json_table: %v", err)
+	}
+
+	matches, err := matchJSONPath(jt.Path, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlmode := evalengine.ParseSQLMode(vcursor.SQLMode())
+	width := len(flattenJSONTableColumns(jt.Columns))
+	for idx, match := range matches {
+		rows, err := expandJSONTableColumns(jt.Columns, match, idx+1, 0, width, sqlmode)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			result.Rows = append(result.Rows, slice.Map(jt.Cols, func(offset int) sqltypes.Value {
+				return row[offset]
+			}))
+		}
+	}
+	return result, nil
+}
+
+// expandJSONTableColumns produces the rows for a single value matched by the path of the given columns.
+// Values of nested paths produce a row each, and sibling nested paths produce their rows one after
+// the other, with NULL in the columns of the other siblings.
+func expandJSONTableColumns(
+	columns []*JSONTableColumn,
+	value *json.Value,
+	ordinal, offset, width int,
+	sqlmode evalengine.SQLMode,
+) ([][]sqltypes.Value, error) {
+	row := make([]sqltypes.Value, width)
+	var own []int
+	var nestedRows [][]sqltypes.Value
+	for _, col := range columns {
+		if col.Nested == nil {
+			val, err := col.evaluate(value, ordinal, sqlmode)
+			if err != nil {
+				return nil, err
+			}
+			row[offset] = val
+			own = append(own, offset)
+			offset++
+			continue
+		}
+
+		matches, err := matchJSONPath(col.Path, value)
+		if err != nil {
+			return nil, err
+		}
+		for idx, match := range matches {
+			rows, err := expandJSONTableColumns(col.Nested, match, idx+1, offset, width, sqlmode)
+			if err != nil {
+				return nil, err
+			}
+			nestedRows = append(nestedRows, rows...)
+		}
+		offset += len(flattenJSONTableColumns(col.Nested))
+	}
+
+	if len(nestedRows) == 0 {
+		return [][]sqltypes.Value{row}, nil
+	}
+	for _, nestedRow := range nestedRows {
+		for _, idx := range own {
+			nestedRow[idx] = row[idx]
+		}
+	}
+	return nestedRows, nil
+}
+
+func (col *JSONTableColumn) evaluate(value *json.Value, ordinal int, sqlmode evalengine.SQLMode) (sqltypes.Value, error) {
+	if col.Ordinality {
+		return sqltypes.NewUint32(uint32(ordinal)), nil
+	}
+
+	matches, err := matchJSONPath(col.Path, value)
+	if err != nil {
+		return sqltypes.NULL, err
+	}
+
+	if col.Exists {
+		exists := sqltypes.NewInt64(0)
+		if len(matches) > 0 {
+			exists = sqltypes.NewInt64(1)
+		}
+		return evalengine.CastTo(exists, col.Type, sqlmode)
+	}
+
+	switch len(matches) {
+	case 0:
+		return col.respond(col.OnEmpty, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Missing value for JSON_TABLE column '%s'", col.Name), sqlmode)
+	case 1:
+		// expected case, handled below
+	default:
+		return col.respond(col.OnError, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Can't store an array or an object in the scalar column '%s' of JSON_TABLE", col.Name), sqlmode)
+	}
+
+	val, err := col.fromJSON(matches[0], sqlmode)
+	if err != nil {
+		return col.respond(col.OnError, err, sqlmode)
+	}
+	return val, nil
+}
+
+func (col *JSONTableColumn) fromJSON(value *json.Value, sqlmode evalengine.SQLMode) (sqltypes.Value, error) {
+	if col.Type.Type() == sqltypes.TypeJSON {
+		return sqltypes.MakeTrusted(sqltypes.TypeJSON, value.MarshalTo(nil)), nil
+	}
+
+	var val sqltypes.Value
+	switch value.Type() {
+	case json.TypeNull:
+		return sqltypes.NULL, nil
+	case json.TypeObject, json.TypeArray:
+		return sqltypes.NULL, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Can't store an array or an object in the scalar column '%s' of JSON_TABLE", col.Name)
+	case json.TypeBoolean:
+		val = sqltypes.NewInt64(0)
+		if value == json.ValueTrue {
+			val = sqltypes.NewInt64(1)
+		}
+	case json.TypeNumber:
+		switch value.NumberType() {
+		case json.NumberTypeSigned:
+			val = sqltypes.MakeTrusted(sqltypes.Int64, []byte(value.Raw()))
+		case json.NumberTypeUnsigned:
+			val = sqltypes.MakeTrusted(sqltypes.Uint64, []byte(value.Raw()))
+		case json.NumberTypeDecimal:
+			val = sqltypes.MakeTrusted(sqltypes.Decimal, []byte(value.Raw()))
+		default:
+			val = sqltypes.MakeTrusted(sqltypes.Float64, []byte(value.Raw()))
+		}
+	default:
+		val = sqltypes.NewVarChar(value.Raw())
+	}
+	return evalengine.CastTo(val, col.Type, sqlmode)
+}
+
+func (col *JSONTableColumn) respond(response *JSONTableOnResponse, err error, sqlmode evalengine.SQLMode) (sqltypes.Value, error) {
+	switch {
+	case response == nil:
+		return sqltypes.NULL, nil
+	case response.Error:
+		return sqltypes.NULL, err
+	default:
+		return evalengine.CastTo(response.Default, col.Type, sqlmode)
+	}
+}
+
+func (col *JSONTableColumn) String() string {
+	switch {
+	case col.Ordinality:
+		return fmt.Sprintf("%s FOR ORDINALITY", col.Name)
+	case col.Nested != nil:
+		nested := slice.Map(col.Nested, (*JSONTableColumn).String)
+		return fmt.Sprintf("NESTED PATH '%s' COLUMNS(%s)", col.Path, strings.Join(nested, ", "))
+	}
+
+	typ := col.Type.Type()
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s %s", col.Name, strings.ToLower(typ.String())))
+	if col.Exists {
+		sb.WriteString(" EXISTS")
+	}
+	sb.WriteString(fmt.Sprintf(" PATH '%s'", col.Path))
+	writeResponse := func(response *JSONTableOnResponse, on string) {
+		switch {
+		case response == nil:
+		case response.Error:
+			sb.WriteString(" ERROR ON " + on)
+		default:
+			sb.WriteString(fmt.Sprintf(" DEFAULT %s ON %s", response.Default.String(), on))
+		}
+	}
+	writeResponse(col.OnEmpty, "EMPTY")
+	writeResponse(col.OnError, "ERROR")
+	return sb.String()
+}
+
+func flattenJSONTableColumns(columns []*JSONTableColumn) (result []*JSONTableColumn) {
+	for _, col := range columns {
+		if col.Nested != nil {
+			result = append(result, flattenJSONTableColumns(col.Nested)...)
+			continue
+		}
+		result = append(result, col)
+	}
+	return
+}
+
+func matchJSONPath(path string, doc *json.Value) ([]*json.Value, error) {
+	var p json.PathParser
+	jp, err := p.ParseBytes([]byte(path))
+	if err != nil {
+		return nil, err
+	}
+	var matches []*json.Value
+	jp.Match(doc, false, func(value *json.Value) {
+		matches = append(matches, value)
+	})
+	return matches, nil
+}
+
+func (jt *JSONTable) description() PrimitiveDescription {
+	other := map[string]any{
+		"Expression":  sqlparser.String(jt.Doc),
+		"Path":        jt.Path,
+		"Definitions": slice.Map(jt.Columns, (*JSONTableColumn).String),
+		"Columns":     jt.Cols,
+	}
+	return PrimitiveDescription{
+		OperatorType: "JSONTable",
+		Other:        other,
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+func TestJSONTable(t *testing.T) {
+	intType := evalengine.NewType(sqltypes.Int64, collations.CollationBinaryID)
+	strType := evalengine.NewType(sqltypes.VarChar, collations.MySQL8().DefaultConnectionCharset())
+
+	tcases := []struct {
+		name    string
+		doc     string
+		path    string
+		columns []*JSONTableColumn
+		cols    []int
+		expRes  string
+		expErr  string
+	}{{
+		name: "simple columns",
+		doc:  `[{"a": 1, "b": "x"}, {"a": 2, "b": "y"}, {"b": "z"}]`,
+		path: "$[*]",
+		columns: []*JSONTableColumn{
+			{Name: "id", Type: intType, Ordinality: true},
+			{Name: "a", Type: intType, Path: "$.a"},
+			{Name: "b", Type: strType, Path: "$.b"},
+		},
+		cols:   []int{0, 1, 2},
+		expRes: `[[UINT32(1) INT64(1) VARCHAR("x")] [UINT32(2) INT64(2) VARCHAR("y")] [UINT32(3) NULL VARCHAR("z")]]`,
+	}, {
+		name: "selected columns",
+		doc:  `[{"a": 1, "b": "x"}, {"a": 2, "b": "y"}]`,
+		path: "$[*]",
+		columns: []*JSONTableColumn{
+			{Name: "a", Type: intType, Path: "$.a"},
+			{Name: "b", Type: strType, Path: "$.b"},
+		},
+		cols:   []int{1},
+		expRes: `[[VARCHAR("x")] [VARCHAR("y")]]`,
+	}, {
+		name: "exists and defaults",
+		doc:  `[{"a": 1}, {"b": 2}, {"a": [1, 2]}]`,
+		path: "$[*]",
+		columns: []*JSONTableColumn{
+			{Name: "has_a", Type: intType, Exists: true, Path: "$.a"},
+			{
+				Name:    "a",
+				Type:    intType,
+				Path:    "$.a",
+				OnEmpty: &JSONTableOnResponse{Default: sqltypes.NewVarChar("42")},
+				OnError: &JSONTableOnResponse{Default: sqltypes.NewVarChar("-1")},
+			},
+		},
+		cols:   []int{0, 1},
+		expRes: `[[INT64(1) INT64(1)] [INT64(0) INT64(42)] [INT64(1) INT64(-1)]]`,
+	}, {
+		name: "nested paths",
+		doc:  `[{"a": 1, "b": [11, 111], "c": [12]}, {"a": 2, "b": []}]`,
+		path: "$[*]",
+		columns: []*JSONTableColumn{
+			{Name: "a", Type: intType, Path: "$.a"},
+			{Path: "$.b[*]", Nested: []*JSONTableColumn{
+				{Name: "b_id", Type: intType, Ordinality: true},
+				{Name: "b", Type: intType, Path: "$"},
+			}},
+			{Path: "$.c[*]", Nested: []*JSONTableColumn{
+				{Name: "c", Type: intType, Path: "$"},
+			}},
+		},
+		cols:   []int{0, 1, 2, 3},
+		expRes: `[[INT64(1) UINT32(1) INT64(11) NULL] [INT64(1) UINT32(2) INT64(111) NULL] [INT64(1) NULL NULL INT64(12)] [INT64(2) NULL NULL NULL]]`,
+	}, {
+		name: "error on empty",
+		doc:  `[{"b": 1}]`,
+		path: "$[*]",
+		columns: []*JSONTableColumn{
+			{Name: "a", Type: intType, Path: "$.a", OnEmpty: &JSONTableOnResponse{Error: true}},
+		},
+		cols:   []int{0},
+		expErr: "Missing value for JSON_TABLE column 'a'",
+	}, {
+		name: "error on error",
+		doc:  `[{"a": {"x": 1}}]`,
+		path: "$[*]",
+		columns: []*JSONTableColumn{
+			{Name: "a", Type: intType, Path: "$.a", OnError: &JSONTableOnResponse{Error: true}},
+		},
+		cols:   []int{0},
+		expErr: "Can't store an array or an object in the scalar column 'a' of JSON_TABLE",
+	}, {
+		name: "null document",
+		path: "$[*]",
+		columns: []*JSONTableColumn{
+			{Name: "a", Type: intType, Path: "$.a"},
+		},
+		cols:   []int{0},
+		expRes: `[]`,
+	}}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var doc evalengine.Expr = evalengine.NullExpr
+			if tc.doc != "" {
+				doc = evalengine.NewBindVar("doc", evalengine.NewType(sqltypes.VarChar, collations.MySQL8().DefaultConnectionCharset()))
+			}
+			jt := &JSONTable{
+				Doc:     doc,
+				Path:    tc.path,
+				Columns: tc.columns,
+				Cols:    tc.cols,
+			}
+			bv := map[string]*querypb.BindVariable{"doc": sqltypes.StringBindVariable(tc.doc)}
+
+			qr, err := jt.TryExecute(context.Background(), &noopVCursor{}, bv, true)
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expRes, fmt.Sprintf("%v", qr.Rows))
+			require.Len(t, qr.Fields, len(tc.cols))
+		})
+	}
+}
@@ -19,6 +19,7 @@ package engine
 import (
 	"context"
 	"fmt"
+	"io"
 	"math"
 	"reflect"
 	"strconv"
@@ -61,6 +62,9 @@ func (ms *MemorySort) GetTableName() string {
 
 // TryExecute satisfies the Primitive interface.
 func (ms *MemorySort) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	if vcursor.SpillToDisk() != nil {
+		return executeStreaming(ctx, vcursor, ms, bindVars, wantfields)
+	}
 	count, err := ms.fetchCount(ctx, vcursor, bindVars)
 	if err != nil {
 		return nil, err
@@ -98,6 +102,12 @@ func (ms *MemorySort) TryStreamExecute(ctx context.Context, vcursor VCursor, bin
 		Limit:   count,
 	}
 
+	// runs are the sorted rows written to disk when there are too many to keep in memory
+	var runs []*spillFile
+	defer func() {
+		closeSpillFiles(runs)
+	}()
+
 	var mu sync.Mutex
 	err = vcursor.StreamExecutePrimitive(ctx, ms.Input, bindVars, wantfields, func(qr *sqltypes.Result) error {
 		mu.Lock()
@@ -111,14 +121,84 @@ func (ms *MemorySort) TryStreamExecute(ctx context.Context, vcursor VCursor, bin
 			sorter.Push(row)
 		}
 		if vcursor.ExceedsMaxMemoryRows(sorter.Len()) {
-			return fmt.Errorf("in-memory row count exceeded allowed limit of %d", vcursor.MaxMemoryRows())
+			spiller := vcursor.SpillToDisk()
+			if spiller == nil {
+				return fmt.Errorf("in-memory row count exceeded allowed limit of %d", vcursor.MaxMemoryRows())
+			}
+			run, err := spiller.spill("Sort", sorter.Sorted())
+			if err != nil {
+				return err
+			}
+			runs = append(runs, run)
+			sorter = &evalengine.Sorter{
+				Compare: ms.OrderBy,
+				Limit:   count,
+			}
 		}
 		return nil
 	})
 	if err != nil {
 		return err
 	}
-	return cb(&sqltypes.Result{Rows: sorter.Sorted()})
+	if len(runs) == 0 {
+		return cb(&sqltypes.Result{Rows: sorter.Sorted()})
+	}
+	return sendInBatches(func(send func(row sqltypes.Row) error) error {
+		return ms.mergeRuns(runs, sorter.Sorted(), count, send)
+	}, func(rows []sqltypes.Row) error {
+		return cb(&sqltypes.Result{Rows: rows})
+	})
+}
+
+// mergeRuns merges the sorted runs that were spilled to disk with the sorted rows still in memory,
+// and sends the first count rows of the result
+func (ms *MemorySort) mergeRuns(runs []*spillFile, inMemory []sqltypes.Row, count int, send func(row sqltypes.Row) error) error {
+	merge := &evalengine.Merger{
+		Compare: ms.OrderBy,
+	}
+	readers := make([]*spillReader, len(runs))
+	for i, run := range runs {
+		r, err := run.reader()
+		if err != nil {
+			return err
+		}
+		row, err := r.next()
+		if err != nil {
+			return err
+		}
+		readers[i] = r
+		merge.Push(row, i)
+	}
+	// the rows in memory are the last source of the merge
+	memSource := len(runs)
+	if len(inMemory) > 0 {
+		merge.Push(inMemory[0], memSource)
+	}
+	merge.Init()
+
+	for ; merge.Len() != 0 && count > 0; count-- {
+		row, source := merge.Pop()
+		if err := send(row); err != nil {
+			return err
+		}
+
+		if source == memSource {
+			inMemory = inMemory[1:]
+			if len(inMemory) > 0 {
+				merge.Push(inMemory[0], memSource)
+			}
+			continue
+		}
+		next, err := readers[source].next()
+		if err == io.EOF {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		merge.Push(next, source)
+	}
+	return nil
 }
 
 // GetFields satisfies the Primitive interface.
@@ -611,6 +611,61 @@ func TestMemorySortMaxMemoryRows(t *testing.T) {
 	}
 }
 
+func TestMemorySortStreamSpillToDisk(t *testing.T) {
+	withSpillToDisk(t, 2, SpillConfig{})
+
+	fields := sqltypes.MakeTestFields(
+		"c1|c2",
+		"varbinary|decimal",
+	)
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			fields,
+			"a|1",
+			"g|2",
+			"a|1",
+			"c|4",
+			"c|3",
+			"b|0",
+			"e|5",
+		)},
+	}
+
+	ms := &MemorySort{
+		OrderBy: []evalengine.OrderByParams{{
+			WeightStringCol: -1,
+			Col:             1,
+		}},
+		Input: fp,
+	}
+
+	result, err := wrapStreamExecute(ms, &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, sqltypes.MakeTestResult(
+		fields,
+		"b|0",
+		"a|1",
+		"a|1",
+		"g|2",
+		"c|3",
+		"c|4",
+		"e|5",
+	), result)
+
+	fp.rewind()
+	ms.UpperLimit = evalengine.NewBindVar("__upper_limit", evalengine.NewType(sqltypes.Int64, collations.CollationBinaryID))
+	bv := map[string]*querypb.BindVariable{"__upper_limit": sqltypes.Int64BindVariable(3)}
+
+	result, err = wrapStreamExecute(ms, &noopVCursor{}, bv, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, sqltypes.MakeTestResult(
+		fields,
+		"b|0",
+		"a|1",
+		"a|1",
+	), result)
+}
+
 func TestMemorySortExecuteNoVarChar(t *testing.T) {
 	fields := sqltypes.MakeTestFields(
 		"c1|c2",
@@ -703,3 +758,50 @@ func TestMemorySortStreamAsync(t *testing.T) {
 [VARBINARY("c") DECIMAL(4)] [VARBINARY("c") DECIMAL(4)] [VARBINARY("c") DECIMAL(4)]]`,
 		qr.Rows))
 }
+
+func TestMemorySortExecuteSpillToDisk(t *testing.T) {
+	withSpillToDisk(t, 2, SpillConfig{})
+
+	fields := sqltypes.MakeTestFields(
+		"c1|c2",
+		"varbinary|decimal",
+	)
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			fields,
+			"a|1",
+			"g|2",
+			"a|1",
+			"c|4",
+			"c|3",
+			"b|0",
+			"e|5",
+		)},
+	}
+
+	ms := &MemorySort{
+		OrderBy: []evalengine.OrderByParams{{
+			WeightStringCol: -1,
+			Col:             1,
+		}},
+		UpperLimit: evalengine.NewBindVar("__upper_limit", evalengine.NewType(sqltypes.Int64, collations.CollationBinaryID)),
+		Input:      fp,
+	}
+
+	// the input is streamed, so that it does not have to fit in memory
+	bv := map[string]*querypb.BindVariable{"__upper_limit": sqltypes.Int64BindVariable(2)}
+	result, err := ms.TryExecute(context.Background(), &noopVCursor{}, bv, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, sqltypes.MakeTestResult(
+		fields,
+		"b|0",
+		"a|1",
+	), result)
+	require.Equal(t, []string{"StreamExecute __upper_limit: type:INT64 value:\"2\" true"}, fp.log)
+
+	// but the result still has to
+	fp.rewind()
+	bv = map[string]*querypb.BindVariable{"__upper_limit": sqltypes.Int64BindVariable(3)}
+	_, err = ms.TryExecute(context.Background(), &noopVCursor{}, bv, true)
+	require.EqualError(t, err, "in-memory row count exceeded allowed limit of 2")
+}
@@ -200,3 +200,92 @@ func (code AggregateOpcode) IsDistinct() bool {
 		return false
 	}
 }
+
+// WindowOpcode is the opcode for the window functions evaluated by the Window primitive.
+type WindowOpcode int
+
+// These constants list the possible window function opcodes.
+const (
+	WindowUnassigned = WindowOpcode(iota)
+	WindowRowNumber
+	WindowRank
+	WindowDenseRank
+	WindowPercentRank
+	WindowCumeDist
+	WindowNtile
+	WindowLag
+	WindowLead
+	WindowFirstValue
+	WindowLastValue
+	WindowNthValue
+	WindowCount
+	WindowCountStar
+	WindowSum
+	WindowMin
+	WindowMax
+	_NumOfWindowOpCodes // This line must be last of the opcodes!
+)
+
+var WindowName = map[WindowOpcode]string{
+	WindowRowNumber:   "row_number",
+	WindowRank:        "rank",
+	WindowDenseRank:   "dense_rank",
+	WindowPercentRank: "percent_rank",
+	WindowCumeDist:    "cume_dist",
+	WindowNtile:       "ntile",
+	WindowLag:         "lag",
+	WindowLead:        "lead",
+	WindowFirstValue:  "first_value",
+	WindowLastValue:   "last_value",
+	WindowNthValue:    "nth_value",
+	WindowCount:       "count",
+	WindowCountStar:   "count_star",
+	WindowSum:         "sum",
+	WindowMin:         "min",
+	WindowMax:         "max",
+}
+
+func (code WindowOpcode) String() string {
+	name := WindowName[code]
+	if name == "" {
+		name = "ERROR"
+	}
+	return name
+}
+
+// MarshalJSON serializes the WindowOpcode as a JSON string.
+// It's used for testing and diagnostics.
+func (code WindowOpcode) MarshalJSON() ([]byte, error) {
+	return ([]byte)(fmt.Sprintf("\"%s\"", code.String())), nil
+}
+
+// SQLType returns the type produced by the window function, given the type of its argument
+func (code WindowOpcode) SQLType(typ querypb.Type) querypb.Type {
+	switch code {
+	case WindowUnassigned:
+		return sqltypes.Null
+	case WindowRowNumber, WindowRank, WindowDenseRank, WindowNtile:
+		return sqltypes.Uint64
+	case WindowPercentRank, WindowCumeDist:
+		return sqltypes.Float64
+	case WindowCount, WindowCountStar:
+		return sqltypes.Int64
+	case WindowSum:
+		return AggregateSum.SQLType(typ)
+	case WindowLag, WindowLead, WindowFirstValue, WindowLastValue, WindowNthValue, WindowMin, WindowMax:
+		return typ
+	default:
+		panic(code.String()) // we have a unit test checking we never reach here
+	}
+}
+
+// UsesFrame returns true if the result of the window function depends on the frame of the window,
+// and false for the functions that always operate on the whole partition
+func (code WindowOpcode) UsesFrame() bool {
+	switch code {
+	case WindowFirstValue, WindowLastValue, WindowNthValue, WindowCount, WindowCountStar, WindowSum, WindowMin, WindowMax:
+		return true
+	default:
+		return false
+	}
+}
@@ -34,6 +34,36 @@ func TestCheckAllAggrOpCodes(t *testing.T) {
 	}
 }
 
+func TestCheckAllWindowOpCodes(t *testing.T) {
+	// This test is just checking that we never reach the panic when using SQLType() on valid opcodes
+	for i := WindowOpcode(0); i < _NumOfWindowOpCodes; i++ {
+		i.SQLType(sqltypes.Null)
+	}
+}
+
+func TestWindowType(t *testing.T) {
+	tt := []struct {
+		opcode WindowOpcode
+		typ    querypb.Type
+		out    querypb.Type
+	}{
+		{WindowRowNumber, sqltypes.Null, sqltypes.Uint64},
+		{WindowCumeDist, sqltypes.Null, sqltypes.Float64},
+		{WindowLag, sqltypes.VarChar, sqltypes.VarChar},
+		{WindowSum, sqltypes.Int64, sqltypes.Decimal},
+		{WindowSum, sqltypes.Float32, sqltypes.Float64},
+		{WindowCountStar, sqltypes.Null, sqltypes.Int64},
+		{WindowMax, sqltypes.Datetime, sqltypes.Datetime},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.opcode.String()+"_"+tc.typ.String(), func(t *testing.T) {
+			out := tc.opcode.SQLType(tc.typ)
+			assert.Equal(t, tc.out, out)
+		})
+	}
+}
+
 func TestType(t *testing.T) {
 	tt := []struct {
 		opcode AggregateOpcode
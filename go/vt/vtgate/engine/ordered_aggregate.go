@@ -96,6 +96,9 @@ func (oa *OrderedAggregate) GetTableName() string {
 
 // TryExecute is a Primitive function.
 func (oa *OrderedAggregate) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, _ bool) (*sqltypes.Result, error) {
+	if vcursor.SpillToDisk() != nil {
+		return executeStreaming(ctx, vcursor, oa, bindVars, true)
+	}
 	qr, err := oa.execute(ctx, vcursor, bindVars)
 	if err != nil {
 		return nil, err
@@ -146,7 +149,7 @@ func (oa *OrderedAggregate) execute(ctx context.Context, vcursor VCursor, bindVa
 		return oa.executeGroupBy(result)
 	}
 
-	agg, fields, err := newAggregation(result.Fields, oa.Aggregates)
+	agg, fields, err := newAggregation(vcursor, result.Fields, oa.Aggregates)
 	if err != nil {
 		return nil, err
 	}
@@ -251,7 +254,7 @@ func (oa *OrderedAggregate) TryStreamExecute(ctx context.Context, vcursor VCurso
 		var err error
 
 		if agg == nil && len(qr.Fields) != 0 {
-			agg, fields, err = newAggregation(qr.Fields, oa.Aggregates)
+			agg, fields, err = newAggregation(vcursor, qr.Fields, oa.Aggregates)
 			if err != nil {
 				return err
 			}
@@ -306,7 +309,7 @@ func (oa *OrderedAggregate) GetFields(ctx context.Context, vcursor VCursor, bind
 		return nil, err
 	}
 
-	_, fields, err := newAggregation(qr.Fields, oa.Aggregates)
+	_, fields, err := newAggregation(nil, qr.Fields, oa.Aggregates)
 	if err != nil {
 		return nil, err
 	}
@@ -326,35 +329,55 @@ func (oa *OrderedAggregate) NeedsTransaction() bool {
 }
 
 func (oa *OrderedAggregate) nextGroupBy(currentKey, nextRow []sqltypes.Value) (nextKey []sqltypes.Value, nextGroup bool, err error) {
+	return nextGroupBy(oa.GroupByKeys, currentKey, nextRow)
+}
+
+// nextGroupBy compares the grouping keys of nextRow against currentKey,
+// and reports whether nextRow starts a new group
+func nextGroupBy(keys []*GroupByParams, currentKey, nextRow []sqltypes.Value) (nextKey []sqltypes.Value, nextGroup bool, err error) {
 	if currentKey == nil {
 		return nextRow, false, nil
 	}
 
-	for _, gb := range oa.GroupByKeys {
+	idx, err := firstChangedKey(keys, currentKey, nextRow)
+	if err != nil {
+		return nil, false, err
+	}
+	if idx < len(keys) {
+		return nextRow, true, nil
+	}
+	return currentKey, false, nil
+}
+
+// firstChangedKey returns the index of the first grouping key that is
+// different between the two rows, or len(keys) if all the keys are equal
+func firstChangedKey(keys []*GroupByParams, currentKey, nextRow []sqltypes.Value) (int, error) {
+	for idx, gb := range keys {
 		v1 := currentKey[gb.KeyCol]
 		v2 := nextRow[gb.KeyCol]
 		if v1.TinyWeightCmp(v2) != 0 {
-			return nextRow, true, nil
+			return idx, nil
 		}
 
 		cmp, err := evalengine.NullsafeCompare(v1, v2, gb.CollationEnv, gb.Type.Collation(), gb.Type.Values())
 		if err != nil {
 			_, isCollationErr := err.(evalengine.UnsupportedCollationError)
 			if !isCollationErr || gb.WeightStringCol == -1 {
-				return nil, false, err
+				return 0, err
 			}
 			gb.KeyCol = gb.WeightStringCol
 			cmp, err = evalengine.NullsafeCompare(currentKey[gb.WeightStringCol], nextRow[gb.WeightStringCol], gb.CollationEnv, gb.Type.Collation(), gb.Type.Values())
 			if err != nil {
-				return nil, false, err
+				return 0, err
 			}
 		}
 		if cmp != 0 {
-			return nextRow, true, nil
+			return idx, nil
 		}
 	}
-	return currentKey, false, nil
+	return len(keys), nil
 }
+
 func aggregateParamsToString(in any) string {
 	return in.(*AggregateParams).String()
 }
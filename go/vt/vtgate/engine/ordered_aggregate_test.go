@@ -875,6 +875,67 @@ func TestMultiDistinct(t *testing.T) {
 	utils.MustMatch(t, want, results)
 }
 
+func TestMultiDistinctHash(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"c1|c2|c3|c4",
+		"int64|int64|int64|int64",
+	)
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			fields,
+			"10|2|1|2",
+			"10|3|null|3",
+			"10|2|3|2",
+			"10|2|1|2",
+			"10|null|5|null",
+			"10|3|3|3",
+			"20|1|1|1",
+			"20|1|1|1",
+			"30|null|null|null",
+		)},
+	}
+
+	intType := evalengine.NewType(sqltypes.Int64, collations.CollationBinaryID)
+	countC2 := NewAggregateParam(AggregateCountDistinct, 1, "count(distinct c2)", collations.MySQL8())
+	countC2.DistinctCols = []CheckCol{{Col: 1, Type: intType}}
+	sumC3 := NewAggregateParam(AggregateSumDistinct, 2, "sum(distinct c3)", collations.MySQL8())
+	sumC3.DistinctCols = []CheckCol{{Col: 2, Type: intType}}
+	countC2C3 := NewAggregateParam(AggregateCountDistinct, 3, "count(distinct c2, c3)", collations.MySQL8())
+	countC2C3.DistinctCols = []CheckCol{{Col: 3, Type: intType}, {Col: 2, Type: intType}}
+
+	oa := &OrderedAggregate{
+		Aggregates:  []*AggregateParams{countC2, sumC3, countC2C3},
+		GroupByKeys: []*GroupByParams{{KeyCol: 0}},
+		Input:       fp,
+	}
+
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"c1|count(distinct c2)|sum(distinct c3)|count(distinct c2, c3)",
+			"int64|int64|decimal|int64",
+		),
+		`10|2|9|3`,
+		`20|1|1|1`,
+		`30|0|null|0`,
+	)
+
+	qr, err := oa.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.NoError(t, err)
+	utils.MustMatch(t, want, qr)
+
+	fp.rewind()
+	results := &sqltypes.Result{}
+	err = oa.TryStreamExecute(context.Background(), &noopVCursor{}, nil, true, func(qr *sqltypes.Result) error {
+		if qr.Fields != nil {
+			results.Fields = qr.Fields
+		}
+		results.Rows = append(results.Rows, qr.Rows...)
+		return nil
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, want, results)
+}
+
 func TestOrderedAggregateCollate(t *testing.T) {
 	fields := sqltypes.MakeTestFields(
 		"col|count(*)",
@@ -1169,3 +1230,52 @@ func TestGroupConcat(t *testing.T) {
 		})
 	}
 }
+
+func TestOrderedAggregateExecuteSpillToDisk(t *testing.T) {
+	withSpillToDisk(t, 3, SpillConfig{})
+
+	fields := sqltypes.MakeTestFields(
+		"col|count(*)",
+		"varbinary|decimal",
+	)
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			fields,
+			"c|3",
+			"a|1",
+			"b|2",
+			"a|1",
+			"c|4",
+			"b|1",
+			"c|1",
+		)},
+	}
+
+	oa := &OrderedAggregate{
+		Aggregates:  []*AggregateParams{NewAggregateParam(AggregateSum, 1, "", collations.MySQL8())},
+		GroupByKeys: []*GroupByParams{{KeyCol: 0}},
+		Input: &MemorySort{
+			OrderBy: []evalengine.OrderByParams{{
+				Col:             0,
+				WeightStringCol: -1,
+				Type:            evalengine.NewType(sqltypes.VarBinary, collations.CollationBinaryID),
+			}},
+			Input: fp,
+		},
+	}
+
+	// the input is streamed, sorted on disk, and aggregated one group at a time
+	spilled := spillFiles.Counts()["Sort"]
+	result, err := oa.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.NoError(t, err)
+
+	wantResult := sqltypes.MakeTestResult(
+		fields,
+		"a|2",
+		"b|3",
+		"c|8",
+	)
+	utils.MustMatch(t, wantResult, result)
+	assert.Equal(t, []string{"StreamExecute  true"}, fp.log)
+	assert.Greater(t, spillFiles.Counts()["Sort"], spilled)
+}
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"math"
 	"sort"
 	"strings"
 
@@ -49,6 +50,21 @@ type PrimitiveDescription struct {
 	Inputs    []PrimitiveDescription
 }
 
+// CostEstimate is the planner's estimate of how expensive it is to run a primitive, and how many rows it returns.
+// It is based on the table statistics collected by the schema tracker, and is only used in plan descriptions.
+type CostEstimate struct {
+	Cost float64
+	Rows float64
+}
+
+func (ce *CostEstimate) addToDescription(other map[string]any) {
+	if ce == nil {
+		return
+	}
+	other["EstimatedCost"] = uint64(math.Ceil(ce.Cost))
+	other["EstimatedRows"] = uint64(math.Ceil(ce.Rows))
+}
+
 // MarshalJSON serializes the PlanDescription into a JSON representation.
 // We do this rather manual thing here so the `other` map looks like
 // fields belonging to pd and not a map in a field.
@@ -56,6 +56,10 @@ type (
 		// if the max memory rows override directive is set to true
 		ExceedsMaxMemoryRows(numRows int) bool
 
+		// SpillToDisk returns the Spiller used to write rows to disk once a primitive holds
+		// more than max memory rows. Returns nil if spilling to disk is disabled
+		SpillToDisk() *Spiller
+
 		Execute(ctx context.Context, method string, query string, bindVars map[string]*querypb.BindVariable, rollbackOnError bool, co vtgatepb.CommitOrder) (*sqltypes.Result, error)
 		AutocommitApproval() bool
 
@@ -96,6 +100,12 @@ type (
 
 		InTransactionAndIsDML() bool
 
+		InTransaction() bool
+
+		// AfterTransaction calls f once the transaction of the session is over,
+		// or once the query is done if it is not in a transaction.
+		AfterTransaction(f func())
+
 		LookupRowLockShardSession() vtgatepb.CommitOrder
 
 		FindRoutedTable(tablename sqlparser.TableName) (*vindexes.Table, error)
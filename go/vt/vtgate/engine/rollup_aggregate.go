@@ -0,0 +1,210 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+
+	"vitess.io/vitess/go/slice"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+var _ Primitive = (*RollupAggregate)(nil)
+
+// RollupAggregate is a primitive used to evaluate GROUP BY ... WITH ROLLUP.
+// Just like OrderedAggregate, it expects the underlying primitive to feed
+// results sorted by the grouping keys, and the keys have to be in the same
+// order as in the GROUP BY clause. In addition to the rows of every group,
+// it produces the super-aggregate rows: after the last row of a group at
+// every level of the rollup, a row is produced where the grouping keys that
+// were rolled up are set to NULL. The last row produced is the grand total.
+type RollupAggregate struct {
+	OrderedAggregate
+}
+
+// TryExecute is a Primitive function.
+func (ra *RollupAggregate) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, _ bool) (*sqltypes.Result, error) {
+	if vcursor.SpillToDisk() != nil {
+		return executeStreaming(ctx, vcursor, ra, bindVars, true)
+	}
+	result, err := vcursor.ExecutePrimitive(
+		ctx,
+		ra.Input,
+		bindVars,
+		true, /*wantFields - we need the input fields types to correctly calculate the output types*/
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := ra.newRollupState(vcursor, result.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &sqltypes.Result{
+		Fields: state.fields,
+		Rows:   make([][]sqltypes.Value, 0, len(result.Rows)),
+	}
+	for _, row := range result.Rows {
+		rows, err := state.add(row)
+		if err != nil {
+			return nil, err
+		}
+		out.Rows = append(out.Rows, rows...)
+	}
+	out.Rows = append(out.Rows, state.finish()...)
+
+	return out.Truncate(ra.TruncateColumnCount), nil
+}
+
+// TryStreamExecute is a Primitive function.
+func (ra *RollupAggregate) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, _ bool, callback func(*sqltypes.Result) error) error {
+	cb := func(qr *sqltypes.Result) error {
+		return callback(qr.Truncate(ra.TruncateColumnCount))
+	}
+
+	var state *rollupState
+
+	visitor := func(qr *sqltypes.Result) error {
+		var err error
+
+		if state == nil && len(qr.Fields) != 0 {
+			state, err = ra.newRollupState(vcursor, qr.Fields)
+			if err != nil {
+				return err
+			}
+			if err = cb(&sqltypes.Result{Fields: state.fields}); err != nil {
+				return err
+			}
+		}
+
+		var out []sqltypes.Row
+		for _, row := range qr.Rows {
+			rows, err := state.add(row)
+			if err != nil {
+				return err
+			}
+			out = append(out, rows...)
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		return cb(&sqltypes.Result{Rows: out})
+	}
+
+	/* we need the input fields types to correctly calculate the output types */
+	err := vcursor.StreamExecutePrimitive(ctx, ra.Input, bindVars, true, visitor)
+	if err != nil {
+		return err
+	}
+
+	if state == nil {
+		return nil
+	}
+	if rows := state.finish(); len(rows) > 0 {
+		return cb(&sqltypes.Result{Rows: rows})
+	}
+	return nil
+}
+
+// rollupState keeps one aggregation state for every level of the rollup.
+// Level 0 aggregates the groups using all the grouping keys, and level N
+// aggregates the groups using the first len(keys)-N grouping keys.
+type rollupState struct {
+	ra         *RollupAggregate
+	keys       []*GroupByParams
+	levels     []aggregationState
+	fields     []*querypb.Field
+	currentKey []sqltypes.Value
+}
+
+func (ra *RollupAggregate) newRollupState(vcursor VCursor, fields []*querypb.Field) (*rollupState, error) {
+	state := &rollupState{
+		ra: ra,
+		// the keys are copied, because comparing the keys can change which column is used for the comparison,
+		// and we need to know the original columns to produce the super-aggregate rows
+		keys: slice.Map(ra.GroupByKeys, func(from *GroupByParams) *GroupByParams {
+			gb := *from
+			return &gb
+		}),
+	}
+	for range len(ra.GroupByKeys) + 1 {
+		agg, outFields, err := newAggregation(vcursor, fields, ra.Aggregates)
+		if err != nil {
+			return nil, err
+		}
+		state.levels = append(state.levels, agg)
+		state.fields = outFields
+	}
+	return state, nil
+}
+
+// add adds the row to all the levels of the rollup, and returns the rows
+// of the groups that were completed by this row
+func (rs *rollupState) add(row sqltypes.Row) ([]sqltypes.Row, error) {
+	var out []sqltypes.Row
+	if rs.currentKey != nil {
+		changed, err := firstChangedKey(rs.keys, rs.currentKey, row)
+		if err != nil {
+			return nil, err
+		}
+		// when the key at index changed changes, all groups that use that key are done
+		out = rs.flush(len(rs.keys) - changed)
+	}
+	rs.currentKey = row
+
+	for _, level := range rs.levels {
+		if err := level.add(row); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// finish returns the rows of all the groups that are still open, including the grand total
+func (rs *rollupState) finish() []sqltypes.Row {
+	if rs.currentKey == nil {
+		return nil
+	}
+	return rs.flush(len(rs.levels))
+}
+
+// flush produces the rows for the first count levels, and resets them
+func (rs *rollupState) flush(count int) []sqltypes.Row {
+	out := make([]sqltypes.Row, 0, count)
+	for level := range count {
+		row := rs.levels[level].finish()
+		// the last `level` grouping keys are rolled up in this row
+		for _, gb := range rs.ra.GroupByKeys[len(rs.ra.GroupByKeys)-level:] {
+			row[gb.KeyCol] = sqltypes.NULL
+			if gb.WeightStringCol >= 0 {
+				row[gb.WeightStringCol] = sqltypes.NULL
+			}
+		}
+		out = append(out, row)
+		rs.levels[level].reset()
+	}
+	return out
+}
+
+func (ra *RollupAggregate) description() PrimitiveDescription {
+	desc := ra.OrderedAggregate.description()
+	desc.Variant = "Rollup"
+	return desc
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
+	. "vitess.io/vitess/go/vt/vtgate/engine/opcode"
+)
+
+func rollupTestInput() *fakePrimitive {
+	return &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields(
+				"a|b|count(*)",
+				"int64|int64|int64",
+			),
+			"1|1|2",
+			"1|2|3",
+			"2|1|4",
+			"2|1|1",
+		)},
+	}
+}
+
+func newRollupTestAggregate(input Primitive) *RollupAggregate {
+	aggr := NewAggregateParam(AggregateSum, 2, "", collations.MySQL8())
+	aggr.OrigOpcode = AggregateCountStar
+	return &RollupAggregate{OrderedAggregate{
+		Aggregates:  []*AggregateParams{aggr},
+		GroupByKeys: []*GroupByParams{{KeyCol: 0, WeightStringCol: -1}, {KeyCol: 1, WeightStringCol: -1}},
+		Input:       input,
+	}}
+}
+
+func TestRollupAggregateExecute(t *testing.T) {
+	ra := newRollupTestAggregate(rollupTestInput())
+
+	result, err := ra.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.NoError(t, err)
+
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"a|b|count(*)",
+			"int64|int64|int64",
+		),
+		"1|1|2",
+		"1|2|3",
+		"1|null|5",
+		"2|1|5",
+		"2|null|5",
+		"null|null|10",
+	)
+	utils.MustMatch(t, want, result)
+}
+
+func TestRollupAggregateStreamExecute(t *testing.T) {
+	ra := newRollupTestAggregate(rollupTestInput())
+
+	var results []*sqltypes.Result
+	err := ra.TryStreamExecute(context.Background(), &noopVCursor{}, nil, true, func(qr *sqltypes.Result) error {
+		results = append(results, qr)
+		return nil
+	})
+	require.NoError(t, err)
+
+	want := sqltypes.MakeTestStreamingResults(
+		sqltypes.MakeTestFields(
+			"a|b|count(*)",
+			"int64|int64|int64",
+		),
+		"1|1|2",
+		"---",
+		"1|2|3",
+		"1|null|5",
+		"---",
+		"2|1|5",
+		"2|null|5",
+		"null|null|10",
+	)
+	utils.MustMatch(t, want, results)
+}
+
+func TestRollupAggregateNoRows(t *testing.T) {
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields(
+				"a|b|count(*)",
+				"int64|int64|int64",
+			),
+		)},
+	}
+	ra := newRollupTestAggregate(fp)
+
+	result, err := ra.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.NoError(t, err)
+	assert.Empty(t, result.Rows)
+	assert.Equal(t, "Rollup", ra.description().Variant)
+}
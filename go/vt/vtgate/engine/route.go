@@ -95,6 +95,9 @@ type Route struct {
 	// select count(*) from tbl where lookupColumn = 'not there'
 	// select exists(<subq>)
 	NoRoutesSpecialHandling bool
+
+	// Estimate is the planner's estimate of the cost of this route, if table statistics were available
+	Estimate *CostEstimate
 }
 
 // NewRoute creates a Route.
@@ -400,11 +403,7 @@ func (route *Route) description() PrimitiveDescription {
 		other["Vindex"] = route.Vindex.String()
 	}
 	if route.Values != nil {
-		formattedValues := make([]string, 0, len(route.Values))
-		for _, value := range route.Values {
-			formattedValues = append(formattedValues, sqlparser.String(value))
-		}
-		other["Values"] = formattedValues
+		other["Values"] = route.formatValues()
 	}
 	if len(route.SysTableTableSchema) != 0 {
 		sysTabSchema := "["
@@ -438,6 +437,7 @@ func (route *Route) description() PrimitiveDescription {
 	if route.QueryTimeout > 0 {
 		other["QueryTimeout"] = route.QueryTimeout
 	}
+	route.Estimate.addToDescription(other)
 	return PrimitiveDescription{
 		OperatorType:      "Route",
 		Variant:           route.Opcode.String(),
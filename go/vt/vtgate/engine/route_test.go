@@ -261,6 +261,56 @@ func TestSelectEqualUnique(t *testing.T) {
 	expectResult(t, result, defaultSelectResult)
 }
 
+func TestSelectRange(t *testing.T) {
+	vindex, _ := vindexes.CreateVindex("numeric", "", nil)
+	sel := NewRoute(
+		Range,
+		&vindexes.Keyspace{
+			Name:    "ks",
+			Sharded: true,
+		},
+		"dummy_select",
+		"dummy_select_field",
+	)
+	sel.Vindex = vindex.(vindexes.SingleColumn)
+	sel.Values = []evalengine.Expr{
+		evalengine.NewLiteralInt(10),
+		evalengine.NewBindVar("upper", evalengine.NewType(sqltypes.Int64, collations.CollationBinaryID)),
+	}
+	sel.RangeInclusive = [2]bool{true, false}
+
+	vc := &loggingVCursor{
+		shards:       []string{"-20", "20-"},
+		shardForKsid: []string{"-20"},
+		results:      []*sqltypes.Result{defaultSelectResult},
+	}
+	result, err := sel.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{"upper": sqltypes.Int64BindVariable(20)}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationKeyRange(000000000000000a-0000000000000014)`,
+		`ExecuteMultiShard ks.-20: dummy_select {upper: type:INT64 value:"20"} false false`,
+	})
+	expectResult(t, result, defaultSelectResult)
+
+	// a null bound leaves the range open
+	vc.Rewind()
+	_, err = sel.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{"upper": sqltypes.NullBindVariable}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationKeyRange(000000000000000a-)`,
+		`ExecuteMultiShard ks.-20: dummy_select {upper: } false false`,
+	})
+
+	// an empty range doesn't go to any shard
+	vc.Rewind()
+	result, err = sel.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{"upper": sqltypes.Int64BindVariable(5)}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationNone()`,
+	})
+	expectResult(t, result, &sqltypes.Result{})
+}
+
 func TestSelectNone(t *testing.T) {
 	vindex, _ := vindexes.CreateVindex("hash", "", nil)
 	sel := NewRoute(
@@ -56,6 +56,10 @@ const (
 	MultiEqual
 	// SubShard is for when we are missing one or more columns from a composite vindex
 	SubShard
+	// Range is for routing a query using the range of values of an ordered vindex.
+	// Requires: An Ordered Vindex, and two Values for the start and the end of
+	// the range, where a null leaves the range open on that side.
+	Range
 	// Scatter is for routing a scattered statement.
 	Scatter
 	// Next is for fetching from a sequence.
@@ -86,6 +90,7 @@ var opName = map[Opcode]string{
 	None:          "None",
 	ByDestination: "ByDestination",
 	SubShard:      "SubShard",
+	Range:         "Range",
 }
 
 // MarshalJSON serializes the Opcode as a JSON string.
@@ -119,6 +124,10 @@ type RoutingParameters struct {
 
 	// Values specifies the vindex values to use for routing.
 	Values []evalengine.Expr
+
+	// RangeInclusive tells whether the start and the end of the
+	// range of values of the Range opcode are part of the range.
+	RangeInclusive [2]bool
 }
 
 func (code Opcode) IsSingleShard() bool {
@@ -164,6 +173,8 @@ func (rp *RoutingParameters) findRoute(ctx context.Context, vcursor VCursor, bin
 		default:
 			return rp.multiEqual(ctx, vcursor, bindVars)
 		}
+	case Range:
+		return rp.valueRange(ctx, vcursor, bindVars)
 	default:
 		// Unreachable.
 		return nil, nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unsupported opcode: %v", rp.Opcode)
@@ -348,6 +359,51 @@ func (rp *RoutingParameters) equal(ctx context.Context, vcursor VCursor, bindVar
 	return rss, multiBindVars, nil
 }
 
+func (rp *RoutingParameters) valueRange(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) ([]*srvtopo.ResolvedShard, []map[string]*querypb.BindVariable, error) {
+	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
+	start, err := env.Evaluate(rp.Values[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	end, err := env.Evaluate(rp.Values[1])
+	if err != nil {
+		return nil, nil, err
+	}
+	destinations, err := rp.Vindex.(vindexes.Ordered).MapRange(ctx, vcursor, start.Value(vcursor.ConnCollation()), end.Value(vcursor.ConnCollation()), rp.RangeInclusive[0], rp.RangeInclusive[1])
+	if err != nil {
+		return nil, nil, err
+	}
+	rss, _, err := vcursor.ResolveDestinations(ctx, rp.Keyspace.Name, nil, destinations)
+	if err != nil {
+		return nil, nil, err
+	}
+	multiBindVars := make([]map[string]*querypb.BindVariable, len(rss))
+	for i := range multiBindVars {
+		multiBindVars[i] = bindVars
+	}
+	return rss, multiBindVars, nil
+}
+
+// formatValues formats the values for the description of a plan. The values
+// of the Range opcode are shown as an interval, like "[:v1, :v2)".
+func (rp *RoutingParameters) formatValues() []string {
+	formattedValues := make([]string, 0, len(rp.Values))
+	for _, value := range rp.Values {
+		formattedValues = append(formattedValues, sqlparser.String(value))
+	}
+	if rp.Opcode != Range || len(formattedValues) != 2 {
+		return formattedValues
+	}
+	start, end := "(", ")"
+	if rp.RangeInclusive[0] {
+		start = "["
+	}
+	if rp.RangeInclusive[1] {
+		end = "]"
+	}
+	return []string{start + formattedValues[0] + ", " + formattedValues[1] + end}
+}
+
 func (rp *RoutingParameters) equalMultiCol(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) ([]*srvtopo.ResolvedShard, []map[string]*querypb.BindVariable, error) {
 	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
 	var rowValue []sqltypes.Value
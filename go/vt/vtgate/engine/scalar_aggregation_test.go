@@ -29,6 +29,7 @@ import (
 	"vitess.io/vitess/go/test/utils"
 	"vitess.io/vitess/go/vt/sqlparser"
 	. "vitess.io/vitess/go/vt/vtgate/engine/opcode"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
 )
 
 func TestEmptyRows(outer *testing.T) {
@@ -257,6 +258,89 @@ func TestScalarGroupConcatWithAggrOnEngine(t *testing.T) {
 	}
 }
 
+// TestScalarGroupConcatDistinctOrderBy tests group_concat with distinct, order by, multiple columns
+// and group_concat_max_len when fully evaluated on the engine.
+func TestScalarGroupConcatDistinctOrderBy(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"c1|c2",
+		"varchar|int64",
+	)
+	textOutFields := sqltypes.MakeTestFields(
+		"group_concat(c1)",
+		"text",
+	)
+	intType := evalengine.NewType(sqltypes.Int64, collations.CollationBinaryID)
+	varcharType := evalengine.NewType(sqltypes.VarChar, collations.MySQL8().DefaultConnectionCharset())
+
+	var tcases = []struct {
+		name      string
+		setup     func(aggr *AggregateParams)
+		sysVars   map[string]string
+		expResult *sqltypes.Result
+	}{{
+		name:      "plain",
+		setup:     func(*AggregateParams) {},
+		expResult: sqltypes.MakeTestResult(textOutFields, `a,b,a,c`),
+	}, {
+		name: "distinct",
+		setup: func(aggr *AggregateParams) {
+			aggr.DistinctCols = []CheckCol{{Col: 0, Type: varcharType, CollationEnv: collations.MySQL8()}}
+		},
+		expResult: sqltypes.MakeTestResult(textOutFields, `a,b,c`),
+	}, {
+		name: "distinct with order by desc",
+		setup: func(aggr *AggregateParams) {
+			aggr.DistinctCols = []CheckCol{{Col: 0, Type: varcharType, CollationEnv: collations.MySQL8()}}
+			aggr.OrderBy = evalengine.Comparison{{Col: 1, WeightStringCol: -1, Desc: true, Type: intType}}
+		},
+		expResult: sqltypes.MakeTestResult(textOutFields, `b,c,a`),
+	}, {
+		name: "multiple columns with order by",
+		setup: func(aggr *AggregateParams) {
+			aggr.ConcatCols = []int{1}
+			aggr.OrderBy = evalengine.Comparison{{Col: 1, WeightStringCol: -1, Type: intType}}
+		},
+		expResult: sqltypes.MakeTestResult(textOutFields, `a1,a1,c2,b3`),
+	}, {
+		name:      "group_concat_max_len",
+		setup:     func(*AggregateParams) {},
+		sysVars:   map[string]string{"group_concat_max_len": "5"},
+		expResult: sqltypes.MakeTestResult(textOutFields, `a,b,a`),
+	}}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			fp := &fakePrimitive{results: []*sqltypes.Result{sqltypes.MakeTestResult(fields,
+				"a|1", "b|3", "null|4", "a|1", "c|2")}}
+			aggr := NewAggregateParam(AggregateGroupConcat, 0, "group_concat(c1)", collations.MySQL8())
+			aggr.Func = &sqlparser.GroupConcatExpr{Separator: ","}
+			tcase.setup(aggr)
+			oa := &ScalarAggregate{
+				Aggregates:          []*AggregateParams{aggr},
+				TruncateColumnCount: 1,
+				Input:               fp,
+			}
+			vc := &loggingVCursor{systemVariables: tcase.sysVars}
+
+			qr, err := oa.TryExecute(context.Background(), vc, nil, false)
+			require.NoError(t, err)
+			utils.MustMatch(t, tcase.expResult, qr)
+
+			fp.rewind()
+			results := &sqltypes.Result{}
+			err = oa.TryStreamExecute(context.Background(), vc, nil, true, func(qr *sqltypes.Result) error {
+				if qr.Fields != nil {
+					results.Fields = qr.Fields
+				}
+				results.Rows = append(results.Rows, qr.Rows...)
+				return nil
+			})
+			require.NoError(t, err)
+			utils.MustMatch(t, tcase.expResult, results)
+		})
+	}
+}
+
 // TestScalarDistinctAggr tests distinct aggregation on engine.
 func TestScalarDistinctAggrOnEngine(t *testing.T) {
 	fields := sqltypes.MakeTestFields(
@@ -418,3 +502,34 @@ func TestScalarGroupConcat(t *testing.T) {
 		})
 	}
 }
+
+func TestScalarAggregateExecuteSpillToDisk(t *testing.T) {
+	withSpillToDisk(t, 2, SpillConfig{})
+
+	fields := sqltypes.MakeTestFields(
+		"count(*)",
+		"uint64",
+	)
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(fields,
+			"1",
+			"3",
+			"2",
+			"4",
+		)},
+	}
+
+	sa := &ScalarAggregate{
+		Aggregates: []*AggregateParams{{
+			Opcode: AggregateSum,
+			Col:    0,
+		}},
+		Input: fp,
+	}
+
+	// the input has more rows than max_memory_rows, but it is streamed
+	result, err := sa.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	assert.Equal(t, "[[DECIMAL(10)]]", fmt.Sprintf("%v", result.Rows))
+	assert.Equal(t, []string{"StreamExecute  true"}, fp.log)
+}
@@ -0,0 +1,309 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vthash"
+)
+
+const (
+	// spillPartitionCount is the number of files rows are partitioned into by the primitives that spill by hash
+	spillPartitionCount = 16
+
+	// spillBatchSize is the number of rows sent to the callback at a time when reading spilled rows back
+	spillBatchSize = 1000
+)
+
+var (
+	spillBytesInUse atomic.Int64
+
+	spillFiles         = stats.NewCountersWithSingleLabel("SpillToDiskFiles", "Number of temporary files created by primitives spilling rows to disk", "Primitive")
+	spillBytes         = stats.NewCountersWithSingleLabel("SpillToDiskBytes", "Number of bytes written to disk by primitives spilling rows", "Primitive")
+	spillQuotaExceeded = stats.NewCountersWithSingleLabel("SpillToDiskQuotaExceeded", "Number of times a query failed because it exceeded a disk quota while spilling rows", "Quota")
+	_                  = stats.NewGaugeFunc("SpillToDiskBytesInUse", "Number of bytes currently on disk for rows spilled by primitives", spillBytesInUse.Load)
+)
+
+type (
+	// SpillConfig configures how the in-memory primitives write rows to temporary files once
+	// they hold more rows than max_memory_rows, instead of failing the query.
+	SpillConfig struct {
+		// Dir is the directory the temporary files are created in
+		Dir string
+
+		// MaxQueryBytes is the maximum number of bytes a single query can have on disk. 0 means no limit.
+		MaxQueryBytes int64
+
+		// MaxTotalBytes is the maximum number of bytes all the queries on this vtgate can have on disk. 0 means no limit.
+		MaxTotalBytes int64
+	}
+
+	// Spiller creates the temporary files used by the primitives of a single query,
+	// and keeps track of the disk space they use
+	Spiller struct {
+		cfg  SpillConfig
+		used atomic.Int64
+	}
+
+	// spillFile is a temporary file rows are appended to, and read back from once the input is exhausted
+	spillFile struct {
+		spiller   *Spiller
+		primitive string
+
+		file *os.File
+		w    *bufio.Writer
+		size int64
+		buf  []byte
+	}
+
+	spillReader struct {
+		r *bufio.Reader
+	}
+
+	// spillPartitions are spill files rows are distributed to by the hash of their key,
+	// so that rows with the same key can be processed together, one partition at a time
+	spillPartitions []*spillFile
+)
+
+// NewSpiller returns a Spiller for a single query
+func NewSpiller(cfg SpillConfig) *Spiller {
+	return &Spiller{cfg: cfg}
+}
+
+func (s *Spiller) newFile(primitive string) (*spillFile, error) {
+	file, err := os.CreateTemp(s.cfg.Dir, "vtgate-spill-*")
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "failed to create a temporary file to spill rows")
+	}
+	spillFiles.Add(primitive, 1)
+	return &spillFile{
+		spiller:   s,
+		primitive: primitive,
+		file:      file,
+		w:         bufio.NewWriter(file),
+	}, nil
+}
+
+// spill writes the rows to a new temporary file
+func (s *Spiller) spill(primitive string, rows []sqltypes.Row) (*spillFile, error) {
+	file, err := s.newFile(primitive)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if err := file.writeRow(row); err != nil {
+			file.close()
+			return nil, err
+		}
+	}
+	return file, nil
+}
+
+func (s *Spiller) newPartitions(primitive string) (spillPartitions, error) {
+	partitions := make(spillPartitions, 0, spillPartitionCount)
+	for i := 0; i < spillPartitionCount; i++ {
+		file, err := s.newFile(primitive)
+		if err != nil {
+			partitions.close()
+			return nil, err
+		}
+		partitions = append(partitions, file)
+	}
+	return partitions, nil
+}
+
+// reserve accounts for n more bytes on disk, failing if this goes over one of the quotas
+func (s *Spiller) reserve(n int64) error {
+	if used := s.used.Add(n); s.cfg.MaxQueryBytes > 0 && used > s.cfg.MaxQueryBytes {
+		s.used.Add(-n)
+		spillQuotaExceeded.Add("Query", 1)
+		return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "query exceeded the allowed disk usage of %d bytes for spilled rows", s.cfg.MaxQueryBytes)
+	}
+	if used := spillBytesInUse.Add(n); s.cfg.MaxTotalBytes > 0 && used > s.cfg.MaxTotalBytes {
+		spillBytesInUse.Add(-n)
+		s.used.Add(-n)
+		spillQuotaExceeded.Add("Total", 1)
+		return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "vtgate exceeded the allowed disk usage of %d bytes for spilled rows", s.cfg.MaxTotalBytes)
+	}
+	return nil
+}
+
+func (s *Spiller) release(n int64) {
+	s.used.Add(-n)
+	spillBytesInUse.Add(-n)
+}
+
+func (f *spillFile) writeRow(row sqltypes.Row) error {
+	f.buf = binary.AppendUvarint(f.buf[:0], uint64(len(row)))
+	for _, value := range row {
+		raw := value.Raw()
+		f.buf = binary.AppendUvarint(f.buf, uint64(value.Type()))
+		f.buf = binary.AppendUvarint(f.buf, uint64(len(raw)))
+		f.buf = append(f.buf, raw...)
+	}
+
+	n := int64(len(f.buf))
+	if err := f.spiller.reserve(n); err != nil {
+		return err
+	}
+	f.size += n
+	spillBytes.Add(f.primitive, n)
+	_, err := f.w.Write(f.buf)
+	return err
+}
+
+// reader returns a reader for the rows written to the file so far
+func (f *spillFile) reader() (*spillReader, error) {
+	if err := f.w.Flush(); err != nil {
+		return nil, err
+	}
+	if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return &spillReader{r: bufio.NewReader(f.file)}, nil
+}
+
+// forEach calls fn for every row written to the file
+func (f *spillFile) forEach(fn func(row sqltypes.Row) error) error {
+	r, err := f.reader()
+	if err != nil {
+		return err
+	}
+	for {
+		row, err := r.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+}
+
+func (f *spillFile) close() {
+	_ = f.file.Close()
+	_ = os.Remove(f.file.Name())
+	f.spiller.release(f.size)
+	f.size = 0
+}
+
+// next returns the next row in the file, or io.EOF when all the rows have been read
+func (r *spillReader) next() (sqltypes.Row, error) {
+	cols, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return nil, err
+	}
+	row := make(sqltypes.Row, 0, cols)
+	for i := uint64(0); i < cols; i++ {
+		typ, err := binary.ReadUvarint(r.r)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		size, err := binary.ReadUvarint(r.r)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		raw := make([]byte, size)
+		if _, err := io.ReadFull(r.r, raw); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		row = append(row, sqltypes.MakeTrusted(querypb.Type(typ), raw))
+	}
+	return row, nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// write adds the row to the partition the hash belongs to
+func (p spillPartitions) write(hash vthash.Hash, row sqltypes.Row) error {
+	idx := binary.LittleEndian.Uint64(hash[:8]) % uint64(len(p))
+	return p[idx].writeRow(row)
+}
+
+func (p spillPartitions) close() {
+	for _, file := range p {
+		file.close()
+	}
+}
+
+func closeSpillFiles(files []*spillFile) {
+	for _, file := range files {
+		file.close()
+	}
+}
+
+// sendInBatches calls the callback with the rows produced by fn, spillBatchSize rows at a time
+func sendInBatches(fn func(send func(row sqltypes.Row) error) error, callback func(rows []sqltypes.Row) error) error {
+	var batch []sqltypes.Row
+	err := fn(func(row sqltypes.Row) error {
+		batch = append(batch, row)
+		if len(batch) < spillBatchSize {
+			return nil
+		}
+		rows := batch
+		batch = nil
+		return callback(rows)
+	})
+	if err != nil || len(batch) == 0 {
+		return err
+	}
+	return callback(batch)
+}
+
+// executeStreaming runs the primitive through its streaming path and collects its result. The primitives holding
+// many rows use it once spilling to disk is enabled: executing their input would fail as soon as it returns more
+// rows than max_memory_rows, while streaming it lets them spill, or aggregate it one group at a time. The result
+// itself is still held in memory, so it is bound by max_memory_rows.
+func executeStreaming(ctx context.Context, vcursor VCursor, primitive Primitive, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	result := &sqltypes.Result{}
+	var mu sync.Mutex
+	err := primitive.TryStreamExecute(ctx, vcursor, bindVars, wantfields, func(qr *sqltypes.Result) error {
+		mu.Lock()
+		defer mu.Unlock()
+		result.AppendResult(qr)
+		if vcursor.ExceedsMaxMemoryRows(len(result.Rows)) {
+			return fmt.Errorf("in-memory row count exceeded allowed limit of %d", vcursor.MaxMemoryRows())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+)
+
+// withSpillToDisk makes the noopVCursor spill rows to a temporary directory once
+// more than maxMemoryRows are held in memory, and checks that all the files are gone at the end of the test
+func withSpillToDisk(t *testing.T, maxMemoryRows int, cfg SpillConfig) {
+	saveMax, saveSpiller := testMaxMemoryRows, testSpiller
+	cfg.Dir = t.TempDir()
+	testMaxMemoryRows = maxMemoryRows
+	testSpiller = NewSpiller(cfg)
+	t.Cleanup(func() {
+		testMaxMemoryRows, testSpiller = saveMax, saveSpiller
+		entries, err := os.ReadDir(cfg.Dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "spill files were not removed")
+	})
+}
+
+func TestSpillFileRoundTrip(t *testing.T) {
+	withSpillToDisk(t, 1, SpillConfig{})
+
+	rows := []sqltypes.Row{
+		{sqltypes.NewInt64(1), sqltypes.NewVarChar("a"), sqltypes.NULL},
+		{sqltypes.NewInt64(-2), sqltypes.NewVarChar(""), sqltypes.NewFloat64(1.5)},
+		{},
+	}
+	file, err := testSpiller.spill("Test", rows)
+	require.NoError(t, err)
+	defer file.close()
+	assert.Equal(t, file.size, testSpiller.used.Load())
+
+	r, err := file.reader()
+	require.NoError(t, err)
+	for _, want := range rows {
+		got, err := r.next()
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	_, err = r.next()
+	assert.Equal(t, io.EOF, err)
+
+	file.close()
+	assert.Zero(t, testSpiller.used.Load())
+}
+
+func TestSpillQuotas(t *testing.T) {
+	rows := []sqltypes.Row{{sqltypes.NewVarChar("0123456789")}, {sqltypes.NewVarChar("0123456789")}}
+
+	withSpillToDisk(t, 1, SpillConfig{MaxQueryBytes: 20})
+	_, err := testSpiller.spill("Test", rows)
+	require.EqualError(t, err, "query exceeded the allowed disk usage of 20 bytes for spilled rows")
+	assert.Zero(t, testSpiller.used.Load())
+
+	spiller := NewSpiller(SpillConfig{Dir: t.TempDir(), MaxTotalBytes: 20})
+	_, err = spiller.spill("Test", rows)
+	require.EqualError(t, err, "vtgate exceeded the allowed disk usage of 20 bytes for spilled rows")
+	assert.Zero(t, spiller.used.Load())
+}
@@ -0,0 +1,571 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/slice"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/vterrors"
+	. "vitess.io/vitess/go/vt/vtgate/engine/opcode"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+var _ Primitive = (*Window)(nil)
+
+// Window is a primitive that evaluates window functions over the rows produced by its input.
+// The input must be sorted by the PARTITION BY columns followed by the ORDER BY columns
+// of the window. The rows of a partition are kept in memory until the partition is complete,
+// and are then sent on with the results of the window functions.
+type Window struct {
+	// Cols specifies, for every column produced by this primitive, the input column
+	// that it is copied from. Columns that are produced by a window function are set to -1.
+	Cols []int
+
+	// Functions are the window functions evaluated by this primitive.
+	Functions []*WindowFunc
+
+	// PartitionBy specifies the input columns that split the rows into partitions.
+	PartitionBy []*GroupByParams
+
+	// OrderBy is the ordering of the rows inside each partition.
+	// It is used to find the peers of a row, the rows that sort equal to it.
+	OrderBy evalengine.Comparison
+
+	// Input is the primitive that will feed into this Primitive.
+	Input Primitive
+}
+
+// WindowFunc specifies a single window function evaluated by the Window primitive.
+type WindowFunc struct {
+	Opcode WindowOpcode
+
+	// Col is the output column that the result of the function is written to.
+	Col int
+
+	// ArgCol is the input column holding the argument of the function, or -1 if it takes no argument.
+	ArgCol int
+
+	// DefaultCol is the input column holding the default value of LAG and LEAD, or -1 when the default is NULL.
+	DefaultCol int
+
+	// N is the offset used by LAG and LEAD, the number of buckets of NTILE and the row used by NTH_VALUE.
+	N int64
+
+	// Frame is the frame the function is evaluated over. When nil, the default frame is used:
+	// all rows from the start of the partition to the last peer of the current row.
+	Frame *WindowFrame
+
+	Alias string
+
+	// Type is the type of the argument of the function.
+	Type         evalengine.Type
+	CollationEnv *collations.Environment
+}
+
+// WindowFrame specifies the rows of a partition that a window function is evaluated over.
+type WindowFrame struct {
+	// Rows is true for ROWS frames. For RANGE frames, only unbounded and CURRENT ROW
+	// bounds are supported, and CURRENT ROW includes all the peers of the current row.
+	Rows       bool
+	Start, End FrameBound
+}
+
+// FrameBound is one of the bounds of a WindowFrame.
+type FrameBound struct {
+	// Unbounded is true for UNBOUNDED PRECEDING when used as the start of the frame,
+	// and for UNBOUNDED FOLLOWING when used as the end of it.
+	Unbounded bool
+
+	// Offset is the number of rows between the current row and the bound.
+	// Negative values point to preceding rows, and 0 is the current row.
+	Offset int64
+}
+
+// String returns a string. Used for plan descriptions
+func (wf *WindowFunc) String() string {
+	var args []string
+	if wf.ArgCol >= 0 {
+		args = append(args, strconv.Itoa(wf.ArgCol))
+	}
+	switch wf.Opcode {
+	case WindowLag, WindowLead, WindowNthValue:
+		args = append(args, strconv.FormatInt(wf.N, 10))
+	case WindowNtile:
+		args = append(args, strconv.FormatInt(wf.N, 10))
+	}
+	if wf.DefaultCol >= 0 {
+		args = append(args, strconv.Itoa(wf.DefaultCol))
+	}
+
+	out := fmt.Sprintf("%s(%s)", wf.Opcode.String(), strings.Join(args, ", "))
+	if wf.Frame != nil && wf.Opcode.UsesFrame() {
+		out += " " + wf.Frame.String()
+	}
+	if wf.Alias != "" {
+		out += " AS " + wf.Alias
+	}
+	return out
+}
+
+// String returns a string. Used for plan descriptions
+func (wf *WindowFrame) String() string {
+	unit := "RANGE"
+	if wf.Rows {
+		unit = "ROWS"
+	}
+	return fmt.Sprintf("%s BETWEEN %s AND %s", unit, wf.Start.string("PRECEDING"), wf.End.string("FOLLOWING"))
+}
+
+func (fb FrameBound) string(unbounded string) string {
+	switch {
+	case fb.Unbounded:
+		return "UNBOUNDED " + unbounded
+	case fb.Offset < 0:
+		return fmt.Sprintf("%d PRECEDING", -fb.Offset)
+	case fb.Offset > 0:
+		return fmt.Sprintf("%d FOLLOWING", fb.Offset)
+	default:
+		return "CURRENT ROW"
+	}
+}
+
+// RouteType returns a description of the query routing type used by the primitive
+func (w *Window) RouteType() string {
+	return w.Input.RouteType()
+}
+
+// GetKeyspaceName specifies the Keyspace that this primitive routes to.
+func (w *Window) GetKeyspaceName() string {
+	return w.Input.GetKeyspaceName()
+}
+
+// GetTableName specifies the table that this primitive routes to.
+func (w *Window) GetTableName() string {
+	return w.Input.GetTableName()
+}
+
+// NeedsTransaction implements the Primitive interface
+func (w *Window) NeedsTransaction() bool {
+	return w.Input.NeedsTransaction()
+}
+
+// Inputs returns the Primitive input for this window
+func (w *Window) Inputs() ([]Primitive, []map[string]any) {
+	return []Primitive{w.Input}, nil
+}
+
+// TryExecute is a Primitive function.
+func (w *Window) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, _ bool) (result *sqltypes.Result, err error) {
+	defer evalengine.PanicHandler(&err)
+
+	/* we need the input fields types to correctly calculate the output types */
+	qr, err := vcursor.ExecutePrimitive(ctx, w.Input, bindVars, true)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := w.buildFields(qr.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	result = &sqltypes.Result{
+		Fields: fields,
+		Rows:   make([]sqltypes.Row, 0, len(qr.Rows)),
+	}
+
+	var partition []sqltypes.Row
+	for _, row := range qr.Rows {
+		if len(partition) > 0 {
+			newPartition, err := w.nextPartition(partition[0], row)
+			if err != nil {
+				return nil, err
+			}
+			if newPartition {
+				if result.Rows, err = w.evaluate(result.Rows, partition, qr.Fields); err != nil {
+					return nil, err
+				}
+				partition = nil
+			}
+		}
+		partition = append(partition, row)
+	}
+
+	if len(partition) > 0 {
+		if result.Rows, err = w.evaluate(result.Rows, partition, qr.Fields); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// TryStreamExecute is a Primitive function.
+func (w *Window) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, _ bool, callback func(*sqltypes.Result) error) (err error) {
+	defer evalengine.PanicHandler(&err)
+
+	var inputFields []*querypb.Field
+	var partition []sqltypes.Row
+
+	flush := func() error {
+		rows, err := w.evaluate(nil, partition, inputFields)
+		if err != nil {
+			return err
+		}
+		partition = nil
+		return callback(&sqltypes.Result{Rows: rows})
+	}
+
+	visitor := func(qr *sqltypes.Result) error {
+		if inputFields == nil && len(qr.Fields) != 0 {
+			inputFields = qr.Fields
+			fields, err := w.buildFields(qr.Fields)
+			if err != nil {
+				return err
+			}
+			if err := callback(&sqltypes.Result{Fields: fields}); err != nil {
+				return err
+			}
+		}
+
+		for _, row := range qr.Rows {
+			if len(partition) > 0 {
+				newPartition, err := w.nextPartition(partition[0], row)
+				if err != nil {
+					return err
+				}
+				if newPartition {
+					if err := flush(); err != nil {
+						return err
+					}
+				}
+			}
+			partition = append(partition, row)
+		}
+
+		if vcursor.ExceedsMaxMemoryRows(len(partition)) {
+			return fmt.Errorf("in-memory row count exceeded allowed limit of %d", vcursor.MaxMemoryRows())
+		}
+		return nil
+	}
+
+	/* we need the input fields types to correctly calculate the output types */
+	err = vcursor.StreamExecutePrimitive(ctx, w.Input, bindVars, true, visitor)
+	if err != nil {
+		return err
+	}
+
+	if len(partition) > 0 {
+		return flush()
+	}
+	return nil
+}
+
+// GetFields is a Primitive function.
+func (w *Window) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	qr, err := w.Input.GetFields(ctx, vcursor, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := w.buildFields(qr.Fields)
+	if err != nil {
+		return nil, err
+	}
+	return &sqltypes.Result{Fields: fields}, nil
+}
+
+func (w *Window) buildFields(input []*querypb.Field) ([]*querypb.Field, error) {
+	if len(input) == 0 {
+		return nil, nil
+	}
+	fields := make([]*querypb.Field, len(w.Cols))
+	for idx, col := range w.Cols {
+		if col >= 0 {
+			fields[idx] = input[col]
+		}
+	}
+	for _, wf := range w.Functions {
+		inputType := sqltypes.Null
+		if wf.ArgCol >= 0 {
+			inputType = input[wf.ArgCol].Type
+		}
+		if wf.Col >= len(fields) || fields[wf.Col] != nil {
+			return nil, vterrors.VT13001(fmt.Sprintf("invalid output column for window function %s", wf.String()))
+		}
+		fields[wf.Col] = &querypb.Field{
+			Name: wf.Alias,
+			Type: wf.Opcode.SQLType(inputType),
+		}
+	}
+	return fields, nil
+}
+
+// nextPartition returns true if the row does not belong to the partition started by first
+func (w *Window) nextPartition(first, row sqltypes.Row) (bool, error) {
+	_, next, err := nextGroupBy(w.PartitionBy, first, row)
+	return next, err
+}
+
+// evaluate computes the window functions for a complete partition and appends the output rows to out
+func (w *Window) evaluate(out []sqltypes.Row, partition []sqltypes.Row, fields []*querypb.Field) ([]sqltypes.Row, error) {
+	size := len(partition)
+	if size == 0 {
+		return out, nil
+	}
+
+	// first we find the peers of every row. peers are rows that are equal according to the ORDER BY
+	peerStart := make([]int, size)
+	peerEnd := make([]int, size)
+	start := 0
+	for i := 1; i <= size; i++ {
+		if i < size && w.OrderBy.Compare(partition[start], partition[i]) == 0 {
+			continue
+		}
+		for j := start; j < i; j++ {
+			peerStart[j] = start
+			peerEnd[j] = i
+		}
+		start = i
+	}
+
+	rows := make([]sqltypes.Row, size)
+	for i, row := range partition {
+		output := make(sqltypes.Row, len(w.Cols))
+		for idx, col := range w.Cols {
+			if col >= 0 {
+				output[idx] = row[col]
+			}
+		}
+		rows[i] = output
+	}
+
+	for _, wf := range w.Functions {
+		if err := wf.evaluate(rows, partition, peerStart, peerEnd, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	return append(out, rows...), nil
+}
+
+func (wf *WindowFunc) evaluate(output, partition []sqltypes.Row, peerStart, peerEnd []int, fields []*querypb.Field) error {
+	size := len(partition)
+	switch wf.Opcode {
+	case WindowRowNumber:
+		for i := range partition {
+			output[i][wf.Col] = sqltypes.NewUint64(uint64(i + 1))
+		}
+	case WindowRank:
+		for i := range partition {
+			output[i][wf.Col] = sqltypes.NewUint64(uint64(peerStart[i] + 1))
+		}
+	case WindowDenseRank:
+		var rank uint64
+		for i := range partition {
+			if peerStart[i] == i {
+				rank++
+			}
+			output[i][wf.Col] = sqltypes.NewUint64(rank)
+		}
+	case WindowPercentRank:
+		for i := range partition {
+			var pr float64
+			if size > 1 {
+				pr = float64(peerStart[i]) / float64(size-1)
+			}
+			output[i][wf.Col] = sqltypes.NewFloat64(pr)
+		}
+	case WindowCumeDist:
+		for i := range partition {
+			output[i][wf.Col] = sqltypes.NewFloat64(float64(peerEnd[i]) / float64(size))
+		}
+	case WindowNtile:
+		if wf.N <= 0 {
+			return vterrors.VT03001("ntile")
+		}
+		// the first (size % N) buckets get one extra row each
+		buckets := int(wf.N)
+		small := size / buckets
+		extra := size % buckets
+		bucket, inBucket := 1, 0
+		for i := range partition {
+			bucketSize := small
+			if bucket <= extra {
+				bucketSize++
+			}
+			if inBucket == bucketSize {
+				bucket++
+				inBucket = 0
+			}
+			inBucket++
+			output[i][wf.Col] = sqltypes.NewUint64(uint64(bucket))
+		}
+	case WindowLag, WindowLead:
+		offset := int(wf.N)
+		if wf.Opcode == WindowLag {
+			offset = -offset
+		}
+		for i := range partition {
+			target := i + offset
+			switch {
+			case target >= 0 && target < size:
+				output[i][wf.Col] = partition[target][wf.ArgCol]
+			case wf.DefaultCol >= 0:
+				output[i][wf.Col] = partition[i][wf.DefaultCol]
+			default:
+				output[i][wf.Col] = sqltypes.NULL
+			}
+		}
+	case WindowFirstValue, WindowLastValue, WindowNthValue:
+		for i := range partition {
+			start, end := wf.frame(i, size, peerStart, peerEnd)
+			target := -1
+			switch wf.Opcode {
+			case WindowFirstValue:
+				target = start
+			case WindowLastValue:
+				target = end - 1
+			case WindowNthValue:
+				target = start + int(wf.N) - 1
+			}
+			if start < end && target >= start && target < end {
+				output[i][wf.Col] = partition[target][wf.ArgCol]
+			} else {
+				output[i][wf.Col] = sqltypes.NULL
+			}
+		}
+	case WindowCount, WindowCountStar, WindowSum, WindowMin, WindowMax:
+		return wf.evaluateAggregation(output, partition, peerStart, peerEnd, fields)
+	default:
+		return vterrors.VT12001(fmt.Sprintf("window function %s", wf.Opcode.String()))
+	}
+	return nil
+}
+
+// evaluateAggregation evaluates an aggregation function over the frame of every row.
+// When the frame always starts at the beginning of the partition, the aggregation is computed
+// incrementally, otherwise it is recomputed from scratch for every row.
+func (wf *WindowFunc) evaluateAggregation(output, partition []sqltypes.Row, peerStart, peerEnd []int, fields []*querypb.Field) error {
+	aggr := wf.newAggregator(fields)
+	incremental := wf.Frame == nil || wf.Frame.Start.Unbounded
+
+	added := 0
+	for i := range partition {
+		start, end := wf.frame(i, len(partition), peerStart, peerEnd)
+		if !incremental || end < added {
+			aggr.reset()
+			added = start
+		}
+		for ; added < end; added++ {
+			if err := aggr.add(partition[added]); err != nil {
+				return err
+			}
+		}
+		if start >= end && !incremental {
+			aggr.reset()
+		}
+		output[i][wf.Col] = aggr.finish()
+	}
+	return nil
+}
+
+func (wf *WindowFunc) newAggregator(fields []*querypb.Field) aggregator {
+	noDistinct := aggregatorDistinct{column: -1}
+	sourceType := sqltypes.Null
+	if wf.ArgCol >= 0 {
+		sourceType = fields[wf.ArgCol].Type
+	}
+
+	switch wf.Opcode {
+	case WindowCountStar:
+		return &aggregatorCountStar{}
+	case WindowCount:
+		return &aggregatorCount{from: wf.ArgCol, distinct: noDistinct}
+	case WindowSum:
+		return &aggregatorSum{from: wf.ArgCol, sum: evalengine.NewAggregationSum(sourceType), distinct: noDistinct}
+	case WindowMin:
+		return &aggregatorMin{aggregatorMinMax{
+			from:   wf.ArgCol,
+			minmax: evalengine.NewAggregationMinMax(sourceType, wf.CollationEnv, wf.Type.Collation(), wf.Type.Values()),
+		}}
+	default:
+		return &aggregatorMax{aggregatorMinMax{
+			from:   wf.ArgCol,
+			minmax: evalengine.NewAggregationMinMax(sourceType, wf.CollationEnv, wf.Type.Collation(), wf.Type.Values()),
+		}}
+	}
+}
+
+// frame returns the rows of the partition, as a half-open interval, that make up the frame of the current row
+func (wf *WindowFunc) frame(current, size int, peerStart, peerEnd []int) (start, end int) {
+	f := wf.Frame
+	if f == nil {
+		return 0, peerEnd[current]
+	}
+
+	switch {
+	case f.Start.Unbounded:
+		start = 0
+	case f.Rows:
+		start = current + int(f.Start.Offset)
+	default:
+		start = peerStart[current]
+	}
+
+	switch {
+	case f.End.Unbounded:
+		end = size
+	case f.Rows:
+		end = current + int(f.End.Offset) + 1
+	default:
+		end = peerEnd[current]
+	}
+
+	start = max(0, min(start, size))
+	end = max(0, min(end, size))
+	if end < start {
+		end = start
+	}
+	return start, end
+}
+
+func windowFuncToString(i any) string {
+	return i.(*WindowFunc).String()
+}
+
+func (w *Window) description() PrimitiveDescription {
+	other := map[string]any{
+		"Columns":   strings.Join(slice.Map(w.Cols, strconv.Itoa), ","),
+		"Functions": GenericJoin(w.Functions, windowFuncToString),
+	}
+	if len(w.PartitionBy) > 0 {
+		other["PartitionBy"] = GenericJoin(w.PartitionBy, groupByParamsToString)
+	}
+	if len(w.OrderBy) > 0 {
+		other["OrderBy"] = GenericJoin(w.OrderBy, orderByParamsToString)
+	}
+	return PrimitiveDescription{
+		OperatorType: "Window",
+		Other:        other,
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
+	. "vitess.io/vitess/go/vt/vtgate/engine/opcode"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+func windowTestInput() *fakePrimitive {
+	return &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields(
+				"grp|val",
+				"int64|int64",
+			),
+			"1|10",
+			"1|20",
+			"1|20",
+			"1|40",
+			"2|5",
+		)},
+	}
+}
+
+func windowOrderByVal() evalengine.Comparison {
+	return evalengine.Comparison{{
+		Col:             1,
+		WeightStringCol: -1,
+		Type:            evalengine.NewType(sqltypes.Int64, collations.CollationBinaryID),
+	}}
+}
+
+func TestWindowRanking(t *testing.T) {
+	w := &Window{
+		Cols: []int{0, 1, -1, -1, -1, -1},
+		Functions: []*WindowFunc{
+			{Opcode: WindowRowNumber, Col: 2, ArgCol: -1, DefaultCol: -1, Alias: "row_number()"},
+			{Opcode: WindowRank, Col: 3, ArgCol: -1, DefaultCol: -1, Alias: "rank()"},
+			{Opcode: WindowDenseRank, Col: 4, ArgCol: -1, DefaultCol: -1, Alias: "dense_rank()"},
+			{Opcode: WindowNtile, Col: 5, ArgCol: -1, DefaultCol: -1, N: 3, Alias: "ntile(3)"},
+		},
+		PartitionBy: []*GroupByParams{{KeyCol: 0, WeightStringCol: -1}},
+		OrderBy:     windowOrderByVal(),
+		Input:       windowTestInput(),
+	}
+
+	result, err := w.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.NoError(t, err)
+
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"grp|val|row_number()|rank()|dense_rank()|ntile(3)",
+			"int64|int64|uint64|uint64|uint64|uint64",
+		),
+		"1|10|1|1|1|1",
+		"1|20|2|2|2|1",
+		"1|20|3|2|2|2",
+		"1|40|4|4|3|3",
+		"2|5|1|1|1|1",
+	)
+	utils.MustMatch(t, want, result)
+}
+
+func TestWindowValueFunctions(t *testing.T) {
+	w := &Window{
+		Cols: []int{0, 1, -1, -1, -1},
+		Functions: []*WindowFunc{
+			{Opcode: WindowLag, Col: 2, ArgCol: 1, DefaultCol: -1, N: 1, Alias: "lag(val)"},
+			{Opcode: WindowLead, Col: 3, ArgCol: 1, DefaultCol: 0, N: 2, Alias: "lead(val, 2, grp)"},
+			{Opcode: WindowFirstValue, Col: 4, ArgCol: 1, DefaultCol: -1, Alias: "first_value(val)", Frame: &WindowFrame{
+				Rows:  true,
+				Start: FrameBound{Offset: -1},
+				End:   FrameBound{Offset: 1},
+			}},
+		},
+		PartitionBy: []*GroupByParams{{KeyCol: 0, WeightStringCol: -1}},
+		OrderBy:     windowOrderByVal(),
+		Input:       windowTestInput(),
+	}
+
+	result, err := w.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.NoError(t, err)
+
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"grp|val|lag(val)|lead(val, 2, grp)|first_value(val)",
+			"int64|int64|int64|int64|int64",
+		),
+		"1|10|null|20|10",
+		"1|20|10|40|10",
+		"1|20|20|1|20",
+		"1|40|20|1|20",
+		"2|5|null|2|5",
+	)
+	utils.MustMatch(t, want, result)
+}
+
+func TestWindowAggregations(t *testing.T) {
+	w := &Window{
+		Cols: []int{0, 1, -1, -1, -1},
+		Functions: []*WindowFunc{
+			{Opcode: WindowSum, Col: 2, ArgCol: 1, DefaultCol: -1, Alias: "sum(val)"},
+			{Opcode: WindowCountStar, Col: 3, ArgCol: -1, DefaultCol: -1, Alias: "count(*)", Frame: &WindowFrame{
+				Start: FrameBound{Unbounded: true},
+				End:   FrameBound{Unbounded: true},
+			}},
+			{Opcode: WindowMax, Col: 4, ArgCol: 1, DefaultCol: -1, Alias: "max(val)", Frame: &WindowFrame{
+				Rows:  true,
+				Start: FrameBound{Offset: -1},
+				End:   FrameBound{Offset: 0},
+			}, Type: evalengine.NewType(sqltypes.Int64, collations.CollationBinaryID), CollationEnv: collations.MySQL8()},
+		},
+		PartitionBy: []*GroupByParams{{KeyCol: 0, WeightStringCol: -1}},
+		OrderBy:     windowOrderByVal(),
+		Input:       windowTestInput(),
+	}
+
+	var results []*sqltypes.Result
+	err := w.TryStreamExecute(context.Background(), &noopVCursor{}, nil, true, func(qr *sqltypes.Result) error {
+		results = append(results, qr)
+		return nil
+	})
+	require.NoError(t, err)
+
+	want := sqltypes.MakeTestStreamingResults(
+		sqltypes.MakeTestFields(
+			"grp|val|sum(val)|count(*)|max(val)",
+			"int64|int64|decimal|int64|int64",
+		),
+		"1|10|10|4|10",
+		"1|20|50|4|20",
+		"1|20|50|4|20",
+		"1|40|90|4|40",
+		"---",
+		"2|5|5|1|5",
+	)
+	utils.MustMatch(t, want, results)
+}
+
+func TestWindowDescription(t *testing.T) {
+	w := &Window{
+		Cols: []int{0, -1},
+		Functions: []*WindowFunc{
+			{Opcode: WindowLag, Col: 1, ArgCol: 0, DefaultCol: -1, N: 1, Alias: "lag(val)"},
+		},
+		PartitionBy: []*GroupByParams{{KeyCol: 1, WeightStringCol: -1}},
+		OrderBy:     windowOrderByVal(),
+	}
+
+	desc := w.description()
+	assert.Equal(t, "Window", desc.OperatorType)
+	assert.Equal(t, "lag(0, 1) AS lag(val)", desc.Other["Functions"])
+	assert.Equal(t, "0,-1", desc.Other["Columns"])
+}
@@ -31,6 +31,16 @@ func CoerceTo(value sqltypes.Value, typ Type, sqlmode SQLMode) (sqltypes.Value,
 	return evalToSQLValueWithType(cast, typ), nil
 }
 
+// CastTo converts the given value into the given type, instead of keeping the type of the value
+// like CoerceTo does. It is used when values need to be stored in a column of a known type.
+func CastTo(value sqltypes.Value, typ Type, sqlmode SQLMode) (sqltypes.Value, error) {
+	cast, err := valueToEvalCast(value, typ.Type(), typ.collation, typ.values, sqlmode)
+	if err != nil {
+		return sqltypes.Value{}, err
+	}
+	return evalToSQLValueWithType(cast, typ), nil
+}
+
 // CoerceTypes takes two input types, and decides how they should be coerced before compared
 func CoerceTypes(v1, v2 Type, collationEnv *collations.Environment) (out Type, err error) {
 	if v1.Equal(&v2) {
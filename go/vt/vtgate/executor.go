@@ -38,6 +38,7 @@ import (
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/streamlog"
+	"vitess.io/vitess/go/sync2"
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/key"
@@ -60,6 +61,9 @@ import (
 	"vitess.io/vitess/go/vt/vtgate/logstats"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/queryrules"
+	"vitess.io/vitess/go/vt/vtgate/resultcache"
+	"vitess.io/vitess/go/vt/vtgate/userlimits"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
 	"vitess.io/vitess/go/vt/vtgate/vschemaacl"
 	"vitess.io/vitess/go/vt/vtgate/vtgateservice"
@@ -130,6 +134,24 @@ type Executor struct {
 
 	warmingReadsPercent int
 	warmingReadsChannel chan bool
+
+	// queryRules are the rules that can deny, time out, redirect or rate limit the queries
+	queryRules atomic.Pointer[queryrules.Rules]
+
+	// userLimits are the resource limits of the users, nil when the users are not limited
+	userLimits *userlimits.Governor
+
+	// resultCache caches the results of read-only queries, nil when it is disabled
+	resultCache *resultcache.Cache
+
+	// afterTransaction has the functions to call once the open transactions are over, by session
+	afterTransactionMu sync.Mutex
+	afterTransaction   map[string][]func()
+
+	// consolidator shares the result of a read with the identical reads running at the same time,
+	// nil when it is disabled. consolidations counts how often recent reads waited.
+	consolidator   sync2.Consolidator
+	consolidations *sync2.ConsolidatorCache
 }
 
 var executorOnce sync.Once
@@ -137,6 +159,7 @@ var executorOnce sync.Once
 const pathQueryPlans = "/debug/query_plans"
 const pathScatterStats = "/debug/scatter_stats"
 const pathVSchema = "/debug/vschema"
+const pathConsolidations = "/debug/consolidations"
 
 type PlanCacheKey = theine.HashKey256
 type PlanCache = theine.Store[PlanCacheKey, *engine.Plan]
@@ -178,6 +201,7 @@ func NewExecutor(
 		plans:               plans,
 		warmingReadsPercent: warmingReadsPercent,
 		warmingReadsChannel: make(chan bool, warmingReadsConcurrency),
+		afterTransaction:    make(map[string][]func()),
 	}
 
 	vschemaacl.Init()
@@ -213,6 +237,7 @@ func NewExecutor(
 		servenv.HTTPHandle(pathQueryPlans, e)
 		servenv.HTTPHandle(pathScatterStats, e)
 		servenv.HTTPHandle(pathVSchema, e)
+		servenv.HTTPHandle(pathConsolidations, e)
 	})
 	return e
 }
@@ -225,6 +250,7 @@ func (e *Executor) Execute(ctx context.Context, mysqlCtx vtgateservice.MySQLConn
 	defer span.Finish()
 
 	logStats := logstats.NewLogStats(ctx, method, sql, safeSession.GetSessionUUID(), bindVars)
+	logStats.QueryAttributes = safeSession.GetOptions().GetQueryAttributes()
 	stmtType, result, err := e.execute(ctx, mysqlCtx, safeSession, sql, bindVars, logStats)
 	logStats.Error = err
 	if result == nil {
@@ -292,6 +318,7 @@ func (e *Executor) StreamExecute(
 	defer span.Finish()
 
 	logStats := logstats.NewLogStats(ctx, method, sql, safeSession.GetSessionUUID(), bindVars)
+	logStats.QueryAttributes = safeSession.GetOptions().GetQueryAttributes()
 	srr := &streaminResultReceiver{callback: callback}
 	var err error
 
@@ -336,7 +363,11 @@ func (e *Executor) StreamExecute(
 		}
 
 		// 4: Execute!
+		var rows atomic.Int64
 		err := vc.StreamExecutePrimitive(ctx, plan.Instructions, bindVars, true, func(qr *sqltypes.Result) error {
+			if err := vc.userLimits.CheckRows(int(rows.Add(int64(len(qr.Rows))))); err != nil {
+				return err
+			}
 			return srr.storeResultStats(plan.Type, qr)
 		})
 
@@ -711,12 +742,44 @@ func (e *Executor) handleKill(ctx context.Context, mysqlCtx vtgateservice.MySQLC
 	return &sqltypes.Result{}, nil
 }
 
+// SetUserLimits sets the resource limits of the users running queries
+func (e *Executor) SetUserLimits(g *userlimits.Governor) {
+	e.userLimits = g
+}
+
 // CloseSession releases the current connection, which rollbacks open transactions and closes reserved connections.
 // It is called then the MySQL servers closes the connection to its client.
 func (e *Executor) CloseSession(ctx context.Context, safeSession *SafeSession) error {
+	e.userLimits.For(callerid.ImmediateCallerIDFromContext(ctx).GetUsername()).TrackTransaction(safeSession.GetSessionUUID(), false, nil)
+	e.resultCache.TrackWrites(safeSession.GetSessionUUID(), false, nil)
+	defer e.runAfterTransaction(safeSession, false)
 	return e.txConn.ReleaseAll(ctx, safeSession)
 }
 
+// runAfterTransaction calls the functions registered with SafeSession.AfterTransaction once
+// the transaction of the session is over. It must be called after every query. Sessions
+// without a SessionUUID can't be followed across queries, so their functions are called
+// once the query that registered them is done, even in a transaction.
+func (e *Executor) runAfterTransaction(safeSession *SafeSession, inTransaction bool) {
+	funcs := safeSession.takeAfterTransaction()
+	if sessionUUID := safeSession.GetSessionUUID(); sessionUUID != "" {
+		e.afterTransactionMu.Lock()
+		if inTransaction {
+			if len(funcs) > 0 {
+				e.afterTransaction[sessionUUID] = append(e.afterTransaction[sessionUUID], funcs...)
+			}
+			e.afterTransactionMu.Unlock()
+			return
+		}
+		funcs = append(e.afterTransaction[sessionUUID], funcs...)
+		delete(e.afterTransaction, sessionUUID)
+		e.afterTransactionMu.Unlock()
+	}
+	for _, f := range funcs {
+		f()
+	}
+}
+
 func (e *Executor) setVitessMetadata(ctx context.Context, name, value string) error {
 	// TODO(kalfonso): move to its own acl check and consolidate into an acl component that can handle multiple operations (vschema, metadata)
 	user := callerid.ImmediateCallerIDFromContext(ctx)
@@ -1057,6 +1120,9 @@ func (e *Executor) SaveVSchema(vschema *vindexes.VSchema, stats *VSchemaStats) {
 	}
 	e.vschemaStats = stats
 	e.ClearPlans()
+	// the schema of the tables might have changed
+	e.resultCache.InvalidateAll()
+	e.resultCache.SetTables(resultCacheTables(e.vschema))
 
 	if vschemaCounters != nil {
 		vschemaCounters.Add("Reload", 1)
@@ -1107,6 +1173,10 @@ func (e *Executor) getPlan(
 
 	vcursor.SetIgnoreMaxMemoryRows(sqlparser.IgnoreMaxMaxMemoryRowsDirective(stmt))
 	vcursor.SetConsolidator(sqlparser.Consolidator(stmt))
+	if e.resultCache != nil {
+		vcursor.resultCacheTTL = sqlparser.ResultCacheTTL(stmt)
+		vcursor.resultCacheable = resultCacheable(stmt)
+	}
 	vcursor.SetWorkloadName(sqlparser.GetWorkloadNameFromStatement(stmt))
 	vcursor.UpdateForeignKeyChecksState(sqlparser.ForeignKeyChecksState(stmt))
 	priority, err := sqlparser.GetPriorityFromStatement(stmt)
@@ -1258,6 +1328,8 @@ func (e *Executor) ServeHTTP(response http.ResponseWriter, request *http.Request
 		returnAsJSON(response, e.VSchema())
 	case pathScatterStats:
 		e.WriteScatterStats(response)
+	case pathConsolidations:
+		e.writeConsolidations(response)
 	default:
 		response.WriteHeader(http.StatusNotFound)
 	}
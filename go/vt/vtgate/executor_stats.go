@@ -32,6 +32,7 @@ const (
   <a href="/debug/queryz">Query Plan Stats</a><br>
   <a href="/debug/query_plans">Query Plans</a><br>
   <a href="/debug/scatter_stats">Scatter Query Statistics</a><br>
+  <a href="/debug/consolidations">Consolidations</a><br>
 </td>
 </tr>
 </table>
@@ -2946,3 +2946,36 @@ func exec(executor *Executor, session *SafeSession, sql string) (*sqltypes.Resul
 func makeComments(text string) sqlparser.MarginComments {
 	return sqlparser.MarginComments{Trailing: text}
 }
+
+func TestExecutorAfterTransaction(t *testing.T) {
+	executor, _, _, _, ctx := createExecutorEnv(t)
+
+	var calls int
+	session := NewSafeSession(&vtgatepb.Session{TargetString: "@primary", SessionUUID: "s1"})
+	_, err := executor.Execute(ctx, nil, "TestExecute", session, "begin", nil)
+	require.NoError(t, err)
+	session.AfterTransaction(func() { calls++ })
+	_, err = executor.Execute(ctx, nil, "TestExecute", session, "select id from user where id = 1", nil)
+	require.NoError(t, err)
+	assert.Zero(t, calls)
+
+	_, err = executor.Execute(ctx, nil, "TestExecute", session, "commit", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	// the functions of the sessions closed in a transaction are called as well
+	_, err = executor.Execute(ctx, nil, "TestExecute", session, "begin", nil)
+	require.NoError(t, err)
+	session.AfterTransaction(func() { calls++ })
+	_, err = executor.Execute(ctx, nil, "TestExecute", session, "select id from user where id = 1", nil)
+	require.NoError(t, err)
+	require.NoError(t, executor.CloseSession(ctx, session))
+	assert.Equal(t, 2, calls)
+
+	// without a SessionUUID, they are called once the query is done
+	session = NewSafeSession(&vtgatepb.Session{TargetString: "@primary", InTransaction: true})
+	session.AfterTransaction(func() { calls++ })
+	_, err = executor.Execute(ctx, nil, "TestExecute", session, "select id from user where id = 1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
@@ -52,6 +52,8 @@ type LogStats struct {
 	SessionUUID    string
 	CachedPlan     bool
 	ActiveKeyspace string // ActiveKeyspace is the selected keyspace `use ks`
+	// QueryAttributes are the query attributes the MySQL client sent with the query.
+	QueryAttributes map[string]string
 }
 
 // NewLogStats constructs a new LogStats with supplied Method and ctx
@@ -177,6 +179,12 @@ func (stats *LogStats) Logf(w io.Writer, params url.Values) error {
 	log.Strings(stats.TablesUsed)
 	log.Key("ActiveKeyspace")
 	log.String(stats.ActiveKeyspace)
+	log.Key("QueryAttributes")
+	if redacted {
+		log.Redacted()
+	} else {
+		log.StringMap(stats.QueryAttributes)
+	}
 
 	return log.Flush(w)
 }
@@ -23,11 +23,13 @@ import (
 	"time"
 
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vtgate/logstats"
+	"vitess.io/vitess/go/vt/vtgate/userlimits"
 	"vitess.io/vitess/go/vt/vtgate/vtgateservice"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -67,6 +69,25 @@ func (e *Executor) newExecute(
 ) (err error) {
 	// 1: Prepare before planning and execution.
 
+	user := e.userLimits.For(callerid.ImmediateCallerIDFromContext(ctx).GetUsername())
+	release, err := user.Admit()
+	if err != nil {
+		return err
+	}
+	defer release()
+	err = e.checkTransactionDuration(ctx, safeSession, user)
+	if err != nil {
+		return err
+	}
+	// written are the tables written by the query. Their cached results are invalidated
+	// once they are committed, even when the query fails as it might have written some rows.
+	var written []string
+	defer func() {
+		e.trackTransaction(ctx, user, safeSession)
+		e.resultCache.TrackWrites(safeSession.GetSessionUUID(), safeSession.InTransaction(), written)
+		e.runAfterTransaction(safeSession, safeSession.InTransaction())
+	}()
+
 	// Start an implicit transaction if necessary.
 	err = e.startTxIfNecessary(ctx, safeSession)
 	if err != nil {
@@ -115,6 +136,7 @@ func (e *Executor) newExecute(
 		if err != nil {
 			return err
 		}
+		vcursor.userLimits = user
 
 		// 3: Create a plan for the query.
 		// If we are retrying, it is likely that the routing rules have changed and hence we need to
@@ -134,6 +156,7 @@ func (e *Executor) newExecute(
 		if plan.Type != sqlparser.StmtShow {
 			safeSession.ClearWarnings()
 		}
+		written = writtenTables(plan)
 
 		// Add any warnings that the planner wants to add.
 		for _, warning := range plan.Warnings {
@@ -155,15 +178,22 @@ func (e *Executor) newExecute(
 			return err
 		}
 
+		execCtx, done, err := e.applyQueryRules(ctx, safeSession, stmt, plan, vcursor)
+		if err != nil {
+			logStats.Error = err
+			return err
+		}
+
 		// 5: Execute the plan.
 		if plan.Instructions.NeedsTransaction() {
 			err = e.insideTransaction(ctx, safeSession, logStats,
 				func() error {
-					return execPlan(ctx, plan, vcursor, bindVars, execStart)
+					return execPlan(execCtx, plan, vcursor, bindVars, execStart)
 				})
 		} else {
-			err = execPlan(ctx, plan, vcursor, bindVars, execStart)
+			err = execPlan(execCtx, plan, vcursor, bindVars, execStart)
 		}
+		done()
 
 		if err == nil || safeSession.InTransaction() {
 			return err
@@ -256,6 +286,38 @@ func (e *Executor) handleTransactions(
 	return nil, nil
 }
 
+// checkTransactionDuration rolls back the transaction of the session if it has been open for longer than the user is allowed to
+func (e *Executor) checkTransactionDuration(ctx context.Context, safeSession *SafeSession, user *userlimits.User) error {
+	if !safeSession.InTransaction() {
+		return nil
+	}
+	err := user.CheckTransaction(safeSession.GetSessionUUID())
+	if err != nil {
+		_ = e.txConn.Rollback(ctx, safeSession)
+	}
+	return err
+}
+
+// trackTransaction records whether the session of the user is in a transaction. The transactions open
+// for longer than the user is allowed to are rolled back in the background, even if the session is idle,
+// with the shard sessions it has at the end of its last query.
+func (e *Executor) trackTransaction(ctx context.Context, user *userlimits.User, safeSession *SafeSession) {
+	if !user.LimitsTransactions() {
+		return
+	}
+	var rollback func()
+	if safeSession.InTransaction() {
+		session := NewSafeSession(safeSession.Session.CloneVT())
+		rollbackCtx := callerid.NewContext(context.Background(), callerid.EffectiveCallerIDFromContext(ctx), callerid.ImmediateCallerIDFromContext(ctx))
+		rollback = func() {
+			if err := e.txConn.Rollback(rollbackCtx, session); err != nil {
+				log.Warningf("Failed to roll back the transaction of session %s, open for too long: %v", session.GetSessionUUID(), err)
+			}
+		}
+	}
+	user.TrackTransaction(safeSession.GetSessionUUID(), safeSession.InTransaction(), rollback)
+}
+
 func (e *Executor) startTxIfNecessary(ctx context.Context, safeSession *SafeSession) error {
 	if !safeSession.Autocommit && !safeSession.InTransaction() {
 		if err := e.txConn.Begin(ctx, safeSession, nil); err != nil {
@@ -318,7 +380,10 @@ func (e *Executor) executePlan(
 ) (*sqltypes.Result, error) {
 
 	// 4: Execute!
-	qr, err := vcursor.ExecutePrimitive(ctx, plan.Instructions, bindVars, true)
+	qr, err := e.executeWithResultCache(ctx, safeSession, plan, vcursor, bindVars)
+	if err == nil {
+		err = vcursor.userLimits.CheckRows(len(qr.Rows))
+	}
 
 	// 5: Log and add statistics
 	e.setLogStats(logStats, plan, vcursor, execStart, err, qr)
@@ -32,10 +32,6 @@ func gen4DeleteStmtPlanner(
 	reservedVars *sqlparser.ReservedVars,
 	vschema plancontext.VSchema,
 ) (*planResult, error) {
-	if deleteStmt.With != nil {
-		return nil, vterrors.VT12001("WITH expression in DELETE statement")
-	}
-
 	var err error
 	if len(deleteStmt.TableExprs) == 1 && len(deleteStmt.Targets) == 1 {
 		deleteStmt, err = rewriteSingleTbl(deleteStmt)
@@ -73,6 +69,10 @@ func gen4DeleteStmtPlanner(
 		return nil, ctx.SemTable.NotUnshardedErr
 	}
 
+	if deleteStmt.With != nil && deleteStmt.With.Recursive {
+		return nil, vterrors.VT12001("recursive common table expression in DELETE statement")
+	}
+
 	op, err := operators.PlanQuery(ctx, deleteStmt)
 	if err != nil {
 		return nil, err
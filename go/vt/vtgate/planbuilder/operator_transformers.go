@@ -63,6 +63,8 @@ func transformToPrimitive(ctx *plancontext.PlanningContext, op operators.Operato
 		return transformAggregator(ctx, op)
 	case *operators.Distinct:
 		return transformDistinct(ctx, op)
+	case *operators.Window:
+		return transformWindow(ctx, op)
 	case *operators.FkCascade:
 		return transformFkCascade(ctx, op)
 	case *operators.FkVerify:
@@ -79,6 +81,8 @@ func transformToPrimitive(ctx *plancontext.PlanningContext, op operators.Operato
 		return transformDMLWithInput(ctx, op)
 	case *operators.RecurseCTE:
 		return transformRecurseCTE(ctx, op)
+	case *operators.JSONTable:
+		return transformJSONTable(ctx, op)
 	}
 
 	return nil, vterrors.VT13001(fmt.Sprintf("unknown type encountered: %T (transformToPrimitive)", op))
@@ -186,6 +190,14 @@ func transformInsertionSelection(ctx *plancontext.PlanningContext, op *operators
 	}
 
 	eins.Input = selectionPlan
+
+	if op.Delete != nil {
+		eins.Delete, err = transformToPrimitive(ctx, op.Delete)
+		if err != nil {
+			return nil, err
+		}
+		eins.PKOffsets = op.PKOffsets
+	}
 	return eins, nil
 }
 
@@ -240,6 +252,20 @@ func transformSubQuery(ctx *plancontext.PlanningContext, op *operators.SubQuery)
 		return nil, err
 	}
 
+	if op.Apply {
+		return &engine.CorrelatedSubquery{
+			Opcode:         op.FilterType,
+			SubqueryResult: op.SubqueryValueName,
+			HasValues:      op.HasValuesName,
+			Vars:           op.Vars,
+			Filter:         op.ApplyFilterWithOffsets,
+			ASTFilter:      op.ApplyFilter,
+			Cols:           op.ColumnOffsets,
+			Outer:          outer,
+			Subquery:       inner,
+		}, nil
+	}
+
 	cols, err := op.GetJoinColumns(ctx, op.Outer)
 	if err != nil {
 		return nil, err
@@ -294,9 +320,6 @@ func transformFkVerify(ctx *plancontext.PlanningContext, fkv *operators.FkVerify
 }
 
 func transformAggregator(ctx *plancontext.PlanningContext, op *operators.Aggregator) (engine.Primitive, error) {
-	if op.WithRollup {
-		return nil, vterrors.VT12001("GROUP BY WITH ROLLUP not supported for sharded queries")
-	}
 	src, err := transformToPrimitive(ctx, op.Source)
 	if err != nil {
 		return nil, err
@@ -314,6 +337,10 @@ func transformAggregator(ctx *plancontext.PlanningContext, op *operators.Aggrega
 			return nil, vterrors.VT12001(message)
 		}
 
+		if op.WithRollup && aggr.OpCode.IsDistinct() {
+			return nil, vterrors.VT12001(fmt.Sprintf("DISTINCT aggregation with GROUP BY WITH ROLLUP for sharded queries: '%s'", sqlparser.String(aggr.Original)))
+		}
+
 		aggrParam := engine.NewAggregateParam(aggr.OpCode, aggr.ColOffset, aggr.Alias, ctx.VSchema.Environment().CollationEnv())
 		aggrParam.Func = aggr.Func
 		if gcFunc, isGc := aggrParam.Func.(*sqlparser.GroupConcatExpr); isGc && gcFunc.Separator == "" {
@@ -323,6 +350,12 @@ func transformAggregator(ctx *plancontext.PlanningContext, op *operators.Aggrega
 		aggrParam.OrigOpcode = aggr.OriginalOpCode
 		aggrParam.WCol = aggr.WSOffset
 		aggrParam.Type = aggr.GetTypeCollation(ctx)
+		if aggr.HashDistinct {
+			aggrParam.DistinctCols = distinctCheckCols(ctx, aggr)
+		}
+		if aggr.OpCode == opcode.AggregateGroupConcat {
+			setGroupConcatParams(ctx, aggr, aggrParam)
+		}
 		aggregates = append(aggregates, aggrParam)
 	}
 
@@ -345,12 +378,55 @@ func transformAggregator(ctx *plancontext.PlanningContext, op *operators.Aggrega
 		}, nil
 	}
 
-	return &engine.OrderedAggregate{
+	oa := engine.OrderedAggregate{
 		Aggregates:          aggregates,
 		GroupByKeys:         groupByKeys,
 		TruncateColumnCount: op.ResultColumns,
 		Input:               src,
-	}, nil
+	}
+	if op.WithRollup {
+		return &engine.RollupAggregate{OrderedAggregate: oa}, nil
+	}
+	return &oa, nil
+}
+
+// distinctCheckCols returns the columns used to hash the arguments of a distinct aggregation
+func distinctCheckCols(ctx *plancontext.PlanningContext, aggr operators.Aggr) []engine.CheckCol {
+	collationEnv := ctx.VSchema.Environment().CollationEnv()
+	checkCol := func(col, wsCol int, typ evalengine.Type) engine.CheckCol {
+		cc := engine.CheckCol{Col: col, Type: typ, CollationEnv: collationEnv}
+		if wsCol >= 0 {
+			cc.WsCol = &wsCol
+		}
+		return cc
+	}
+
+	typ, _ := ctx.TypeForExpr(aggr.Func.GetArg())
+	cols := []engine.CheckCol{checkCol(aggr.ColOffset, aggr.WSOffset, typ)}
+	for _, arg := range aggr.ExtraArgs {
+		argType, _ := ctx.TypeForExpr(arg.Expr)
+		cols = append(cols, checkCol(arg.ColOffset, arg.WSOffset, argType))
+	}
+	return cols
+}
+
+// setGroupConcatParams sets the extra columns and the ordering of a GROUP_CONCAT evaluated at the vtgate level
+func setGroupConcatParams(ctx *plancontext.PlanningContext, aggr operators.Aggr, aggrParam *engine.AggregateParams) {
+	for _, arg := range aggr.ExtraArgs {
+		aggrParam.ConcatCols = append(aggrParam.ConcatCols, arg.ColOffset)
+	}
+
+	gc := aggr.Func.(*sqlparser.GroupConcatExpr)
+	for idx, arg := range aggr.OrderByArgs {
+		typ, _ := ctx.TypeForExpr(arg.Expr)
+		aggrParam.OrderBy = append(aggrParam.OrderBy, evalengine.OrderByParams{
+			Col:             arg.ColOffset,
+			WeightStringCol: arg.WSOffset,
+			Desc:            gc.OrderBy[idx].Direction == sqlparser.DescOrder,
+			Type:            typ,
+			CollationEnv:    ctx.VSchema.Environment().CollationEnv(),
+		})
+	}
 }
 
 func transformDistinct(ctx *plancontext.PlanningContext, op *operators.Distinct) (engine.Primitive, error) {
@@ -366,6 +442,199 @@ func transformDistinct(ctx *plancontext.PlanningContext, op *operators.Distinct)
 	}, nil
 }
 
+func transformWindow(ctx *plancontext.PlanningContext, op *operators.Window) (engine.Primitive, error) {
+	src, err := transformToPrimitive(ctx, op.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	prim := &engine.Window{
+		Cols:  op.ColumnOffsets,
+		Input: src,
+	}
+
+	for idx, expr := range op.PartitionBy {
+		typ, _ := ctx.TypeForExpr(expr)
+		prim.PartitionBy = append(prim.PartitionBy, &engine.GroupByParams{
+			KeyCol:          op.PartitionOffsets[idx],
+			WeightStringCol: op.PartitionWSOffsets[idx],
+			Expr:            expr,
+			Type:            typ,
+			CollationEnv:    ctx.VSchema.Environment().CollationEnv(),
+		})
+	}
+
+	for idx, order := range op.OrderBy {
+		typ, _ := ctx.TypeForExpr(order.SimplifiedExpr)
+		prim.OrderBy = append(prim.OrderBy, evalengine.OrderByParams{
+			Col:             op.OrderOffsets[idx],
+			WeightStringCol: op.OrderWSOffsets[idx],
+			Desc:            order.Inner.Direction == sqlparser.DescOrder,
+			Type:            typ,
+			CollationEnv:    ctx.VSchema.Environment().CollationEnv(),
+		})
+	}
+
+	for _, fn := range op.Functions {
+		if fn.ColOffset < 0 {
+			// this function is not used by any operator above us
+			continue
+		}
+		wf, err := createWindowFunc(ctx, fn)
+		if err != nil {
+			return nil, err
+		}
+		wf.Alias = op.Columns[fn.ColOffset].ColumnName()
+		prim.Functions = append(prim.Functions, wf)
+	}
+
+	return prim, nil
+}
+
+func createWindowFunc(ctx *plancontext.PlanningContext, fn *operators.WindowFunc) (*engine.WindowFunc, error) {
+	wf := &engine.WindowFunc{
+		Col:          fn.ColOffset,
+		ArgCol:       fn.ArgOffset,
+		DefaultCol:   fn.DefaultOffset,
+		CollationEnv: ctx.VSchema.Environment().CollationEnv(),
+	}
+	unsupported := func() (*engine.WindowFunc, error) {
+		return nil, vterrors.VT12001(fmt.Sprintf("window function '%s' on sharded keyspace", sqlparser.String(fn.Expr)))
+	}
+
+	var err error
+	switch expr := fn.Expr.(type) {
+	case *sqlparser.ArgumentLessWindowExpr:
+		switch expr.Type {
+		case sqlparser.RowNumberExprType:
+			wf.Opcode = opcode.WindowRowNumber
+		case sqlparser.RankExprType:
+			wf.Opcode = opcode.WindowRank
+		case sqlparser.DenseRankExprType:
+			wf.Opcode = opcode.WindowDenseRank
+		case sqlparser.PercentRankExprType:
+			wf.Opcode = opcode.WindowPercentRank
+		case sqlparser.CumeDistExprType:
+			wf.Opcode = opcode.WindowCumeDist
+		default:
+			return unsupported()
+		}
+	case *sqlparser.NtileExpr:
+		wf.Opcode = opcode.WindowNtile
+		wf.N, err = windowFuncConstant(expr.N, 0)
+	case *sqlparser.LagLeadExpr:
+		if expr.NullTreatmentClause != nil && expr.NullTreatmentClause.Type == sqlparser.IgnoreNullsType {
+			return unsupported()
+		}
+		wf.Opcode = opcode.WindowLag
+		if expr.Type == sqlparser.LeadExprType {
+			wf.Opcode = opcode.WindowLead
+		}
+		wf.N, err = windowFuncConstant(expr.N, 1)
+	case *sqlparser.FirstOrLastValueExpr:
+		if expr.NullTreatmentClause != nil && expr.NullTreatmentClause.Type == sqlparser.IgnoreNullsType {
+			return unsupported()
+		}
+		wf.Opcode = opcode.WindowFirstValue
+		if expr.Type == sqlparser.LastValueExprType {
+			wf.Opcode = opcode.WindowLastValue
+		}
+	case *sqlparser.NTHValueExpr:
+		if expr.NullTreatmentClause != nil && expr.NullTreatmentClause.Type == sqlparser.IgnoreNullsType ||
+			expr.FromFirstLastClause != nil && expr.FromFirstLastClause.Type == sqlparser.FromLastType {
+			return unsupported()
+		}
+		wf.Opcode = opcode.WindowNthValue
+		wf.N, err = windowFuncConstant(expr.N, 0)
+	case *sqlparser.CountStar:
+		wf.Opcode = opcode.WindowCountStar
+	case *sqlparser.Count:
+		if expr.Distinct || len(expr.Args) != 1 {
+			return unsupported()
+		}
+		wf.Opcode = opcode.WindowCount
+	case *sqlparser.Sum:
+		if expr.Distinct {
+			return unsupported()
+		}
+		wf.Opcode = opcode.WindowSum
+	case *sqlparser.Min:
+		wf.Opcode = opcode.WindowMin
+	case *sqlparser.Max:
+		wf.Opcode = opcode.WindowMax
+	default:
+		return unsupported()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if wf.ArgCol >= 0 {
+		arg, _ := operators.WindowFuncArguments(fn.Expr)
+		wf.Type, _ = ctx.TypeForExpr(arg)
+	}
+
+	over := sqlparser.GetOverClause(fn.Expr)
+	if over == nil || over.WindowSpec == nil || over.WindowSpec.FrameClause == nil || !wf.Opcode.UsesFrame() {
+		return wf, nil
+	}
+	wf.Frame, err = createWindowFrame(over.WindowSpec.FrameClause)
+	if err != nil {
+		return nil, err
+	}
+	return wf, nil
+}
+
+func createWindowFrame(frame *sqlparser.FrameClause) (*engine.WindowFrame, error) {
+	wf := &engine.WindowFrame{Rows: frame.Unit == sqlparser.FrameRowsType}
+
+	bound := func(point *sqlparser.FramePoint) (engine.FrameBound, error) {
+		if point == nil {
+			// without BETWEEN, the frame ends at the current row
+			return engine.FrameBound{}, nil
+		}
+		switch point.Type {
+		case sqlparser.CurrentRowType:
+			return engine.FrameBound{}, nil
+		case sqlparser.UnboundedPrecedingType, sqlparser.UnboundedFollowingType:
+			return engine.FrameBound{Unbounded: true}, nil
+		}
+		if !wf.Rows {
+			return engine.FrameBound{}, vterrors.VT12001("RANGE frame with offsets on sharded keyspace")
+		}
+		offset, err := windowFuncConstant(point.Expr, 0)
+		if err != nil {
+			return engine.FrameBound{}, err
+		}
+		if point.Type == sqlparser.ExprPrecedingType {
+			offset = -offset
+		}
+		return engine.FrameBound{Offset: offset}, nil
+	}
+
+	var err error
+	if wf.Start, err = bound(frame.Start); err != nil {
+		return nil, err
+	}
+	if wf.End, err = bound(frame.End); err != nil {
+		return nil, err
+	}
+	return wf, nil
+}
+
+// windowFuncConstant returns the value of an integer literal used by a window function,
+// or the default value given when the expression is missing
+func windowFuncConstant(expr sqlparser.Expr, def int64) (int64, error) {
+	if expr == nil {
+		return def, nil
+	}
+	lit, ok := expr.(*sqlparser.Literal)
+	if !ok || lit.Type != sqlparser.IntVal {
+		return 0, vterrors.VT12001(fmt.Sprintf("non-literal offset '%s' in window function on sharded keyspace", sqlparser.String(expr)))
+	}
+	return strconv.ParseInt(lit.Val, 10, 64)
+}
+
 func transformOrdering(ctx *plancontext.PlanningContext, op *operators.Ordering) (engine.Primitive, error) {
 	plan, err := transformToPrimitive(ctx, op.Source)
 	if err != nil {
@@ -499,11 +768,12 @@ func transformApplyJoinPlan(ctx *plancontext.PlanningContext, n *operators.Apply
 	}
 
 	return &engine.Join{
-		Opcode: opCode,
-		Left:   lhs,
-		Right:  rhs,
-		Cols:   n.Columns,
-		Vars:   n.Vars,
+		Opcode:   opCode,
+		Left:     lhs,
+		Right:    rhs,
+		Cols:     n.Columns,
+		Vars:     n.Vars,
+		Estimate: operators.EstimateCost(ctx, n),
 	}, nil
 }
 
@@ -598,6 +868,7 @@ func buildRoutePrimitive(ctx *plancontext.PlanningContext, op *operators.Route,
 	if err != nil {
 		return nil, err
 	}
+	eroute.Estimate = operators.EstimateCost(ctx, op)
 
 	for _, order := range op.Ordering {
 		typ, _ := ctx.TypeForExpr(order.AST)
@@ -682,16 +953,20 @@ func autoIncGenerate(gen *operators.Generate) *engine.Generate {
 
 func generateInsertShardedQuery(ins *sqlparser.Insert) (prefix string, mids sqlparser.Values, suffix sqlparser.OnDup) {
 	mids, isValues := ins.Rows.(sqlparser.Values)
-	prefixFormat := "insert %v%sinto %v%v "
+	prefixFormat := "%s %v%sinto %v%v "
 	if isValues {
 		// the mid values are filled differently
 		// with select uses sqlparser.String for sqlparser.Values
 		// with rows uses string.
 		prefixFormat += "values "
 	}
+	action := sqlparser.InsertStr
+	if ins.Action == sqlparser.ReplaceAct {
+		action = sqlparser.ReplaceStr
+	}
 	prefixBuf := sqlparser.NewTrackedBuffer(dmlFormatter)
 	prefixBuf.Myprintf(prefixFormat,
-		ins.Comments, ins.Ignore.ToString(),
+		action, ins.Comments, ins.Ignore.ToString(),
 		ins.Table, ins.Columns, ins.RowAlias)
 	prefix = prefixBuf.String()
 
@@ -906,24 +1181,38 @@ func transformHashJoin(ctx *plancontext.PlanningContext, op *operators.HashJoin)
 		return nil, err
 	}
 
-	if len(op.LHSKeys) != 1 {
-		return nil, vterrors.VT12001("hash joins must have exactly one join predicate")
-	}
-
 	joinOp := engine.InnerJoin
 	if op.LeftJoin {
 		joinOp = engine.LeftJoin
 	}
 
 	var missingTypes []string
+	keys := make([]engine.HashJoinKey, 0, len(op.JoinComparisons))
+	for i, cmp := range op.JoinComparisons {
+		ltyp, found := ctx.TypeForExpr(cmp.LHS)
+		if !found {
+			missingTypes = append(missingTypes, sqlparser.String(cmp.LHS))
+		}
+		rtyp, found := ctx.TypeForExpr(cmp.RHS)
+		if !found {
+			missingTypes = append(missingTypes, sqlparser.String(cmp.RHS))
+		}
+		if len(missingTypes) > 0 {
+			continue
+		}
 
-	ltyp, found := ctx.TypeForExpr(op.JoinComparisons[0].LHS)
-	if !found {
-		missingTypes = append(missingTypes, sqlparser.String(op.JoinComparisons[0].LHS))
-	}
-	rtyp, found := ctx.TypeForExpr(op.JoinComparisons[0].RHS)
-	if !found {
-		missingTypes = append(missingTypes, sqlparser.String(op.JoinComparisons[0].RHS))
+		comparisonType, err := evalengine.CoerceTypes(ltyp, rtyp, ctx.VSchema.Environment().CollationEnv())
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, engine.HashJoinKey{
+			LHS:       op.LHSKeys[i],
+			RHS:       op.RHSKeys[i],
+			Collation: comparisonType.Collation(),
+			Type:      comparisonType.Type(),
+			Values:    comparisonType.Values(),
+			NullSafe:  cmp.NullSafe,
+		})
 	}
 
 	if len(missingTypes) > 0 {
@@ -931,23 +1220,29 @@ func transformHashJoin(ctx *plancontext.PlanningContext, op *operators.HashJoin)
 			fmt.Sprintf("missing type information for [%s]", strings.Join(missingTypes, ", ")))
 	}
 
-	comparisonType, err := evalengine.CoerceTypes(ltyp, rtyp, ctx.VSchema.Environment().CollationEnv())
-	if err != nil {
-		return nil, err
+	var filter evalengine.Expr
+	if op.FilterExpr != nil {
+		filter, err = evalengine.Translate(op.FilterExpr, &evalengine.Config{
+			ResolveType: ctx.TypeForExpr,
+			Collation:   ctx.SemTable.Collation,
+			Environment: ctx.VSchema.Environment(),
+		})
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &engine.HashJoin{
-		Left:           lhs,
-		Right:          rhs,
-		Opcode:         joinOp,
-		Cols:           op.ColumnOffsets,
-		LHSKey:         op.LHSKeys[0],
-		RHSKey:         op.RHSKeys[0],
-		ASTPred:        op.JoinPredicate(),
-		Collation:      comparisonType.Collation(),
-		ComparisonType: comparisonType.Type(),
-		CollationEnv:   ctx.VSchema.Environment().CollationEnv(),
-		Values:         comparisonType.Values(),
+		Left:         lhs,
+		Right:        rhs,
+		Opcode:       joinOp,
+		Cols:         op.ColumnOffsets,
+		Keys:         keys,
+		ASTPred:      op.JoinPredicate(),
+		Filter:       filter,
+		FilterCols:   op.FilterOffsets,
+		CollationEnv: ctx.VSchema.Environment().CollationEnv(),
+		Estimate:     operators.EstimateCost(ctx, op),
 	}, nil
 }
 
@@ -999,6 +1294,124 @@ func transformRecurseCTE(ctx *plancontext.PlanningContext, op *operators.Recurse
 	}, nil
 }
 
+func transformJSONTable(ctx *plancontext.PlanningContext, op *operators.JSONTable) (engine.Primitive, error) {
+	doc, err := evalengine.Translate(op.Expr.Expr, &evalengine.Config{
+		Collation:   ctx.SemTable.Collation,
+		ResolveType: ctx.TypeForExpr,
+		Environment: ctx.VSchema.Environment(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	path, err := jsonTableLiteral(op.Expr.Filter)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := transformJSONTableColumns(ctx, op.Expr.Columns)
+	if err != nil {
+		return nil, err
+	}
+	return &engine.JSONTable{
+		Doc:     doc,
+		Path:    path.ToString(),
+		Columns: columns,
+		Cols:    op.Offsets,
+	}, nil
+}
+
+func transformJSONTableColumns(ctx *plancontext.PlanningContext, defs []*sqlparser.JtColumnDefinition) ([]*engine.JSONTableColumn, error) {
+	var columns []*engine.JSONTableColumn
+	for _, def := range defs {
+		switch {
+		case def.JtOrdinal != nil:
+			columns = append(columns, &engine.JSONTableColumn{
+				Name:       def.JtOrdinal.Name.String(),
+				Type:       evalengine.NewType(sqltypes.Uint32, collations.CollationBinaryID),
+				Ordinality: true,
+			})
+		case def.JtNestedPath != nil:
+			path, err := jsonTableLiteral(def.JtNestedPath.Path)
+			if err != nil {
+				return nil, err
+			}
+			nested, err := transformJSONTableColumns(ctx, def.JtNestedPath.Columns)
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, &engine.JSONTableColumn{
+				Path:   path.ToString(),
+				Nested: nested,
+			})
+		case def.JtPath != nil:
+			col, err := transformJSONTablePathColumn(ctx, def.JtPath)
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, col)
+		}
+	}
+	return columns, nil
+}
+
+func transformJSONTablePathColumn(ctx *plancontext.PlanningContext, def *sqlparser.JtPathColDef) (*engine.JSONTableColumn, error) {
+	path, err := jsonTableLiteral(def.Path)
+	if err != nil {
+		return nil, err
+	}
+	onEmpty, err := transformJSONTableOnResponse(def.EmptyOnResponse)
+	if err != nil {
+		return nil, err
+	}
+	onError, err := transformJSONTableOnResponse(def.ErrorOnResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	var size, scale int32
+	if def.Type.Length != nil {
+		size = int32(*def.Type.Length)
+	}
+	if def.Type.Scale != nil {
+		scale = int32(*def.Type.Scale)
+	}
+	typ := def.Type.SQLType()
+	coll := collations.CollationForType(typ, ctx.VSchema.Environment().CollationEnv().DefaultConnectionCharset())
+	return &engine.JSONTableColumn{
+		Name:    def.Name.String(),
+		Type:    evalengine.NewTypeEx(typ, coll, true, size, scale, nil),
+		Exists:  def.JtColExists,
+		Path:    path.ToString(),
+		OnEmpty: onEmpty,
+		OnError: onError,
+	}, nil
+}
+
+func transformJSONTableOnResponse(response *sqlparser.JtOnResponse) (*engine.JSONTableOnResponse, error) {
+	if response == nil {
+		return nil, nil
+	}
+	switch response.ResponseType {
+	case sqlparser.ErrorJSONType:
+		return &engine.JSONTableOnResponse{Error: true}, nil
+	case sqlparser.DefaultJSONType:
+		value, err := jsonTableLiteral(response.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return &engine.JSONTableOnResponse{Default: value}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func jsonTableLiteral(expr sqlparser.Expr) (sqltypes.Value, error) {
+	lit, ok := expr.(*sqlparser.Literal)
+	if !ok {
+		return sqltypes.NULL, vterrors.VT12001(fmt.Sprintf("non-literal value in JSON_TABLE evaluated on vtgate: %s", sqlparser.String(expr)))
+	}
+	return sqlparser.LiteralToValue(lit)
+}
+
 func generateQuery(statement sqlparser.Statement) string {
 	buf := sqlparser.NewTrackedBuffer(dmlFormatter)
 	statement.Format(buf)
@@ -283,16 +283,22 @@ func (qb *queryBuilder) joinWith(other *queryBuilder, onCondition sqlparser.Expr
 		sel.SelectExprs = append(sel.SelectExprs, otherSel.SelectExprs...)
 	}
 
-	qb.mergeWhereClauses(stmt, otherStmt)
-
 	var newFromClause []sqlparser.TableExpr
 	switch joinType {
 	case sqlparser.NormalJoinType:
+		qb.mergeWhereClauses(stmt, otherStmt)
 		newFromClause = append(stmt.GetFrom(), otherStmt.GetFrom()...)
 		for _, pred := range sqlparser.SplitAndExpression(nil, onCondition) {
 			qb.addPredicate(pred)
 		}
 	default:
+		if joinType.IsInner() {
+			qb.mergeWhereClauses(stmt, otherStmt)
+		} else if otherPredicate := otherStmt.GetWherePredicate(); otherPredicate != nil {
+			// the filters on the outer side have to be applied before null-extending the rows,
+			// so they are added to the join condition instead of the WHERE clause
+			onCondition = qb.ctx.SemTable.AndExpressions(onCondition, otherPredicate)
+		}
 		newFromClause = []sqlparser.TableExpr{buildJoin(stmt, otherStmt, onCondition, joinType)}
 	}
 
@@ -375,10 +381,19 @@ func (ts *tableSorter) Less(i, j int) bool {
 	if !ok {
 		return i < j
 	}
+	if isLateralDerivedTable(left) || isLateralDerivedTable(right) {
+		// lateral derived tables use the tables before them, so we keep them where they are
+		return i < j
+	}
 
 	return ts.tbl.TableSetFor(left).TableOffset() < ts.tbl.TableSetFor(right).TableOffset()
 }
 
+func isLateralDerivedTable(tbl *sqlparser.AliasedTableExpr) bool {
+	dt, ok := tbl.Expr.(*sqlparser.DerivedTable)
+	return ok && dt.Lateral
+}
+
 // Swap implements the Sort interface
 func (ts *tableSorter) Swap(i, j int) {
 	ts.sel.From[i], ts.sel.From[j] = ts.sel.From[j], ts.sel.From[i]
@@ -459,6 +474,8 @@ func buildQuery(op Operator, qb *queryBuilder) {
 		buildDML(op, qb)
 	case *RecurseCTE:
 		buildRecursiveCTE(op, qb)
+	case *JSONTable:
+		buildJSONTable(op, qb)
 	default:
 		panic(vterrors.VT13001(fmt.Sprintf("unknown operator to convert to SQL: %T", op)))
 	}
@@ -568,6 +585,15 @@ func buildTable(op *Table, qb *queryBuilder) {
 	}
 }
 
+func buildJSONTable(op *JSONTable, qb *queryBuilder) {
+	if qb.stmt == nil {
+		qb.stmt = &sqlparser.Select{}
+	}
+	tableExpr := sqlparser.Clone(op.Expr)
+	qb.stmt.(FromStatement).SetFrom(append(qb.stmt.(FromStatement).GetFrom(), tableExpr))
+	qb.tableNames = append(qb.tableNames, tableExpr.Alias.String())
+}
+
 func buildProjection(op *Projection, qb *queryBuilder) {
 	buildQuery(op.Source, qb)
 
@@ -613,6 +639,10 @@ func buildApplyJoin(op *ApplyJoin, qb *queryBuilder) {
 
 	qbR := &queryBuilder{ctx: qb.ctx}
 	buildQuery(op.RHS, qbR)
+	if len(op.ExtraLHSVars) > 0 && qbR.stmt != nil {
+		// the RHS is using columns from the LHS, which are available in the same query now
+		restoreLateralVars(qbR.stmt, op.ExtraLHSVars)
+	}
 
 	switch {
 	// if we have a recursive cte, we might be missing a statement from one of the sides
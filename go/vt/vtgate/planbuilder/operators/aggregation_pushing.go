@@ -27,20 +27,14 @@ import (
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
 )
 
-func errDistinctAggrWithMultiExpr(f sqlparser.AggrFunc) {
-	if f == nil {
-		panic(vterrors.VT12001("distinct aggregation function with multiple expressions"))
-	}
-	panic(vterrors.VT12001(fmt.Sprintf("distinct aggregation function with multiple expressions '%s'", sqlparser.String(f))))
-}
-
 func tryPushAggregator(ctx *plancontext.PlanningContext, aggregator *Aggregator) (output Operator, applyResult *ApplyResult) {
 	if aggregator.Pushed {
 		return aggregator, NoRewrite
 	}
 
-	// this rewrite is always valid, and we should do it whenever possible
-	if route, ok := aggregator.Source.(*Route); ok && (route.IsSingleShard() || overlappingUniqueVindex(ctx, aggregator.Grouping)) {
+	// this rewrite is always valid, and we should do it whenever possible.
+	// the super-aggregate rows of WITH ROLLUP span all the groups, so for rollup it's only valid on a single shard
+	if route, ok := aggregator.Source.(*Route); ok && (route.IsSingleShard() || !aggregator.WithRollup && overlappingUniqueVindex(ctx, aggregator.Grouping)) {
 		return Swap(aggregator, route, "push down aggregation under route - remove original")
 	}
 
@@ -55,6 +49,17 @@ func tryPushAggregator(ctx *plancontext.PlanningContext, aggregator *Aggregator)
 		return splitAvgAggregations(ctx, aggregator)
 	}
 
+	// the values of a GROUP_CONCAT with ORDER BY can't be concatenated by the shards and then merged,
+	// so we have to evaluate the aggregation on all the rows at the vtgate level
+	if aggregator.hasOrderedGroupConcat() {
+		for i, aggr := range aggregator.Aggregations {
+			if aggr.Distinct {
+				aggregator.Aggregations[i].setHashDistinct()
+			}
+		}
+		return aggregator, NoRewrite
+	}
+
 	switch src := aggregator.Source.(type) {
 	case *Route:
 		// if we have a single sharded route, we can push it down
@@ -169,9 +174,9 @@ func pushAggregationThroughRoute(
 
 // pushAggregations splits aggregations between the original aggregator and the one we are pushing down
 func pushAggregations(ctx *plancontext.PlanningContext, aggregator *Aggregator, aggrBelowRoute *Aggregator) {
-	canPushDistinctAggr, distinctExprs := checkIfWeCanPush(ctx, aggregator)
+	canPushDistinctAggr, distinctExpr := checkIfWeCanPush(ctx, aggregator)
 
-	distinctAggrGroupByAdded := false
+	var distinctGroupBy sqlparser.Exprs
 
 	for i, aggr := range aggregator.Aggregations {
 		if !aggr.Distinct || canPushDistinctAggr {
@@ -181,35 +186,47 @@ func pushAggregations(ctx *plancontext.PlanningContext, aggregator *Aggregator,
 			continue
 		}
 
-		if len(distinctExprs) != 1 {
-			errDistinctAggrWithMultiExpr(aggr.Func)
-		}
-
 		// We handle a distinct aggregation by turning it into a group by and
 		// doing the aggregating on the vtgate level instead
-		aeDistinctExpr := aeWrap(distinctExprs[0])
-		aggrBelowRoute.Columns[aggr.ColOffset] = aeDistinctExpr
+		args := aggr.Func.GetArgs()
+		aggrBelowRoute.Columns[aggr.ColOffset] = aeWrap(args[0])
 
-		// We handle a distinct aggregation by turning it into a group by and
-		// doing the aggregating on the vtgate level instead
-		// Adding to group by can be done only once even though there are multiple distinct aggregation with same expression.
-		if !distinctAggrGroupByAdded {
-			groupBy := NewGroupBy(distinctExprs[0])
-			groupBy.ColOffset = aggr.ColOffset
+		// Adding to group by can be done only once per expression, even though there are multiple distinct aggregation with same expression.
+		for argIdx, arg := range args {
+			if slices.ContainsFunc(distinctGroupBy, func(e sqlparser.Expr) bool { return ctx.SemTable.EqualsExpr(e, arg) }) {
+				continue
+			}
+			distinctGroupBy = append(distinctGroupBy, arg)
+			groupBy := NewGroupBy(arg)
+			if argIdx == 0 {
+				groupBy.ColOffset = aggr.ColOffset
+			}
 			aggrBelowRoute.Grouping = append(aggrBelowRoute.Grouping, groupBy)
-			distinctAggrGroupByAdded = true
 		}
-	}
 
-	if !canPushDistinctAggr {
-		aggregator.DistinctExpr = distinctExprs[0]
+		if distinctExpr == nil || aggr.OpCode == opcode.AggregateGroupConcat {
+			aggregator.Aggregations[i].setHashDistinct()
+		}
 	}
+
+	aggregator.DistinctExpr = distinctExpr
 }
 
-func checkIfWeCanPush(ctx *plancontext.PlanningContext, aggregator *Aggregator) (bool, sqlparser.Exprs) {
+func (a *Aggregator) hasOrderedGroupConcat() bool {
+	return slices.ContainsFunc(a.Aggregations, func(aggr Aggr) bool {
+		gc, ok := aggr.Func.(*sqlparser.GroupConcatExpr)
+		return ok && len(gc.OrderBy) > 0
+	})
+}
+
+// checkIfWeCanPush returns true if all the distinct aggregations can be pushed down to MySQL.
+// If they can't, it also returns the expression that the input has to be ordered by for the
+// distinct aggregations to be evaluated at the vtgate level. This is only possible when all of
+// them use the same single expression - otherwise nil is returned, and hashing has to be used.
+func checkIfWeCanPush(ctx *plancontext.PlanningContext, aggregator *Aggregator) (bool, sqlparser.Expr) {
 	canPush := true
-	var distinctExprs sqlparser.Exprs
-	var differentExpr *sqlparser.AliasedExpr
+	sameExpr := true
+	var distinctExpr sqlparser.Expr
 
 	for _, aggr := range aggregator.Aggregations {
 		if !aggr.Distinct {
@@ -217,32 +234,27 @@ func checkIfWeCanPush(ctx *plancontext.PlanningContext, aggregator *Aggregator)
 		}
 
 		args := aggr.Func.GetArgs()
-		hasUniqVindex := false
-		for _, arg := range args {
-			if exprHasUniqueVindex(ctx, arg) {
-				hasUniqVindex = true
-				break
-			}
-		}
+		hasUniqVindex := slices.ContainsFunc(args, func(arg sqlparser.Expr) bool {
+			return exprHasUniqueVindex(ctx, arg)
+		})
 		if !hasUniqVindex {
 			canPush = false
 		}
-		if len(distinctExprs) == 0 {
-			distinctExprs = args
-		}
-		for idx, expr := range distinctExprs {
-			if !ctx.SemTable.EqualsExpr(expr, args[idx]) {
-				differentExpr = aggr.Original
-				break
-			}
+		switch {
+		case len(args) != 1:
+			sameExpr = false
+		case distinctExpr == nil:
+			distinctExpr = args[0]
+		case !ctx.SemTable.EqualsExpr(distinctExpr, args[0]):
+			sameExpr = false
 		}
 	}
 
-	if !canPush && differentExpr != nil {
-		panic(vterrors.VT12001(fmt.Sprintf("only one DISTINCT aggregation is allowed in a SELECT: %s", sqlparser.String(differentExpr))))
+	if canPush || !sameExpr {
+		return canPush, nil
 	}
 
-	return canPush, distinctExprs
+	return false, distinctExpr
 }
 
 func pushAggregationThroughFilter(
@@ -251,7 +263,7 @@ func pushAggregationThroughFilter(
 	filter *Filter,
 ) (Operator, *ApplyResult) {
 
-	columnsNeeded := collectColNamesNeeded(ctx, filter)
+	columnsNeeded := collectColNamesNeeded(ctx, filter.Predicates)
 	pushedAggr := aggregator.SplitAggregatorBelowOperators(ctx, []Operator{filter.Source})
 withNextColumn:
 	for _, col := range columnsNeeded {
@@ -275,8 +287,8 @@ withNextColumn:
 	return aggregator, Rewrote("push aggregation under filter - keep original")
 }
 
-func collectColNamesNeeded(ctx *plancontext.PlanningContext, f *Filter) (columnsNeeded []*sqlparser.ColName) {
-	for _, p := range f.Predicates {
+func collectColNamesNeeded(ctx *plancontext.PlanningContext, predicates []sqlparser.Expr) (columnsNeeded []*sqlparser.ColName) {
+	for _, p := range predicates {
 		_ = sqlparser.Walk(func(node sqlparser.SQLNode) (kontinue bool, err error) {
 			col, ok := node.(*sqlparser.ColName)
 			if !ok {
@@ -422,6 +434,19 @@ func pushAggregationThroughHashJoin(ctx *plancontext.PlanningContext, rootAggr *
 		columns.addRight(cmp.RHS)
 	}
 
+	// The columns used by the rest of the join condition are also added as grouping expressions,
+	// so that the condition gives the same result for all the rows in a group
+	for _, col := range collectColNamesNeeded(ctx, join.Filters) {
+		switch deps := ctx.SemTable.RecursiveDeps(col); {
+		case deps.IsSolvedBy(lhs.tableID):
+			lhs.addGrouping(ctx, NewGroupBy(col))
+			columns.addLeft(col)
+		case deps.IsSolvedBy(rhs.tableID):
+			rhs.addGrouping(ctx, NewGroupBy(col))
+			columns.addRight(col)
+		}
+	}
+
 	// The grouping columns need to be pushed down as grouping columns on the respective sides
 	for _, groupBy := range rootAggr.Grouping {
 		deps := ctx.SemTable.RecursiveDeps(groupBy.Inner)
@@ -542,15 +567,18 @@ func splitAggrColumnsToLeftAndRight(
 		outerJoin:   leftJoin,
 	}
 
-	canPushDistinctAggr, distinctExprs := checkIfWeCanPush(ctx, aggregator)
+	canPushDistinctAggr, distinctExpr := checkIfWeCanPush(ctx, aggregator)
 
 	// Distinct aggregation cannot be pushed down in the join.
-	// We keep node of the distinct aggregation expression to be used later for ordering.
+	// We keep node of the distinct aggregation expression to be used later for ordering,
+	// or use hashing if the distinct aggregations are not all on the same expression.
 	if !canPushDistinctAggr {
-		if len(distinctExprs) != 1 {
-			errDistinctAggrWithMultiExpr(nil)
+		for i, aggr := range aggregator.Aggregations {
+			if aggr.Distinct && (distinctExpr == nil || aggr.OpCode == opcode.AggregateGroupConcat) {
+				aggregator.Aggregations[i].setHashDistinct()
+			}
 		}
-		aggregator.DistinctExpr = distinctExprs[0]
+		aggregator.DistinctExpr = distinctExpr
 		return nil, errAbortAggrPushing
 	}
 
@@ -617,13 +645,9 @@ func splitAvgAggregations(ctx *plancontext.PlanningContext, aggr *Aggregator) (O
 			continue
 		}
 
-		if avg.Distinct {
-			panic(vterrors.VT12001("AVG(distinct <>)"))
-		}
-
 		// We have an AVG that we need to split
-		sumExpr := &sqlparser.Sum{Arg: avg.Arg}
-		countExpr := &sqlparser.Count{Args: []sqlparser.Expr{avg.Arg}}
+		sumExpr := &sqlparser.Sum{Arg: avg.Arg, Distinct: avg.Distinct}
+		countExpr := &sqlparser.Count{Args: []sqlparser.Expr{avg.Arg}, Distinct: avg.Distinct}
 		calcExpr := &sqlparser.BinaryExpr{
 			Operator: sqlparser.DivOp,
 			Left:     sumExpr,
@@ -639,9 +663,13 @@ func splitAvgAggregations(ctx *plancontext.PlanningContext, aggr *Aggregator) (O
 			if offset == aggregation.ColOffset {
 				// We have found the AVG column. We'll change it to SUM, and then we add a COUNT as well
 				aggr.Aggregations[aggrOffset].OpCode = opcode.AggregateSum
+				if avg.Distinct {
+					aggr.Aggregations[aggrOffset].OpCode = opcode.AggregateSumDistinct
+				}
 
 				countExprAlias := aeWrap(countExpr)
-				countAggr := NewAggr(opcode.AggregateCount, countExpr, countExprAlias, sqlparser.String(countExpr))
+				countAggr := createAggrFromAggrFunc(countExpr, countExprAlias)
+				countAggr.Alias = sqlparser.String(countExpr)
 				countAggr.ColOffset = len(aggr.Columns) + len(columns)
 				aggregations = append(aggregations, countAggr)
 				columns = append(columns, countExprAlias)
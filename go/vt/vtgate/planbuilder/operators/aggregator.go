@@ -405,6 +405,21 @@ func (a *Aggregator) planOffsets(ctx *plancontext.PlanningContext) Operator {
 		}
 		a.Aggregations[idx].WSOffset = offset
 	}
+
+	// hash based distinct aggregations need the values of all their arguments
+	for idx, aggr := range a.Aggregations {
+		if len(aggr.ExtraArgs) == 0 {
+			continue
+		}
+		args := slices.Clone(aggr.ExtraArgs)
+		for argIdx, arg := range args {
+			args[argIdx].ColOffset = a.internalAddColumn(ctx, aeWrap(arg.Expr), false)
+			if ctx.NeedsWeightString(arg.Expr) {
+				args[argIdx].WSOffset = a.internalAddColumn(ctx, aeWrap(weightStringFor(arg.Expr)), true)
+			}
+		}
+		a.Aggregations[idx].ExtraArgs = args
+	}
 	return nil
 }
 
@@ -430,14 +445,13 @@ func (aggr Aggr) getPushColumn() sqlparser.Expr {
 	case opcode.AggregateCountStar:
 		return sqlparser.NewIntLiteral("1")
 	case opcode.AggregateGroupConcat:
-		if len(aggr.Func.GetArgs()) > 1 {
-			panic(vterrors.VT12001("group_concat with more than 1 column"))
-		}
+		// the remaining arguments are kept in ExtraArgs
 		return aggr.Func.GetArg()
 	default:
-		if len(aggr.Func.GetArgs()) > 1 {
+		if len(aggr.Func.GetArgs()) > 1 && !aggr.HashDistinct {
 			panic(vterrors.VT03001(sqlparser.String(aggr.Func)))
 		}
+		// the remaining arguments of hash based distinct aggregations are kept in ExtraArgs
 		return aggr.Func.GetArg()
 	}
 }
@@ -472,9 +486,32 @@ func (a *Aggregator) planOffsetsNotPushed(ctx *plancontext.PlanningContext) {
 		}
 	}
 
+	a.pushExtraArgColumns(ctx)
 	a.pushRemainingGroupingColumnsAndWeightStrings(ctx)
 }
 
+// pushExtraArgColumns pushes the arguments after the first one of aggregations with
+// multiple arguments, and the ORDER BY expressions of GROUP_CONCAT aggregations
+func (a *Aggregator) pushExtraArgColumns(ctx *plancontext.PlanningContext) {
+	for idx := range a.Aggregations {
+		aggr := &a.Aggregations[idx]
+		if aggr.OpCode == opcode.AggregateGroupConcat {
+			aggr.setExtraArgs()
+			aggr.setOrderByArgs()
+		}
+		aggr.ExtraArgs = a.pushArgColumns(ctx, aggr.ExtraArgs)
+		aggr.OrderByArgs = a.pushArgColumns(ctx, aggr.OrderByArgs)
+	}
+}
+
+func (a *Aggregator) pushArgColumns(ctx *plancontext.PlanningContext, args []AggrArg) []AggrArg {
+	args = slices.Clone(args)
+	for idx, arg := range args {
+		args[idx].ColOffset = a.internalAddColumn(ctx, aeWrap(arg.Expr), false)
+	}
+	return args
+}
+
 func (a *Aggregator) addIfAggregationColumn(ctx *plancontext.PlanningContext, colIdx int) int {
 	for _, aggr := range a.Aggregations {
 		if aggr.ColOffset != colIdx {
@@ -538,6 +575,21 @@ func (a *Aggregator) pushRemainingGroupingColumnsAndWeightStrings(ctx *planconte
 
 		a.Aggregations[idx].WSOffset = offset
 	}
+	for _, aggr := range a.Aggregations {
+		if aggr.HashDistinct {
+			a.addArgWeightStrings(ctx, aggr.ExtraArgs)
+		}
+		a.addArgWeightStrings(ctx, aggr.OrderByArgs)
+	}
+}
+
+func (a *Aggregator) addArgWeightStrings(ctx *plancontext.PlanningContext, args []AggrArg) {
+	for idx, arg := range args {
+		if arg.WSOffset != -1 || !ctx.NeedsWeightString(arg.Expr) {
+			continue
+		}
+		args[idx].WSOffset = a.internalAddWSColumn(ctx, arg.ColOffset, aeWrap(weightStringFor(arg.Expr)))
+	}
 }
 
 func (a *Aggregator) internalAddWSColumn(ctx *plancontext.PlanningContext, inOffset int, aliasedExpr *sqlparser.AliasedExpr) int {
@@ -587,6 +639,8 @@ func (a *Aggregator) SplitAggregatorBelowOperators(ctx *plancontext.PlanningCont
 	newOp.Pushed = false
 	newOp.Original = false
 	newOp.DT = nil
+	// the rollup rows are produced by the original aggregator, the pushed down one just groups by all the columns
+	newOp.WithRollup = false
 
 	// We need to make sure that the columns are cloned so that the original operator is not affected
 	// by the changes we make to the new operator
@@ -224,6 +224,8 @@ func getOperatorFromTableExpr(ctx *plancontext.PlanningContext, tableExpr sqlpar
 		return getOperatorFromJoinTableExpr(ctx, tableExpr)
 	case *sqlparser.ParenTableExpr:
 		return crossJoin(ctx, tableExpr.Exprs)
+	case *sqlparser.JSONTableExpr:
+		return newJSONTable(ctx, tableExpr)
 	default:
 		panic(vterrors.VT13001(fmt.Sprintf("unable to use: %T table type", tableExpr)))
 	}
@@ -286,13 +288,24 @@ func getOperatorFromAliasedTableExpr(ctx *plancontext.PlanningContext, tableExpr
 			tbl.Select.SetOrderBy(nil)
 		}
 
-		inner := translateQueryToOp(ctx, tbl.Select)
+		stmt := tbl.Select
+		var lateralVars []BindVarExpr
+		var lateralPreds []sqlparser.Expr
+		if tbl.Lateral {
+			innerTables := findTablesContained(ctx, stmt)
+			lateralPreds = lateralPredicates(ctx, stmt, innerTables)
+			stmt, lateralVars = rewriteLateralDependencies(ctx, stmt, innerTables)
+		}
+
+		inner := translateQueryToOp(ctx, stmt)
 		if horizon, ok := inner.(*Horizon); ok {
 			horizon.TableId = &tableID
 			horizon.Alias = tableExpr.As.String()
 			horizon.ColumnAliases = tableExpr.Columns
-			qp := CreateQPFromSelectStatement(ctx, tbl.Select)
+			qp := CreateQPFromSelectStatement(ctx, stmt)
 			horizon.QP = qp
+			horizon.LateralVars = lateralVars
+			horizon.LateralPredicates = lateralPreds
 		}
 
 		return inner
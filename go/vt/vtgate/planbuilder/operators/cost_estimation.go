@@ -0,0 +1,210 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operators
+
+import (
+	"io"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+)
+
+const (
+	// queryCostInRows is how many rows we consider sending a query to be worth, for every unit of routing cost.
+	// This makes sending a query to all shards as expensive as reading a fair amount of rows.
+	queryCostInRows = 10
+
+	// defaultSelectivity is the fraction of rows we expect a predicate to keep when we know nothing about it
+	defaultSelectivity = 1.0 / 3
+
+	// defaultEqualitySelectivity is the fraction of rows we expect an equality comparison to keep
+	// when we don't know the cardinality of the column
+	defaultEqualitySelectivity = 0.1
+)
+
+// costEstimate is how expensive it is to run an operator, and how many rows it produces.
+// The cost is measured in rows read, with sending queries to the tablets adding to it.
+type costEstimate struct {
+	cost, rows float64
+}
+
+// EstimateCost estimates the cost of an operator tree using the table statistics collected by the schema tracker.
+// It returns nil when statistics are missing for any of the tables in the tree.
+func EstimateCost(ctx *plancontext.PlanningContext, op Operator) *engine.CostEstimate {
+	est, ok := estimateCost(ctx, op)
+	if !ok {
+		return nil
+	}
+	return &engine.CostEstimate{Cost: est.cost, Rows: est.rows}
+}
+
+func estimateCost(ctx *plancontext.PlanningContext, op Operator) (costEstimate, bool) {
+	switch op := op.(type) {
+	case *Route:
+		return estimateRouteCost(ctx, op)
+	case *ApplyJoin:
+		lhs, ok := estimateCost(ctx, op.LHS)
+		if !ok {
+			return costEstimate{}, false
+		}
+		// the RHS has the join predicates pushed down to it, so its estimate is for a single row from the LHS
+		rhs, ok := estimateCost(ctx, op.RHS)
+		if !ok {
+			return costEstimate{}, false
+		}
+		rows := lhs.rows * rhs.rows
+		if !op.JoinType.IsInner() {
+			rows = max(rows, lhs.rows)
+		}
+		return costEstimate{cost: lhs.cost + lhs.rows*rhs.cost, rows: rows}, true
+	case *HashJoin:
+		lhs, ok := estimateCost(ctx, op.LHS)
+		if !ok {
+			return costEstimate{}, false
+		}
+		rhs, ok := estimateCost(ctx, op.RHS)
+		if !ok {
+			return costEstimate{}, false
+		}
+		rows := lhs.rows * rhs.rows
+		for _, cmp := range op.JoinComparisons {
+			rows *= selectivity(ctx, op, cmp.AsExpr())
+		}
+		for _, filter := range op.Filters {
+			rows *= selectivity(ctx, op, filter)
+		}
+		if op.LeftJoin {
+			rows = max(rows, lhs.rows)
+		}
+		// both sides are read once, and all the rows go through the vtgate
+		return costEstimate{cost: lhs.cost + rhs.cost + lhs.rows + rhs.rows, rows: rows}, true
+	case *Filter:
+		est, ok := estimateCost(ctx, op.Source)
+		if !ok {
+			return costEstimate{}, false
+		}
+		for _, pred := range op.Predicates {
+			est.rows *= selectivity(ctx, op, pred)
+		}
+		return est, true
+	}
+
+	inputs := op.Inputs()
+	if len(inputs) != 1 {
+		return costEstimate{}, false
+	}
+	return estimateCost(ctx, inputs[0])
+}
+
+// estimateRouteCost estimates the number of rows a route returns from the number of rows in its tables,
+// and the predicates on them. Sending the query costs more the more shards it is sent to.
+func estimateRouteCost(ctx *plancontext.PlanningContext, route *Route) (costEstimate, bool) {
+	if !hasTableStats(route.Source) {
+		return costEstimate{}, false
+	}
+
+	rows := 1.0
+	_ = Visit(route.Source, func(op Operator) error {
+		switch op := op.(type) {
+		case *Table:
+			rows *= max(float64(op.VTable.Stats.Rows), 1)
+			for _, pred := range op.QTable.Predicates {
+				rows *= selectivity(ctx, route, pred)
+			}
+		case *Filter:
+			for _, pred := range op.Predicates {
+				rows *= selectivity(ctx, route, pred)
+			}
+		}
+		return nil
+	})
+	return costEstimate{cost: float64(route.Cost()*queryCostInRows) + rows, rows: rows}, true
+}
+
+// hasTableStats returns true if the operator reads from tables, and we have statistics for all of them
+func hasTableStats(op Operator) bool {
+	found, ok := false, true
+	_ = Visit(op, func(op Operator) error {
+		tbl, isTable := op.(*Table)
+		if !isTable {
+			return nil
+		}
+		found = true
+		if tbl.VTable == nil || tbl.VTable.Stats == nil {
+			ok = false
+			return io.EOF
+		}
+		return nil
+	})
+	return found && ok
+}
+
+// selectivity estimates the fraction of rows a predicate keeps, using the cardinality of the columns when we know it
+func selectivity(ctx *plancontext.PlanningContext, root Operator, pred sqlparser.Expr) float64 {
+	switch pred := pred.(type) {
+	case *sqlparser.AndExpr:
+		return selectivity(ctx, root, pred.Left) * selectivity(ctx, root, pred.Right)
+	case *sqlparser.OrExpr:
+		return min(selectivity(ctx, root, pred.Left)+selectivity(ctx, root, pred.Right), 1)
+	case *sqlparser.ComparisonExpr:
+		switch pred.Operator {
+		case sqlparser.EqualOp, sqlparser.NullSafeEqualOp:
+			return equalitySelectivity(ctx, root, pred.Left, pred.Right)
+		case sqlparser.InOp:
+			values := 10.0
+			if tuple, ok := pred.Right.(sqlparser.ValTuple); ok {
+				values = float64(len(tuple))
+			}
+			return min(values*equalitySelectivity(ctx, root, pred.Left, pred.Right), 1)
+		case sqlparser.NotEqualOp, sqlparser.NotInOp:
+			return 1 - defaultEqualitySelectivity
+		}
+	case *sqlparser.IsExpr:
+		return defaultEqualitySelectivity
+	}
+	return defaultSelectivity
+}
+
+// equalitySelectivity estimates the fraction of rows kept by an equality comparison.
+// Comparing with a column that has N distinct values keeps 1/N of the rows
+func equalitySelectivity(ctx *plancontext.PlanningContext, root Operator, left, right sqlparser.Expr) float64 {
+	distinct := max(columnCardinality(ctx, root, left), columnCardinality(ctx, root, right))
+	if distinct == 0 {
+		return defaultEqualitySelectivity
+	}
+	return 1 / float64(distinct)
+}
+
+// columnCardinality returns the number of distinct values of a column of one of the tables in the operator tree,
+// or 0 if the expression is not a column, or we don't know its cardinality
+func columnCardinality(ctx *plancontext.PlanningContext, root Operator, expr sqlparser.Expr) (cardinality uint64) {
+	col, ok := expr.(*sqlparser.ColName)
+	if !ok {
+		return 0
+	}
+	deps := ctx.SemTable.DirectDeps(col)
+	_ = Visit(root, func(op Operator) error {
+		tbl, ok := op.(*Table)
+		if !ok || tbl.QTable.ID != deps {
+			return nil
+		}
+		cardinality = tbl.VTable.Stats.ColumnCardinality(col.Name)
+		return io.EOF
+	})
+	return
+}
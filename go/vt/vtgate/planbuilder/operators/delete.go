@@ -226,6 +226,10 @@ func createDeleteOperator(ctx *plancontext.PlanningContext, del *sqlparser.Delet
 	if err != nil {
 		panic(err)
 	}
+	if _, isATable := tblInfo.(*semantics.RealTable); !isATable {
+		// derived tables and common table expressions can't be the target of a delete
+		panic(vterrors.VT03004(del.Targets[0].Name.String()))
+	}
 
 	vTbl := tblInfo.GetVindexTable()
 	// Reference table should delete from the source table.
@@ -39,6 +39,10 @@ type (
 		// Before offset planning
 		JoinComparisons []Comparison
 
+		// Filters are the join predicates that can't be used as hash keys.
+		// They are checked on the rows that have matching keys
+		Filters []sqlparser.Expr
+
 		// These columns are the output columns of the hash join. While in operator mode we keep track of complex expression,
 		// but once we move to the engine primitives, the hash join only passes through column from either left or right.
 		// anything more complex will be solved by a projection on top of the hash join
@@ -53,11 +57,19 @@ type (
 		// These are the values that will be hashed together
 		LHSKeys, RHSKeys []int
 
+		// FilterExpr is the AND of the Filters, rewritten to use offsets into the row built from FilterOffsets.
+		// FilterOffsets use the same encoding as ColumnOffsets
+		FilterExpr    sqlparser.Expr
+		FilterOffsets []int
+
 		offset bool
 	}
 
 	Comparison struct {
 		LHS, RHS sqlparser.Expr
+
+		// NullSafe is true for comparisons using <=>
+		NullSafe bool
 	}
 
 	hashJoinColumn struct {
@@ -94,6 +106,8 @@ func (hj *HashJoin) Clone(inputs []Operator) Operator {
 	kopy.LHSKeys = slices.Clone(hj.LHSKeys)
 	kopy.RHSKeys = slices.Clone(hj.RHSKeys)
 	kopy.JoinComparisons = slices.Clone(hj.JoinComparisons)
+	kopy.Filters = slices.Clone(hj.Filters)
+	kopy.FilterOffsets = slices.Clone(hj.FilterOffsets)
 	return &kopy
 }
 
@@ -158,6 +172,9 @@ func (hj *HashJoin) planOffsets(ctx *plancontext.PlanningContext) Operator {
 		rOffset := hj.RHS.AddColumn(ctx, true, false, aeWrap(cmp.RHS))
 		hj.RHSKeys = append(hj.RHSKeys, rOffset)
 	}
+	if len(hj.Filters) > 0 {
+		hj.FilterExpr = hj.rewriteToOffsets(ctx, ctx.SemTable.AndExpressions(hj.Filters...), &hj.FilterOffsets)
+	}
 
 	needsProj := false
 	lID := TableID(hj.LHS)
@@ -213,6 +230,9 @@ func (hj *HashJoin) ShortDescription() string {
 	comparisons := slice.Map(hj.JoinComparisons, func(from Comparison) string {
 		return from.String()
 	})
+	for _, filter := range hj.Filters {
+		comparisons = append(comparisons, sqlparser.String(filter))
+	}
 	cmp := strings.Join(comparisons, " AND ")
 
 	if len(hj.columns.columns) > 0 {
@@ -263,9 +283,23 @@ func (hj *HashJoin) IsInner() bool {
 }
 
 func (hj *HashJoin) AddJoinPredicate(ctx *plancontext.PlanningContext, expr sqlparser.Expr) {
+	if cmp, ok := hj.hashComparison(ctx, expr); ok {
+		hj.JoinComparisons = append(hj.JoinComparisons, cmp)
+		return
+	}
+
+	if !ctx.SemTable.RecursiveDeps(expr).IsSolvedBy(TableID(hj)) {
+		panic(vterrors.VT12001(fmt.Sprintf("can't use [%s] with hash joins", sqlparser.String(expr))))
+	}
+	hj.Filters = append(hj.Filters, expr)
+}
+
+// hashComparison returns the comparison to use as a hash key if the predicate
+// compares an expression from the LHS with an expression from the RHS for equality
+func (hj *HashJoin) hashComparison(ctx *plancontext.PlanningContext, expr sqlparser.Expr) (Comparison, bool) {
 	cmp, ok := expr.(*sqlparser.ComparisonExpr)
 	if !ok || !canBeSolvedWithHashJoin(cmp.Operator) {
-		panic(vterrors.VT12001(fmt.Sprintf("can't use [%s] with hash joins", sqlparser.String(expr))))
+		return Comparison{}, false
 	}
 	lExpr := cmp.Left
 	lDeps := ctx.SemTable.RecursiveDeps(lExpr)
@@ -280,13 +314,14 @@ func (hj *HashJoin) AddJoinPredicate(ctx *plancontext.PlanningContext, expr sqlp
 	}
 
 	if !lDeps.IsSolvedBy(lID) || !rDeps.IsSolvedBy(rID) {
-		panic(vterrors.VT12001(fmt.Sprintf("can't use [%s] with hash joins", sqlparser.String(expr))))
+		return Comparison{}, false
 	}
 
-	hj.JoinComparisons = append(hj.JoinComparisons, Comparison{
-		LHS: lExpr,
-		RHS: rExpr,
-	})
+	return Comparison{
+		LHS:      lExpr,
+		RHS:      rExpr,
+		NullSafe: cmp.Operator == sqlparser.NullSafeEqualOp,
+	}, true
 }
 
 func canBeSolvedWithHashJoin(op sqlparser.ComparisonExprOperator) bool {
@@ -299,11 +334,48 @@ func canBeSolvedWithHashJoin(op sqlparser.ComparisonExprOperator) bool {
 }
 
 func (c Comparison) String() string {
-	return sqlparser.String(c.LHS) + " = " + sqlparser.String(c.RHS)
+	return sqlparser.String(c.AsExpr())
+}
+
+// AsExpr returns the comparison as an AST expression
+func (c Comparison) AsExpr() sqlparser.Expr {
+	op := sqlparser.EqualOp
+	if c.NullSafe {
+		op = sqlparser.NullSafeEqualOp
+	}
+	return &sqlparser.ComparisonExpr{
+		Operator: op,
+		Left:     c.LHS,
+		Right:    c.RHS,
+	}
 }
 func lhsOffset(i int) int { return (i * -1) - 1 }
 func rhsOffset(i int) int { return i + 1 }
 func (hj *HashJoin) addColumn(ctx *plancontext.PlanningContext, in sqlparser.Expr) (*ProjExpr, bool) {
+	rewrittenExpr := hj.rewriteToOffsets(ctx, in, &hj.ColumnOffsets)
+	cfg := &evalengine.Config{
+		ResolveType: ctx.TypeForExpr,
+		Collation:   ctx.SemTable.Collation,
+		Environment: ctx.VSchema.Environment(),
+	}
+	eexpr, err := evalengine.Translate(rewrittenExpr, cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	_, isPureOffset := rewrittenExpr.(*sqlparser.Offset)
+
+	return &ProjExpr{
+		Original: aeWrap(in),
+		EvalExpr: rewrittenExpr,
+		ColExpr:  rewrittenExpr,
+		Info:     &EvalEngine{EExpr: eexpr},
+	}, isPureOffset
+}
+
+// rewriteToOffsets replaces the parts of the expression that come from the inputs with offsets.
+// The columns fetched from the inputs are added to the given offsets, using the same encoding as ColumnOffsets
+func (hj *HashJoin) rewriteToOffsets(ctx *plancontext.PlanningContext, in sqlparser.Expr, offsets *[]int) sqlparser.Expr {
 	lId, rId := TableID(hj.LHS), TableID(hj.RHS)
 	r := new(replacer) // this is the expression we will put in instead of whatever we find there
 	pre := func(node, parent sqlparser.SQLNode) bool {
@@ -328,8 +400,8 @@ func (hj *HashJoin) addColumn(ctx *plancontext.PlanningContext, in sqlparser.Exp
 
 			// we have to turn the incoming offset to an outgoing offset of the columns this operator is exposing
 			internalOffset := offsetter(inOffset)
-			hj.ColumnOffsets = append(hj.ColumnOffsets, internalOffset)
-			return len(hj.ColumnOffsets) - 1
+			*offsets = append(*offsets, internalOffset)
+			return len(*offsets) - 1
 		}
 
 		if lOffset := check(lId, hj.LHS, lhsOffset); lOffset >= 0 {
@@ -345,36 +417,13 @@ func (hj *HashJoin) addColumn(ctx *plancontext.PlanningContext, in sqlparser.Exp
 		return true
 	}
 
-	rewrittenExpr := sqlparser.CopyOnRewrite(in, pre, r.post, ctx.SemTable.CopySemanticInfo).(sqlparser.Expr)
-	cfg := &evalengine.Config{
-		ResolveType: ctx.TypeForExpr,
-		Collation:   ctx.SemTable.Collation,
-		Environment: ctx.VSchema.Environment(),
-	}
-	eexpr, err := evalengine.Translate(rewrittenExpr, cfg)
-	if err != nil {
-		panic(err)
-	}
-
-	_, isPureOffset := rewrittenExpr.(*sqlparser.Offset)
-
-	return &ProjExpr{
-		Original: aeWrap(in),
-		EvalExpr: rewrittenExpr,
-		ColExpr:  rewrittenExpr,
-		Info:     &EvalEngine{EExpr: eexpr},
-	}, isPureOffset
+	return sqlparser.CopyOnRewrite(in, pre, r.post, ctx.SemTable.CopySemanticInfo).(sqlparser.Expr)
 }
 
 // JoinPredicate produces an AST representation of the join condition this join has
 func (hj *HashJoin) JoinPredicate() sqlparser.Expr {
-	exprs := slice.Map(hj.JoinComparisons, func(from Comparison) sqlparser.Expr {
-		return &sqlparser.ComparisonExpr{
-			Left:  from.LHS,
-			Right: from.RHS,
-		}
-	})
-	return sqlparser.AndExpressions(exprs...)
+	exprs := slice.Map(hj.JoinComparisons, Comparison.AsExpr)
+	return sqlparser.AndExpressions(append(exprs, hj.Filters...)...)
 }
 
 type replacer struct {
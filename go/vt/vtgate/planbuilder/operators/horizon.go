@@ -51,6 +51,11 @@ type Horizon struct {
 	ColumnsOffset []int
 
 	Truncate bool
+
+	// LateralVars are the columns a LATERAL derived table uses from the tables preceding it,
+	// and LateralPredicates are the predicates comparing these tables with the derived table
+	LateralVars       []BindVarExpr
+	LateralPredicates []sqlparser.Expr
 }
 
 func newHorizon(src Operator, query sqlparser.SelectStatement) *Horizon {
@@ -64,6 +69,8 @@ func (h *Horizon) Clone(inputs []Operator) Operator {
 	klone.ColumnAliases = sqlparser.Clone(h.ColumnAliases)
 	klone.Columns = slices.Clone(h.Columns)
 	klone.ColumnsOffset = slices.Clone(h.ColumnsOffset)
+	klone.LateralVars = slices.Clone(h.LateralVars)
+	klone.LateralPredicates = slices.Clone(h.LateralPredicates)
 	klone.QP = h.QP
 	return &klone
 }
@@ -102,7 +109,7 @@ func (h *Horizon) AddPredicate(ctx *plancontext.PlanningContext, expr sqlparser.
 	}
 
 	newExpr := ctx.RewriteDerivedTableExpression(expr, tableInfo)
-	if ctx.ContainsAggr(newExpr) {
+	if ctx.ContainsAggr(newExpr) || sqlparser.ContainsWindowFunction(newExpr) {
 		return newFilter(h, expr)
 	}
 	h.Source = h.Source.AddPredicate(ctx, newExpr)
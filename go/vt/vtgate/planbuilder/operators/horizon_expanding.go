@@ -18,6 +18,7 @@ package operators
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"vitess.io/vitess/go/slice"
@@ -63,6 +64,7 @@ func expandUnionHorizon(ctx *plancontext.PlanningContext, horizon *Horizon, unio
 			TableID: *horizon.TableId,
 			Alias:   horizon.Alias,
 			Columns: horizon.ColumnAliases,
+			Lateral: len(horizon.LateralVars) > 0,
 		}
 		op = proj
 	}
@@ -204,18 +206,73 @@ func createProjectionFromSelect(ctx *plancontext.PlanningContext, horizon *Horiz
 			TableID: *horizon.TableId,
 			Alias:   horizon.Alias,
 			Columns: horizon.ColumnAliases,
+			Lateral: len(horizon.LateralVars) > 0,
 		}
 	}
 
 	if qp.NeedsAggregation() {
+		if slices.ContainsFunc(qp.SelectExprs, func(se SelectExpr) bool {
+			return sqlparser.ContainsWindowFunction(se.Col)
+		}) {
+			panic(vterrors.VT12001("window functions with aggregation on sharded keyspace"))
+		}
 		return createProjectionWithAggr(ctx, qp, dt, horizon)
 	}
 
-	projX := createProjectionWithoutAggr(ctx, qp, horizon.src())
+	projX := createProjectionWithoutAggr(ctx, qp, createWindowOperators(ctx, qp, horizon.src()))
 	projX.DT = dt
 	return projX
 }
 
+// createWindowOperators adds Window operators for the window functions used by the query,
+// one operator for every window specification
+func createWindowOperators(ctx *plancontext.PlanningContext, qp *QueryProjection, src Operator) Operator {
+	if !ctx.SemTable.QuerySignature.WindowFunctions {
+		return src
+	}
+
+	var specs []string
+	functions := map[string][]sqlparser.Expr{}
+	windows := map[string]*sqlparser.WindowSpecification{}
+	collect := func(node sqlparser.SQLNode) (bool, error) {
+		expr, ok := node.(sqlparser.Expr)
+		if !ok || !sqlparser.IsWindowFunction(expr) {
+			return true, nil
+		}
+		over := sqlparser.GetOverClause(expr)
+		if over.WindowSpec == nil {
+			panic(vterrors.VT12001("named window in OVER CLAUSE with sharded keyspace"))
+		}
+		spec := &sqlparser.WindowSpecification{
+			PartitionClause: over.WindowSpec.PartitionClause,
+			OrderClause:     over.WindowSpec.OrderClause,
+		}
+		key := sqlparser.String(spec)
+		if _, found := windows[key]; !found {
+			specs = append(specs, key)
+			windows[key] = spec
+		}
+		if !slices.ContainsFunc(functions[key], func(e sqlparser.Expr) bool {
+			return ctx.SemTable.EqualsExprWithDeps(e, expr)
+		}) {
+			functions[key] = append(functions[key], expr)
+		}
+		return false, nil
+	}
+
+	for _, se := range qp.SelectExprs {
+		_ = sqlparser.Walk(collect, se.Col)
+	}
+	for _, order := range qp.OrderExprs {
+		_ = sqlparser.Walk(collect, order.Inner)
+	}
+
+	for _, key := range specs {
+		src = newWindow(src, windows[key], functions[key])
+	}
+	return src
+}
+
 func createProjectionWithAggr(ctx *plancontext.PlanningContext, qp *QueryProjection, dt *DerivedTable, horizon *Horizon) Operator {
 	aggregations, complexAggr := qp.AggregationExpressions(ctx, true)
 	src := horizon.Source
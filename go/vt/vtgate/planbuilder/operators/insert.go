@@ -17,6 +17,8 @@ limitations under the License.
 package operators
 
 import (
+	"fmt"
+	"io"
 	"strconv"
 
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
@@ -117,6 +119,20 @@ func createOperatorFromInsert(ctx *plancontext.PlanningContext, ins *sqlparser.I
 		ins.Action = sqlparser.InsertAct
 		deleteBeforeInsert = true
 	}
+	if ins.Action == sqlparser.ReplaceAct && vTbl.Keyspace.Sharded && len(vTbl.Owned) > 0 {
+		// without the primary key we can't find the replaced rows, whose owned vindex entries have to be deleted.
+		panic(vterrors.VT09015())
+	}
+
+	// the delete has to be built before the insert operator, since planning the insert rewrites the values into bind variables.
+	var delStmt *sqlparser.Delete
+	rows, isRows := ins.Rows.(sqlparser.Values)
+	if deleteBeforeInsert && isRows {
+		if ins.Columns == nil && vTbl.ColumnListAuthoritative {
+			populateInsertColumnlist(ins, vTbl)
+		}
+		delStmt = createDeleteBeforeInsert(vTbl, ins, sqlparser.Clone(rows))
+	}
 
 	insOp := checkAndCreateInsertOperator(ctx, ins, vTbl, routing)
 
@@ -124,22 +140,80 @@ func createOperatorFromInsert(ctx *plancontext.PlanningContext, ins *sqlparser.I
 		return insOp
 	}
 
-	rows, isRows := ins.Rows.(sqlparser.Values)
 	if !isRows {
-		panic(vterrors.VT12001("REPLACE INTO using select statement"))
+		addDeleteToInsertSelection(ctx, ins, insOp, vTbl)
+		return insOp
+	}
+
+	if delStmt == nil {
+		// none of the inserted rows can clash with an existing row.
+		return insOp
+	}
+
+	delOp := createOpFromStmt(ctx, delStmt, false, "")
+	return &Sequential{Sources: []Operator{delOp, insOp}}
+}
+
+// addDeleteToInsertSelection adds the delete that REPLACE INTO ... SELECT needs to run before inserting the selected rows.
+// The rows are deleted using the primary key values of the selected rows, which are only known at runtime.
+func addDeleteToInsertSelection(ctx *plancontext.PlanningContext, ins *sqlparser.Insert, op Operator, vTbl *vindexes.Table) {
+	if len(vTbl.PrimaryKey) == 0 || len(vTbl.UniqueKeys) > 0 {
+		panic(vterrors.VT12001("REPLACE INTO using select statement on a table with unique keys"))
+	}
+
+	var insSel *InsertSelection
+	_ = Visit(op, func(op Operator) error {
+		if is, ok := op.(*InsertSelection); ok {
+			insSel = is
+			return io.EOF
+		}
+		return nil
+	})
+	if insSel == nil {
+		panic(vterrors.VT13001("InsertSelection not found for REPLACE INTO using select statement"))
 	}
 
+	var lhs sqlparser.ValTuple
+	for _, col := range vTbl.PrimaryKey {
+		idx := ins.Columns.FindColumn(col)
+		if idx == -1 {
+			panic(vterrors.VT12001(fmt.Sprintf("REPLACE INTO using select statement without primary key column '%s' in the column list", col.String())))
+		}
+		insSel.PKOffsets = append(insSel.PKOffsets, idx)
+		lhs = append(lhs, sqlparser.NewColName(col.String()))
+	}
+
+	// optimize for case when there is only single column on left hand side.
+	var pkExpr sqlparser.Expr = lhs
+	if len(lhs) == 1 {
+		pkExpr = lhs[0]
+	}
+	delStmt := &sqlparser.Delete{
+		Comments:   ins.Comments,
+		TableExprs: sqlparser.TableExprs{sqlparser.Clone(ins.Table)},
+		Where:      sqlparser.NewWhere(sqlparser.WhereClause, sqlparser.NewComparisonExpr(sqlparser.InOp, pkExpr, sqlparser.ListArg(engine.DmlVals), nil)),
+	}
+	insSel.Delete = createOpFromStmt(ctx, delStmt, false, "")
+
+	// the rows have to be deleted before the next batch of rows is inserted, so we can't stream the selection.
+	insSel.ForceNonStreaming = true
+}
+
+// createDeleteBeforeInsert creates the delete statement removing the rows that clash with the rows of a REPLACE statement.
+// It returns nil when no existing row can clash with the inserted rows.
+func createDeleteBeforeInsert(vTbl *vindexes.Table, ins *sqlparser.Insert, rows sqlparser.Values) *sqlparser.Delete {
 	pkCompExpr := pkCompExpression(vTbl, ins, rows)
 	uniqKeyCompExprs := uniqKeyCompExpressions(vTbl, ins, rows)
 	whereExpr := getWhereCondExpr(append(uniqKeyCompExprs, pkCompExpr))
+	if whereExpr == nil {
+		return nil
+	}
 
-	delStmt := &sqlparser.Delete{
+	return &sqlparser.Delete{
 		Comments:   ins.Comments,
 		TableExprs: sqlparser.TableExprs{sqlparser.Clone(ins.Table)},
 		Where:      sqlparser.NewWhere(sqlparser.WhereClause, whereExpr),
 	}
-	delOp := createOpFromStmt(ctx, delStmt, false, "")
-	return &Sequential{Sources: []Operator{delOp, insOp}}
 }
 
 func checkAndCreateInsertOperator(ctx *plancontext.PlanningContext, ins *sqlparser.Insert, vTbl *vindexes.Table, routing Routing) Operator {
@@ -201,6 +275,9 @@ func pkCompExpression(vTbl *vindexes.Table, ins *sqlparser.Insert, rows sqlparse
 		return nil
 	}
 	pIndexes, pColTuple := findPKIndexes(vTbl, ins)
+	if len(pIndexes) == 0 {
+		return nil
+	}
 
 	var pValTuple sqlparser.ValTuple
 	for _, row := range rows {
@@ -227,6 +304,10 @@ func findPKIndexes(vTbl *vindexes.Table, ins *sqlparser.Insert) (pIndexes []pCom
 	for _, pCol := range vTbl.PrimaryKey {
 		var def sqlparser.Expr
 		idx := ins.Columns.FindColumn(pCol)
+		if idx == -1 && vTbl.AutoIncrement != nil && vTbl.AutoIncrement.Column.Equal(pCol) {
+			// The value will be generated from the sequence, so it can't clash with an existing row.
+			return nil, nil
+		}
 		if idx == -1 {
 			def = findDefault(vTbl, pCol)
 			if def == nil {
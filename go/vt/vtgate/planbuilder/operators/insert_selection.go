@@ -29,25 +29,41 @@ type InsertSelection struct {
 	// ForceNonStreaming when true, select first then insert, this is to avoid locking rows by select for insert.
 	ForceNonStreaming bool
 
+	// Delete is used by REPLACE INTO ... SELECT to delete the rows clashing on the primary key before inserting.
+	Delete Operator
+	// PKOffsets are the offsets of the primary key columns in the selection.
+	PKOffsets []int
+
 	noColumns
 	noPredicates
 }
 
 func (is *InsertSelection) Clone(inputs []Operator) Operator {
-	return &InsertSelection{
+	clone := &InsertSelection{
 		Select:            inputs[0],
 		Insert:            inputs[1],
 		ForceNonStreaming: is.ForceNonStreaming,
+		PKOffsets:         is.PKOffsets,
+	}
+	if len(inputs) > 2 {
+		clone.Delete = inputs[2]
 	}
+	return clone
 }
 
 func (is *InsertSelection) Inputs() []Operator {
-	return []Operator{is.Select, is.Insert}
+	if is.Delete == nil {
+		return []Operator{is.Select, is.Insert}
+	}
+	return []Operator{is.Select, is.Insert, is.Delete}
 }
 
 func (is *InsertSelection) SetInputs(inputs []Operator) {
 	is.Select = inputs[0]
 	is.Insert = inputs[1]
+	if len(inputs) > 2 {
+		is.Delete = inputs[2]
+	}
 }
 
 func (is *InsertSelection) ShortDescription() string {
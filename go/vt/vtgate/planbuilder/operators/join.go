@@ -17,6 +17,8 @@ limitations under the License.
 package operators
 
 import (
+	"slices"
+
 	"vitess.io/vitess/go/slice"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
@@ -32,6 +34,11 @@ type Join struct {
 	// NormalJoinType, StraightJoinType and LeftJoinType.
 	JoinType sqlparser.JoinType
 
+	// LateralVars are the columns the RHS uses from the LHS, when the RHS
+	// contains a LATERAL derived table or a JSON_TABLE depending on the LHS
+	LateralVars       []BindVarExpr
+	LateralPredicates []sqlparser.Expr
+
 	noColumns
 }
 
@@ -43,10 +50,12 @@ func (j *Join) Clone(inputs []Operator) Operator {
 	clone.LHS = inputs[0]
 	clone.RHS = inputs[1]
 	return &Join{
-		LHS:       inputs[0],
-		RHS:       inputs[1],
-		Predicate: j.Predicate,
-		JoinType:  j.JoinType,
+		LHS:               inputs[0],
+		RHS:               inputs[1],
+		Predicate:         j.Predicate,
+		JoinType:          j.JoinType,
+		LateralVars:       slices.Clone(j.LateralVars),
+		LateralPredicates: slices.Clone(j.LateralPredicates),
 	}
 }
 
@@ -89,7 +98,7 @@ func (j *Join) Compact(ctx *plancontext.PlanningContext) (Operator, *ApplyResult
 
 func createStraightJoin(ctx *plancontext.PlanningContext, join *sqlparser.JoinTableExpr, lhs, rhs Operator) Operator {
 	// for inner joins we can treat the predicates as filters on top of the join
-	joinOp := &Join{LHS: lhs, RHS: rhs, JoinType: join.Join}
+	joinOp := newJoin(ctx, lhs, rhs, join.Join)
 
 	return addJoinPredicates(ctx, join.Condition.On, joinOp)
 }
@@ -105,23 +114,50 @@ func createLeftOuterJoin(ctx *plancontext.PlanningContext, join *sqlparser.JoinT
 		join.Join = sqlparser.NaturalLeftJoinType
 	}
 
-	joinOp := &Join{LHS: lhs, RHS: rhs, JoinType: join.Join}
+	joinOp := newJoin(ctx, lhs, rhs, join.Join)
 
 	// mark the RHS as outer tables so we know which columns are nullable
 	ctx.OuterTables = ctx.OuterTables.Merge(TableID(rhs))
 
 	// for outer joins we have to be careful with the predicates we use
-	var op Operator
-	subq, _ := getSubQuery(join.Condition.On)
-	if subq != nil {
-		panic(vterrors.VT12001("subquery in outer join predicate"))
-	}
 	predicate := join.Condition.On
 	sqlparser.RemoveKeyspaceInCol(predicate)
-	joinOp.Predicate = predicate
-	op = joinOp
+	subq, _ := getSubQuery(predicate)
+	if subq == nil {
+		joinOp.Predicate = predicate
+		return joinOp
+	}
 
-	return op
+	return addOuterJoinPredicates(ctx, predicate, joinOp)
+}
+
+// addOuterJoinPredicates adds the ON condition of an outer join that contains subqueries.
+// Predicates that only depend on the RHS are used to filter the RHS before joining, which
+// keeps the null-extension semantics of the outer join. Other predicates using uncorrelated
+// subqueries are kept on the join, and the subquery is evaluated once before the join.
+func addOuterJoinPredicates(ctx *plancontext.PlanningContext, predicate sqlparser.Expr, joinOp *Join) Operator {
+	rhsID := TableID(joinOp.RHS)
+	rhsSqc := &SubQueryBuilder{}
+	joinSqc := &SubQueryBuilder{}
+	var predicates []sqlparser.Expr
+	for _, pred := range sqlparser.SplitAndExpression(nil, predicate) {
+		subq, _ := getSubQuery(pred)
+		switch {
+		case subq == nil:
+			predicates = append(predicates, pred)
+		case ctx.SemTable.RecursiveDeps(pred).IsSolvedBy(rhsID):
+			rhsSqc.handleSubquery(ctx, pred, rhsID)
+		case ctx.SemTable.RecursiveDeps(subq).IsEmpty():
+			sq := joinSqc.handleSubquery(ctx, pred, TableID(joinOp))
+			predicates = append(predicates, sq.pullOutOfOuterJoin(ctx)...)
+		default:
+			panic(vterrors.VT12001("correlated subquery in outer join predicate using columns from the left side of the join"))
+		}
+	}
+
+	joinOp.RHS = rhsSqc.getRootOperator(joinOp.RHS, nil)
+	joinOp.Predicate = sqlparser.AndExpressions(predicates...)
+	return joinSqc.getRootOperator(joinOp, nil)
 }
 
 func createInnerJoin(ctx *plancontext.PlanningContext, tableExpr *sqlparser.JoinTableExpr, lhs, rhs Operator) Operator {
@@ -197,7 +233,13 @@ func createJoin(ctx *plancontext.PlanningContext, LHS, RHS Operator) Operator {
 		}
 		return op
 	}
-	return &Join{LHS: LHS, RHS: RHS}
+	return newJoin(ctx, LHS, RHS, sqlparser.NormalJoinType)
+}
+
+func newJoin(ctx *plancontext.PlanningContext, lhs, rhs Operator, joinType sqlparser.JoinType) *Join {
+	join := &Join{LHS: lhs, RHS: rhs, JoinType: joinType}
+	join.addLateralDependencies(ctx)
+	return join
 }
 
 func (j *Join) AddPredicate(ctx *plancontext.PlanningContext, expr sqlparser.Expr) Operator {
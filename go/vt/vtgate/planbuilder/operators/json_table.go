@@ -0,0 +1,213 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operators
+
+import (
+	"fmt"
+	"slices"
+
+	"vitess.io/vitess/go/slice"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+)
+
+// JSONTable represents a JSON_TABLE expression in the FROM clause.
+// When it can be merged with the route producing the JSON document, it is sent down to MySQL
+// as part of that query. Otherwise, it is evaluated on the vtgate.
+type JSONTable struct {
+	ID semantics.TableSet
+
+	// Expr is the JSON_TABLE expression. Columns from other tables used in the JSON document
+	// have been replaced by arguments, and the expressions they stand for are kept in LateralVars
+	Expr        *sqlparser.JSONTableExpr
+	LateralVars []BindVarExpr
+
+	// Columns are the expressions this operator has been asked to produce
+	Columns []*sqlparser.AliasedExpr
+
+	// Offsets point into the flattened column definitions of the JSON_TABLE,
+	// and are the columns produced when evaluating it on the vtgate
+	Offsets []int
+	offset  bool
+
+	noInputs
+}
+
+func newJSONTable(ctx *plancontext.PlanningContext, expr *sqlparser.JSONTableExpr) *JSONTable {
+	// all columns used in the JSON document come from the tables preceding the JSON_TABLE
+	doc, vars := rewriteLateralDependencies(ctx, expr.Expr, findTablesContained(ctx, expr.Expr))
+	rewritten := sqlparser.Clone(expr)
+	rewritten.Expr = doc
+	return &JSONTable{
+		ID:          ctx.SemTable.TableSetForJSONTable(expr),
+		Expr:        rewritten,
+		LateralVars: vars,
+	}
+}
+
+// Clone implements the Operator interface
+func (jt *JSONTable) Clone([]Operator) Operator {
+	klone := *jt
+	klone.LateralVars = slices.Clone(jt.LateralVars)
+	klone.Columns = slices.Clone(jt.Columns)
+	klone.Offsets = slices.Clone(jt.Offsets)
+	return &klone
+}
+
+func (jt *JSONTable) introducesTableID() semantics.TableSet {
+	return jt.ID
+}
+
+func (jt *JSONTable) AddPredicate(_ *plancontext.PlanningContext, expr sqlparser.Expr) Operator {
+	return newFilter(jt, expr)
+}
+
+func (jt *JSONTable) AddColumn(ctx *plancontext.PlanningContext, reuse bool, _ bool, ae *sqlparser.AliasedExpr) int {
+	if reuse {
+		offset := jt.FindCol(ctx, ae.Expr, false)
+		if offset > -1 {
+			return offset
+		}
+	}
+	if jt.offset {
+		panic(vterrors.VT13001("cannot add columns to a JSON_TABLE after offsets have been planned"))
+	}
+	jt.Columns = append(jt.Columns, ae)
+	return len(jt.Columns) - 1
+}
+
+func (*JSONTable) AddWSColumn(*plancontext.PlanningContext, int, bool) int {
+	panic(vterrors.VT13001("did not expect this method to be called"))
+}
+
+func (jt *JSONTable) FindCol(ctx *plancontext.PlanningContext, expr sqlparser.Expr, _ bool) int {
+	return slices.IndexFunc(jt.Columns, func(ae *sqlparser.AliasedExpr) bool {
+		return ctx.SemTable.EqualsExprWithDeps(expr, ae.Expr)
+	})
+}
+
+func (jt *JSONTable) GetColumns(*plancontext.PlanningContext) []*sqlparser.AliasedExpr {
+	return jt.Columns
+}
+
+func (jt *JSONTable) GetSelectExprs(ctx *plancontext.PlanningContext) sqlparser.SelectExprs {
+	return transformColumnsToSelectExprs(ctx, jt)
+}
+
+func (jt *JSONTable) GetOrdering(*plancontext.PlanningContext) []OrderBy {
+	return nil
+}
+
+func (jt *JSONTable) ShortDescription() string {
+	return fmt.Sprintf("JSON_TABLE(%s) AS %s", sqlparser.String(jt.Expr.Expr), jt.Expr.Alias.String())
+}
+
+// ColumnDefinitions returns the column definitions of the JSON_TABLE, with the
+// columns of nested paths flattened in the order they are declared in
+func (jt *JSONTable) ColumnDefinitions() []*sqlparser.JtColumnDefinition {
+	return flattenJSONTableColumns(jt.Expr.Columns)
+}
+
+func flattenJSONTableColumns(columns []*sqlparser.JtColumnDefinition) (result []*sqlparser.JtColumnDefinition) {
+	for _, col := range columns {
+		if col.JtNestedPath != nil {
+			result = append(result, flattenJSONTableColumns(col.JtNestedPath.Columns)...)
+			continue
+		}
+		result = append(result, col)
+	}
+	return
+}
+
+// definitionOffset returns the offset of the column definition the given expression refers to, or -1
+func (jt *JSONTable) definitionOffset(ctx *plancontext.PlanningContext, expr sqlparser.Expr) int {
+	col, ok := expr.(*sqlparser.ColName)
+	if !ok || ctx.SemTable.RecursiveDeps(col) != jt.ID {
+		return -1
+	}
+	return slices.IndexFunc(jt.ColumnDefinitions(), func(def *sqlparser.JtColumnDefinition) bool {
+		if def.JtOrdinal != nil {
+			return def.JtOrdinal.Name.Equal(col.Name)
+		}
+		return def.JtPath.Name.Equal(col.Name)
+	})
+}
+
+func (jt *JSONTable) planOffsets(ctx *plancontext.PlanningContext) Operator {
+	if jt.offset {
+		return nil
+	}
+	jt.offset = true
+
+	needsProj := slices.ContainsFunc(jt.Columns, func(ae *sqlparser.AliasedExpr) bool {
+		return jt.definitionOffset(ctx, ae.Expr) == -1
+	})
+	if !needsProj {
+		jt.Offsets = slice.Map(jt.Columns, func(ae *sqlparser.AliasedExpr) int {
+			return jt.definitionOffset(ctx, ae.Expr)
+		})
+		return nil
+	}
+
+	// some of the expressions can't be produced by the JSON_TABLE itself,
+	// so we produce the columns they need and evaluate them in a projection on top
+	var columns []*sqlparser.AliasedExpr
+	offsetFor := func(col *sqlparser.ColName) int {
+		defOffset := jt.definitionOffset(ctx, col)
+		if defOffset == -1 {
+			panic(vterrors.VT13001(fmt.Sprintf("column %s not found in JSON_TABLE", sqlparser.String(col))))
+		}
+		if idx := slices.Index(jt.Offsets, defOffset); idx != -1 {
+			return idx
+		}
+		jt.Offsets = append(jt.Offsets, defOffset)
+		columns = append(columns, aeWrap(col))
+		return len(jt.Offsets) - 1
+	}
+
+	cfg := &evalengine.Config{
+		ResolveType: ctx.TypeForExpr,
+		Collation:   ctx.SemTable.Collation,
+		Environment: ctx.VSchema.Environment(),
+	}
+	projExprs := slice.Map(jt.Columns, func(ae *sqlparser.AliasedExpr) *ProjExpr {
+		if col, ok := ae.Expr.(*sqlparser.ColName); ok && jt.definitionOffset(ctx, col) != -1 {
+			return &ProjExpr{Original: ae, EvalExpr: ae.Expr, ColExpr: ae.Expr, Info: Offset(offsetFor(col))}
+		}
+
+		rewritten := sqlparser.CopyOnRewrite(ae.Expr, nil, func(cursor *sqlparser.CopyOnWriteCursor) {
+			col, ok := cursor.Node().(*sqlparser.ColName)
+			if !ok {
+				return
+			}
+			cursor.Replace(sqlparser.NewOffset(offsetFor(col), col))
+		}, ctx.SemTable.CopySemanticInfo).(sqlparser.Expr)
+		eexpr, err := evalengine.Translate(rewritten, cfg)
+		if err != nil {
+			panic(err)
+		}
+		return &ProjExpr{Original: ae, EvalExpr: rewritten, ColExpr: ae.Expr, Info: &EvalEngine{EExpr: eexpr}}
+	})
+	jt.Columns = columns
+
+	proj := newAliasedProjection(jt)
+	proj.addProjExpr(projExprs...)
+	return proj
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operators
+
+import (
+	"slices"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+)
+
+// rewriteLateralDependencies replaces the columns in the node that are not coming from the inner tables
+// with arguments, and returns the rewritten node together with the expressions the arguments stand for.
+// LATERAL derived tables and JSON_TABLE expressions can use columns from the tables preceding them in
+// the FROM clause, and these columns are passed in as arguments when they can't be sent to MySQL together
+func rewriteLateralDependencies[T sqlparser.SQLNode](
+	ctx *plancontext.PlanningContext,
+	node T,
+	inner semantics.TableSet,
+) (T, []BindVarExpr) {
+	var vars []BindVarExpr
+	cloned := func(from, to sqlparser.SQLNode) {
+		ctx.SemTable.CopySemanticInfo(from, to)
+		// the outer columns are replaced by arguments, so the rewritten expressions only depend on the inner tables
+		if expr, ok := to.(sqlparser.Expr); ok && semantics.ValidAsMapKey(expr) {
+			ctx.SemTable.Recursive[expr] = ctx.SemTable.RecursiveDeps(expr).KeepOnly(inner)
+			ctx.SemTable.Direct[expr] = ctx.SemTable.DirectDeps(expr).KeepOnly(inner)
+		}
+	}
+	result := sqlparser.CopyOnRewrite(node, nil, func(cursor *sqlparser.CopyOnWriteCursor) {
+		col, ok := cursor.Node().(*sqlparser.ColName)
+		if !ok || ctx.SemTable.RecursiveDeps(col).IsSolvedBy(inner) {
+			return
+		}
+
+		bvName := ctx.GetReservedArgumentFor(col)
+		if !slices.ContainsFunc(vars, func(bve BindVarExpr) bool { return bve.Name == bvName }) {
+			vars = append(vars, BindVarExpr{Name: bvName, Expr: col})
+		}
+		typ, _ := ctx.TypeForExpr(col)
+		arg := sqlparser.NewTypedArgument(bvName, typ.Type())
+		arg.Scale = typ.Scale()
+		arg.Size = typ.Size()
+		ctx.SemTable.CopyExprInfo(col, arg)
+		cursor.Replace(arg)
+	}, cloned).(T)
+	return result, vars
+}
+
+// lateralPredicates returns the predicates in the WHERE clause of a LATERAL derived table
+// that compare the inner tables with the tables preceding the derived table.
+// They are used to check if the derived table can be merged with the LHS of the join.
+func lateralPredicates(ctx *plancontext.PlanningContext, stmt sqlparser.SelectStatement, inner semantics.TableSet) (result []sqlparser.Expr) {
+	sel, ok := stmt.(*sqlparser.Select)
+	if !ok || sel.Where == nil {
+		return nil
+	}
+	for _, pred := range sqlparser.SplitAndExpression(nil, sel.Where.Expr) {
+		if !ctx.SemTable.RecursiveDeps(pred).IsSolvedBy(inner) {
+			result = append(result, pred)
+		}
+	}
+	return
+}
+
+// addLateralDependencies finds the arguments the RHS of the join needs from the LHS,
+// because the RHS contains a LATERAL derived table or a JSON_TABLE depending on the LHS
+func (j *Join) addLateralDependencies(ctx *plancontext.PlanningContext) {
+	lhsID := TableID(j.LHS)
+	_ = Visit(j.RHS, func(op Operator) error {
+		var vars []BindVarExpr
+		switch op := op.(type) {
+		case *Horizon:
+			vars = op.LateralVars
+			for _, pred := range op.LateralPredicates {
+				if ctx.SemTable.RecursiveDeps(pred).IsOverlapping(lhsID) {
+					j.LateralPredicates = append(j.LateralPredicates, pred)
+				}
+			}
+		case *JSONTable:
+			vars = op.LateralVars
+		}
+		for _, bve := range vars {
+			if !ctx.SemTable.RecursiveDeps(bve.Expr).IsSolvedBy(lhsID) ||
+				slices.ContainsFunc(j.LateralVars, func(e BindVarExpr) bool { return e.Name == bve.Name }) {
+				continue
+			}
+			j.LateralVars = append(j.LateralVars, bve)
+		}
+		return nil
+	})
+}
+
+// isJSONTable returns true if the operator is a JSON_TABLE, possibly filtered
+func isJSONTable(op Operator) bool {
+	switch op := op.(type) {
+	case *JSONTable:
+		return true
+	case *Filter:
+		return isJSONTable(op.Source)
+	}
+	return false
+}
+
+// optimizeLateralJoin plans a join where the RHS depends on the LHS, or where one of the sides is a JSON_TABLE.
+// Since the RHS can't be evaluated without the LHS, the sides of the join are never switched.
+func optimizeLateralJoin(ctx *plancontext.PlanningContext, op *Join) (Operator, *ApplyResult) {
+	joinPredicates := sqlparser.SplitAndExpression(nil, op.Predicate)
+	jm := newJoinMerge(joinPredicates, op.JoinType)
+	if route := jm.mergeLateralInputs(ctx, op.LHS, op.RHS, op.LateralPredicates, len(op.LateralVars) > 0); route != nil {
+		if aj, ok := route.Source.(*ApplyJoin); ok {
+			// the arguments will be replaced by the LHS expressions when building the query for the route
+			aj.ExtraLHSVars = slices.Clone(op.LateralVars)
+		}
+		return route, Rewrote("merge lateral join inputs into single route")
+	}
+
+	join := NewApplyJoin(ctx, Clone(op.LHS), Clone(op.RHS), nil, op.JoinType)
+	join.ExtraLHSVars = slices.Clone(op.LateralVars)
+	for _, pred := range joinPredicates {
+		join.AddJoinPredicate(ctx, pred)
+	}
+	return join, Rewrote("logical join to applyJoin, passing lateral dependencies")
+}
+
+// mergeLateralInputs checks if the two sides of a lateral join can be sent to MySQL as a single query.
+// The routing of the RHS can depend on arguments coming from the LHS, so the LHS routing is used when merging.
+func (jm *joinMerger) mergeLateralInputs(
+	ctx *plancontext.PlanningContext,
+	lhs, rhs Operator,
+	lateralPredicates []sqlparser.Expr,
+	lateral bool,
+) *Route {
+	switch {
+	case isJSONTable(rhs):
+		// a JSON_TABLE does not need any data from the database,
+		// so it can be evaluated on any route that produces its inputs
+		lhsRoute, ok := lhs.(*Route)
+		if !ok {
+			return nil
+		}
+		return &Route{
+			Source:  NewApplyJoin(ctx, lhsRoute.Source, rhs, ctx.SemTable.AndExpressions(jm.predicates...), jm.joinType),
+			Routing: lhsRoute.Routing,
+		}
+	case isJSONTable(lhs):
+		rhsRoute, ok := rhs.(*Route)
+		if !ok || lateral {
+			return nil
+		}
+		// if the JSON_TABLE is on the outer side of a left join, it can only be sent to a single shard,
+		// or else we would produce the null-extended rows from every shard
+		if !jm.joinType.IsInner() && !rhsRoute.Routing.OpCode().IsSingleShard() {
+			return nil
+		}
+		return &Route{
+			Source:  NewApplyJoin(ctx, lhs, rhsRoute.Source, ctx.SemTable.AndExpressions(jm.predicates...), jm.joinType),
+			Routing: rhsRoute.Routing,
+		}
+	}
+
+	lhsRoute, rhsRoute, routingA, _, a, b, sameKeyspace := prepareInputRoutes(lhs, rhs)
+	if lhsRoute == nil {
+		return nil
+	}
+
+	switch {
+	case b == dual:
+	case b == anyShard && sameKeyspace:
+	case a == sharded && b == sharded && sameKeyspace:
+		// the LATERAL derived table can be merged if it only needs rows from the same shard as the LHS
+		if !canMergeOnFilters(ctx, lhsRoute, rhsRoute, lateralPredicates) {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return jm.merge(ctx, lhsRoute, rhsRoute, routingA)
+}
+
+// restoreLateralVars replaces the arguments in a query with the expressions they stand for.
+// This is used when the RHS of a lateral join has been merged with the LHS into a single route,
+// and derived tables that now use columns from the LHS are marked as LATERAL.
+func restoreLateralVars(node sqlparser.SQLNode, vars []BindVarExpr) {
+	var replaced int
+	var seen []int
+	_ = sqlparser.Rewrite(node, func(cursor *sqlparser.Cursor) bool {
+		switch node := cursor.Node().(type) {
+		case *sqlparser.DerivedTable:
+			seen = append(seen, replaced)
+		case *sqlparser.Argument:
+			idx := slices.IndexFunc(vars, func(bve BindVarExpr) bool { return bve.Name == node.Name })
+			if idx != -1 {
+				cursor.Replace(sqlparser.Clone(vars[idx].Expr))
+				replaced++
+			}
+		}
+		return true
+	}, func(cursor *sqlparser.Cursor) bool {
+		dt, ok := cursor.Node().(*sqlparser.DerivedTable)
+		if !ok {
+			return true
+		}
+		before := seen[len(seen)-1]
+		seen = seen[:len(seen)-1]
+		if replaced > before {
+			dt.Lateral = true
+		}
+		return true
+	})
+}
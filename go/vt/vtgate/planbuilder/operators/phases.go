@@ -38,6 +38,7 @@ const (
 	delegateAggregation
 	recursiveCTEHorizons
 	addAggrOrdering
+	addWindowOrdering
 	cleanOutPerfDistinct
 	dmlWithInput
 	subquerySettling
@@ -58,6 +59,8 @@ func (p Phase) String() string {
 		return "expand recursive CTE horizons"
 	case addAggrOrdering:
 		return "optimize aggregations with ORDER BY"
+	case addWindowOrdering:
+		return "add ORDER BY for window functions"
 	case cleanOutPerfDistinct:
 		return "optimize Distinct operations"
 	case subquerySettling:
@@ -79,6 +82,8 @@ func (p Phase) shouldRun(s semantics.QuerySignature) bool {
 		return s.RecursiveCTE
 	case addAggrOrdering:
 		return s.Aggregation
+	case addWindowOrdering:
+		return s.WindowFunctions
 	case cleanOutPerfDistinct:
 		return s.Distinct
 	case subquerySettling:
@@ -98,6 +103,8 @@ func (p Phase) act(ctx *plancontext.PlanningContext, op Operator) Operator {
 		return enableDelegateAggregation(ctx, op)
 	case addAggrOrdering:
 		return addOrderingForAllAggregations(ctx, op)
+	case addWindowOrdering:
+		return addOrderingForAllWindows(ctx, op)
 	case recursiveCTEHorizons:
 		return planRecursiveCTEHorizons(ctx, op)
 	case cleanOutPerfDistinct:
@@ -237,6 +244,43 @@ func addOrderingForAllAggregations(ctx *plancontext.PlanningContext, root Operat
 	return BottomUp(root, TableID, visitor, stopAtRoute)
 }
 
+func addOrderingForAllWindows(ctx *plancontext.PlanningContext, root Operator) Operator {
+	visitor := func(in Operator, _ semantics.TableSet, isRoot bool) (Operator, *ApplyResult) {
+		window, ok := in.(*Window)
+		if !ok {
+			return in, NoRewrite
+		}
+
+		requiredOrder := window.requiredOrdering()
+		if len(requiredOrder) == 0 || orderingSatisfied(ctx, window.Source, requiredOrder) {
+			return in, NoRewrite
+		}
+
+		window.Source = &Ordering{
+			Source: window.Source,
+			Order:  requiredOrder,
+		}
+		return in, Rewrote("added ordering before window functions")
+	}
+
+	return BottomUp(root, TableID, visitor, stopAtRoute)
+}
+
+// orderingSatisfied returns true if the operator already produces rows in the required order
+func orderingSatisfied(ctx *plancontext.PlanningContext, op Operator, required []OrderBy) bool {
+	srcOrdering := op.GetOrdering(ctx)
+	if len(srcOrdering) < len(required) {
+		return false
+	}
+	for idx, order := range required {
+		if !ctx.SemTable.EqualsExprWithDeps(srcOrdering[idx].SimplifiedExpr, order.SimplifiedExpr) ||
+			srcOrdering[idx].Inner.Direction != order.Inner.Direction {
+			return false
+		}
+	}
+	return true
+}
+
 func addOrderingFor(aggrOp *Aggregator) {
 	orderBys := slice.Map(aggrOp.Grouping, func(from GroupBy) OrderBy {
 		return from.AsOrderBy()
@@ -291,18 +335,31 @@ func addGroupByOnRHSOfJoin(root Operator) Operator {
 }
 
 func addLiteralGroupingToRHS(in *ApplyJoin) (Operator, *ApplyResult) {
-	_ = Visit(in.RHS, func(op Operator) error {
-		aggr, isAggr := op.(*Aggregator)
-		if !isAggr {
-			return nil
+	addLiteralGrouping(in.RHS)
+	return in, NoRewrite
+}
+
+func addLiteralGrouping(op Operator) {
+	switch op := op.(type) {
+	case *Aggregator:
+		if op.DT != nil && op.DT.Lateral {
+			// a lateral derived table produces a row for every row of the outer side,
+			// so the aggregation has to return a row even when there is no input
+			return
 		}
-		if len(aggr.Grouping) == 0 {
+		if len(op.Grouping) == 0 {
 			gb := sqlparser.NewFloatLiteral(".0")
-			aggr.Grouping = append(aggr.Grouping, NewGroupBy(gb))
+			op.Grouping = append(op.Grouping, NewGroupBy(gb))
 		}
-		return nil
-	})
-	return in, NoRewrite
+	case *SubQuery:
+		// the inner side of a subquery produces a value for every row of the outer side,
+		// so an aggregation there still has to return a row when there is no input
+		addLiteralGrouping(op.Outer)
+		return
+	}
+	for _, input := range op.Inputs() {
+		addLiteralGrouping(input)
+	}
 }
 
 // prepareForAggregationPushing adds columns needed by an operator to its input.
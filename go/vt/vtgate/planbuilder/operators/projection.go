@@ -47,6 +47,7 @@ type (
 		TableID semantics.TableSet
 		Alias   string
 		Columns sqlparser.Columns
+		Lateral bool
 	}
 )
 
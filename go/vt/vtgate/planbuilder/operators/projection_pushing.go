@@ -126,7 +126,9 @@ func pushProjectionToOuter(ctx *plancontext.PlanningContext, p *Projection, sq *
 		return p, NoRewrite
 	}
 
-	if !reachedPhase(ctx, subquerySettling) {
+	if !reachedPhase(ctx, subquerySettling) || sq.producesValue() {
+		// a correlated subquery that produces a value keeps track of the columns of its
+		// outer side, so we can't replace the outer side with the projection
 		return p, NoRewrite
 	}
 
@@ -90,6 +90,8 @@ func runRewriters(ctx *plancontext.PlanningContext, root Operator) Operator {
 			return tryPushFilter(ctx, in)
 		case *Distinct:
 			return tryPushDistinct(in)
+		case *Window:
+			return tryPushWindow(ctx, in)
 		case *Union:
 			return tryPushUnion(ctx, in)
 		case *SubQueryContainer:
@@ -213,7 +215,10 @@ func pushOrExpandHorizon(ctx *plancontext.PlanningContext, in *Horizon) (Operato
 		!needsOrdering &&
 		!qp.NeedsAggregation() &&
 		!in.selectStatement().IsDistinct() &&
-		in.selectStatement().GetLimit() == nil
+		in.selectStatement().GetLimit() == nil &&
+		(!ctx.SemTable.QuerySignature.WindowFunctions || windowFunctionsPushable(func(expr sqlparser.Expr) bool {
+			return exprHasUniqueVindex(ctx, expr)
+		}, in.selectStatement()))
 
 	if canPush {
 		return Swap(in, rb, "push horizon into route")
@@ -532,6 +537,10 @@ func tryPushOrdering(ctx *plancontext.PlanningContext, in *Ordering) (Operator,
 	case *Projection:
 		return pushOrderingUnderProjection(ctx, in, src)
 	case *Aggregator:
+		if src.WithRollup {
+			// the super-aggregate rows have to be sorted after they have been produced
+			return in, NoRewrite
+		}
 		if !src.QP.AlignGroupByAndOrderBy(ctx) && !overlaps(ctx, in.Order, src.Grouping) {
 			return in, NoRewrite
 		}
@@ -704,7 +713,7 @@ func tryPushFilter(ctx *plancontext.PlanningContext, in *Filter) (Operator, *App
 		outerTableID := TableID(src.Outer)
 		for _, pred := range in.Predicates {
 			deps := ctx.SemTable.RecursiveDeps(pred)
-			if !deps.IsSolvedBy(outerTableID) {
+			if !deps.IsSolvedBy(outerTableID) || src.usesSubqueryValue(pred) {
 				return in, NoRewrite
 			}
 		}
@@ -717,6 +726,11 @@ func tryPushFilter(ctx *plancontext.PlanningContext, in *Filter) (Operator, *App
 
 func pushFilterUnderProjection(ctx *plancontext.PlanningContext, filter *Filter, projection *Projection) (Operator, *ApplyResult) {
 	for _, p := range filter.Predicates {
+		if sqlparser.ContainsWindowFunction(projection.DT.RewriteExpression(ctx, p)) {
+			// predicates on the results of window functions have to stay above them
+			return filter, NoRewrite
+		}
+
 		cantPush := false
 		_ = sqlparser.Walk(func(node sqlparser.SQLNode) (kontinue bool, err error) {
 			if !mustFetchFromInput(ctx, node) {
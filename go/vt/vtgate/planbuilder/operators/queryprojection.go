@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 
 	"vitess.io/vitess/go/vt/sqlparser"
@@ -85,11 +86,71 @@ type (
 		SubQueryExpression []*SubQuery // Subqueries associated with this aggregation
 
 		PushedDown bool // Whether the aggregation has been pushed down to the next layer
+
+		// HashDistinct is set for DISTINCT aggregations evaluated at the vtgate level when the
+		// input can't be ordered by a single distinct expression. The values already seen are then
+		// tracked using hashing instead of being compared to the previous row.
+		HashDistinct bool
+
+		// ExtraArgs holds the arguments after the first one of aggregations with multiple
+		// arguments evaluated at the vtgate level, such as COUNT(DISTINCT a, b) or GROUP_CONCAT(a, b)
+		ExtraArgs []AggrArg
+
+		// OrderByArgs holds the ORDER BY expressions of a GROUP_CONCAT evaluated at the vtgate level
+		OrderByArgs []AggrArg
+	}
+
+	// AggrArg is an expression needed to evaluate an aggregation at the vtgate level
+	AggrArg struct {
+		Expr      sqlparser.Expr
+		ColOffset int
+		WSOffset  int
 	}
 )
 
 func (aggr Aggr) NeedsWeightString(ctx *plancontext.PlanningContext) bool {
-	return aggr.OpCode.NeedsComparableValues() && ctx.NeedsWeightString(aggr.Func.GetArg())
+	return (aggr.OpCode.NeedsComparableValues() || aggr.HashDistinct) && ctx.NeedsWeightString(aggr.Func.GetArg())
+}
+
+// setHashDistinct marks the aggregation as evaluated using hashing at the vtgate level
+func (aggr *Aggr) setHashDistinct() {
+	aggr.HashDistinct = true
+	aggr.setExtraArgs()
+}
+
+// setExtraArgs keeps track of the arguments after the first one,
+// which are needed when the aggregation is evaluated at the vtgate level
+func (aggr *Aggr) setExtraArgs() {
+	if aggr.ExtraArgs != nil {
+		return
+	}
+	for _, arg := range aggr.Func.GetArgs()[1:] {
+		aggr.ExtraArgs = append(aggr.ExtraArgs, newAggrArg(arg))
+	}
+}
+
+// setOrderByArgs keeps track of the ORDER BY expressions of a GROUP_CONCAT. Column
+// numbers refer to the arguments of the GROUP_CONCAT, just like they do in MySQL.
+func (aggr *Aggr) setOrderByArgs() {
+	gc, ok := aggr.Func.(*sqlparser.GroupConcatExpr)
+	if !ok || aggr.OrderByArgs != nil {
+		return
+	}
+	for _, order := range gc.OrderBy {
+		expr := order.Expr
+		if lit, ok := expr.(*sqlparser.Literal); ok && lit.Type == sqlparser.IntVal {
+			num, err := strconv.Atoi(lit.Val)
+			if err != nil || num < 1 || num > len(gc.Exprs) {
+				panic(vterrors.VT03014(lit.Val, "order clause"))
+			}
+			expr = gc.Exprs[num-1]
+		}
+		aggr.OrderByArgs = append(aggr.OrderByArgs, newAggrArg(expr))
+	}
+}
+
+func newAggrArg(expr sqlparser.Expr) AggrArg {
+	return AggrArg{Expr: expr, ColOffset: -1, WSOffset: -1}
 }
 
 func (aggr Aggr) GetTypeCollation(ctx *plancontext.PlanningContext) evalengine.Type {
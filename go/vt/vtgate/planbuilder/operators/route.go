@@ -75,6 +75,9 @@ type (
 		OpCode      engine.Opcode
 		FoundVindex vindexes.Vindex
 		Cost        Cost
+		// RangeInclusive tells whether the start and the end of the range of Values
+		// are part of the range, when routing with a range of values
+		RangeInclusive [2]bool
 	}
 
 	// Cost is used to make it easy to compare the Cost of two plans with each other
@@ -246,13 +249,14 @@ func copyOption(orig *VindexOption) *VindexOption {
 		colsSeen[k] = v
 	}
 	vo := &VindexOption{
-		Values:      values,
-		ColsSeen:    colsSeen,
-		ValueExprs:  valueExprs,
-		Predicates:  predicates,
-		OpCode:      orig.OpCode,
-		FoundVindex: orig.FoundVindex,
-		Cost:        orig.Cost,
+		Values:         values,
+		ColsSeen:       colsSeen,
+		ValueExprs:     valueExprs,
+		Predicates:     predicates,
+		OpCode:         orig.OpCode,
+		FoundVindex:    orig.FoundVindex,
+		Cost:           orig.Cost,
+		RangeInclusive: orig.RangeInclusive,
 	}
 	return vo
 }
@@ -53,6 +53,14 @@ func pushDerived(ctx *plancontext.PlanningContext, op *Horizon) (Operator, *Appl
 }
 
 func optimizeJoin(ctx *plancontext.PlanningContext, op *Join) (Operator, *ApplyResult) {
+	if _, ok := op.RHS.(*SubQueryContainer); ok && !op.IsInner() && !reachedPhase(ctx, initialPlanning) {
+		// the RHS of an outer join is filtered using subqueries from the ON condition.
+		// we give them a chance to merge with the RHS, so the join can be merged into a single route
+		return op, NoRewrite
+	}
+	if len(op.LateralVars) > 0 || isJSONTable(op.LHS) || isJSONTable(op.RHS) {
+		return optimizeLateralJoin(ctx, op)
+	}
 	return mergeOrJoin(ctx, op.LHS, op.RHS, sqlparser.SplitAndExpression(nil, op.Predicate), op.JoinType)
 }
 
@@ -248,7 +256,7 @@ func findBestJoin(
 				continue
 			}
 			plan := getJoinFor(ctx, planCache, lhs, rhs, joinPredicates)
-			if bestPlan == nil || CostOf(plan) < CostOf(bestPlan) {
+			if bestPlan == nil || cheaper(ctx, plan, bestPlan) {
 				bestPlan = plan
 				// remember which plans we based on, so we can remove them later
 				lIdx = i
@@ -259,6 +267,17 @@ func findBestJoin(
 	return bestPlan, lIdx, rIdx
 }
 
+// cheaper returns true if the first plan is estimated to be cheaper than the second one. The table statistics
+// are used to estimate the costs when they are available, otherwise the routing costs of the plans are compared.
+func cheaper(ctx *plancontext.PlanningContext, a, b Operator) bool {
+	aCost, aOK := estimateCost(ctx, a)
+	bCost, bOK := estimateCost(ctx, b)
+	if aOK && bOK {
+		return aCost.cost < bCost.cost
+	}
+	return CostOf(a) < CostOf(b)
+}
+
 func getJoinFor(ctx *plancontext.PlanningContext, cm opCacheMap, lhs, rhs Operator, joinPredicates []sqlparser.Expr) Operator {
 	solves := tableSetPair{left: TableID(lhs), right: TableID(rhs)}
 	cachedPlan := cm[solves]
@@ -317,9 +336,91 @@ func mergeOrJoin(ctx *plancontext.PlanningContext, lhs, rhs Operator, joinPredic
 		join.AddJoinPredicate(ctx, pred)
 	}
 
+	if hj := planHashJoin(ctx, lhs, rhs, join, joinPredicates); hj != nil {
+		ctx.SemTable.QuerySignature.HashJoin = true
+		return hj, Rewrote("use a hash join because it is estimated to be cheaper than a nested loop join")
+	}
+
 	return join, Rewrote("logical join to applyJoin ")
 }
 
+// planHashJoin returns a hash join for the inputs if it is estimated to be cheaper than the apply join.
+// The apply join sends one query to the RHS per row coming from the LHS, while the hash join reads both sides once.
+// When we have table statistics, the estimated costs of both joins are compared. Otherwise, a hash join is only
+// used when the query has the ALLOW_HASH_JOIN directive, and we compare the estimated number of rows
+// the two strategies have to fetch from the RHS.
+func planHashJoin(ctx *plancontext.PlanningContext, lhs, rhs Operator, applyJoin *ApplyJoin, joinPredicates []sqlparser.Expr) *HashJoin {
+	hj := NewHashJoin(lhs, rhs, !applyJoin.JoinType.IsInner())
+	var rhsKeys []sqlparser.Expr
+	for _, pred := range joinPredicates {
+		if cmp, ok := hj.hashComparison(ctx, pred); ok {
+			rhsKeys = append(rhsKeys, cmp.RHS)
+			continue
+		}
+		if !ctx.SemTable.RecursiveDeps(pred).IsSolvedBy(TableID(hj)) {
+			return nil
+		}
+	}
+	if len(rhsKeys) == 0 {
+		// without an equality between the two sides, every row would be compared with every other row
+		return nil
+	}
+	for _, pred := range joinPredicates {
+		hj.AddJoinPredicate(ctx, pred)
+	}
+
+	hashCost, hashOK := estimateCost(ctx, hj)
+	applyCost, applyOK := estimateCost(ctx, applyJoin)
+	if hashOK && applyOK {
+		if hashCost.cost < applyCost.cost {
+			return hj
+		}
+		return nil
+	}
+
+	cmt, ok := ctx.Statement.(sqlparser.Commented)
+	if !ok || !cmt.GetParsedComments().Directives().IsSet(sqlparser.DirectiveAllowHashJoin) {
+		return nil
+	}
+
+	// if the join column is a vindex column on the RHS, every query the apply join sends will be routed to a single shard
+	costPerRow := CostOf(rhs)
+	for _, key := range rhsKeys {
+		if findColumnVindex(ctx, rhs, key) != nil {
+			costPerRow = 1
+			break
+		}
+	}
+	if estimatedRows(lhs)*costPerRow > estimatedRows(rhs) {
+		return hj
+	}
+	return nil
+}
+
+// estimatedRows is a very rough estimate of the number of rows an operator will return,
+// based on the routing of the routes in it
+func estimatedRows(op Operator) (rows int) {
+	_ = Visit(op, func(op Operator) error {
+		route, ok := op.(*Route)
+		if !ok {
+			return nil
+		}
+		switch route.Routing.OpCode() {
+		case engine.None:
+		case engine.EqualUnique, engine.Next:
+			rows += 1
+		case engine.Equal, engine.IN, engine.MultiEqual, engine.SubShard:
+			rows += 10
+		case engine.Range:
+			rows += 100
+		default:
+			rows += 1000
+		}
+		return nil
+	})
+	return max(rows, 1)
+}
+
 func operatorsToRoutes(a, b Operator) (*Route, *Route) {
 	aRoute, ok := a.(*Route)
 	if !ok {
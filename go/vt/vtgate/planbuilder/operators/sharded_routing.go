@@ -167,6 +167,7 @@ func (tr *ShardedRouting) UpdateRoutingParams(_ *plancontext.PlanningContext, rp
 	if tr.Selected != nil {
 		rp.Vindex = tr.Selected.FoundVindex
 		rp.Values = tr.Selected.Values
+		rp.RangeInclusive = tr.Selected.RangeInclusive
 	}
 }
 
@@ -229,6 +230,10 @@ func (tr *ShardedRouting) searchForNewVindexes(ctx *plancontext.PlanningContext,
 	case *sqlparser.IsExpr:
 		found := tr.planIsExpr(ctx, node)
 		newVindexFound = newVindexFound || found
+
+	case *sqlparser.BetweenExpr:
+		found := tr.planBetween(ctx, node)
+		newVindexFound = newVindexFound || found
 	}
 
 	return nil, newVindexFound
@@ -245,7 +250,9 @@ func (tr *ShardedRouting) planComparison(ctx *plancontext.PlanningContext, cmp *
 	case sqlparser.LikeOp:
 		found := tr.planLikeOp(ctx, cmp)
 		return nil, found
-
+	case sqlparser.LessThanOp, sqlparser.LessEqualOp, sqlparser.GreaterThanOp, sqlparser.GreaterEqualOp:
+		found := tr.planRangeOp(ctx, cmp)
+		return nil, found
 	}
 	return nil, false
 }
@@ -324,6 +331,136 @@ func (tr *ShardedRouting) planLikeOp(ctx *plancontext.PlanningContext, node *sql
 	return tr.haveMatchingVindex(ctx, node, vdValue, column, val, selectEqual, vdx)
 }
 
+func (tr *ShardedRouting) planRangeOp(ctx *plancontext.PlanningContext, cmp *sqlparser.ComparisonExpr) bool {
+	op := cmp.Operator
+	column, ok := cmp.Left.(*sqlparser.ColName)
+	vdValue := cmp.Right
+	if !ok {
+		column, ok = cmp.Right.(*sqlparser.ColName)
+		if !ok {
+			return false
+		}
+		vdValue = cmp.Left
+		// `10 < id` is `id > 10`
+		switch op {
+		case sqlparser.LessThanOp:
+			op = sqlparser.GreaterThanOp
+		case sqlparser.LessEqualOp:
+			op = sqlparser.GreaterEqualOp
+		case sqlparser.GreaterThanOp:
+			op = sqlparser.LessThanOp
+		case sqlparser.GreaterEqualOp:
+			op = sqlparser.LessEqualOp
+		}
+	}
+
+	switch op {
+	case sqlparser.LessThanOp, sqlparser.LessEqualOp:
+		return tr.planRange(ctx, cmp, column, nil, vdValue, false, op == sqlparser.LessEqualOp)
+	default:
+		return tr.planRange(ctx, cmp, column, vdValue, nil, op == sqlparser.GreaterEqualOp, false)
+	}
+}
+
+func (tr *ShardedRouting) planBetween(ctx *plancontext.PlanningContext, node *sqlparser.BetweenExpr) bool {
+	column, ok := node.Left.(*sqlparser.ColName)
+	if !ok || !node.IsBetween {
+		return false
+	}
+	return tr.planRange(ctx, node, column, node.From, node.To, true, true)
+}
+
+// planRange adds the options of routing with the ordered vindexes of the column, using the range
+// of values between start and end. A nil start or end leaves the range open on that side.
+func (tr *ShardedRouting) planRange(
+	ctx *plancontext.PlanningContext,
+	node sqlparser.Expr,
+	column *sqlparser.ColName,
+	start, end sqlparser.Expr,
+	startInclusive, endInclusive bool,
+) bool {
+	values := []evalengine.Expr{evalengine.NullExpr, evalengine.NullExpr}
+	var valueExprs []sqlparser.Expr
+	for i, expr := range []sqlparser.Expr{start, end} {
+		if expr == nil {
+			continue
+		}
+		if values[i] = makeEvalEngineExpr(ctx, expr); values[i] == nil {
+			return false
+		}
+		valueExprs = append(valueExprs, expr)
+	}
+
+	newVindexFound := false
+	for _, v := range tr.VindexPreds {
+		if !ctx.SemTable.DirectDeps(column).IsSolvedBy(v.TableID) {
+			continue
+		}
+		if !isOrderedFor(ctx, v.ColVindex.Vindex, column) || !column.Name.Equal(v.ColVindex.Columns[0]) {
+			continue
+		}
+
+		vo := &VindexOption{
+			Values:         values,
+			ValueExprs:     valueExprs,
+			Predicates:     []sqlparser.Expr{node},
+			OpCode:         engine.Range,
+			FoundVindex:    v.ColVindex.Vindex,
+			Cost:           costFor(v.ColVindex, engine.Range),
+			Ready:          true,
+			RangeInclusive: [2]bool{startInclusive, endInclusive},
+		}
+		// `id >= 10 and id < 20` routes with the range of values between 10 and 20
+		for _, other := range v.Options {
+			if combined := combineRanges(other, vo); combined != nil {
+				vo = combined
+				break
+			}
+		}
+		v.Options = append(v.Options, vo)
+		newVindexFound = true
+	}
+	return newVindexFound
+}
+
+// isOrderedFor returns whether the vindex keeps the order of the values of the column.
+// The binary vindex compares the bytes of the values, which is only the order of the
+// column when its collation is binary: with the other collations, 'a' can equal 'A'
+// or 'a ', whose bytes sort differently.
+func isOrderedFor(ctx *plancontext.PlanningContext, vindex vindexes.Vindex, column *sqlparser.ColName) bool {
+	if _, ok := vindex.(vindexes.Ordered); !ok {
+		return false
+	}
+	if _, ok := vindex.(*vindexes.Binary); ok {
+		typ, found := ctx.TypeForExpr(column)
+		return found && typ.Collation() == collations.CollationBinaryID
+	}
+	return true
+}
+
+// combineRanges returns the option routing with the intersection of the ranges of values of both
+// options, when each side of the range is only bounded by one of them.
+func combineRanges(a, b *VindexOption) *VindexOption {
+	if a.OpCode != engine.Range || a.FoundVindex != b.FoundVindex {
+		return nil
+	}
+	combined := copyOption(a)
+	combined.Ready = true
+	combined.ValueExprs = append(combined.ValueExprs, b.ValueExprs...)
+	combined.Predicates = append(combined.Predicates, b.Predicates...)
+	for i := range combined.Values {
+		aOpen, bOpen := a.Values[i] == evalengine.NullExpr, b.Values[i] == evalengine.NullExpr
+		switch {
+		case !aOpen && !bOpen:
+			return nil
+		case aOpen:
+			combined.Values[i] = b.Values[i]
+			combined.RangeInclusive[i] = b.RangeInclusive[i]
+		}
+	}
+	return combined
+}
+
 func (tr *ShardedRouting) Cost() int {
 	switch tr.RouteOpCode {
 	case engine.EqualUnique:
@@ -334,6 +471,8 @@ func (tr *ShardedRouting) Cost() int {
 		return 10
 	case engine.MultiEqual:
 		return 10
+	case engine.Range:
+		return 15
 	case engine.Scatter:
 		return 20
 	default:
@@ -662,7 +801,7 @@ func tryMergeShardedRouting(
 		// can merge via join predicates instead.
 		fallthrough
 
-	case engine.Scatter, engine.IN, engine.None:
+	case engine.Scatter, engine.IN, engine.Range, engine.None:
 		if len(joinPredicates) == 0 {
 			// If we are doing two Scatters, we have to make sure that the
 			// joins are on the correct vindex to allow them to be merged
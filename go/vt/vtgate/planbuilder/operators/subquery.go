@@ -25,6 +25,7 @@ import (
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/engine/opcode"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
 	"vitess.io/vitess/go/vt/vtgate/semantics"
 )
@@ -55,6 +56,24 @@ type SubQuery struct {
 
 	// IsArgument is set to true if the subquery puts the
 	IsArgument bool
+
+	// usedInOuterJoin is set when the subquery has been pulled out of the ON condition of an outer join.
+	// The predicate using the result of the subquery is kept on the join, so the subquery can't be merged
+	// or pushed down, and is evaluated before the join.
+	usedInOuterJoin bool
+
+	// Fields related to correlated subqueries that could not be merged with the outer query.
+	// These are evaluated at the vtgate, by running the subquery once for every row of the outer side.
+	Apply bool
+	// ApplyFilter is set when the subquery is used for filtering. It is the predicate
+	// that is evaluated using the outer row and the result of the subquery.
+	ApplyFilter            sqlparser.Expr
+	ApplyFilterWithOffsets evalengine.Expr
+	// Columns and ColumnOffsets are set when the subquery is used as an argument.
+	// ColumnOffsets holds for every column the offset in the outer that it is copied from,
+	// and the column holding the value of the subquery is set to -1
+	Columns       []*sqlparser.AliasedExpr
+	ColumnOffsets []int
 }
 
 func (sq *SubQuery) planOffsets(ctx *plancontext.PlanningContext) Operator {
@@ -69,6 +88,19 @@ func (sq *SubQuery) planOffsets(ctx *plancontext.PlanningContext) Operator {
 			sq.Vars[lhsExpr.Name] = offset
 		}
 	}
+
+	if sq.ApplyFilter != nil {
+		rewritten := useOffsets(ctx, sq.ApplyFilter, sq)
+		eexpr, err := evalengine.Translate(rewritten, &evalengine.Config{
+			ResolveType: ctx.TypeForExpr,
+			Collation:   ctx.SemTable.Collation,
+			Environment: ctx.VSchema.Environment(),
+		})
+		if err != nil {
+			panic(err)
+		}
+		sq.ApplyFilterWithOffsets = eexpr
+	}
 	return nil
 }
 
@@ -126,6 +158,8 @@ func (sq *SubQuery) Clone(inputs []Operator) Operator {
 	klone.JoinColumns = slices.Clone(sq.JoinColumns)
 	klone.Vars = maps.Clone(sq.Vars)
 	klone.Predicates = sqlparser.Clone(sq.Predicates)
+	klone.Columns = slices.Clone(sq.Columns)
+	klone.ColumnOffsets = slices.Clone(sq.ColumnOffsets)
 	return &klone
 }
 
@@ -162,6 +196,9 @@ func (sq *SubQuery) ShortDescription() string {
 	} else {
 		typ = "FILTER"
 	}
+	if sq.Apply {
+		typ = "APPLY " + typ
+	}
 	var pred string
 
 	if len(sq.Predicates) > 0 || sq.OuterPredicate != nil {
@@ -177,28 +214,121 @@ func (sq *SubQuery) AddPredicate(ctx *plancontext.PlanningContext, expr sqlparse
 }
 
 func (sq *SubQuery) AddColumn(ctx *plancontext.PlanningContext, reuseExisting bool, addToGroupBy bool, ae *sqlparser.AliasedExpr) int {
+	if sq.producesValue() {
+		return sq.addApplyColumn(ctx, reuseExisting, addToGroupBy, ae)
+	}
 	ae = sqlparser.Clone(ae)
 	// we need to rewrite the column name to an argument if it's the same as the subquery column name
 	ae.Expr = rewriteColNameToArgument(ctx, ae.Expr, []*SubQuery{sq}, sq)
 	return sq.Outer.AddColumn(ctx, reuseExisting, addToGroupBy, ae)
 }
 
+// addApplyColumn adds a column to a correlated subquery that is used as an argument.
+// The value of the subquery is produced by this operator, and all other columns are fetched from the outer side
+func (sq *SubQuery) addApplyColumn(ctx *plancontext.PlanningContext, reuse bool, addToGroupBy bool, ae *sqlparser.AliasedExpr) int {
+	if reuse {
+		if offset := sq.FindCol(ctx, ae.Expr, false); offset >= 0 {
+			return offset
+		}
+	}
+
+	offset := -1
+	if !sq.isSubqueryValue(ae.Expr) {
+		offset = sq.Outer.AddColumn(ctx, reuse, addToGroupBy, ae)
+	}
+	sq.Columns = append(sq.Columns, ae)
+	sq.ColumnOffsets = append(sq.ColumnOffsets, offset)
+	return len(sq.Columns) - 1
+}
+
 func (sq *SubQuery) AddWSColumn(ctx *plancontext.PlanningContext, offset int, underRoute bool) int {
-	return sq.Outer.AddWSColumn(ctx, offset, underRoute)
+	if !sq.producesValue() {
+		return sq.Outer.AddWSColumn(ctx, offset, underRoute)
+	}
+	if offset >= len(sq.Columns) {
+		panic(vterrors.VT13001("offset out of range"))
+	}
+
+	outerOffset := sq.ColumnOffsets[offset]
+	if outerOffset < 0 {
+		panic(vterrors.VT12001("weight_string of a correlated subquery result"))
+	}
+
+	wsExpr := weightStringFor(sq.Columns[offset].Expr)
+	if idx := sq.FindCol(ctx, wsExpr, underRoute); idx >= 0 {
+		return idx
+	}
+
+	wsOffset := sq.Outer.AddWSColumn(ctx, outerOffset, underRoute)
+	sq.Columns = append(sq.Columns, aeWrap(wsExpr))
+	sq.ColumnOffsets = append(sq.ColumnOffsets, wsOffset)
+	return len(sq.Columns) - 1
 }
 
 func (sq *SubQuery) FindCol(ctx *plancontext.PlanningContext, expr sqlparser.Expr, underRoute bool) int {
-	return sq.Outer.FindCol(ctx, expr, underRoute)
+	if !sq.producesValue() {
+		return sq.Outer.FindCol(ctx, expr, underRoute)
+	}
+	isValue := sq.isSubqueryValue(expr)
+	for idx, col := range sq.Columns {
+		if isValue && sq.ColumnOffsets[idx] < 0 {
+			return idx
+		}
+		if !isValue && ctx.SemTable.EqualsExprWithDeps(col.Expr, expr) {
+			return idx
+		}
+	}
+	return -1
 }
 
 func (sq *SubQuery) GetColumns(ctx *plancontext.PlanningContext) []*sqlparser.AliasedExpr {
+	if sq.producesValue() {
+		return sq.Columns
+	}
 	return sq.Outer.GetColumns(ctx)
 }
 
 func (sq *SubQuery) GetSelectExprs(ctx *plancontext.PlanningContext) sqlparser.SelectExprs {
+	if sq.producesValue() {
+		return transformColumnsToSelectExprs(ctx, sq)
+	}
 	return sq.Outer.GetSelectExprs(ctx)
 }
 
+// producesValue returns true for correlated subqueries evaluated at the vtgate that are used as an argument.
+// These add the value of the subquery as a column to the rows of the outer side.
+func (sq *SubQuery) producesValue() bool {
+	return sq.Apply && sq.IsArgument
+}
+
+// isSubqueryValue returns true if the expression is the column or argument
+// that represents the value of this subquery in the outer query
+func (sq *SubQuery) isSubqueryValue(expr sqlparser.Expr) bool {
+	switch expr := expr.(type) {
+	case *sqlparser.ColName:
+		return expr.Qualifier.IsEmpty() && expr.Name.String() == sq.ArgName
+	case *sqlparser.Argument:
+		return expr.Name == sq.ArgName || (sq.HasValuesName != "" && expr.Name == sq.HasValuesName)
+	}
+	return false
+}
+
+// usesSubqueryValue returns true if the expression uses the value produced by
+// a correlated subquery that is evaluated at the vtgate
+func (sq *SubQuery) usesSubqueryValue(expr sqlparser.Expr) bool {
+	if !sq.Apply {
+		return false
+	}
+	found := false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if e, ok := node.(sqlparser.Expr); ok && sq.isSubqueryValue(e) {
+			found = true
+		}
+		return !found, nil
+	}, expr)
+	return found
+}
+
 // GetMergePredicates returns the predicates that we can use to try to merge this subquery with the outer query.
 func (sq *SubQuery) GetMergePredicates() []sqlparser.Expr {
 	if sq.OuterPredicate != nil {
@@ -213,21 +343,53 @@ func (sq *SubQuery) settle(ctx *plancontext.PlanningContext, outer Operator) Ope
 	if !sq.TopLevel && sq.correlated {
 		panic(subqueryNotAtTopErr)
 	}
-	if sq.correlated && sq.FilterType != opcode.PulloutExists {
-		panic(correlatedSubqueryErr)
+	if sq.correlated && !sq.usesOuterColumns(ctx) {
+		// the subquery has been pushed to where the values it needs from the
+		// outer query are already available as arguments, so we can plan it
+		// as if it was not correlated with its outer side
+		sq.correlated = false
+		sq.Predicates = nil
+		sq.JoinColumns = nil
+	}
+	if sq.correlated && (sq.IsArgument || sq.FilterType != opcode.PulloutExists) {
+		return sq.settleCorrelated(ctx, outer)
 	}
 	if sq.IsArgument {
-		if len(sq.GetMergePredicates()) > 0 {
-			// this means that we have a correlated subquery on our hands
-			panic(correlatedSubqueryErr)
-		}
 		sq.SubqueryValueName = sq.ArgName
 		return outer
 	}
+	if sq.usedInOuterJoin {
+		if sq.FilterType != opcode.PulloutExists {
+			sq.SubqueryValueName = sq.ArgName
+		}
+		return outer
+	}
 	return sq.settleFilter(ctx, outer)
 }
 
-var correlatedSubqueryErr = vterrors.VT12001("correlated subquery is only supported for EXISTS")
+// pullOutOfOuterJoin is used for uncorrelated subqueries in the ON condition of an outer join.
+// It returns the predicates that use the result of the subquery instead of the subquery itself,
+// so they can be evaluated as part of the join.
+func (sq *SubQuery) pullOutOfOuterJoin(ctx *plancontext.PlanningContext) []sqlparser.Expr {
+	sq.usedInOuterJoin = true
+	return sq.filterPredicates(ctx)
+}
+
+// usesOuterColumns returns true if the subquery needs values from the outer side. When a subquery
+// is pushed down, its join predicates can be rewritten to use arguments instead of the outer columns.
+func (sq *SubQuery) usesOuterColumns(ctx *plancontext.PlanningContext) bool {
+	if len(sq.Predicates) == 0 {
+		// the subquery is correlated through something else than its predicates
+		return true
+	}
+	innerID := TableID(sq.Subquery)
+	return slices.ContainsFunc(sq.Predicates, func(pred sqlparser.Expr) bool {
+		return !ctx.SemTable.RecursiveDeps(pred).IsSolvedBy(innerID)
+	})
+}
+
+var correlatedSubqueryErr = vterrors.VT12001("correlated subquery with outer references that can not be passed as arguments")
+var correlatedInValueErr = vterrors.VT12001("correlated IN subquery used as a value")
 var subqueryNotAtTopErr = vterrors.VT12001("unmergable subquery can not be inside complex expression")
 
 func (sq *SubQuery) addLimit() {
@@ -239,15 +401,57 @@ func (sq *SubQuery) addLimit() {
 	}
 }
 
-func (sq *SubQuery) settleFilter(ctx *plancontext.PlanningContext, outer Operator) Operator {
-	if len(sq.Predicates) > 0 {
-		if sq.FilterType != opcode.PulloutExists {
+// settleCorrelated plans a correlated subquery that could not be merged with the outer query.
+// The subquery is evaluated at the vtgate once for every row of the outer side, with the
+// outer columns used in the join predicates passed to it as arguments.
+func (sq *SubQuery) settleCorrelated(ctx *plancontext.PlanningContext, outer Operator) Operator {
+	if len(sq.Predicates) == 0 {
+		// the outer columns are used somewhere else than in the predicates of
+		// the subquery, so we have no way of passing them to the subquery
+		panic(correlatedSubqueryErr)
+	}
+	// all the outer columns have to be fetched from the outer side, one row at a time
+	availableID := TableID(outer).Merge(TableID(sq.Subquery))
+	for _, pred := range sq.Predicates {
+		if sqlparser.ContainsAggregation(pred) || !ctx.SemTable.RecursiveDeps(pred).IsSolvedBy(availableID) {
 			panic(correlatedSubqueryErr)
 		}
+	}
+	sq.Apply = true
+
+	if !sq.IsArgument {
+		sq.ApplyFilter = sqlparser.AndExpressions(sq.filterPredicates(ctx)...)
+		return outer
+	}
+
+	switch sq.FilterType {
+	case opcode.PulloutIn, opcode.PulloutNotIn:
+		panic(correlatedInValueErr)
+	case opcode.PulloutExists:
+		sq.addLimit()
+		sq.HasValuesName = sq.ArgName
+	default:
+		sq.SubqueryValueName = sq.ArgName
+	}
+
+	// columns added to the outer before the subquery was settled keep their offsets
+	for idx, col := range outer.GetColumns(ctx) {
+		sq.Columns = append(sq.Columns, col)
+		sq.ColumnOffsets = append(sq.ColumnOffsets, idx)
+	}
+	return outer
+}
+
+func (sq *SubQuery) settleFilter(ctx *plancontext.PlanningContext, outer Operator) Operator {
+	if len(sq.Predicates) > 0 {
 		sq.addLimit()
 		return outer
 	}
+	return newFilter(outer, sq.filterPredicates(ctx)...)
+}
 
+// filterPredicates returns the predicates that filter the outer query using the result of the subquery
+func (sq *SubQuery) filterPredicates(ctx *plancontext.PlanningContext) []sqlparser.Expr {
 	hasValuesArg := func() string {
 		s := ctx.ReservedVars.ReserveVariable(string(sqlparser.HasValueSubQueryBaseName))
 		sq.HasValuesName = s
@@ -310,7 +514,7 @@ func (sq *SubQuery) settleFilter(ctx *plancontext.PlanningContext, outer Operato
 		predicates = append(predicates, rhsPred)
 		sq.SubqueryValueName = sq.ArgName
 	}
-	return newFilter(outer, predicates...)
+	return predicates
 }
 
 func dontEnterSubqueries(node, _ sqlparser.SQLNode) bool {
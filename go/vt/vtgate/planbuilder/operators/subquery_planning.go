@@ -42,6 +42,11 @@ func isMergeable(ctx *plancontext.PlanningContext, query sqlparser.SelectStateme
 
 	switch node := query.(type) {
 	case *sqlparser.Select:
+		if !windowFunctionsPushable(validVindex, node.SelectExprs) || !windowFunctionsPushable(validVindex, node.OrderBy) {
+			// window functions need to see all the rows of a partition
+			return false
+		}
+
 		if node.GroupBy != nil && len(node.GroupBy.Exprs) > 0 {
 			// iff we are grouping, we need to check that we can perform the grouping inside a single shard, and we check that
 			// by checking that one of the grouping expressions used is a unique single column vindex.
@@ -557,6 +562,10 @@ func mergingIsBlocked(subQuery *SubQuery, updOp *Update) bool {
 }
 
 func pushOrMerge(ctx *plancontext.PlanningContext, outer Operator, inner *SubQuery) (Operator, *ApplyResult) {
+	if inner.usedInOuterJoin {
+		// the join predicate is using the arguments produced by this subquery
+		return outer, NoRewrite
+	}
 	switch o := outer.(type) {
 	case *Route:
 		return tryMergeSubQuery(ctx, inner, o)
@@ -103,7 +103,12 @@ func createOperatorFromUpdate(ctx *plancontext.PlanningContext, updStmt *sqlpars
 	// slower, because it does a selection and then creates an update statement wherein we have to
 	// list all the primary key values.
 	if updateWithInputPlanningRequired(ctx, childFks, parentFks, updStmt) {
-		return createUpdateWithInputOp(ctx, updStmt)
+		return createUpdateWithInputOp(ctx, updStmt, sqlparser.ForUpdateLock)
+	}
+	// The rows selected to update vindex values are locked in share mode, like when
+	// the update is split into a DML with input after being planned as a route.
+	if isVindexUpdateWithoutOrder(ctx, updStmt) {
+		return createUpdateWithInputOp(ctx, updStmt, sqlparser.ShareModeLock)
 	}
 
 	var updClone *sqlparser.Update
@@ -144,6 +149,31 @@ func updateWithInputPlanningRequired(
 	return false
 }
 
+// isVindexUpdateWithoutOrder returns true for an update that changes vindex columns and uses LIMIT without ORDER BY.
+// The rows read to find the old vindex values might not be the rows that get updated, so we
+// select the primary keys of the rows to update first, and then update them using the primary keys.
+func isVindexUpdateWithoutOrder(ctx *plancontext.PlanningContext, updateStmt *sqlparser.Update) bool {
+	if updateStmt.Limit == nil || len(updateStmt.OrderBy) > 0 {
+		return false
+	}
+	for _, ue := range updateStmt.Exprs {
+		tblInfo, err := ctx.SemTable.TableInfoForExpr(ue.Name)
+		if err != nil {
+			panic(err)
+		}
+		vTbl := tblInfo.GetVindexTable()
+		if vTbl == nil || !vTbl.Keyspace.Sharded || len(vTbl.PrimaryKey) == 0 {
+			continue
+		}
+		for _, cv := range vTbl.ColumnVindexes {
+			if slices.ContainsFunc(cv.Columns, ue.Name.Name.Equal) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func isMultiTargetUpdate(ctx *plancontext.PlanningContext, updateStmt *sqlparser.Update) bool {
 	var targetTS semantics.TableSet
 	for _, ue := range updateStmt.Exprs {
@@ -160,7 +190,7 @@ type updColumn struct {
 
 type updList []updColumn
 
-func createUpdateWithInputOp(ctx *plancontext.PlanningContext, upd *sqlparser.Update) (op Operator) {
+func createUpdateWithInputOp(ctx *plancontext.PlanningContext, upd *sqlparser.Update, lock sqlparser.Lock) (op Operator) {
 	updClone := ctx.SemTable.Clone(upd).(*sqlparser.Update)
 	upd.Limit = nil
 
@@ -180,7 +210,7 @@ func createUpdateWithInputOp(ctx *plancontext.PlanningContext, upd *sqlparser.Up
 		Where:   updClone.Where,
 		OrderBy: updClone.OrderBy,
 		Limit:   updClone.Limit,
-		Lock:    sqlparser.ForUpdateLock,
+		Lock:    lock,
 	}
 
 	// now map the operator, column list and update list
@@ -256,6 +286,9 @@ func createUpdateOpWithTarget(ctx *plancontext.PlanningContext, updStmt *sqlpars
 		panic(vterrors.VT13001(err.Error()))
 	}
 	vTbl := ti.GetVindexTable()
+	if len(vTbl.PrimaryKey) == 0 {
+		panic(vterrors.VT09015())
+	}
 	tblName, err := ti.Name()
 	if err != nil {
 		panic(err)
@@ -0,0 +1,310 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operators
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"vitess.io/vitess/go/slice"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+)
+
+type (
+	// Window evaluates window functions at the vtgate level.
+	// All the window functions of a Window share the same PARTITION BY and ORDER BY,
+	// and the input of the operator has to be sorted by the partition and order expressions.
+	// Window functions that use different window specifications are evaluated by stacked Window operators.
+	Window struct {
+		Source Operator
+
+		PartitionBy []sqlparser.Expr
+		OrderBy     []OrderBy
+		Functions   []*WindowFunc
+
+		// Columns are the columns produced by this operator
+		Columns []*sqlparser.AliasedExpr
+		// ColumnOffsets holds for every column the offset in the input that it is copied from.
+		// Columns produced by a window function are set to -1
+		ColumnOffsets []int
+
+		// These are filled in during offset planning
+		PartitionOffsets, PartitionWSOffsets []int
+		OrderOffsets, OrderWSOffsets         []int
+
+		offsetPlanned bool
+	}
+
+	// WindowFunc is a window function evaluated by the Window operator
+	WindowFunc struct {
+		Expr sqlparser.Expr
+
+		// ColOffset is the offset of the function in the columns of the Window operator,
+		// or -1 when the function has not been requested yet
+		ColOffset int
+
+		// These are filled in during offset planning
+		ArgOffset, DefaultOffset int
+	}
+)
+
+// newWindow creates a Window operator for the window functions given,
+// which are all expected to use the same window specification
+func newWindow(src Operator, spec *sqlparser.WindowSpecification, functions []sqlparser.Expr) *Window {
+	w := &Window{
+		Source:      src,
+		PartitionBy: slices.Clone(spec.PartitionClause),
+	}
+	for _, order := range spec.OrderClause {
+		w.OrderBy = append(w.OrderBy, OrderBy{
+			Inner:          order,
+			SimplifiedExpr: order.Expr,
+		})
+	}
+	for _, fn := range functions {
+		w.Functions = append(w.Functions, &WindowFunc{
+			Expr:          fn,
+			ColOffset:     -1,
+			ArgOffset:     -1,
+			DefaultOffset: -1,
+		})
+	}
+	return w
+}
+
+func (w *Window) Clone(inputs []Operator) Operator {
+	kopy := *w
+	kopy.Source = inputs[0]
+	kopy.PartitionBy = slices.Clone(w.PartitionBy)
+	kopy.OrderBy = slices.Clone(w.OrderBy)
+	kopy.Functions = slice.Map(w.Functions, func(from *WindowFunc) *WindowFunc {
+		fn := *from
+		return &fn
+	})
+	kopy.Columns = slices.Clone(w.Columns)
+	kopy.ColumnOffsets = slices.Clone(w.ColumnOffsets)
+	kopy.PartitionOffsets = slices.Clone(w.PartitionOffsets)
+	kopy.PartitionWSOffsets = slices.Clone(w.PartitionWSOffsets)
+	kopy.OrderOffsets = slices.Clone(w.OrderOffsets)
+	kopy.OrderWSOffsets = slices.Clone(w.OrderWSOffsets)
+	return &kopy
+}
+
+func (w *Window) Inputs() []Operator {
+	return []Operator{w.Source}
+}
+
+func (w *Window) SetInputs(operators []Operator) {
+	w.Source = operators[0]
+}
+
+func (w *Window) AddPredicate(_ *plancontext.PlanningContext, expr sqlparser.Expr) Operator {
+	// the window functions have to see all the rows of a partition,
+	// so predicates can't be pushed below this operator
+	return newFilter(w, expr)
+}
+
+func (w *Window) findFunction(ctx *plancontext.PlanningContext, expr sqlparser.Expr) *WindowFunc {
+	for _, fn := range w.Functions {
+		if ctx.SemTable.EqualsExprWithDeps(fn.Expr, expr) {
+			return fn
+		}
+	}
+	return nil
+}
+
+func (w *Window) AddColumn(ctx *plancontext.PlanningContext, reuse bool, gb bool, ae *sqlparser.AliasedExpr) int {
+	if reuse {
+		if offset := w.FindCol(ctx, ae.Expr, false); offset >= 0 {
+			return offset
+		}
+	}
+
+	if fn := w.findFunction(ctx, ae.Expr); fn != nil {
+		if fn.ColOffset < 0 {
+			fn.ColOffset = len(w.Columns)
+			w.Columns = append(w.Columns, ae)
+			w.ColumnOffsets = append(w.ColumnOffsets, -1)
+		}
+		return fn.ColOffset
+	}
+
+	offset := w.Source.AddColumn(ctx, reuse, gb, ae)
+	w.Columns = append(w.Columns, ae)
+	w.ColumnOffsets = append(w.ColumnOffsets, offset)
+	return len(w.Columns) - 1
+}
+
+func (w *Window) AddWSColumn(ctx *plancontext.PlanningContext, offset int, underRoute bool) int {
+	if offset >= len(w.Columns) {
+		panic(vterrors.VT13001("offset out of range"))
+	}
+
+	inputOffset := w.ColumnOffsets[offset]
+	if inputOffset < 0 {
+		panic(vterrors.VT12001(fmt.Sprintf("weight_string of a window function result: %s", sqlparser.String(w.Columns[offset].Expr))))
+	}
+
+	wsExpr := weightStringFor(w.Columns[offset].Expr)
+	if idx := w.FindCol(ctx, wsExpr, underRoute); idx >= 0 {
+		return idx
+	}
+
+	wsOffset := w.Source.AddWSColumn(ctx, inputOffset, underRoute)
+	w.Columns = append(w.Columns, aeWrap(wsExpr))
+	w.ColumnOffsets = append(w.ColumnOffsets, wsOffset)
+	return len(w.Columns) - 1
+}
+
+func (w *Window) FindCol(ctx *plancontext.PlanningContext, expr sqlparser.Expr, _ bool) int {
+	offset, found := canReuseColumn(ctx, w.Columns, expr, func(ae *sqlparser.AliasedExpr) sqlparser.Expr {
+		return ae.Expr
+	})
+	if !found {
+		return -1
+	}
+	return offset
+}
+
+func (w *Window) GetColumns(*plancontext.PlanningContext) []*sqlparser.AliasedExpr {
+	return w.Columns
+}
+
+func (w *Window) GetSelectExprs(ctx *plancontext.PlanningContext) sqlparser.SelectExprs {
+	return transformColumnsToSelectExprs(ctx, w)
+}
+
+// GetOrdering returns the ordering of the input - the Window operator keeps the order of the rows it receives
+func (w *Window) GetOrdering(ctx *plancontext.PlanningContext) []OrderBy {
+	return w.Source.GetOrdering(ctx)
+}
+
+// requiredOrdering returns the ordering the input of the Window operator has to be sorted by
+func (w *Window) requiredOrdering() []OrderBy {
+	order := slice.Map(w.PartitionBy, func(from sqlparser.Expr) OrderBy {
+		return OrderBy{
+			Inner:          &sqlparser.Order{Expr: from},
+			SimplifiedExpr: from,
+		}
+	})
+	return append(order, w.OrderBy...)
+}
+
+func (w *Window) planOffsets(ctx *plancontext.PlanningContext) Operator {
+	if w.offsetPlanned {
+		return nil
+	}
+	defer func() {
+		w.offsetPlanned = true
+	}()
+
+	for _, expr := range w.PartitionBy {
+		offset := w.Source.AddColumn(ctx, true, false, aeWrap(expr))
+		w.PartitionOffsets = append(w.PartitionOffsets, offset)
+		wsOffset := -1
+		if ctx.NeedsWeightString(expr) {
+			wsOffset = w.Source.AddWSColumn(ctx, offset, false)
+		}
+		w.PartitionWSOffsets = append(w.PartitionWSOffsets, wsOffset)
+	}
+
+	for _, order := range w.OrderBy {
+		offset := w.Source.AddColumn(ctx, true, false, aeWrap(order.SimplifiedExpr))
+		w.OrderOffsets = append(w.OrderOffsets, offset)
+		wsOffset := -1
+		if ctx.NeedsWeightString(order.SimplifiedExpr) {
+			wsOffset = w.Source.AddWSColumn(ctx, offset, false)
+		}
+		w.OrderWSOffsets = append(w.OrderWSOffsets, wsOffset)
+	}
+
+	for _, fn := range w.Functions {
+		arg, def := WindowFuncArguments(fn.Expr)
+		if arg != nil {
+			fn.ArgOffset = w.Source.AddColumn(ctx, true, false, aeWrap(arg))
+		}
+		if def != nil {
+			fn.DefaultOffset = w.Source.AddColumn(ctx, true, false, aeWrap(def))
+		}
+	}
+	return nil
+}
+
+// WindowFuncArguments returns the argument of a window function and the default
+// value used by LAG and LEAD, if the function has them
+func WindowFuncArguments(expr sqlparser.Expr) (arg, def sqlparser.Expr) {
+	switch fn := expr.(type) {
+	case *sqlparser.LagLeadExpr:
+		return fn.Expr, fn.Default
+	case *sqlparser.FirstOrLastValueExpr:
+		return fn.Expr, nil
+	case *sqlparser.NTHValueExpr:
+		return fn.Expr, nil
+	case *sqlparser.Count:
+		if len(fn.Args) == 1 {
+			return fn.Args[0], nil
+		}
+	case sqlparser.AggrFunc:
+		if _, isCountStar := fn.(*sqlparser.CountStar); !isCountStar {
+			return fn.GetArg(), nil
+		}
+	}
+	return nil, nil
+}
+
+func (w *Window) ShortDescription() string {
+	functions := slice.Map(w.Functions, func(from *WindowFunc) string {
+		return sqlparser.String(from.Expr)
+	})
+	return strings.Join(functions, ", ")
+}
+
+// tryPushWindow pushes the window functions into the route when every partition
+// of the window is guaranteed to be found on a single shard
+func tryPushWindow(ctx *plancontext.PlanningContext, in *Window) (Operator, *ApplyResult) {
+	src, ok := in.Source.(*Route)
+	if !ok {
+		return in, NoRewrite
+	}
+	if !src.IsSingleShard() && !slices.ContainsFunc(in.PartitionBy, func(expr sqlparser.Expr) bool {
+		return exprHasUniqueVindex(ctx, expr)
+	}) {
+		return in, NoRewrite
+	}
+
+	// the operators above this one will send the window functions to the route
+	return src, Rewrote("push window functions into route")
+}
+
+// windowFunctionsPushable returns true if all the window functions used by the
+// node can be evaluated by the shards, without looking at rows from other shards.
+// That is the case when every window is partitioned by a unique vindex column
+func windowFunctionsPushable(validVindex func(sqlparser.Expr) bool, node sqlparser.SQLNode) bool {
+	pushable := true
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		over, ok := node.(*sqlparser.OverClause)
+		if !ok {
+			return pushable, nil
+		}
+		pushable = over.WindowSpec != nil && slices.ContainsFunc(over.WindowSpec.PartitionClause, validVindex)
+		return pushable, nil
+	}, node)
+	return pushable
+}
@@ -293,6 +293,35 @@ func (s *planTestSuite) TestViews() {
 	s.testFile("view_cases.json", vschemaWrapper, false)
 }
 
+// TestTableStatistics tests the planning of joins when the schema tracker has collected table statistics.
+func (s *planTestSuite) TestTableStatistics() {
+	vschema := loadSchema(s.T(), "vschemas/schema.json", true)
+	s.setTableStats(vschema)
+	vschemaWrapper := &vschemawrapper.VSchemaWrapper{
+		V:           vschema,
+		TestBuilder: TestBuilder,
+		Env:         vtenv.NewTestEnv(),
+	}
+
+	s.testFile("table_stats_cases.json", vschemaWrapper, false)
+}
+
+func (s *planTestSuite) setTableStats(vschema *vindexes.VSchema) {
+	tables := vschema.Keyspaces["user"].Tables
+	tables["user"].Stats = &vindexes.TableStats{
+		Rows:        100000,
+		Cardinality: map[string]uint64{"id": 100000, "col": 10, "intcol": 50000, "name": 80000},
+	}
+	tables["user_extra"].Stats = &vindexes.TableStats{
+		Rows:        200000,
+		Cardinality: map[string]uint64{"id": 200000, "user_id": 100000, "col": 10},
+	}
+	tables["music"].Stats = &vindexes.TableStats{
+		Rows:        50,
+		Cardinality: map[string]uint64{"id": 50, "user_id": 20},
+	}
+}
+
 func (s *planTestSuite) TestOne() {
 	reset := operators.EnableDebugPrinting()
 	defer reset()
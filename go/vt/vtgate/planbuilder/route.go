@@ -38,6 +38,10 @@ func WireupRoute(ctx *plancontext.PlanningContext, eroute *engine.Route, sel sql
 	if !ok {
 		return eroute, nil
 	}
+	// the lookups of a cached vindex go through the vindex, which uses its cache
+	if cached, ok := planableVindex.(vindexes.LookupCached); ok && cached.LookupCacheEnabled() {
+		return eroute, nil
+	}
 
 	query, args := planableVindex.Query()
 	stmt, reserved, err := ctx.VSchema.Environment().Parser().Parse2(query)
@@ -32,10 +32,6 @@ func gen4UpdateStmtPlanner(
 	reservedVars *sqlparser.ReservedVars,
 	vschema plancontext.VSchema,
 ) (*planResult, error) {
-	if updStmt.With != nil {
-		return nil, vterrors.VT12001("WITH expression in UPDATE statement")
-	}
-
 	ctx, err := plancontext.CreatePlanningContext(updStmt, reservedVars, vschema, version)
 	if err != nil {
 		return nil, err
@@ -69,6 +65,10 @@ func gen4UpdateStmtPlanner(
 		return nil, ctx.SemTable.NotUnshardedErr
 	}
 
+	if updStmt.With != nil && updStmt.With.Recursive {
+		return nil, vterrors.VT12001("recursive common table expression in UPDATE statement")
+	}
+
 	op, err := operators.PlanQuery(ctx, updStmt)
 	if err != nil {
 		return nil, err
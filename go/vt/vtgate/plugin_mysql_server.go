@@ -70,6 +70,7 @@ var (
 	mysqlConnReadTimeout          time.Duration
 	mysqlConnWriteTimeout         time.Duration
 	mysqlQueryTimeout             time.Duration
+	mysqlCursorTimeout            time.Duration
 	mysqlSlowConnectWarnThreshold time.Duration
 	mysqlConnBufferPooling        bool
 
@@ -78,6 +79,9 @@ var (
 	mysqlDrainOnTerm         bool
 
 	mysqlServerFlushDelay = 100 * time.Millisecond
+
+	mysqlServerCompressionAlgorithms []string
+	mysqlServerQueryAttributes       bool
 )
 
 func registerPluginFlags(fs *pflag.FlagSet) {
@@ -85,7 +89,7 @@ func registerPluginFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&mysqlServerBindAddress, "mysql_server_bind_address", mysqlServerBindAddress, "Binds on this address when listening to MySQL binary protocol. Useful to restrict listening to 'localhost' only for instance.")
 	fs.StringVar(&mysqlServerSocketPath, "mysql_server_socket_path", mysqlServerSocketPath, "This option specifies the Unix socket file to use when listening for local connections. By default it will be empty and it won't listen to a unix socket")
 	fs.StringVar(&mysqlTCPVersion, "mysql_tcp_version", mysqlTCPVersion, "Select tcp, tcp4, or tcp6 to control the socket type.")
-	fs.StringVar(&mysqlAuthServerImpl, "mysql_auth_server_impl", mysqlAuthServerImpl, "Which auth server implementation to use. Options: none, ldap, clientcert, static, vault.")
+	fs.StringVar(&mysqlAuthServerImpl, "mysql_auth_server_impl", mysqlAuthServerImpl, "Which auth server implementation to use. Options: none, ldap, clientcert, static, vault, jwt.")
 	fs.BoolVar(&mysqlAllowClearTextWithoutTLS, "mysql_allow_clear_text_without_tls", mysqlAllowClearTextWithoutTLS, "If set, the server will allow the use of a clear text password over non-SSL connections.")
 	fs.BoolVar(&mysqlProxyProtocol, "proxy_protocol", mysqlProxyProtocol, "Enable HAProxy PROXY protocol on MySQL listener socket")
 	fs.BoolVar(&mysqlServerRequireSecureTransport, "mysql_server_require_secure_transport", mysqlServerRequireSecureTransport, "Reject insecure connections but only if mysql_server_ssl_cert and mysql_server_ssl_key are provided")
@@ -99,11 +103,14 @@ func registerPluginFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&mysqlConnReadTimeout, "mysql_server_read_timeout", mysqlConnReadTimeout, "connection read timeout")
 	fs.DurationVar(&mysqlConnWriteTimeout, "mysql_server_write_timeout", mysqlConnWriteTimeout, "connection write timeout")
 	fs.DurationVar(&mysqlQueryTimeout, "mysql_server_query_timeout", mysqlQueryTimeout, "mysql query timeout")
+	fs.DurationVar(&mysqlCursorTimeout, "mysql-server-cursor-timeout", mysqlCursorTimeout, "How long a cursor opened by a prepared statement can stay open, until its last row is fetched. 0 means no limit. --mysql_server_query_timeout does not apply to cursors, as they are fetched at the pace of the client. KILL QUERY aborts the open cursors of the connection as well as its running query.")
 	fs.BoolVar(&mysqlConnBufferPooling, "mysql-server-pool-conn-read-buffers", mysqlConnBufferPooling, "If set, the server will pool incoming connection read buffers")
 	fs.DurationVar(&mysqlKeepAlivePeriod, "mysql-server-keepalive-period", mysqlKeepAlivePeriod, "TCP period between keep-alives")
 	fs.DurationVar(&mysqlServerFlushDelay, "mysql_server_flush_delay", mysqlServerFlushDelay, "Delay after which buffered response will be flushed to the client.")
 	fs.StringVar(&mysqlDefaultWorkloadName, "mysql_default_workload", mysqlDefaultWorkloadName, "Default session workload (OLTP, OLAP, DBA)")
 	fs.BoolVar(&mysqlDrainOnTerm, "mysql-server-drain-onterm", mysqlDrainOnTerm, "If set, the server waits for --onterm_timeout for already connected clients to complete their in flight work")
+	fs.StringSliceVar(&mysqlServerCompressionAlgorithms, "mysql-server-compression-algorithms", mysqlServerCompressionAlgorithms, "Comma separated list of the algorithms (zlib, zstd) the server offers to compress the MySQL protocol with over TCP. Compression is disabled if empty.")
+	fs.BoolVar(&mysqlServerQueryAttributes, "mysql-server-query-attributes", mysqlServerQueryAttributes, "If set, the server accepts query attributes from the clients, logs them, forwards them to the tablets, and routes queries with the vt_tablet_type, vt_workload and vt_query_timeout_ms attributes.")
 }
 
 // vtgateHandler implements the Listener interface.
@@ -149,6 +156,27 @@ func (vh *vtgateHandler) ComResetConnection(c *mysql.Conn) {
 	}
 }
 
+// ComChangeUser closes the session of the previous user of the connection,
+// so that the next query starts a new one for the new user.
+func (vh *vtgateHandler) ComChangeUser(c *mysql.Conn) {
+	// Close the session as the previous user, for the user limits to
+	// account for its transaction.
+	ef := callerid.NewEffectiveCallerID(
+		c.User,                  /* principal: who */
+		c.RemoteAddr().String(), /* component: running client process */
+		"VTGate MySQL Connector" /* subcomponent: part of the client */)
+	ctx := callerid.NewContext(context.Background(), ef, c.UserData.Get())
+
+	session := vh.session(c)
+	if session.InTransaction {
+		defer vh.busyConnections.Add(-1)
+	}
+	if err := vh.vtg.CloseSession(ctx, session); err != nil {
+		log.Errorf("Error happened in transaction rollback: %v", err)
+	}
+	c.ClientData = nil
+}
+
 func (vh *vtgateHandler) ConnectionClosed(c *mysql.Conn) {
 	// Rollback if there is an ongoing transaction. Ignore error.
 	defer func() {
@@ -250,6 +278,12 @@ func (vh *vtgateHandler) ComQuery(c *mysql.Conn, query string, callback func(*sq
 		}
 	}()
 
+	restore, err := applyQueryAttributes(session, c.QueryAttributes)
+	if err != nil {
+		return sqlerror.NewSQLErrorFromError(err)
+	}
+	defer restore()
+
 	if session.Options.Workload == querypb.ExecuteOptions_OLAP {
 		session, err := vh.vtg.StreamExecute(ctx, vh, session, query, make(map[string]*querypb.BindVariable), callback)
 		if err != nil {
@@ -323,14 +357,53 @@ func (vh *vtgateHandler) ComPrepare(c *mysql.Conn, query string, bindVars map[st
 	return fld, nil
 }
 
-func (vh *vtgateHandler) ComStmtExecute(c *mysql.Conn, prepare *mysql.PrepareData, callback func(*sqltypes.Result) error) error {
+// executeContext returns the context of the execution of a prepared statement, and the
+// function to call once it is done. Without a cursor, the execution is the running query
+// of the connection: --mysql_server_query_timeout applies, and KILL QUERY cancels it
+// until the next command replaces it. With a cursor, the execution lasts until the
+// client fetched its last row, while other commands run in between. It is then bound
+// by --mysql-server-cursor-timeout instead, and registered with the connection so that
+// KILL QUERY cancels it whatever ran since.
+func executeContext(c *mysql.Conn, withCursor bool) (context.Context, func()) {
 	ctx, cancel := context.WithCancel(context.Background())
-	c.UpdateCancelCtx(cancel)
+	if !withCursor {
+		c.UpdateCancelCtx(cancel)
+		if mysqlQueryTimeout == 0 {
+			return ctx, cancel
+		}
+		ctx, cancelTimeout := context.WithTimeout(ctx, mysqlQueryTimeout)
+		return ctx, func() {
+			cancelTimeout()
+			cancel()
+		}
+	}
 
-	if mysqlQueryTimeout != 0 {
-		ctx, cancel = context.WithTimeout(ctx, mysqlQueryTimeout)
-		defer cancel()
+	remove := c.AddCursorCancelCtx(cancel)
+	if mysqlCursorTimeout != 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, mysqlCursorTimeout)
+		return ctx, func() {
+			remove()
+			cancelTimeout()
+			cancel()
+		}
+	}
+	return ctx, func() {
+		remove()
+		cancel()
 	}
+}
+
+func (vh *vtgateHandler) ComStmtExecute(c *mysql.Conn, prepare *mysql.PrepareData, callback func(*sqltypes.Result) error) error {
+	session := vh.session(c)
+
+	// The rows of a cursor are fetched in chunks, so we stream them rather
+	// than buffering the whole result. Not in a transaction though, as the
+	// transaction is used by other statements while the cursor is open.
+	withCursor := prepare.CursorType&mysql.CursorTypeReadOnly != 0 && !session.InTransaction
+
+	ctx, done := executeContext(c, withCursor)
+	defer done()
 
 	ctx = callinfo.MysqlCallInfo(ctx, c)
 
@@ -346,7 +419,6 @@ func (vh *vtgateHandler) ComStmtExecute(c *mysql.Conn, prepare *mysql.PrepareDat
 		"VTGate MySQL Connector" /* subcomponent: part of the client */)
 	ctx = callerid.NewContext(ctx, ef, im)
 
-	session := vh.session(c)
 	if !session.InTransaction {
 		vh.busyConnections.Add(1)
 	}
@@ -356,15 +428,29 @@ func (vh *vtgateHandler) ComStmtExecute(c *mysql.Conn, prepare *mysql.PrepareDat
 		}
 	}()
 
-	if session.Options.Workload == querypb.ExecuteOptions_OLAP {
-		_, err := vh.vtg.StreamExecute(ctx, vh, session, prepare.PrepareStmt, prepare.BindVars, callback)
+	// The statements run while the cursor is open must not see the query
+	// attributes of the cursor, so it streams with its own copy of the
+	// session. Being read-only and outside of a transaction, there is
+	// nothing to keep of it but its warnings, which are lost.
+	execSession := session
+	if withCursor {
+		execSession = session.CloneVT()
+	}
+	restore, err := applyQueryAttributes(execSession, c.QueryAttributes)
+	if err != nil {
+		return sqlerror.NewSQLErrorFromError(err)
+	}
+	defer restore()
+
+	if execSession.Options.Workload == querypb.ExecuteOptions_OLAP || withCursor {
+		_, err := vh.vtg.StreamExecute(ctx, vh, execSession, prepare.PrepareStmt, prepare.BindVars, callback)
 		if err != nil {
 			return sqlerror.NewSQLErrorFromError(err)
 		}
 		fillInTxStatusFlags(c, session)
 		return nil
 	}
-	_, qr, err := vh.vtg.Execute(ctx, vh, session, prepare.PrepareStmt, prepare.BindVars)
+	_, qr, err := vh.vtg.Execute(ctx, vh, execSession, prepare.PrepareStmt, prepare.BindVars)
 	if err != nil {
 		return sqlerror.NewSQLErrorFromError(err)
 	}
@@ -521,6 +607,15 @@ func initMySQLProtocol(vtgate *VTGate) *mysqlServer {
 		log.Exitf("-mysql_tcp_version must be one of [tcp, tcp4, tcp6]")
 	}
 
+	var compressionAlgorithms []mysql.CompressionAlgorithm
+	for _, name := range mysqlServerCompressionAlgorithms {
+		algorithm, err := mysql.ParseCompressionAlgorithm(name)
+		if err != nil {
+			log.Exitf("-mysql-server-compression-algorithms: %v", err)
+		}
+		compressionAlgorithms = append(compressionAlgorithms, algorithm)
+	}
+
 	// Create a Listener.
 	var err error
 	srv := &mysqlServer{}
@@ -550,6 +645,8 @@ func initMySQLProtocol(vtgate *VTGate) *mysqlServer {
 			_ = initTLSConfig(context.Background(), srv, mysqlSslCert, mysqlSslKey, mysqlSslCa, mysqlSslCrl, mysqlSslServerCA, mysqlServerRequireSecureTransport, tlsVersion)
 		}
 		srv.tcpListener.AllowClearTextWithoutTLS.Store(mysqlAllowClearTextWithoutTLS)
+		srv.tcpListener.CompressionAlgorithms = compressionAlgorithms
+		srv.tcpListener.EnableQueryAttributes = mysqlServerQueryAttributes
 		// Check for the connection threshold
 		if mysqlSlowConnectWarnThreshold != 0 {
 			log.Infof("setting mysql slow connection threshold to %v", mysqlSlowConnectWarnThreshold)
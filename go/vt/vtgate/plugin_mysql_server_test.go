@@ -346,6 +346,185 @@ func TestKillMethods(t *testing.T) {
 	require.True(t, mysqlConn.IsMarkedForClose())
 }
 
+// TestExecuteContext tests the deadlines and the cancellation of the executions of prepared statements.
+func TestExecuteContext(t *testing.T) {
+	executor, _, _, _, _ := createExecutorEnv(t)
+	vh := newVtgateHandler(&VTGate{executor: executor})
+	mysqlConn := mysql.GetTestConn()
+	mysqlConn.ConnectionID = 1
+	vh.connections[1] = mysqlConn
+
+	defer func(queryTimeout, cursorTimeout time.Duration) {
+		mysqlQueryTimeout, mysqlCursorTimeout = queryTimeout, cursorTimeout
+	}(mysqlQueryTimeout, mysqlCursorTimeout)
+	mysqlQueryTimeout = time.Minute
+
+	// the query timeout applies to queries, not to cursors
+	ctx, done := executeContext(mysqlConn, false)
+	_, ok := ctx.Deadline()
+	assert.True(t, ok)
+	done()
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+
+	ctx, done = executeContext(mysqlConn, true)
+	_, ok = ctx.Deadline()
+	assert.False(t, ok)
+	done()
+
+	mysqlCursorTimeout = time.Hour
+	ctx, done = executeContext(mysqlConn, true)
+	defer done()
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok)
+	assert.Greater(t, time.Until(deadline), time.Minute)
+
+	// the commands run while the cursor is open don't keep KILL QUERY from reaching it
+	queryCtx, queryDone := executeContext(mysqlConn, false)
+	queryDone()
+	require.ErrorIs(t, queryCtx.Err(), context.Canceled)
+	require.NoError(t, ctx.Err())
+
+	err := vh.KillQuery(1)
+	require.NoError(t, err)
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+func TestComChangeUser(t *testing.T) {
+	executor, _, _, _, _ := createExecutorEnv(t)
+
+	vh := newVtgateHandler(&VTGate{executor: executor, timings: timings, rowsReturned: rowsReturned, rowsAffected: rowsAffected, queryTextCharsProcessed: queryTextCharsProcessed})
+	th := &testHandler{}
+	listener, err := mysql.NewListener("tcp", "127.0.0.1:", mysql.NewAuthServerNone(), th, 0, 0, false, false, 0, 0)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	mysqlConn := mysql.GetTestServerConn(listener)
+	mysqlConn.ConnectionID = 1
+	mysqlConn.User = "user1"
+	mysqlConn.UserData = &mysql.StaticUserData{Username: "user1"}
+	vh.connections[1] = mysqlConn
+
+	err = vh.ComQuery(mysqlConn, "BEGIN", func(result *sqltypes.Result) error {
+		return nil
+	})
+	require.NoError(t, err)
+	err = vh.ComQuery(mysqlConn, "set @foo = 1", func(result *sqltypes.Result) error {
+		return nil
+	})
+	require.NoError(t, err)
+	previous := vh.session(mysqlConn)
+	require.True(t, previous.InTransaction)
+	require.EqualValues(t, 1, vh.busyConnections.Load())
+
+	// The session of the previous user is closed, and the next query
+	// starts a new one.
+	vh.ComChangeUser(mysqlConn)
+	assert.False(t, previous.InTransaction)
+	assert.EqualValues(t, 0, vh.busyConnections.Load())
+	session := vh.session(mysqlConn)
+	assert.NotSame(t, previous, session)
+	assert.NotEqual(t, previous.SessionUUID, session.SessionUUID)
+	assert.Empty(t, session.UserDefinedVariables)
+}
+
+func TestComStmtExecuteWithCursor(t *testing.T) {
+	executor, _, _, _, _ := createExecutorEnv(t)
+
+	vh := newVtgateHandler(&VTGate{executor: executor, timings: timings, rowsReturned: rowsReturned, rowsAffected: rowsAffected, queryTextCharsProcessed: queryTextCharsProcessed})
+	th := &testHandler{}
+	listener, err := mysql.NewListener("tcp", "127.0.0.1:", mysql.NewAuthServerNone(), th, 0, 0, false, false, 0, 0)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	mysqlConn := mysql.GetTestServerConn(listener)
+	mysqlConn.ConnectionID = 1
+	mysqlConn.UserData = &mysql.StaticUserData{}
+	vh.connections[1] = mysqlConn
+	vh.session(mysqlConn).Options.Workload = querypb.ExecuteOptions_OLTP
+
+	// execute returns the number of results the rows came in.
+	execute := func(cursorType byte) int {
+		prepare := &mysql.PrepareData{
+			PrepareStmt: "select id from user",
+			BindVars:    map[string]*querypb.BindVariable{},
+			CursorType:  cursorType,
+		}
+		results := 0
+		err := vh.ComStmtExecute(mysqlConn, prepare, func(result *sqltypes.Result) error {
+			results++
+			return nil
+		})
+		require.NoError(t, err)
+		return results
+	}
+
+	// A cursor streams the rows, the fields coming first.
+	assert.Greater(t, execute(mysql.CursorTypeReadOnly), 1)
+
+	// Without a cursor, or in a transaction, the rows are buffered.
+	assert.Equal(t, 1, execute(mysql.CursorTypeNoCursor))
+
+	err = vh.ComQuery(mysqlConn, "BEGIN", func(result *sqltypes.Result) error {
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, execute(mysql.CursorTypeReadOnly))
+}
+
+func TestComStmtExecuteWithCursorAndAttributes(t *testing.T) {
+	executor, _, _, _, _ := createExecutorEnv(t)
+
+	vh := newVtgateHandler(&VTGate{executor: executor, timings: timings, rowsReturned: rowsReturned, rowsAffected: rowsAffected, queryTextCharsProcessed: queryTextCharsProcessed})
+	th := &testHandler{}
+	listener, err := mysql.NewListener("tcp", "127.0.0.1:", mysql.NewAuthServerNone(), th, 0, 0, false, false, 0, 0)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	mysqlConn := mysql.GetTestServerConn(listener)
+	mysqlConn.ConnectionID = 1
+	mysqlConn.UserData = &mysql.StaticUserData{}
+	vh.connections[1] = mysqlConn
+	vh.session(mysqlConn).Options.Workload = querypb.ExecuteOptions_OLTP
+
+	workload := func() string {
+		var workload string
+		err := vh.ComQuery(mysqlConn, "select @@workload from dual", func(result *sqltypes.Result) error {
+			if len(result.Rows) > 0 {
+				workload = result.Rows[0][0].ToString()
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		return workload
+	}
+
+	mysqlConn.QueryAttributes = map[string]string{QueryAttributeWorkload: "dba"}
+	prepare := &mysql.PrepareData{
+		PrepareStmt: "select id from user",
+		BindVars:    map[string]*querypb.BindVariable{},
+		CursorType:  mysql.CursorTypeReadOnly,
+	}
+	queried := false
+	err = vh.ComStmtExecute(mysqlConn, prepare, func(result *sqltypes.Result) error {
+		if queried {
+			return nil
+		}
+		queried = true
+
+		// The statements run while the cursor is open, without query
+		// attributes, do not see those of the cursor.
+		mysqlConn.QueryAttributes = nil
+		assert.Equal(t, "OLTP", workload())
+		session := vh.session(mysqlConn)
+		assert.Equal(t, querypb.ExecuteOptions_OLTP, session.Options.Workload)
+		assert.Nil(t, session.Options.QueryAttributes)
+		return nil
+	})
+	require.NoError(t, err)
+	require.True(t, queried)
+	assert.Equal(t, "OLTP", workload())
+}
+
 func TestGracefulShutdown(t *testing.T) {
 	executor, _, _, _, _ := createExecutorEnv(t)
 
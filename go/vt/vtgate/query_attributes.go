@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"strconv"
+	"strings"
+
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// Query attributes sent by MySQL clients that direct the routing of the query
+// they are sent with, as an alternative to query comments. All the query
+// attributes are also forwarded to the tablets, for their query logs.
+const (
+	// QueryAttributeTabletType sends the query to the given tablet type,
+	// as if it was the tablet type of the target.
+	QueryAttributeTabletType = "vt_tablet_type"
+
+	// QueryAttributeWorkload executes the query with the given workload
+	// (OLTP, OLAP or DBA).
+	QueryAttributeWorkload = "vt_workload"
+
+	// QueryAttributeQueryTimeout is the timeout of the query in milliseconds,
+	// like the QUERY_TIMEOUT_MS comment directive.
+	QueryAttributeQueryTimeout = "vt_query_timeout_ms"
+)
+
+// applyQueryAttributes sets the query attributes in the options of the
+// session, and applies their routing directives to the session, for one
+// query. It returns the function that restores the session afterwards,
+// leaving alone what the query itself changed.
+func applyQueryAttributes(session *vtgatepb.Session, attributes map[string]string) (restore func(), err error) {
+	if len(attributes) == 0 {
+		return func() {}, nil
+	}
+
+	if value, ok := attributes[QueryAttributeQueryTimeout]; ok {
+		if timeout, err := strconv.Atoi(value); err != nil || timeout < 0 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid %s query attribute: %s", QueryAttributeQueryTimeout, value)
+		}
+	}
+
+	targetString := session.TargetString
+	if value, ok := attributes[QueryAttributeTabletType]; ok {
+		tabletType, err := topoproto.ParseTabletType(value)
+		if err != nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid %s query attribute: %s", QueryAttributeTabletType, value)
+		}
+		keyspaceShard, _, _ := strings.Cut(session.TargetString, "@")
+		targetString = keyspaceShard + "@" + topoproto.TabletTypeLString(tabletType)
+	}
+
+	if session.Options == nil {
+		session.Options = &querypb.ExecuteOptions{}
+	}
+	workload := session.Options.Workload
+	if value, ok := attributes[QueryAttributeWorkload]; ok {
+		out, ok := querypb.ExecuteOptions_Workload_value[strings.ToUpper(value)]
+		if !ok {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid %s query attribute: %s", QueryAttributeWorkload, value)
+		}
+		workload = querypb.ExecuteOptions_Workload(out)
+	}
+
+	prevTargetString, prevWorkload, prevAttributes := session.TargetString, session.Options.Workload, session.Options.QueryAttributes
+	session.TargetString = targetString
+	session.Options.Workload = workload
+	session.Options.QueryAttributes = attributes
+	return func() {
+		if session.TargetString == targetString {
+			session.TargetString = prevTargetString
+		}
+		if session.Options.Workload == workload {
+			session.Options.Workload = prevWorkload
+		}
+		session.Options.QueryAttributes = prevAttributes
+	}, nil
+}
+
+// queryTimeoutFromAttributes returns the query timeout in milliseconds set
+// by the query attributes, or 0.
+func queryTimeoutFromAttributes(attributes map[string]string) int {
+	timeout, err := strconv.Atoi(attributes[QueryAttributeQueryTimeout])
+	if err != nil || timeout < 0 {
+		return 0
+	}
+	return timeout
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+func TestApplyQueryAttributes(t *testing.T) {
+	session := &vtgatepb.Session{
+		TargetString: "ks:-80@primary",
+		Options:      &querypb.ExecuteOptions{Workload: querypb.ExecuteOptions_OLTP},
+	}
+	attributes := map[string]string{
+		QueryAttributeTabletType:   "replica",
+		QueryAttributeWorkload:     "olap",
+		QueryAttributeQueryTimeout: "100",
+		"trace":                    "1",
+	}
+
+	restore, err := applyQueryAttributes(session, attributes)
+	require.NoError(t, err)
+	assert.Equal(t, "ks:-80@replica", session.TargetString)
+	assert.Equal(t, querypb.ExecuteOptions_OLAP, session.Options.Workload)
+	assert.Equal(t, attributes, session.Options.QueryAttributes)
+	assert.Equal(t, 100, queryTimeoutFromAttributes(session.Options.QueryAttributes))
+
+	restore()
+	assert.Equal(t, "ks:-80@primary", session.TargetString)
+	assert.Equal(t, querypb.ExecuteOptions_OLTP, session.Options.Workload)
+	assert.Nil(t, session.Options.QueryAttributes)
+	assert.Zero(t, queryTimeoutFromAttributes(session.Options.QueryAttributes))
+
+	// What the query changed itself is kept.
+	session.TargetString = "ks"
+	restore, err = applyQueryAttributes(session, attributes)
+	require.NoError(t, err)
+	assert.Equal(t, "ks@replica", session.TargetString)
+	session.TargetString = "other"
+	session.Options.Workload = querypb.ExecuteOptions_DBA
+	restore()
+	assert.Equal(t, "other", session.TargetString)
+	assert.Equal(t, querypb.ExecuteOptions_DBA, session.Options.Workload)
+
+	for _, attributes := range []map[string]string{
+		{QueryAttributeTabletType: "unknown_type"},
+		{QueryAttributeWorkload: "oltp2"},
+		{QueryAttributeQueryTimeout: "1s"},
+		{QueryAttributeQueryTimeout: "-1"},
+	} {
+		_, err := applyQueryAttributes(session, attributes)
+		assert.ErrorContains(t, err, "query attribute", attributes)
+	}
+	assert.Equal(t, "other", session.TargetString)
+	assert.Nil(t, session.Options.QueryAttributes)
+}
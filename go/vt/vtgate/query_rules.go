@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"slices"
+
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/queryrules"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// SetQueryRules replaces the query rules applied to the queries run by the executor
+func (e *Executor) SetQueryRules(qrs *queryrules.Rules) {
+	e.queryRules.Store(qrs)
+}
+
+// applyQueryRules applies the query rules to a planned query. If the query is allowed to run,
+// it returns the context to run it with, and a function that must be called when it is done.
+func (e *Executor) applyQueryRules(ctx context.Context, safeSession *SafeSession, stmt sqlparser.Statement, plan *engine.Plan, vcursor *vcursorImpl) (context.Context, func(), error) {
+	qrs := e.queryRules.Load()
+	if qrs.Empty() || plan.Instructions == nil {
+		return ctx, func() {}, nil
+	}
+
+	q := &queryrules.Query{
+		Fingerprint: plan.Original,
+		User:        callerid.ImmediateCallerIDFromContext(ctx).GetUsername(),
+	}
+	engine.Find(func(p engine.Primitive) bool {
+		if routeType := p.RouteType(); !slices.Contains(q.PlanTypes, routeType) {
+			q.PlanTypes = append(q.PlanTypes, routeType)
+		}
+		// only the leaves give us the real keyspace names, the others concatenate the names of their inputs
+		if inputs, _ := p.Inputs(); len(inputs) == 0 {
+			if ks := p.GetKeyspaceName(); ks != "" && !slices.Contains(q.Keyspaces, ks) {
+				q.Keyspaces = append(q.Keyspaces, ks)
+			}
+		}
+		return false
+	}, plan.Instructions)
+
+	outcome, err := qrs.Apply(q)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the tablet type of a transaction cannot change, and only reads can go to replicas
+	if outcome.TabletType != topodatapb.TabletType_UNKNOWN && !safeSession.InTransaction() && isReadOnly(plan, stmt) {
+		vcursor.tabletType = outcome.TabletType
+	}
+	if outcome.Timeout == 0 {
+		return ctx, outcome.Release, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, outcome.Timeout)
+	return ctx, func() {
+		cancel()
+		outcome.Release()
+	}, nil
+}
+
+// isReadOnly returns true if the query is a SELECT that neither locks rows, nor takes named locks,
+// nor writes anything, and can thus be sent to any tablet type.
+func isReadOnly(plan *engine.Plan, stmt sqlparser.Statement) bool {
+	if plan.Type != sqlparser.StmtSelect {
+		return false
+	}
+	readOnly := true
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.Select:
+			if node.Lock != sqlparser.NoLock || node.Into != nil {
+				readOnly = false
+			}
+		case *sqlparser.Union:
+			if node.Lock != sqlparser.NoLock || node.Into != nil {
+				readOnly = false
+			}
+		case *sqlparser.LockingFunc, *sqlparser.Nextval:
+			readOnly = false
+		}
+		return readOnly, nil
+	}, stmt)
+	return readOnly
+}
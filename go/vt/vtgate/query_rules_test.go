@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vtgate/queryrules"
+
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+func TestQueryRulesDenyScatter(t *testing.T) {
+	executor, sbc1, sbc2, _, ctx := createExecutorEnv(t)
+
+	qrs, err := queryrules.Parse([]byte(`[{"Name": "no_scatter", "Keyspaces": ["TestExecutor"], "PlanTypes": ["Scatter"], "Deny": true}]`))
+	require.NoError(t, err)
+	executor.SetQueryRules(qrs)
+
+	session := &vtgatepb.Session{TargetString: "@primary"}
+	_, err = executorExec(ctx, executor, session, "select id from user", nil)
+	require.EqualError(t, err, "query disallowed due to rule: no_scatter")
+	assert.EqualValues(t, 0, sbc1.ExecCount.Load()+sbc2.ExecCount.Load())
+
+	_, err = executorExec(ctx, executor, session, "select id from user where id = 1", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, sbc1.ExecCount.Load())
+
+	// the rule only applies to the sharded keyspace
+	_, err = executorExec(ctx, executor, session, "select id from main1", nil)
+	require.NoError(t, err)
+
+	executor.SetQueryRules(nil)
+	_, err = executorExec(ctx, executor, session, "select id from user", nil)
+	require.NoError(t, err)
+}
+
+func TestQueryRulesTabletType(t *testing.T) {
+	executor, primary, replica := createExecutorEnvWithPrimaryReplicaConn(t, context.Background(), 0)
+
+	qrs, err := queryrules.Parse([]byte(`[{"Name": "reports", "Fingerprint": "from reports", "TabletType": "replica"}]`))
+	require.NoError(t, err)
+	executor.SetQueryRules(qrs)
+
+	session := &vtgatepb.Session{TargetString: KsTestUnsharded, Autocommit: true}
+	_, err = executorExec(context.Background(), executor, session, "select id from reports", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, primary.ExecCount.Load())
+	assert.EqualValues(t, 1, replica.ExecCount.Load())
+
+	_, err = executorExec(context.Background(), executor, session, "select id from orders", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, primary.ExecCount.Load())
+	assert.EqualValues(t, 1, replica.ExecCount.Load())
+
+	// the queries that lock or write stay on the primary
+	for _, query := range []string{
+		"select id from reports for update",
+		"select id from reports lock in share mode",
+		"select id from reports union select id from reports for update",
+		"select get_lock('reports', 10) from reports",
+		"update reports set id = 1",
+		"delete from reports",
+	} {
+		primaryCount := primary.ExecCount.Load()
+		_, err = executorExec(context.Background(), executor, session, query, nil)
+		require.NoError(t, err, query)
+		assert.Greater(t, primary.ExecCount.Load(), primaryCount, query)
+		assert.EqualValues(t, 1, replica.ExecCount.Load(), query)
+	}
+}
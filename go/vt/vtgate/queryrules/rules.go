@@ -0,0 +1,304 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package queryrules implements the query rules of vtgate.
+
+A rule matches queries by their fingerprint, which is the normalized query as it is shown
+in the query plans and the query logs, by the user running them, by the keyspaces they use,
+and by the types of routes in their plans. The queries it matches can be denied, given a
+timeout, sent to another tablet type, or limited in how many of them run concurrently
+or per second.
+
+The rules are a JSON list like:
+
+	[{
+	  "Name": "no_scatter_orders",
+	  "Description": "scatter reads of the orders table overload the shards",
+	  "Fingerprint": "from orders",
+	  "PlanTypes": ["Scatter"],
+	  "MaxConcurrency": 10
+	}]
+*/
+package queryrules
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var (
+	matchCount  = stats.NewCountersWithSingleLabel("QueryRulesMatched", "Queries matched by a vtgate query rule", "Rule")
+	rejectCount = stats.NewCountersWithSingleLabel("QueryRulesRejected", "Queries rejected by a vtgate query rule", "Rule")
+)
+
+// Rule is a single query rule, as stored in the topo.
+// All the conditions that are set have to match for the rule to apply to a query.
+type Rule struct {
+	Name        string
+	Description string `json:",omitempty"`
+
+	// Fingerprint is a regular expression matched against the normalized query
+	Fingerprint string `json:",omitempty"`
+	// Users are the users the rule applies to
+	Users []string `json:",omitempty"`
+	// Keyspaces are the keyspaces the rule applies to. It matches if the query uses any of them
+	Keyspaces []string `json:",omitempty"`
+	// PlanTypes are the route types the rule applies to, like Scatter or EqualUnique.
+	// It matches if any of the routes in the plan are of one of these types
+	PlanTypes []string `json:",omitempty"`
+
+	// Deny fails the queries
+	Deny bool `json:",omitempty"`
+	// QueryTimeout is the timeout in milliseconds for the queries
+	QueryTimeout int `json:",omitempty"`
+	// TabletType is the type of tablets the queries are sent to, when they are SELECTs that do not lock
+	// nor write anything, and are not part of a transaction. It is ignored for the other queries.
+	TabletType string `json:",omitempty"`
+	// MaxConcurrency is the maximum number of the queries that can run at the same time
+	MaxConcurrency int `json:",omitempty"`
+	// MaxQPS is the maximum number of the queries that can run per second
+	MaxQPS float64 `json:",omitempty"`
+}
+
+// Query is what the rules are matched against
+type Query struct {
+	Fingerprint string
+	User        string
+	Keyspaces   []string
+	PlanTypes   []string
+}
+
+// Outcome is what the matching rules require of a query that is allowed to run
+type Outcome struct {
+	// Timeout is the shortest timeout of the matching rules, 0 when none of them set one
+	Timeout time.Duration
+	// TabletType is the tablet type set by the first matching rule setting one, UNKNOWN when none of them do
+	TabletType topodatapb.TabletType
+
+	running []*compiledRule
+}
+
+// Release must be called when the query is done, so that it stops counting towards the concurrency limits
+func (o *Outcome) Release() {
+	for _, r := range o.running {
+		r.running.Add(-1)
+	}
+	o.running = nil
+}
+
+// Rules is a parsed set of rules. It is immutable, and safe to use concurrently.
+type Rules struct {
+	rules []*compiledRule
+	data  []byte
+}
+
+type compiledRule struct {
+	Rule
+	fingerprint *regexp.Regexp
+	tabletType  topodatapb.TabletType
+	limiter     *rate.Limiter
+	running     atomic.Int64
+}
+
+// Parse parses and validates a JSON list of rules
+func Parse(data []byte) (*Rules, error) {
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid query rules: %v", err)
+	}
+
+	qrs := &Rules{data: data}
+	names := map[string]bool{}
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "query rule without a name")
+		}
+		if names[rule.Name] {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "duplicate query rule %s", rule.Name)
+		}
+		names[rule.Name] = true
+
+		cr, err := compile(rule)
+		if err != nil {
+			return nil, err
+		}
+		qrs.rules = append(qrs.rules, cr)
+	}
+	return qrs, nil
+}
+
+func compile(rule Rule) (*compiledRule, error) {
+	cr := &compiledRule{Rule: rule}
+	if rule.Fingerprint != "" {
+		re, err := regexp.Compile(rule.Fingerprint)
+		if err != nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "query rule %s: invalid fingerprint: %v", rule.Name, err)
+		}
+		cr.fingerprint = re
+	}
+	if rule.TabletType != "" {
+		tt, err := topoproto.ParseTabletType(rule.TabletType)
+		if err != nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "query rule %s: %v", rule.Name, err)
+		}
+		cr.tabletType = tt
+	}
+	if rule.QueryTimeout < 0 || rule.MaxConcurrency < 0 || rule.MaxQPS < 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "query rule %s: timeout and limits cannot be negative", rule.Name)
+	}
+	if !rule.Deny && rule.QueryTimeout == 0 && rule.TabletType == "" && rule.MaxConcurrency == 0 && rule.MaxQPS == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "query rule %s does not do anything", rule.Name)
+	}
+	if rule.MaxQPS > 0 {
+		cr.limiter = rate.NewLimiter(rate.Limit(rule.MaxQPS), int(math.Ceil(rule.MaxQPS)))
+	}
+	return cr, nil
+}
+
+// Empty returns true if there are no rules to apply
+func (qrs *Rules) Empty() bool {
+	return qrs == nil || len(qrs.rules) == 0
+}
+
+// Equal returns true if both sets were parsed from the same rules
+func (qrs *Rules) Equal(other *Rules) bool {
+	if qrs == nil || other == nil {
+		return qrs == other
+	}
+	return string(qrs.data) == string(other.data)
+}
+
+// Apply applies the rules matching the query. It fails if any of them denies the query, or if the query is
+// above any of their limits. Otherwise, the caller must run the query with the returned outcome,
+// and release it when the query is done.
+//
+// The rules denying the query are checked first, then the concurrency limits, and the rate limits last,
+// so that the queries rejected by any of the rules neither hold a slot nor use up a token of the others.
+func (qrs *Rules) Apply(q *Query) (*Outcome, error) {
+	outcome := &Outcome{}
+	if qrs.Empty() {
+		return outcome, nil
+	}
+	var matching []*compiledRule
+	for _, r := range qrs.rules {
+		if !r.matches(q) {
+			continue
+		}
+		matchCount.Add(r.Name, 1)
+		if r.Deny {
+			rejectCount.Add(r.Name, 1)
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "query disallowed due to rule: %s", r.Name)
+		}
+		matching = append(matching, r)
+	}
+	for _, r := range matching {
+		if err := r.admit(outcome); err != nil {
+			rejectCount.Add(r.Name, 1)
+			outcome.Release()
+			return nil, err
+		}
+	}
+
+	// the tokens are given back if a later rule rejects the query, which requires
+	// the reservations to be canceled at the time they were made
+	now := time.Now()
+	var reservations []*rate.Reservation
+	for _, r := range matching {
+		if r.limiter == nil {
+			continue
+		}
+		reservation := r.limiter.ReserveN(now, 1)
+		if !reservation.OK() || reservation.DelayFrom(now) > 0 {
+			reservation.CancelAt(now)
+			for _, reservation := range reservations {
+				reservation.CancelAt(now)
+			}
+			rejectCount.Add(r.Name, 1)
+			outcome.Release()
+			return nil, vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "query rule %s: rate limit of %v queries per second exceeded", r.Name, r.MaxQPS)
+		}
+		reservations = append(reservations, reservation)
+	}
+	return outcome, nil
+}
+
+func (r *compiledRule) matches(q *Query) bool {
+	if r.fingerprint != nil && !r.fingerprint.MatchString(q.Fingerprint) {
+		return false
+	}
+	if len(r.Users) > 0 && !slices.Contains(r.Users, q.User) {
+		return false
+	}
+	if len(r.Keyspaces) > 0 && !containsAny(r.Keyspaces, q.Keyspaces) {
+		return false
+	}
+	if len(r.PlanTypes) > 0 && !containsAny(r.PlanTypes, q.PlanTypes) {
+		return false
+	}
+	return true
+}
+
+// admit checks the query against the concurrency limit of the rule, and adds its actions to the outcome
+func (r *compiledRule) admit(outcome *Outcome) error {
+	if r.MaxConcurrency > 0 {
+		if r.running.Add(1) > int64(r.MaxConcurrency) {
+			r.running.Add(-1)
+			return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "query rule %s: concurrency limit of %d queries exceeded", r.Name, r.MaxConcurrency)
+		}
+		outcome.running = append(outcome.running, r)
+	}
+	if r.QueryTimeout > 0 {
+		timeout := time.Duration(r.QueryTimeout) * time.Millisecond
+		if outcome.Timeout == 0 || timeout < outcome.Timeout {
+			outcome.Timeout = timeout
+		}
+	}
+	if r.tabletType != topodatapb.TabletType_UNKNOWN && outcome.TabletType == topodatapb.TabletType_UNKNOWN {
+		outcome.TabletType = r.tabletType
+	}
+	return nil
+}
+
+func containsAny(list, values []string) bool {
+	for _, v := range values {
+		if slices.Contains(list, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the rules as JSON
+func (qrs *Rules) String() string {
+	if qrs == nil {
+		return "[]"
+	}
+	return string(qrs.data)
+}
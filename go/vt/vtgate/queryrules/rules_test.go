@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queryrules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestParseErrors(t *testing.T) {
+	tcases := []struct {
+		rules string
+		err   string
+	}{{
+		rules: `{}`,
+		err:   "invalid query rules: json: cannot unmarshal object into Go value of type []queryrules.Rule",
+	}, {
+		rules: `[{"Deny": true}]`,
+		err:   "query rule without a name",
+	}, {
+		rules: `[{"Name": "r1", "Deny": true}, {"Name": "r1", "Deny": true}]`,
+		err:   "duplicate query rule r1",
+	}, {
+		rules: `[{"Name": "r1", "Fingerprint": "(", "Deny": true}]`,
+		err:   "query rule r1: invalid fingerprint: error parsing regexp: missing closing ): `(`",
+	}, {
+		rules: `[{"Name": "r1", "TabletType": "nope"}]`,
+		err:   "query rule r1: unknown TabletType nope",
+	}, {
+		rules: `[{"Name": "r1", "MaxQPS": -1}]`,
+		err:   "query rule r1: timeout and limits cannot be negative",
+	}, {
+		rules: `[{"Name": "r1", "Users": ["bob"]}]`,
+		err:   "query rule r1 does not do anything",
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.rules, func(t *testing.T) {
+			_, err := Parse([]byte(tcase.rules))
+			require.EqualError(t, err, tcase.err)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	qrs, err := Parse([]byte(`[{
+		"Name": "deny_scatter",
+		"Keyspaces": ["ks"],
+		"PlanTypes": ["Scatter"],
+		"Users": ["app"],
+		"Deny": true
+	}, {
+		"Name": "slow_reports",
+		"Fingerprint": "^select .* from reports",
+		"QueryTimeout": 100,
+		"TabletType": "rdonly"
+	}, {
+		"Name": "all_reads",
+		"Fingerprint": "^select",
+		"QueryTimeout": 1000,
+		"TabletType": "replica"
+	}]`))
+	require.NoError(t, err)
+
+	// the user does not match
+	outcome, err := qrs.Apply(&Query{Fingerprint: "delete from t", User: "admin", Keyspaces: []string{"ks"}, PlanTypes: []string{"Scatter"}})
+	require.NoError(t, err)
+	assert.Zero(t, outcome.Timeout)
+	assert.Equal(t, topodatapb.TabletType_UNKNOWN, outcome.TabletType)
+
+	_, err = qrs.Apply(&Query{Fingerprint: "delete from t", User: "app", Keyspaces: []string{"other", "ks"}, PlanTypes: []string{"Join", "Scatter"}})
+	require.EqualError(t, err, "query disallowed due to rule: deny_scatter")
+
+	// the first tablet type and the shortest timeout win
+	outcome, err = qrs.Apply(&Query{Fingerprint: "select a from reports", User: "app", Keyspaces: []string{"ks"}, PlanTypes: []string{"EqualUnique"}})
+	require.NoError(t, err)
+	assert.Equal(t, 100*time.Millisecond, outcome.Timeout)
+	assert.Equal(t, topodatapb.TabletType_RDONLY, outcome.TabletType)
+
+	outcome, err = qrs.Apply(&Query{Fingerprint: "select a from t", User: "app"})
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, outcome.Timeout)
+	assert.Equal(t, topodatapb.TabletType_REPLICA, outcome.TabletType)
+
+	var empty *Rules
+	outcome, err = empty.Apply(&Query{Fingerprint: "select a from t"})
+	require.NoError(t, err)
+	assert.Zero(t, outcome.Timeout)
+}
+
+func TestApplyLimits(t *testing.T) {
+	qrs, err := Parse([]byte(`[{"Name": "concurrency", "Fingerprint": "from t", "MaxConcurrency": 2}, {"Name": "qps", "Fingerprint": "from q", "MaxQPS": 2}]`))
+	require.NoError(t, err)
+
+	q := &Query{Fingerprint: "select a from t"}
+	first, err := qrs.Apply(q)
+	require.NoError(t, err)
+	second, err := qrs.Apply(q)
+	require.NoError(t, err)
+	_, err = qrs.Apply(q)
+	require.EqualError(t, err, "query rule concurrency: concurrency limit of 2 queries exceeded")
+
+	first.Release()
+	third, err := qrs.Apply(q)
+	require.NoError(t, err)
+	second.Release()
+	third.Release()
+
+	q = &Query{Fingerprint: "select a from q"}
+	for i := 0; i < 2; i++ {
+		outcome, err := qrs.Apply(q)
+		require.NoError(t, err)
+		outcome.Release()
+	}
+	_, err = qrs.Apply(q)
+	require.EqualError(t, err, "query rule qps: rate limit of 2 queries per second exceeded")
+}
+
+func TestApplyRejectedByLaterRule(t *testing.T) {
+	qrs, err := Parse([]byte(`[
+		{"Name": "qps", "Fingerprint": "from", "MaxQPS": 2},
+		{"Name": "deny", "Fingerprint": "from t", "Users": ["bad"], "Deny": true},
+		{"Name": "concurrency", "Fingerprint": "from t", "MaxConcurrency": 1},
+		{"Name": "qps_t", "Fingerprint": "from t", "MaxQPS": 1}
+	]`))
+	require.NoError(t, err)
+
+	// the queries rejected by the later rules don't use up the tokens of the first one
+	_, err = qrs.Apply(&Query{Fingerprint: "select a from t", User: "bad"})
+	require.EqualError(t, err, "query disallowed due to rule: deny")
+
+	first, err := qrs.Apply(&Query{Fingerprint: "select a from t"})
+	require.NoError(t, err)
+	_, err = qrs.Apply(&Query{Fingerprint: "select a from t"})
+	require.EqualError(t, err, "query rule concurrency: concurrency limit of 1 queries exceeded")
+	first.Release()
+
+	_, err = qrs.Apply(&Query{Fingerprint: "select a from t"})
+	require.EqualError(t, err, "query rule qps_t: rate limit of 1 queries per second exceeded")
+
+	outcome, err := qrs.Apply(&Query{Fingerprint: "select a from q"})
+	require.NoError(t, err)
+	outcome.Release()
+	_, err = qrs.Apply(&Query{Fingerprint: "select a from q"})
+	require.EqualError(t, err, "query rule qps: rate limit of 2 queries per second exceeded")
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queryrules
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+)
+
+// sleepDuringTopoFailure is how long to sleep before retrying in case of error.
+// (it's a var not a const so the test can change the value).
+var sleepDuringTopoFailure = 30 * time.Second
+
+// TopoWatcher watches a file in the topo holding the query rules,
+// and hands every new version of the rules to its subscriber.
+type TopoWatcher struct {
+	// watcher watches the file holding the rules.
+	watcher *topo.FileWatcher
+
+	// subscriber is called with the rules every time they change.
+	subscriber func(*Rules)
+
+	// qrs is the current rule set that we read.
+	qrs *Rules
+}
+
+// NewTopoWatcher creates a watcher for the rules stored in the given file of the cell
+func NewTopoWatcher(ts *topo.Server, cell, filePath string, subscriber func(*Rules)) (*TopoWatcher, error) {
+	conn, err := ts.ConnForCell(context.Background(), cell)
+	if err != nil {
+		return nil, err
+	}
+	tw := &TopoWatcher{
+		subscriber: subscriber,
+	}
+	tw.watcher = topo.NewFileWatcher(conn, filePath, sleepDuringTopoFailure, tw.apply)
+	return tw, nil
+}
+
+// Start watches the rules in the background, until Stop is called
+func (tw *TopoWatcher) Start() {
+	tw.watcher.Start()
+}
+
+// Stop stops watching the rules
+func (tw *TopoWatcher) Stop() {
+	tw.watcher.Stop()
+}
+
+func (tw *TopoWatcher) apply(wd *topo.WatchData) error {
+	qrs, err := Parse(wd.Contents)
+	if err != nil {
+		return fmt.Errorf("error parsing query rules: %v, original data '%s' version %v", err, wd.Contents, wd.Version)
+	}
+
+	if !tw.qrs.Equal(qrs) {
+		tw.qrs = qrs
+		tw.subscriber(qrs)
+		log.Infof("Query rules version %v fetched from topo and applied to vtgate", wd.Version)
+	}
+
+	return nil
+}
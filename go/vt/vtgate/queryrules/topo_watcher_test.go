@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queryrules
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+const (
+	rules1 = `[{"Name": "r1", "Fingerprint": "from t1", "Deny": true}]`
+	rules2 = `[{"Name": "r2", "Fingerprint": "from t2", "MaxConcurrency": 1}]`
+)
+
+func TestTopoWatcher(t *testing.T) {
+	cell := "cell1"
+	filePath := "/vtgate/QueryRules"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts := memorytopo.NewServer(ctx, cell)
+	sleepDuringTopoFailure = time.Millisecond
+
+	var current atomic.Pointer[Rules]
+	tw, err := NewTopoWatcher(ts, cell, filePath, current.Store)
+	require.NoError(t, err)
+	tw.Start()
+	defer tw.Stop()
+
+	waitForRules := func(expected string) {
+		require.Eventually(t, func() bool {
+			return current.Load().String() == expected
+		}, 10*time.Second, 10*time.Millisecond)
+	}
+
+	conn, err := ts.ConnForCell(ctx, cell)
+	require.NoError(t, err)
+	_, err = conn.Create(ctx, filePath, []byte(rules1))
+	require.NoError(t, err)
+	waitForRules(rules1)
+
+	// invalid rules are ignored until they get fixed
+	_, err = conn.Update(ctx, filePath, []byte(`[{"Name": "bad"}]`), nil)
+	require.NoError(t, err)
+	_, err = conn.Update(ctx, filePath, []byte(rules2), nil)
+	require.NoError(t, err)
+	waitForRules(rules2)
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/resultcache"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+	"vitess.io/vitess/go/vt/vthash"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// SetResultCache sets the cache for the results of read-only queries
+func (e *Executor) SetResultCache(c *resultcache.Cache) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.resultCache = c
+	c.SetTables(resultCacheTables(e.vschema))
+}
+
+// resultCacheTables returns the tables of the vschema whose results are cached, qualified with their keyspace
+func resultCacheTables(vschema *vindexes.VSchema) []string {
+	if vschema == nil {
+		return nil
+	}
+	var tables []string
+	for ksName, ks := range vschema.Keyspaces {
+		for name, tbl := range ks.Tables {
+			if tbl.ResultCache {
+				tables = append(tables, ksName+"."+name)
+			}
+		}
+	}
+	return tables
+}
+
+// nonDeterministicFuncs are the functions whose results depend on when, or by whom, they are called
+var nonDeterministicFuncs = map[string]bool{
+	"rand":           true,
+	"uuid":           true,
+	"uuid_short":     true,
+	"last_insert_id": true,
+	"found_rows":     true,
+	"row_count":      true,
+	"connection_id":  true,
+	"database":       true,
+	"schema":         true,
+	"user":           true,
+	"current_user":   true,
+	"session_user":   true,
+	"system_user":    true,
+	"current_role":   true,
+	"sysdate":        true,
+	"now":            true,
+	"unix_timestamp": true,
+	"curdate":        true,
+	"current_date":   true,
+	"utc_date":       true,
+	"utc_time":       true,
+	"utc_timestamp":  true,
+	"curtime":        true,
+	"current_time":   true,
+	"sleep":          true,
+	"benchmark":      true,
+}
+
+// resultCacheable returns whether the result of the statement only depends on the rows it reads:
+// it doesn't lock them, doesn't call non-deterministic functions, and doesn't read variables.
+func resultCacheable(stmt sqlparser.Statement) bool {
+	cacheable := true
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.Select:
+			if node.Lock != sqlparser.NoLock || node.Into != nil {
+				cacheable = false
+			}
+		case *sqlparser.Union:
+			if node.Lock != sqlparser.NoLock || node.Into != nil {
+				cacheable = false
+			}
+		case *sqlparser.LockingFunc, *sqlparser.Nextval, *sqlparser.Variable, *sqlparser.CurTimeFuncExpr:
+			cacheable = false
+		case *sqlparser.FuncExpr:
+			if nonDeterministicFuncs[node.Name.Lowered()] {
+				cacheable = false
+			}
+		}
+		return cacheable, nil
+	}, stmt)
+	return cacheable
+}
+
+// writtenTables returns the tables the plan writes to, whose cached results it invalidates
+func writtenTables(plan *engine.Plan) []string {
+	switch plan.Type {
+	case sqlparser.StmtInsert, sqlparser.StmtReplace, sqlparser.StmtUpdate, sqlparser.StmtDelete, sqlparser.StmtDDL:
+		return plan.TablesUsed
+	}
+	return nil
+}
+
+// executeWithResultCache executes the plan, answering from the result cache when the query can be cached
+func (e *Executor) executeWithResultCache(
+	ctx context.Context,
+	safeSession *SafeSession,
+	plan *engine.Plan,
+	vcursor *vcursorImpl,
+	bindVars map[string]*querypb.BindVariable,
+) (*sqltypes.Result, error) {
+	ttl := e.resultCacheTTL(safeSession, plan, vcursor)
+	if ttl == 0 {
+		return e.executeConsolidated(ctx, safeSession, plan, vcursor, bindVars)
+	}
+
+	key := queryResultKey(ctx, vcursor, plan.Original, bindVars)
+	if qr, ok := e.resultCache.Get(key); ok {
+		return qr, nil
+	}
+	generations := e.resultCache.Generations(plan.TablesUsed)
+	qr, err := e.executeConsolidated(ctx, safeSession, plan, vcursor, bindVars)
+	if err == nil {
+		e.resultCache.Set(key, qr, plan.TablesUsed, generations, ttl)
+	}
+	return qr, err
+}
+
+// resultCacheTTL returns for how long the result of the query can be cached, or 0 if it should not be
+func (e *Executor) resultCacheTTL(safeSession *SafeSession, plan *engine.Plan, vcursor *vcursorImpl) time.Duration {
+	// transactions have to see their own changes
+	if e.resultCache == nil || plan.Type != sqlparser.StmtSelect || !vcursor.resultCacheable || safeSession.InTransaction() {
+		return 0
+	}
+	return e.resultCache.TTL(plan.TablesUsed, vcursor.resultCacheTTL)
+}
+
+// queryResultKey identifies the result of a query: the user running it, the query plan, and the values of its bind variables
+func queryResultKey(ctx context.Context, vcursor *vcursorImpl, query string, bindVars map[string]*querypb.BindVariable) resultcache.Key {
+	hasher := vthash.New256()
+	// the tablets might not let every user read every table
+	_, _ = hasher.WriteString(callerid.ImmediateCallerIDFromContext(ctx).GetUsername())
+	_, _ = hasher.WriteString("+Plan:")
+	vcursor.keyForPlan(ctx, query, hasher)
+
+	for _, name := range slices.Sorted(maps.Keys(bindVars)) {
+		bv := bindVars[name]
+		_, _ = hasher.WriteString("+" + name + ":" + bv.Type.String() + ":")
+		writeValue(hasher, bv.Value)
+		for _, v := range bv.Values {
+			_, _ = hasher.WriteString(":" + v.Type.String() + ":")
+			writeValue(hasher, v.Value)
+		}
+	}
+
+	var key resultcache.Key
+	hasher.Sum(key[:0])
+	return key
+}
+
+// writeValue writes the value with its length, so that different values can't be written the same way
+func writeValue(hasher *vthash.Hasher256, value []byte) {
+	_, _ = hasher.WriteString(strconv.Itoa(len(value)) + ":")
+	_, _ = hasher.Write(value)
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/vtgate/resultcache"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// setResultCache caches the results of the user table
+func setResultCache(t *testing.T, executor *Executor) *resultcache.Cache {
+	executor.VSchema().Keyspaces[KsTestSharded].Tables["user"].ResultCache = true
+	rc := resultcache.New(resultcache.Config{MaxMemory: 1024 * 1024, TTL: time.Minute})
+	t.Cleanup(rc.Close)
+	executor.SetResultCache(rc)
+	return rc
+}
+
+func TestResultCache(t *testing.T) {
+	executor, sbc1, _, _, ctx := createExecutorEnv(t)
+	rc := setResultCache(t, executor)
+
+	session := &vtgatepb.Session{TargetString: "@primary", Autocommit: true}
+	result := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1")
+
+	sbc1.SetResults([]*sqltypes.Result{result})
+	qr, err := executorExec(ctx, executor, session, "select id from user where id = 1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, result.Rows, qr.Rows)
+	assert.EqualValues(t, 1, sbc1.ExecCount.Load())
+
+	// the same query is answered from the cache
+	qr, err = executorExec(ctx, executor, session, "select id from user where id = 1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, result.Rows, qr.Rows)
+	assert.EqualValues(t, 1, sbc1.ExecCount.Load())
+
+	// but not with other values of the bind variables
+	_, err = executorExec(ctx, executor, session, "select id from user where id = 2", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, sbc1.ExecCount.Load())
+
+	// nor for other users
+	otherCtx := callerid.NewContext(ctx, &vtrpcpb.CallerID{}, &querypb.VTGateCallerID{Username: "other"})
+	_, err = executorExec(otherCtx, executor, session, "select id from user where id = 1", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, sbc1.ExecCount.Load())
+
+	// nor once the rows of the table changed
+	rc.Invalidate(KsTestSharded + ".user")
+	_, err = executorExec(ctx, executor, session, "select id from user where id = 1", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, sbc1.ExecCount.Load())
+
+	// transactions always read from the tablets
+	txSession := &vtgatepb.Session{TargetString: "@primary", InTransaction: true}
+	_, err = executorExec(ctx, executor, txSession, "select id from user where id = 1", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, sbc1.ExecCount.Load())
+
+	// the results of the other tables are only cached when the query asks for it
+	_, err = executorExec(ctx, executor, session, "select id from music where id = 1", nil)
+	require.NoError(t, err)
+	_, err = executorExec(ctx, executor, session, "select id from music where id = 1", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 7, sbc1.ExecCount.Load())
+
+	_, err = executorExec(ctx, executor, session, "select /*vt+ RESULT_CACHE_TTL_MS=60000 */ id from music where id = 1", nil)
+	require.NoError(t, err)
+	_, err = executorExec(ctx, executor, session, "select /*vt+ RESULT_CACHE_TTL_MS=60000 */ id from music where id = 1", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 8, sbc1.ExecCount.Load())
+}
+
+func TestResultCacheWrites(t *testing.T) {
+	executor, sbc1, _, _, ctx := createExecutorEnv(t)
+	setResultCache(t, executor)
+
+	session := &vtgatepb.Session{TargetString: "@primary", Autocommit: true}
+	read := func() int64 {
+		before := sbc1.ExecCount.Load()
+		_, err := executorExec(ctx, executor, session, "select id from user where id = 1", nil)
+		require.NoError(t, err)
+		return sbc1.ExecCount.Load() - before
+	}
+	require.EqualValues(t, 1, read())
+	require.EqualValues(t, 0, read())
+
+	// the writes invalidate the results before they are returned
+	_, err := executorExec(ctx, executor, session, "update user set a = 2 where id = 1", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, read())
+	assert.EqualValues(t, 0, read())
+
+	// the writes of a transaction invalidate the results when they are committed
+	txSession := &vtgatepb.Session{TargetString: "@primary", SessionUUID: "tx"}
+	_, err = executorExec(ctx, executor, txSession, "begin", nil)
+	require.NoError(t, err)
+	_, err = executorExec(ctx, executor, txSession, "update user set a = 3 where id = 1", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, read())
+	_, err = executorExec(ctx, executor, txSession, "commit", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, read())
+	assert.EqualValues(t, 0, read())
+}
+
+func TestResultCacheNonDeterministic(t *testing.T) {
+	executor, sbc1, _, _, ctx := createExecutorEnv(t)
+	setResultCache(t, executor)
+
+	session := &vtgatepb.Session{TargetString: "@primary", Autocommit: true}
+	queries := []string{
+		"select id, now() from user where id = 1",
+		"select id from user where id = 1 and a < unix_timestamp()",
+		"select id, rand() from user where id = 1",
+		"select id, uuid() from user where id = 1",
+		"select id, last_insert_id() from user where id = 1",
+		"select id, database() from user where id = 1",
+		"select id, current_user() from user where id = 1",
+		"select id, @x from user where id = 1",
+		"select id, @@sql_mode from user where id = 1",
+		"select id from user where id = 1 for update",
+		"select id from user where id = 1 lock in share mode",
+		"select /*vt+ RESULT_CACHE_TTL_MS=60000 */ id, now() from user where id = 1",
+	}
+	for _, query := range queries {
+		t.Run(query, func(t *testing.T) {
+			before := sbc1.ExecCount.Load()
+			for range 2 {
+				_, err := executorExec(ctx, executor, session, query, nil)
+				require.NoError(t, err)
+			}
+			assert.EqualValues(t, 2, sbc1.ExecCount.Load()-before)
+		})
+	}
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package resultcache implements the cache vtgate keeps of the results of read-only queries.
+
+Caching is opt-in: the results of a query are cached when it only reads from tables that set
+result_cache in the VSchema, or when it asks for it with the RESULT_CACHE_TTL_MS directive. Cached
+results expire after their TTL. They are also invalidated when vtgate writes to their tables, once the
+transaction writing is over, and, for the tables of the VSchema, as soon as any of their rows change,
+by following the changes of their keyspaces through VStream.
+*/
+package resultcache
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"vitess.io/vitess/go/cache/theine"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+)
+
+var (
+	cacheHits          = stats.NewCounter("ResultCacheHits", "Number of queries answered from the vtgate result cache")
+	cacheMisses        = stats.NewCounter("ResultCacheMisses", "Number of cacheable queries that were not found in the vtgate result cache")
+	tableInvalidations = stats.NewCountersWithSingleLabel("ResultCacheInvalidations", "Number of times the cached results of a table were invalidated", "Table")
+)
+
+// Key identifies a query and its bind variables
+type Key = theine.HashKey256
+
+// Config is the configuration of the cache
+type Config struct {
+	// MaxMemory is the maximum number of bytes of results the cache can hold
+	MaxMemory int64
+	// TTL is how long results of the tables of the VSchema are cached
+	TTL time.Duration
+	// Doorkeeper only caches the results of queries that have been seen before
+	Doorkeeper bool
+}
+
+// Cache is the result cache. It is safe to use concurrently.
+type Cache struct {
+	store *theine.Store[Key, *entry]
+	epoch atomic.Uint32
+	ttl   time.Duration
+	// retryDelay is how long we wait before following the changes of a keyspace again after an error
+	retryDelay time.Duration
+
+	mu sync.Mutex
+	// tables are the tables whose results are cached, qualified with their keyspace
+	tables map[string]bool
+	// generations are increased every time the cached results of a table are invalidated
+	generations map[string]uint64
+	// written has the tables written by the open transactions, by session
+	written map[string][]string
+	// vstream follows the changes of the tables, once they are watched
+	vstream VStreamer
+	// watchCtx is the context of the watches, once the tables are watched
+	watchCtx context.Context
+	// watches are the watches of the changes of the tables, by keyspace
+	watches map[string]*watch
+}
+
+type entry struct {
+	result      *sqltypes.Result
+	tables      []string
+	generations []uint64
+	expires     time.Time
+}
+
+// CachedSize returns the approximate number of bytes used by the entry
+func (e *entry) CachedSize(alloc bool) int64 {
+	size := int64(80) + e.result.CachedSize(true)
+	for _, tbl := range e.tables {
+		size += int64(16 + len(tbl) + 8)
+	}
+	return size
+}
+
+// New creates a result cache
+func New(cfg Config) *Cache {
+	c := &Cache{
+		store:       theine.NewStore[Key, *entry](cfg.MaxMemory, cfg.Doorkeeper),
+		ttl:         cfg.TTL,
+		retryDelay:  retryDelay,
+		tables:      map[string]bool{},
+		generations: map[string]uint64{},
+		written:     map[string][]string{},
+	}
+	return c
+}
+
+// SetTables sets the tables whose results are cached, qualified with their keyspace. They are
+// the tables that set result_cache in the VSchema.
+func (c *Cache) SetTables(tables []string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tables = map[string]bool{}
+	for _, tbl := range tables {
+		c.tables[tbl] = true
+	}
+	if c.watches != nil {
+		c.updateWatches()
+	}
+}
+
+// TTL returns for how long the results of a query reading from the tables can be cached,
+// or 0 if they cannot be. A positive directiveTTL is used for any tables.
+func (c *Cache) TTL(tables []string, directiveTTL time.Duration) time.Duration {
+	if c == nil || len(tables) == 0 {
+		return 0
+	}
+	if directiveTTL > 0 {
+		return directiveTTL
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, tbl := range tables {
+		if !c.tables[tbl] {
+			return 0
+		}
+		// if the changes of the keyspace are not followed at the moment, we can't know when the results get stale
+		if c.watches != nil && !c.watches[keyspaceOf(tbl)].isWatching() {
+			return 0
+		}
+	}
+	return c.ttl
+}
+
+// Get returns a cached result
+func (c *Cache) Get(key Key) (*sqltypes.Result, bool) {
+	e, ok := c.store.Get(key, c.epoch.Load())
+	if ok && !c.valid(e) {
+		c.store.Delete(key)
+		ok = false
+	}
+	if !ok {
+		cacheMisses.Add(1)
+		return nil, false
+	}
+	cacheHits.Add(1)
+	return e.result.Copy(), true
+}
+
+func (c *Cache) valid(e *entry) bool {
+	if time.Now().After(e.expires) {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, tbl := range e.tables {
+		if c.generations[tbl] != e.generations[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Generations returns the current generations of the tables. They must be read
+// before running the query whose result is cached, and passed on to Set.
+func (c *Cache) Generations(tables []string) []uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	generations := make([]uint64, len(tables))
+	for i, tbl := range tables {
+		generations[i] = c.generations[tbl]
+	}
+	return generations
+}
+
+// Set caches the result of a query reading from the tables. The result is not cached
+// if any of the tables were invalidated since the generations were read.
+func (c *Cache) Set(key Key, result *sqltypes.Result, tables []string, generations []uint64, ttl time.Duration) {
+	e := &entry{
+		result:      result.Copy(),
+		tables:      tables,
+		generations: generations,
+		expires:     time.Now().Add(ttl),
+	}
+	if !c.valid(e) {
+		return
+	}
+	c.store.Set(key, e, 0, c.epoch.Load())
+}
+
+// Invalidate invalidates the cached results that read from the table
+func (c *Cache) Invalidate(table string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.invalidate(table)
+	c.mu.Unlock()
+}
+
+// invalidate must be called with mu held
+func (c *Cache) invalidate(table string) {
+	c.generations[table]++
+	tableInvalidations.Add(table, 1)
+}
+
+// TrackWrites invalidates the cached results of the tables written by a query of the session,
+// before its result is returned. The other sessions only see the writes of a transaction once it
+// is committed, so the tables written in a transaction are invalidated when it is over,
+// i.e. when the session is not in a transaction any more. It must be called after every query.
+func (c *Cache) TrackWrites(session string, inTransaction bool, tables []string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// without a session, the end of the transaction can't be tracked
+	if inTransaction && session != "" {
+		for _, tbl := range tables {
+			if !slices.Contains(c.written[session], tbl) {
+				c.written[session] = append(c.written[session], tbl)
+			}
+		}
+		return
+	}
+	for _, tbl := range tables {
+		c.invalidate(tbl)
+	}
+	for _, tbl := range c.written[session] {
+		c.invalidate(tbl)
+	}
+	delete(c.written, session)
+}
+
+// InvalidateAll invalidates all the cached results
+func (c *Cache) InvalidateAll() {
+	if c == nil {
+		return
+	}
+	c.epoch.Add(1)
+}
+
+// invalidateKeyspace invalidates the cached results of the tables of the keyspace
+func (c *Cache) invalidateKeyspace(keyspace string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for tbl := range c.tables {
+		if keyspaceOf(tbl) == keyspace {
+			c.invalidate(tbl)
+		}
+	}
+}
+
+// Close stops the cache
+func (c *Cache) Close() {
+	c.store.Close()
+}
+
+func keyspaceOf(table string) string {
+	ks, _, _ := strings.Cut(table, ".")
+	return ks
+}
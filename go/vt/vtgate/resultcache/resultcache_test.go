@@ -0,0 +1,214 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resultcache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+func newTestCache(t *testing.T, tables ...string) *Cache {
+	c := New(Config{MaxMemory: 1024 * 1024, TTL: time.Minute})
+	t.Cleanup(c.Close)
+	c.SetTables(tables)
+	return c
+}
+
+func testResult(value string) *sqltypes.Result {
+	return sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "varchar"), value)
+}
+
+func TestTTL(t *testing.T) {
+	c := newTestCache(t, "ks.t1", "ks.t2")
+
+	assert.Equal(t, time.Minute, c.TTL([]string{"ks.t1", "ks.t2"}, 0))
+	assert.Zero(t, c.TTL([]string{"ks.t1", "ks.t3"}, 0))
+	assert.Zero(t, c.TTL(nil, 0))
+	// the directive can cache the results of any table
+	assert.Equal(t, time.Second, c.TTL([]string{"ks.t1", "ks.t3"}, time.Second))
+
+	var disabled *Cache
+	assert.Zero(t, disabled.TTL([]string{"ks.t1"}, time.Second))
+
+	c.SetTables([]string{"ks.t3"})
+	assert.Zero(t, c.TTL([]string{"ks.t1"}, 0))
+	assert.Equal(t, time.Minute, c.TTL([]string{"ks.t3"}, 0))
+}
+
+func TestGetSet(t *testing.T) {
+	c := newTestCache(t, "ks.t1")
+	tables := []string{"ks.t1"}
+	key := Key{1}
+
+	_, ok := c.Get(key)
+	require.False(t, ok)
+
+	c.Set(key, testResult("a"), tables, c.Generations(tables), time.Minute)
+	qr, ok := c.Get(key)
+	require.True(t, ok)
+	assert.Equal(t, testResult("a"), qr)
+
+	// a change of the table invalidates its results
+	c.Invalidate("ks.t1")
+	_, ok = c.Get(key)
+	require.False(t, ok)
+
+	// a result read before the table changed is not cached
+	generations := c.Generations(tables)
+	c.Invalidate("ks.t1")
+	c.Set(key, testResult("b"), tables, generations, time.Minute)
+	_, ok = c.Get(key)
+	require.False(t, ok)
+
+	c.Set(key, testResult("c"), tables, c.Generations(tables), time.Minute)
+	c.InvalidateAll()
+	_, ok = c.Get(key)
+	require.False(t, ok)
+
+	// expired results are not returned
+	c.Set(key, testResult("d"), tables, c.Generations(tables), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	_, ok = c.Get(key)
+	require.False(t, ok)
+}
+
+func TestTrackWrites(t *testing.T) {
+	c := newTestCache(t, "ks.t1", "ks.t2")
+	key1, key2 := Key{1}, Key{2}
+	set := func() {
+		c.Set(key1, testResult("a"), []string{"ks.t1"}, c.Generations([]string{"ks.t1"}), time.Minute)
+		c.Set(key2, testResult("b"), []string{"ks.t2"}, c.Generations([]string{"ks.t2"}), time.Minute)
+	}
+	cached := func(key Key) bool {
+		_, ok := c.Get(key)
+		return ok
+	}
+
+	// the writes outside of a transaction invalidate the results right away
+	set()
+	c.TrackWrites("s1", false, []string{"ks.t1"})
+	assert.False(t, cached(key1))
+	assert.True(t, cached(key2))
+
+	// the writes of a transaction invalidate the results when it is over,
+	// in case other sessions cached them before it was committed
+	set()
+	c.TrackWrites("s1", true, []string{"ks.t1"})
+	c.TrackWrites("s1", true, []string{"ks.t2"})
+	c.TrackWrites("s2", false, nil)
+	set()
+	assert.True(t, cached(key1))
+	assert.True(t, cached(key2))
+	c.TrackWrites("s1", false, nil)
+	assert.False(t, cached(key1))
+	assert.False(t, cached(key2))
+
+	// once
+	set()
+	c.TrackWrites("s1", false, nil)
+	assert.True(t, cached(key1))
+	assert.True(t, cached(key2))
+
+	// without a session, the writes of a transaction invalidate the results right away
+	c.TrackWrites("", true, []string{"ks.t2"})
+	assert.True(t, cached(key1))
+	assert.False(t, cached(key2))
+
+	var disabled *Cache
+	disabled.TrackWrites("s1", false, []string{"ks.t1"})
+}
+
+func TestWatchTables(t *testing.T) {
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = time.Millisecond
+
+	events := make(chan []*binlogdatapb.VEvent)
+	vstream := func(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid,
+		filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags, send func([]*binlogdatapb.VEvent) error) error {
+		assert.Equal(t, "ks", vgtid.ShardGtids[0].Keyspace)
+		assert.Equal(t, "t1", filter.Rules[0].Match)
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case evs := <-events:
+				if evs == nil {
+					return assert.AnError
+				}
+				if err := send(evs); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	c := newTestCache(t, "ks.t1")
+	tables := []string{"ks.t1"}
+	key := Key{1}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c.WatchTables(ctx, vstream)
+
+	// nothing is cached until the changes are followed
+	assert.Zero(t, c.TTL(tables, 0))
+	heartbeat := []*binlogdatapb.VEvent{{Type: binlogdatapb.VEventType_HEARTBEAT}}
+	events <- heartbeat
+	events <- heartbeat
+	assert.Equal(t, time.Minute, c.TTL(tables, 0))
+
+	c.Set(key, testResult("a"), tables, c.Generations(tables), time.Minute)
+	_, ok := c.Get(key)
+	require.True(t, ok)
+
+	events <- []*binlogdatapb.VEvent{{Type: binlogdatapb.VEventType_ROW, RowEvent: &binlogdatapb.RowEvent{TableName: "t1"}}}
+	events <- heartbeat
+	_, ok = c.Get(key)
+	require.False(t, ok)
+
+	// the results are invalidated, and not cached any more, when the stream fails
+	c.Set(key, testResult("b"), tables, c.Generations(tables), time.Minute)
+	events <- nil
+	assert.Eventually(t, func() bool {
+		_, ok := c.Get(key)
+		return !ok
+	}, 5*time.Second, time.Millisecond)
+
+	// until the stream is back
+	events <- heartbeat
+	events <- heartbeat
+	assert.Equal(t, time.Minute, c.TTL(tables, 0))
+
+	// the watch stops with the caching of the table
+	c.Set(key, testResult("c"), tables, c.Generations(tables), time.Minute)
+	c.SetTables(nil)
+	assert.Zero(t, c.TTL(tables, 0))
+	_, ok = c.Get(key)
+	require.False(t, ok)
+	c.mu.Lock()
+	assert.Empty(t, c.watches)
+	c.mu.Unlock()
+}
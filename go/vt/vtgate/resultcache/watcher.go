@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resultcache
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// retryDelay is how long we wait before following the changes of a keyspace again after an error.
+// (it's a var not a const so the test can change the value).
+var retryDelay = 5 * time.Second
+
+// VStreamer streams the changes of a keyspace, like the VStream API of vtgate does
+type VStreamer func(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid,
+	filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags, send func([]*binlogdatapb.VEvent) error) error
+
+// watch follows the changes of the tables of a keyspace
+type watch struct {
+	tables []string
+	cancel context.CancelFunc
+	// watching is set while the changes are being followed
+	watching bool
+}
+
+func (w *watch) isWatching() bool {
+	return w != nil && w.watching
+}
+
+// WatchTables follows the changes of the tables, and invalidates their cached results
+// when any of their rows change, until the context is done. The results of a keyspace
+// are not cached while its changes are not being followed. The watches follow the
+// tables set with SetTables.
+func (c *Cache) WatchTables(ctx context.Context, vstream VStreamer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.watchCtx = ctx
+	c.vstream = vstream
+	c.watches = map[string]*watch{}
+	c.updateWatches()
+}
+
+// updateWatches restarts the watches of the keyspaces whose tables changed.
+// It must be called with mu held.
+func (c *Cache) updateWatches() {
+	tablesByKeyspace := map[string][]string{}
+	for tbl := range c.tables {
+		ks, name, _ := strings.Cut(tbl, ".")
+		tablesByKeyspace[ks] = append(tablesByKeyspace[ks], name)
+	}
+	for _, tables := range tablesByKeyspace {
+		slices.Sort(tables)
+	}
+
+	for ks, w := range c.watches {
+		if slices.Equal(w.tables, tablesByKeyspace[ks]) {
+			continue
+		}
+		w.cancel()
+		delete(c.watches, ks)
+		// the changes of the tables are not followed until the new watch starts
+		for _, name := range w.tables {
+			c.invalidate(ks + "." + name)
+		}
+	}
+	for ks, tables := range tablesByKeyspace {
+		if c.watches[ks] != nil {
+			continue
+		}
+		ctx, cancel := context.WithCancel(c.watchCtx)
+		w := &watch{tables: tables, cancel: cancel}
+		c.watches[ks] = w
+		go c.watchKeyspace(ctx, w, ks)
+	}
+}
+
+func (c *Cache) watchKeyspace(ctx context.Context, w *watch, keyspace string) {
+	filter := &binlogdatapb.Filter{}
+	for _, tbl := range w.tables {
+		filter.Rules = append(filter.Rules, &binlogdatapb.Rule{Match: tbl})
+	}
+	vgtid := &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: keyspace, Gtid: "current"}}}
+
+	for {
+		err := c.vstream(ctx, topodatapb.TabletType_PRIMARY, vgtid, filter, &vtgatepb.VStreamFlags{}, func(events []*binlogdatapb.VEvent) error {
+			c.setWatched(keyspace, w, true)
+			for _, ev := range events {
+				switch ev.Type {
+				case binlogdatapb.VEventType_ROW:
+					c.Invalidate(keyspace + "." + ev.RowEvent.TableName)
+				case binlogdatapb.VEventType_DDL, binlogdatapb.VEventType_JOURNAL:
+					c.invalidateKeyspace(keyspace)
+				}
+			}
+			return nil
+		})
+
+		// we might have missed changes while we were not following them
+		c.setWatched(keyspace, w, false)
+		c.invalidateKeyspace(keyspace)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.retryDelay):
+		}
+		log.Warningf("Following the changes of keyspace %s for the result cache failed, retrying: %v", keyspace, err)
+	}
+}
+
+// setWatched records whether the changes of the keyspace are being followed,
+// unless the watch was replaced in the meantime.
+func (c *Cache) setWatched(keyspace string, w *watch, watching bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.watches[keyspace] == w {
+		w.watching = watching
+	}
+}
@@ -66,6 +66,9 @@ type (
 
 		logging *executeLogger
 
+		// afterTransaction are the functions to call once the transaction is over
+		afterTransaction []func()
+
 		*vtgatepb.Session
 	}
 
@@ -505,6 +508,22 @@ func (session *SafeSession) RecordWarning(warning *querypb.QueryWarning) {
 	session.Session.Warnings = append(session.Session.Warnings, warning)
 }
 
+// AfterTransaction registers a function to call once the transaction of the session is over.
+func (session *SafeSession) AfterTransaction(f func()) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	session.afterTransaction = append(session.afterTransaction, f)
+}
+
+// takeAfterTransaction returns the functions registered with AfterTransaction, and forgets them.
+func (session *SafeSession) takeAfterTransaction() []func() {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	funcs := session.afterTransaction
+	session.afterTransaction = nil
+	return funcs
+}
+
 // ClearWarnings removes all the warnings from the session
 func (session *SafeSession) ClearWarnings() {
 	session.mu.Lock()
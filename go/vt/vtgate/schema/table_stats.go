@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"math/bits"
+	"strings"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
+)
+
+const (
+	// tableRowsQuery fetches the estimated number of rows of the tables, as kept by InnoDB
+	tableRowsQuery = "select table_name, table_rows from information_schema.tables " +
+		"where table_schema = database() and table_type = 'BASE TABLE'"
+
+	// indexCardinalityQuery fetches the estimated number of distinct values for the first column of every index
+	indexCardinalityQuery = "select table_name, column_name, max(cardinality) from information_schema.statistics " +
+		"where table_schema = database() and seq_in_index = 1 and column_name is not null " +
+		"group by table_name, column_name"
+)
+
+// loadTableStats loads the table statistics from the tablet, and stores them for the tables of the keyspace.
+// It returns true if the statistics of any of the tables changed enough to affect the plans.
+func (t *Tracker) loadTableStats(conn queryservice.QueryService, target *querypb.Target) (bool, error) {
+	// we don't want to retry on every health check if the tablet fails to give us the statistics
+	t.tracked[target.Keyspace].setStatsLoaded()
+
+	rowsRes, err := conn.Execute(t.ctx, target, tableRowsQuery, nil, 0, 0, nil)
+	if err != nil {
+		return false, err
+	}
+	cardinalityRes, err := conn.Execute(t.ctx, target, indexCardinalityQuery, nil, 0, 0, nil)
+	if err != nil {
+		return false, err
+	}
+
+	stats := map[tableNameStr]*vindexes.TableStats{}
+	for _, row := range rowsRes.Rows {
+		rows, _ := row[1].ToUint64()
+		stats[row[0].ToString()] = &vindexes.TableStats{Rows: rows}
+	}
+	for _, row := range cardinalityRes.Rows {
+		ts := stats[row[0].ToString()]
+		if ts == nil {
+			continue
+		}
+		if ts.Cardinality == nil {
+			ts.Cardinality = map[string]uint64{}
+		}
+		cardinality, _ := row[2].ToUint64()
+		ts.Cardinality[strings.ToLower(row[1].ToString())] = cardinality
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	changed := false
+	for tbl, tblInfo := range t.tables.m[target.Keyspace] {
+		ts := stats[tbl]
+		if !statsDiffer(tblInfo.Stats, ts) {
+			continue
+		}
+		changed = true
+		// the table info is shared with the vschema manager, so we replace it instead of changing it
+		newInfo := *tblInfo
+		newInfo.Stats = ts
+		t.tables.m[target.Keyspace][tbl] = &newInfo
+	}
+	return changed, nil
+}
+
+// statsDiffer returns true if the statistics are different enough for the planner to possibly make a different choice.
+// The estimates are only compared by their order of magnitude, so that small changes in the data do not cause replanning.
+func statsDiffer(a, b *vindexes.TableStats) bool {
+	if a == nil || b == nil {
+		return a != b
+	}
+	if bits.Len64(a.Rows) != bits.Len64(b.Rows) || len(a.Cardinality) != len(b.Cardinality) {
+		return true
+	}
+	for col, cardinality := range a.Cardinality {
+		other, found := b.Cardinality[col]
+		if !found || bits.Len64(cardinality) != bits.Len64(other) {
+			return true
+		}
+	}
+	return false
+}
@@ -54,6 +54,9 @@ type (
 		tracked      map[keyspaceStr]*updateController
 		consumeDelay time.Duration
 
+		// statsRefreshInterval is how often the table statistics are reloaded. 0 means they are not tracked.
+		statsRefreshInterval time.Duration
+
 		parser *sqlparser.Parser
 	}
 )
@@ -96,6 +99,13 @@ func (t *Tracker) LoadKeyspace(conn queryservice.QueryService, target *querypb.T
 		return err
 	}
 
+	if t.statsRefreshInterval > 0 {
+		// the statistics are only used to improve the plans, so failing to load them is not a problem
+		if _, err := t.loadTableStats(conn, target); err != nil {
+			log.Warningf("error loading table statistics for keyspace %s: %v", target.Keyspace, err)
+		}
+	}
+
 	t.tracked[target.Keyspace].setLoaded(true)
 	return nil
 }
@@ -221,7 +231,13 @@ func (t *Tracker) getKeyspaceUpdateController(th *discovery.TabletHealth) *updat
 }
 
 func (t *Tracker) newUpdateController() *updateController {
-	return &updateController{update: t.updateSchema, reloadKeyspace: t.initKeyspace, signal: t.signal, consumeDelay: t.consumeDelay}
+	return &updateController{
+		update:         t.updateSchema,
+		reloadKeyspace: t.initKeyspace,
+		signal:         t.signal,
+		consumeDelay:   t.consumeDelay,
+		statsInterval:  t.statsRefreshInterval,
+	}
 }
 
 func (t *Tracker) initKeyspace(th *discovery.TabletHealth) error {
@@ -233,6 +249,12 @@ func (t *Tracker) initKeyspace(th *discovery.TabletHealth) error {
 	return nil
 }
 
+// EnableTableStats makes the tracker collect the table statistics of the tracked keyspaces,
+// and reload them at the given interval. It must be called before the tracker is started.
+func (t *Tracker) EnableTableStats(refreshInterval time.Duration) {
+	t.statsRefreshInterval = refreshInterval
+}
+
 // Stop stops the schema tracking
 func (t *Tracker) Stop() {
 	log.Info("Stopping schema tracking")
@@ -315,6 +337,12 @@ func (t *Tracker) updateSchema(th *discovery.TabletHealth) bool {
 		return false
 	}
 
+	statsChanged := t.updateTableStats(th)
+	if len(th.Stats.TableSchemaChanged) == 0 && len(th.Stats.ViewSchemaChanged) == 0 && !th.Stats.UdfsChanged {
+		// we were only asked to refresh the statistics, so we only signal if they changed enough to matter
+		return statsChanged
+	}
+
 	// there is view definition change in the tablet
 	if th.Stats.ViewSchemaChanged != nil {
 		success = t.updatedViewSchema(th)
@@ -327,6 +355,21 @@ func (t *Tracker) updateSchema(th *discovery.TabletHealth) bool {
 	return t.loadUDFs(th.Conn, th.Target) == nil
 }
 
+// updateTableStats reloads the table statistics if they are due for a refresh, or if the table schema changed.
+// It returns true if the statistics changed enough to be worth planning the queries again.
+func (t *Tracker) updateTableStats(th *discovery.TabletHealth) bool {
+	uc := t.tracked[th.Target.Keyspace]
+	if t.statsRefreshInterval == 0 || (len(th.Stats.TableSchemaChanged) == 0 && !uc.statsDue()) {
+		return false
+	}
+	changed, err := t.loadTableStats(th.Conn, th.Target)
+	if err != nil {
+		log.Warningf("error loading table statistics for keyspace %s: %v", th.Target.Keyspace, err)
+		return false
+	}
+	return changed
+}
+
 func (t *Tracker) updatedTableSchema(th *discovery.TabletHealth) bool {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -548,3 +548,77 @@ func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
 		return true // timed out
 	}
 }
+
+// TestTableStatsTracking tests that the tracker loads the table statistics with the schema,
+// and only signals a stats refresh when the statistics changed enough to matter.
+func TestTableStatsTracking(t *testing.T) {
+	ch := make(chan *discovery.TabletHealth)
+	tracker := NewTracker(ch, false, false, sqlparser.NewTestParser())
+	tracker.consumeDelay = 1 * time.Millisecond
+	tracker.EnableTableStats(10 * time.Millisecond)
+	tracker.Start()
+	defer tracker.Stop()
+
+	signals := make(chan struct{}, 10)
+	tracker.RegisterSignalReceiver(func() {
+		signals <- struct{}{}
+	})
+
+	target := &querypb.Target{Cell: cell, Keyspace: keyspace, Shard: "-80", TabletType: topodatapb.TabletType_PRIMARY}
+	tablet := &topodatapb.Tablet{Keyspace: target.Keyspace, Shard: target.Shard, Type: target.TabletType}
+
+	sbc := sandboxconn.NewSandboxConn(tablet)
+	sbc.SetSchemaResult([]sandboxconn.SchemaResult{
+		tables(tbl("t1", "create table t1(id bigint, col bigint, primary key(id), key(col))"), tbl("t2", "create table t2(id bigint)")),
+	})
+	statsResults := func(t1Rows, colCardinality string) []*sqltypes.Result {
+		return []*sqltypes.Result{
+			sqltypes.MakeTestResult(sqltypes.MakeTestFields("table_name|table_rows", "varchar|uint64"),
+				"t1|"+t1Rows,
+				"t2|10",
+			),
+			sqltypes.MakeTestResult(sqltypes.MakeTestFields("table_name|column_name|max(cardinality)", "varchar|varchar|int64"),
+				"t1|id|"+t1Rows,
+				"t1|COL|"+colCardinality,
+			),
+		}
+	}
+
+	sendHealth := func() {
+		// make sure the statistics are due for a refresh
+		time.Sleep(20 * time.Millisecond)
+		ch <- &discovery.TabletHealth{
+			Conn:    sbc,
+			Tablet:  tablet,
+			Target:  target,
+			Serving: true,
+			Stats:   &querypb.RealtimeStats{},
+		}
+	}
+	waitForSignal := func() bool {
+		select {
+		case <-signals:
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}
+
+	sbc.SetResults(statsResults("1000", "10"))
+	sendHealth()
+	require.True(t, waitForSignal(), "schema was loaded but received no signal")
+	utils.MustMatch(t, &vindexes.TableStats{Rows: 1000, Cardinality: map[string]uint64{"id": 1000, "col": 10}}, tracker.Tables(keyspace)["t1"].Stats)
+	utils.MustMatch(t, &vindexes.TableStats{Rows: 10}, tracker.Tables(keyspace)["t2"].Stats)
+
+	// small changes in the statistics are not worth planning the queries again
+	sbc.SetResults(statsResults("1010", "11"))
+	sendHealth()
+	require.False(t, waitForSignal(), "statistics did not change enough to signal")
+	require.EqualValues(t, 4, sbc.ExecCount.Load())
+	assert.EqualValues(t, 1000, tracker.Tables(keyspace)["t1"].Stats.Rows)
+
+	sbc.SetResults(statsResults("100000", "10"))
+	sendHealth()
+	require.True(t, waitForSignal(), "statistics changed but received no signal")
+	assert.EqualValues(t, 100000, tracker.Tables(keyspace)["t1"].Stats.Rows)
+}
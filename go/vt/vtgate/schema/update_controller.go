@@ -41,6 +41,10 @@ type (
 		signal         func()
 		loaded         bool
 
+		// statsInterval is how often the table statistics should be reloaded, and statsLoaded when they last were
+		statsInterval time.Duration
+		statsLoaded   time.Time
+
 		// we'll only log a failed keyspace loading once
 		ignore bool
 	}
@@ -147,8 +151,9 @@ func (u *updateController) add(th *discovery.TabletHealth) {
 		return
 	}
 
-	// If the keyspace schema is loaded and there is no schema change detected. Then there is nothing to process.
-	if len(th.Stats.TableSchemaChanged) == 0 && len(th.Stats.ViewSchemaChanged) == 0 && !th.Stats.UdfsChanged && u.loaded {
+	// If the keyspace schema is loaded and there is no schema change detected. Then there is nothing to process,
+	// unless the table statistics need to be refreshed.
+	if len(th.Stats.TableSchemaChanged) == 0 && len(th.Stats.ViewSchemaChanged) == 0 && !th.Stats.UdfsChanged && u.loaded && !u.statsDueLocked() {
 		return
 	}
 
@@ -181,3 +186,20 @@ func (u *updateController) setIgnore(i bool) {
 	defer u.mu.Unlock()
 	u.ignore = i
 }
+
+func (u *updateController) setStatsLoaded() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.statsLoaded = time.Now()
+}
+
+// statsDue returns true if the table statistics should be reloaded
+func (u *updateController) statsDue() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.statsDueLocked()
+}
+
+func (u *updateController) statsDueLocked() bool {
+	return u.statsInterval > 0 && time.Since(u.statsLoaded) >= u.statsInterval
+}
@@ -453,7 +453,11 @@ func (a *analyzer) noteQuerySignature(node sqlparser.SQLNode) {
 			a.sig.RecursiveCTE = true
 		}
 	case sqlparser.AggrFunc:
-		a.sig.Aggregation = true
+		if !sqlparser.IsWindowFunction(node) {
+			a.sig.Aggregation = true
+		}
+	case *sqlparser.OverClause:
+		a.sig.WindowFunctions = true
 	case *sqlparser.Delete, *sqlparser.Update, *sqlparser.Insert:
 		a.sig.DML = true
 	}
@@ -36,10 +36,6 @@ func (a *analyzer) checkForInvalidConstructs(cursor *sqlparser.Cursor) error {
 		return &LockOnlyWithDualError{Node: node}
 	case *sqlparser.Union:
 		return checkUnion(node)
-	case *sqlparser.JSONTableExpr:
-		return &JSONTablesError{}
-	case *sqlparser.DerivedTable:
-		return checkDerived(node)
 	case *sqlparser.AssignmentExpr:
 		return vterrors.VT12001("Assignment expression")
 	case *sqlparser.ComparisonExpr:
@@ -48,14 +44,8 @@ func (a *analyzer) checkForInvalidConstructs(cursor *sqlparser.Cursor) error {
 		}
 	case *sqlparser.Subquery:
 		return a.checkSubqueryColumns(cursor.Parent(), node)
-	case *sqlparser.Insert:
-		if !a.singleUnshardedKeyspace && node.Action == sqlparser.ReplaceAct {
-			return ShardedError{Inner: &UnsupportedConstruct{errString: "REPLACE INTO with sharded keyspace"}}
-		}
 	case *sqlparser.OverClause:
-		if !a.singleUnshardedKeyspace {
-			return ShardedError{Inner: &UnsupportedConstruct{errString: "OVER CLAUSE with sharded keyspace"}}
-		}
+		return a.checkOverClause(node)
 	}
 
 	return nil
@@ -94,9 +84,13 @@ func (a *analyzer) checkSubqueryColumns(parent sqlparser.SQLNode, subq *sqlparse
 	return nil
 }
 
-func checkDerived(node *sqlparser.DerivedTable) error {
-	if node.Lateral {
-		return vterrors.VT12001("lateral derived tables")
+// checkOverClause checks that window functions only use constructs that we can plan over a sharded keyspace
+func (a *analyzer) checkOverClause(node *sqlparser.OverClause) error {
+	if a.singleUnshardedKeyspace {
+		return nil
+	}
+	if node.WindowName.NotEmpty() || (node.WindowSpec != nil && node.WindowSpec.Name.NotEmpty()) {
+		return ShardedError{Inner: &UnsupportedConstruct{errString: "named window in OVER CLAUSE with sharded keyspace"}}
 	}
 	return nil
 }
@@ -107,6 +107,28 @@ func TestScopingWDerivedTables(t *testing.T) {
 			query:         "select uu.count from (select count(*) as `count` from t1) uu",
 			directDeps:    TS1,
 			recursiveDeps: TS0,
+		}, {
+			query:         "select uu.id from t1, lateral (select t1.id as id, t2.col from t2 where t2.uid = t1.id) uu",
+			directDeps:    TS2,
+			recursiveDeps: TS2,
+		}, {
+			query:         "select uu.col from t1, lateral (select t1.id as id, t2.col from t2 where t2.uid = t1.id) uu",
+			directDeps:    TS2,
+			recursiveDeps: TS1,
+		}, {
+			query:        "select uu.id from t1, (select t1.id as id from t2) uu",
+			errorMessage: "column 't1.id' not found",
+		}, {
+			query:         "select jt.a from t1, json_table(t1.doc, '$[*]' columns(a int path '$.a')) as jt",
+			directDeps:    TS1,
+			recursiveDeps: TS1,
+		}, {
+			query:         "select jt.b from json_table('[]', '$[*]' columns(a int path '$.a', nested path '$.b[*]' columns(b int path '$'))) as jt",
+			directDeps:    TS0,
+			recursiveDeps: TS0,
+		}, {
+			query:        "select jt.c from json_table('[]', '$[*]' columns(a int path '$.a')) as jt",
+			errorMessage: "column 'jt.c' not found",
 		}}
 	for _, query := range queries {
 		t.Run(query.query, func(t *testing.T) {
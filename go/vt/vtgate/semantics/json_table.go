@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package semantics
+
+import (
+	"strings"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+// JSONTable contains the information about a JSON_TABLE expression used in the FROM clause.
+// The columns of a JSON_TABLE are all declared in the expression itself, so the table is
+// always authoritative, and the columns only depend on the JSON_TABLE itself.
+type JSONTable struct {
+	tableName string
+	JSONTable *sqlparser.JSONTableExpr
+
+	// ASTNode is a stand-in for the JSON_TABLE, used so the table can be
+	// found and referenced in the same way as any other table in the FROM clause
+	ASTNode *sqlparser.AliasedTableExpr
+	columns []ColumnInfo
+}
+
+var _ TableInfo = (*JSONTable)(nil)
+
+func newJSONTable(node *sqlparser.JSONTableExpr, collationEnv *collations.Environment) *JSONTable {
+	alias := node.Alias
+	tbl := &JSONTable{
+		tableName: alias.String(),
+		JSONTable: node,
+		ASTNode: &sqlparser.AliasedTableExpr{
+			Expr: sqlparser.NewTableName(alias.String()),
+			As:   alias,
+		},
+	}
+	tbl.addColumns(node.Columns, collationEnv)
+	return tbl
+}
+
+func (jt *JSONTable) addColumns(columns []*sqlparser.JtColumnDefinition, collationEnv *collations.Environment) {
+	for _, col := range columns {
+		switch {
+		case col.JtOrdinal != nil:
+			jt.columns = append(jt.columns, ColumnInfo{
+				Name: col.JtOrdinal.Name.String(),
+				Type: evalengine.NewType(sqltypes.Uint32, collations.CollationBinaryID),
+			})
+		case col.JtPath != nil:
+			typ := col.JtPath.Type.SQLType()
+			jt.columns = append(jt.columns, ColumnInfo{
+				Name: col.JtPath.Name.String(),
+				Type: evalengine.NewType(typ, collations.CollationForType(typ, collationEnv.DefaultConnectionCharset())),
+			})
+		case col.JtNestedPath != nil:
+			jt.addColumns(col.JtNestedPath.Columns, collationEnv)
+		}
+	}
+}
+
+// Name implements the TableInfo interface
+func (jt *JSONTable) Name() (sqlparser.TableName, error) {
+	return sqlparser.NewTableName(jt.tableName), nil
+}
+
+// GetVindexTable implements the TableInfo interface
+func (jt *JSONTable) GetVindexTable() *vindexes.Table {
+	return nil
+}
+
+// IsInfSchema implements the TableInfo interface
+func (jt *JSONTable) IsInfSchema() bool {
+	return false
+}
+
+func (jt *JSONTable) matches(name sqlparser.TableName) bool {
+	return jt.tableName == name.Name.String() && name.Qualifier.IsEmpty()
+}
+
+func (jt *JSONTable) authoritative() bool {
+	return true
+}
+
+// GetAliasedTableExpr implements the TableInfo interface
+func (jt *JSONTable) GetAliasedTableExpr() *sqlparser.AliasedTableExpr {
+	return jt.ASTNode
+}
+
+func (jt *JSONTable) canShortCut() shortCut {
+	return canShortCut
+}
+
+func (jt *JSONTable) getColumns(bool) []ColumnInfo {
+	return jt.columns
+}
+
+func (jt *JSONTable) dependencies(colName string, org originable) (dependencies, error) {
+	directDeps := org.tableSetFor(jt.ASTNode)
+	for _, col := range jt.columns {
+		if strings.EqualFold(col.Name, colName) {
+			return createCertain(directDeps, directDeps, col.Type), nil
+		}
+	}
+	return &nothing{}, nil
+}
+
+func (jt *JSONTable) getExprFor(s string) (sqlparser.Expr, error) {
+	return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "Unknown column '%s' in 'field list'", s)
+}
+
+func (jt *JSONTable) getTableSet(org originable) TableSet {
+	return org.tableSetFor(jt.ASTNode)
+}
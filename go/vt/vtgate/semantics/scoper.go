@@ -175,6 +175,11 @@ func (s *scoper) enterJoinScope(cursor *sqlparser.Cursor) {
 		// To create this special context, we will find the parent scope of the select statement involved.
 		currScope := s.currentScope()
 		stmtScope := currScope.findParentScopeOfStatement()
+		if isLateral(cursor.Node()) {
+			// LATERAL derived tables and JSON_TABLE expressions are allowed to
+			// reference the tables that precede them in the FROM clause
+			stmtScope = currScope
+		}
 		nScope := newScope(stmtScope)
 		if stmtScope == nil {
 			// TODO: this feels hacky. revisit with a better plan
@@ -185,6 +190,26 @@ func (s *scoper) enterJoinScope(cursor *sqlparser.Cursor) {
 	}
 }
 
+// isLateral returns true if the table expression contains a LATERAL derived table or a JSON_TABLE expression
+func isLateral(node sqlparser.SQLNode) bool {
+	switch node := node.(type) {
+	case *sqlparser.AliasedTableExpr:
+		dt, ok := node.Expr.(*sqlparser.DerivedTable)
+		return ok && dt.Lateral
+	case *sqlparser.JSONTableExpr:
+		return true
+	case *sqlparser.JoinTableExpr:
+		return isLateral(node.LeftExpr) || isLateral(node.RightExpr)
+	case *sqlparser.ParenTableExpr:
+		for _, expr := range node.Exprs {
+			if isLateral(expr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (s *scoper) pushSelectScope(node *sqlparser.Select) {
 	currScope := newScope(s.currentScope())
 	currScope.stmtScope = true
@@ -77,13 +77,14 @@ type (
 
 	// QuerySignature is used to identify shortcuts in the planning process
 	QuerySignature struct {
-		Aggregation  bool
-		DML          bool
-		Distinct     bool
-		HashJoin     bool
-		SubQueries   bool
-		Union        bool
-		RecursiveCTE bool
+		Aggregation     bool
+		DML             bool
+		Distinct        bool
+		HashJoin        bool
+		SubQueries      bool
+		Union           bool
+		RecursiveCTE    bool
+		WindowFunctions bool
 	}
 
 	// SemTable contains semantic analysis information about the query.
@@ -577,6 +578,16 @@ func (st *SemTable) TableSetFor(t *sqlparser.AliasedTableExpr) TableSet {
 	return EmptyTableSet()
 }
 
+// TableSetForJSONTable returns the TableSet for the given JSON_TABLE expression
+func (st *SemTable) TableSetForJSONTable(t *sqlparser.JSONTableExpr) TableSet {
+	for idx, t2 := range st.Tables {
+		if jt, ok := t2.(*JSONTable); ok && jt.JSONTable == t {
+			return SingleTableSet(idx)
+		}
+	}
+	return EmptyTableSet()
+}
+
 // ReplaceTableSetFor replaces the given single TabletSet with the new *sqlparser.AliasedTableExpr
 func (st *SemTable) ReplaceTableSetFor(id TableSet, t *sqlparser.AliasedTableExpr) {
 	if st == nil {
@@ -939,8 +950,13 @@ func (st *SemTable) ASTEquals() *sqlparser.Comparator {
 	return st.comparator
 }
 
+// Clone returns a copy of the given node, copying the semantic information to the cloned expressions.
+// Aliased table expressions are not cloned, since the TableSet of a table is tied to its AST node.
 func (st *SemTable) Clone(n sqlparser.SQLNode) sqlparser.SQLNode {
-	return sqlparser.CopyOnRewrite(n, nil, func(cursor *sqlparser.CopyOnWriteCursor) {
+	return sqlparser.CopyOnRewrite(n, func(node, _ sqlparser.SQLNode) bool {
+		_, isTable := node.(*sqlparser.AliasedTableExpr)
+		return !isTable
+	}, func(cursor *sqlparser.CopyOnWriteCursor) {
 		expr, isExpr := cursor.Node().(sqlparser.Expr)
 		if !isExpr {
 			return
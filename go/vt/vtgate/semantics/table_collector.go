@@ -124,6 +124,8 @@ func (tc *tableCollector) up(cursor *sqlparser.Cursor) error {
 		return tc.visitAliasedTableExpr(node)
 	case *sqlparser.Union:
 		return tc.visitUnion(node)
+	case *sqlparser.JSONTableExpr:
+		return tc.visitJSONTable(node)
 	case *sqlparser.RowAlias:
 		ins, ok := cursor.Parent().(*sqlparser.Insert)
 		if !ok {
@@ -145,6 +147,13 @@ func (tc *tableCollector) visitAliasedTableExpr(node *sqlparser.AliasedTableExpr
 	return nil
 }
 
+func (tc *tableCollector) visitJSONTable(node *sqlparser.JSONTableExpr) error {
+	tableInfo := newJSONTable(node, tc.si.Environment().CollationEnv())
+	tc.Tables = append(tc.Tables, tableInfo)
+	scope := tc.scoper.currentScope()
+	return scope.addTable(tableInfo)
+}
+
 func (tc *tableCollector) visitUnion(union *sqlparser.Union) error {
 	firstSelect := sqlparser.GetFirstSelect(union)
 	expanded, selectExprs := getColumnNames(firstSelect.SelectExprs)
@@ -449,6 +458,18 @@ func (tc *tableCollector) addSelectDerivedTable(
 		_, deps[i], types[i] = tc.org.depsForExpr(ae.Expr)
 	}
 
+	if dt, ok := tableExpr.Expr.(*sqlparser.DerivedTable); ok && dt.Lateral {
+		// columns of a LATERAL derived table can use columns from the tables preceding it,
+		// but from the outside, they are only produced by the derived table and its tables
+		inner := tc.scoper.statementIDs[sel]
+		thisTable := SingleTableSet(len(tc.Tables))
+		for i, dep := range deps {
+			if !dep.IsSolvedBy(inner) {
+				deps[i] = dep.KeepOnly(inner).Merge(thisTable)
+			}
+		}
+	}
+
 	tableInfo := createDerivedTableForExpressions(sel.SelectExprs, columns, tables.tables, tc.org, expanded, deps, types)
 	if err := tableInfo.checkForDuplicates(); err != nil {
 		return err
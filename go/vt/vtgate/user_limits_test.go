@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/userlimits"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestUserLimits(t *testing.T) {
+	executor, sbc1, _, _, ctx := createExecutorEnv(t)
+
+	limits, err := userlimits.New(&userlimits.Config{Users: map[string]*userlimits.Limits{
+		"tenant": {MaxShards: 2, MaxRows: 1},
+	}})
+	require.NoError(t, err)
+	executor.SetUserLimits(limits)
+
+	tenantCtx := callerid.NewContext(ctx, &vtrpcpb.CallerID{}, &querypb.VTGateCallerID{Username: "tenant"})
+	session := &vtgatepb.Session{TargetString: "@primary", Autocommit: true}
+
+	_, err = executorExec(tenantCtx, executor, session, "select id from user", nil)
+	require.EqualError(t, err, "query of user 'tenant' is sent to 8 shards, exceeding the limit of 2")
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+
+	sbc1.SetResults([]*sqltypes.Result{sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1", "2")})
+	_, err = executorExec(tenantCtx, executor, session, "select id from user where id = 1", nil)
+	require.EqualError(t, err, "query of user 'tenant' exceeded the limit of 1 rows")
+
+	sbc1.SetResults([]*sqltypes.Result{sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1", "2")})
+	err = executor.StreamExecute(tenantCtx, nil, "TestUserLimits", NewSafeSession(session), "select id from user where id = 1", nil, func(*sqltypes.Result) error {
+		return nil
+	})
+	require.ErrorContains(t, err, "query of user 'tenant' exceeded the limit of 1 rows")
+
+	// other users are not limited
+	_, err = executorExec(ctx, executor, session, "select id from user", nil)
+	require.NoError(t, err)
+}
+
+func TestUserLimitsTransactionDuration(t *testing.T) {
+	executor, sbc1, _, _, ctx := createExecutorEnv(t)
+
+	limits, err := userlimits.New(&userlimits.Config{Default: &userlimits.Limits{MaxTransactionDuration: "10ms"}})
+	require.NoError(t, err)
+	executor.SetUserLimits(limits)
+
+	ctx = callerid.NewContext(ctx, &vtrpcpb.CallerID{}, &querypb.VTGateCallerID{Username: "tenant"})
+	session := NewSafeSession(&vtgatepb.Session{TargetString: "@primary", Autocommit: true, SessionUUID: "session"})
+
+	_, err = executor.Execute(ctx, nil, "TestUserLimits", session, "begin", nil)
+	require.NoError(t, err)
+	_, err = executor.Execute(ctx, nil, "TestUserLimits", session, "select id from user where id = 1", nil)
+	require.NoError(t, err)
+
+	// the transaction is rolled back while the session is idle
+	assert.Eventually(t, func() bool {
+		return sbc1.RollbackCount.Load() == 1
+	}, 5*time.Second, time.Millisecond)
+	// and its next query fails
+	_, err = executor.Execute(ctx, nil, "TestUserLimits", session, "select id from user where id = 1", nil)
+	require.EqualError(t, err, "transaction of user 'tenant' was open for longer than 10ms and was rolled back")
+	assert.False(t, session.InTransaction())
+
+	// the next transaction starts from scratch
+	_, err = executor.Execute(ctx, nil, "TestUserLimits", session, "begin", nil)
+	require.NoError(t, err)
+	_, err = executor.Execute(ctx, nil, "TestUserLimits", session, "select id from user where id = 1", nil)
+	require.NoError(t, err)
+}
@@ -0,0 +1,343 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package userlimits implements the resource limits vtgate applies to the queries of each user.
+
+The limits are read from a JSON file like:
+
+	{
+	  "Default": {"MaxConcurrency": 50, "MaxShards": 8},
+	  "Users": {
+	    "reporting": {"MaxConcurrency": 5, "MaxRows": 100000, "MaxQPS": 20},
+	    "app": {"MaxTransactionDuration": "5s"}
+	  }
+	}
+
+The limits of a user listed in Users replace the default ones. Every user has its own budget:
+the concurrency and the queries per second of one user do not count towards the limits of another.
+The transactions open for longer than MaxTransactionDuration are rolled back in the background,
+even when their session is idle, and the next query of the session fails.
+*/
+package userlimits
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// idleUserTimeout is how long the resources of a user that is not running any query nor
+// transaction are tracked (it's a var not a const so the tests can change the value).
+var idleUserTimeout = 10 * time.Minute
+
+var (
+	limitsExceeded    = stats.NewCountersWithMultiLabels("UserLimitsExceeded", "Number of queries rejected for exceeding a per-user limit", []string{"User", "Limit"})
+	queriesByUser     = stats.NewCountersWithSingleLabel("UserLimitedQueries", "Number of queries run by users with limits", "User")
+	concurrentQueries = stats.NewGaugesWithSingleLabel("UserConcurrentQueries", "Number of queries currently running for users with limits", "User")
+)
+
+// Limits are the resource limits of a user. A zero value means that there is no limit.
+type Limits struct {
+	// MaxConcurrency is the maximum number of queries the user can run at the same time
+	MaxConcurrency int `json:",omitempty"`
+	// MaxShards is the maximum number of shards a single query of the user can be sent to
+	MaxShards int `json:",omitempty"`
+	// MaxRows is the maximum number of rows a query of the user can return or hold in memory
+	MaxRows int `json:",omitempty"`
+	// MaxTransactionDuration is how long a transaction of the user can stay open, like "10s"
+	MaxTransactionDuration string `json:",omitempty"`
+	// MaxQPS is the maximum number of queries the user can run per second
+	MaxQPS float64 `json:",omitempty"`
+}
+
+// Config is the content of the limits file
+type Config struct {
+	// Default are the limits of the users that are not in Users
+	Default *Limits `json:",omitempty"`
+	// Users are the limits of specific users
+	Users map[string]*Limits `json:",omitempty"`
+}
+
+// Governor keeps track of the resources used by every user, and checks them against their limits.
+// A nil Governor does not limit anything.
+type Governor struct {
+	config *Config
+
+	mu    sync.Mutex
+	users map[string]*User
+	// swept is when the idle users were last forgotten
+	swept time.Time
+}
+
+// Load reads the limits from a file
+func Load(path string) (*Governor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid user limits in %s: %v", path, err)
+	}
+	return New(config)
+}
+
+// New validates the limits, and creates a Governor for them
+func New(config *Config) (*Governor, error) {
+	if _, err := newUser("", config.Default); err != nil {
+		return nil, err
+	}
+	for name, limits := range config.Users {
+		if _, err := newUser(name, limits); err != nil {
+			return nil, err
+		}
+	}
+	return &Governor{config: config, users: map[string]*User{}, swept: time.Now()}, nil
+}
+
+// For returns the limits of the user, or nil if the user does not have any
+func (g *Governor) For(name string) *User {
+	if g == nil {
+		return nil
+	}
+	limits, found := g.config.Users[name]
+	if !found {
+		limits = g.config.Default
+	}
+	if limits == nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := time.Now()
+	user := g.users[name]
+	if user == nil {
+		g.sweep(now)
+		// the limits were validated when the governor was created
+		user, _ = newUser(name, limits)
+		g.users[name] = user
+	}
+	user.used = now
+	return user
+}
+
+// sweep forgets the users that have been idle for longer than idleUserTimeout, so that
+// the users that connected once are not tracked forever. It must be called with mu held.
+func (g *Governor) sweep(now time.Time) {
+	if now.Sub(g.swept) < idleUserTimeout {
+		return
+	}
+	g.swept = now
+	for name, user := range g.users {
+		if now.Sub(user.used) >= idleUserTimeout && user.idle() {
+			delete(g.users, name)
+		}
+	}
+}
+
+// User tracks the resources used by a user. All the methods can be called on a nil User, which has no limits.
+type User struct {
+	name           string
+	limits         Limits
+	maxTransaction time.Duration
+
+	running atomic.Int64
+	limiter *rate.Limiter
+	// used is when the user was last returned by Governor.For, protected by the mutex of the Governor
+	used time.Time
+
+	mu sync.Mutex
+	// transactions are the open transactions of the user, by session
+	transactions map[string]*transaction
+}
+
+// transaction is an open transaction of a user
+type transaction struct {
+	start time.Time
+	// timer rolls the transaction back once it has been open for too long
+	timer *time.Timer
+	// rollback rolls the transaction back
+	rollback func()
+	// expired is set once the transaction was rolled back for being open for too long
+	expired bool
+}
+
+func newUser(name string, limits *Limits) (*User, error) {
+	if limits == nil {
+		return nil, nil
+	}
+	if limits.MaxConcurrency < 0 || limits.MaxShards < 0 || limits.MaxRows < 0 || limits.MaxQPS < 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid limits for user '%s': limits cannot be negative", name)
+	}
+	user := &User{name: name, limits: *limits, transactions: map[string]*transaction{}}
+	if limits.MaxTransactionDuration != "" {
+		d, err := time.ParseDuration(limits.MaxTransactionDuration)
+		if err != nil || d <= 0 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid limits for user '%s': invalid transaction duration %s", name, limits.MaxTransactionDuration)
+		}
+		user.maxTransaction = d
+	}
+	if limits.MaxQPS > 0 {
+		user.limiter = rate.NewLimiter(rate.Limit(limits.MaxQPS), int(math.Ceil(limits.MaxQPS)))
+	}
+	return user, nil
+}
+
+// idle returns whether the user is not running any query nor transaction
+func (u *User) idle() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.running.Load() == 0 && len(u.transactions) == 0
+}
+
+func (u *User) exceeded(limit string) {
+	limitsExceeded.Add([]string{u.name, limit}, 1)
+}
+
+// Admit checks that the user can run one more query. If it can, the returned function
+// must be called when the query is done.
+func (u *User) Admit() (func(), error) {
+	if u == nil {
+		return func() {}, nil
+	}
+	queriesByUser.Add(u.name, 1)
+	if u.limiter != nil && !u.limiter.Allow() {
+		u.exceeded("MaxQPS")
+		return nil, vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "user '%s' exceeded the limit of %v queries per second", u.name, u.limits.MaxQPS)
+	}
+	running := u.running.Add(1)
+	if u.limits.MaxConcurrency > 0 && running > int64(u.limits.MaxConcurrency) {
+		u.running.Add(-1)
+		u.exceeded("MaxConcurrency")
+		return nil, vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "user '%s' exceeded the limit of %d concurrent queries", u.name, u.limits.MaxConcurrency)
+	}
+	concurrentQueries.Set(u.name, running)
+	return func() {
+		concurrentQueries.Set(u.name, u.running.Add(-1))
+	}, nil
+}
+
+// CheckShards checks that a query of the user can be sent to this many shards
+func (u *User) CheckShards(shards int) error {
+	if u == nil || u.limits.MaxShards == 0 || shards <= u.limits.MaxShards {
+		return nil
+	}
+	u.exceeded("MaxShards")
+	return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "query of user '%s' is sent to %d shards, exceeding the limit of %d", u.name, shards, u.limits.MaxShards)
+}
+
+// CheckRows checks that a query of the user can return this many rows
+func (u *User) CheckRows(rows int) error {
+	if u == nil || u.limits.MaxRows == 0 || rows <= u.limits.MaxRows {
+		return nil
+	}
+	u.exceeded("MaxRows")
+	return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "query of user '%s' exceeded the limit of %d rows", u.name, u.limits.MaxRows)
+}
+
+// MaxMemoryRows returns the number of rows a query of the user can hold in memory,
+// given the limit that applies to all the users
+func (u *User) MaxMemoryRows(maxMemoryRows int) int {
+	if u == nil || u.limits.MaxRows == 0 {
+		return maxMemoryRows
+	}
+	return min(maxMemoryRows, u.limits.MaxRows)
+}
+
+// LimitsTransactions returns whether the transactions of the user are limited,
+// and must be tracked with TrackTransaction
+func (u *User) LimitsTransactions() bool {
+	return u != nil && u.maxTransaction > 0
+}
+
+// TrackTransaction records whether the session of the user has an open transaction. Once it
+// has been open for longer than the user is allowed to, the last rollback function given for
+// the transaction is called in the background, and CheckTransaction reports it.
+func (u *User) TrackTransaction(session string, inTransaction bool, rollback func()) {
+	if u == nil || u.maxTransaction == 0 || session == "" {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	tx, found := u.transactions[session]
+	if !inTransaction {
+		if found {
+			tx.timer.Stop()
+			delete(u.transactions, session)
+		}
+		return
+	}
+	if found {
+		tx.rollback = rollback
+		return
+	}
+	tx = &transaction{start: time.Now(), rollback: rollback}
+	tx.timer = time.AfterFunc(u.maxTransaction, func() {
+		u.expire(session, tx)
+	})
+	u.transactions[session] = tx
+}
+
+// expire rolls back the transaction of the session, as it has been open for too long
+func (u *User) expire(session string, tx *transaction) {
+	u.mu.Lock()
+	if u.transactions[session] != tx {
+		// the transaction is over
+		u.mu.Unlock()
+		return
+	}
+	tx.expired = true
+	rollback := tx.rollback
+	u.mu.Unlock()
+
+	u.exceeded("MaxTransactionDuration")
+	if rollback != nil {
+		rollback()
+	}
+}
+
+// CheckTransaction checks that the open transaction of the session has not been open for too long.
+// The caller must roll back the transaction of the session when it has, even if it was already
+// rolled back in the background.
+func (u *User) CheckTransaction(session string) error {
+	if u == nil || u.maxTransaction == 0 || session == "" {
+		return nil
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	tx, found := u.transactions[session]
+	if !found || (!tx.expired && time.Since(tx.start) <= u.maxTransaction) {
+		return nil
+	}
+	tx.timer.Stop()
+	delete(u.transactions, session)
+	if !tx.expired {
+		u.exceeded("MaxTransactionDuration")
+	}
+	return vterrors.Errorf(vtrpcpb.Code_ABORTED, "transaction of user '%s' was open for longer than %v and was rolled back", u.name, u.maxTransaction)
+}
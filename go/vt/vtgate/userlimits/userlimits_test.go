@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userlimits
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limits.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"Default": {"MaxShards": 4}, "Users": {"reporting": {"MaxRows": 10}}}`), 0o600))
+
+	g, err := Load(path)
+	require.NoError(t, err)
+	assert.NoError(t, g.For("app").CheckShards(4))
+	assert.EqualError(t, g.For("app").CheckShards(5), "query of user 'app' is sent to 5 shards, exceeding the limit of 4")
+	// the limits of the user replace the default ones
+	assert.NoError(t, g.For("reporting").CheckShards(5))
+	assert.EqualError(t, g.For("reporting").CheckRows(11), "query of user 'reporting' exceeded the limit of 10 rows")
+	assert.Equal(t, 10, g.For("reporting").MaxMemoryRows(300000))
+	assert.Equal(t, 5, g.For("reporting").MaxMemoryRows(5))
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"Users": {"app": {"MaxTransactionDuration": "soon"}}}`), 0o600))
+	_, err = Load(path)
+	require.EqualError(t, err, "invalid limits for user 'app': invalid transaction duration soon")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"Default": {"MaxConcurrency": -1}}`), 0o600))
+	_, err = Load(path)
+	require.EqualError(t, err, "invalid limits for user '': limits cannot be negative")
+}
+
+func TestNoLimits(t *testing.T) {
+	var g *Governor
+	user := g.For("app")
+	assert.Nil(t, user)
+
+	release, err := user.Admit()
+	require.NoError(t, err)
+	release()
+	assert.NoError(t, user.CheckShards(1000))
+	assert.NoError(t, user.CheckRows(1000000))
+	assert.NoError(t, user.CheckTransaction("session"))
+	assert.Equal(t, 100, user.MaxMemoryRows(100))
+
+	g, err = New(&Config{Users: map[string]*Limits{"reporting": {MaxShards: 1}}})
+	require.NoError(t, err)
+	assert.Nil(t, g.For("app"))
+}
+
+func TestAdmit(t *testing.T) {
+	g, err := New(&Config{Users: map[string]*Limits{
+		"app":       {MaxConcurrency: 2},
+		"reporting": {MaxQPS: 1},
+	}})
+	require.NoError(t, err)
+
+	app := g.For("app")
+	first, err := app.Admit()
+	require.NoError(t, err)
+	second, err := app.Admit()
+	require.NoError(t, err)
+	_, err = app.Admit()
+	require.EqualError(t, err, "user 'app' exceeded the limit of 2 concurrent queries")
+	assert.EqualValues(t, 1, limitsExceeded.Counts()["app.MaxConcurrency"])
+	assert.EqualValues(t, 2, concurrentQueries.Counts()["app"])
+
+	first()
+	third, err := app.Admit()
+	require.NoError(t, err)
+	second()
+	third()
+	assert.EqualValues(t, 0, concurrentQueries.Counts()["app"])
+
+	reporting := g.For("reporting")
+	release, err := reporting.Admit()
+	require.NoError(t, err)
+	release()
+	_, err = reporting.Admit()
+	require.EqualError(t, err, "user 'reporting' exceeded the limit of 1 queries per second")
+}
+
+func TestTransactionDuration(t *testing.T) {
+	g, err := New(&Config{Default: &Limits{MaxTransactionDuration: "10ms"}})
+	require.NoError(t, err)
+
+	user := g.For("app")
+	assert.True(t, user.LimitsTransactions())
+	rollbacks := make(chan string, 2)
+	rollback := func(session string) func() {
+		return func() { rollbacks <- session }
+	}
+	user.TrackTransaction("s1", true, nil)
+	user.TrackTransaction("s1", true, rollback("s1"))
+	user.TrackTransaction("s2", true, rollback("s2"))
+	require.NoError(t, user.CheckTransaction("s1"))
+	// a transaction that is over is not rolled back
+	user.TrackTransaction("s2", false, nil)
+
+	// the transactions are rolled back in the background, with the last rollback function given
+	assert.Equal(t, "s1", <-rollbacks)
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(t, rollbacks)
+
+	// a new transaction in the session starts the clock again
+	user.TrackTransaction("s2", true, rollback("s2"))
+	require.NoError(t, user.CheckTransaction("s2"))
+	require.EqualError(t, user.CheckTransaction("s1"), "transaction of user 'app' was open for longer than 10ms and was rolled back")
+	// the transaction is forgotten once it is reported
+	require.NoError(t, user.CheckTransaction("s1"))
+	user.TrackTransaction("s2", false, nil)
+
+	g, err = New(&Config{Default: &Limits{MaxRows: 10}})
+	require.NoError(t, err)
+	assert.False(t, g.For("app").LimitsTransactions())
+}
+
+func TestIdleUsers(t *testing.T) {
+	defer func(d time.Duration) { idleUserTimeout = d }(idleUserTimeout)
+	idleUserTimeout = 10 * time.Millisecond
+
+	g, err := New(&Config{Default: &Limits{MaxConcurrency: 1, MaxTransactionDuration: "1h"}})
+	require.NoError(t, err)
+	running := g.For("running")
+	release, err := running.Admit()
+	require.NoError(t, err)
+	defer release()
+	g.For("in-transaction").TrackTransaction("s1", true, nil)
+	idle := g.For("idle")
+
+	time.Sleep(20 * time.Millisecond)
+	// the idle users are forgotten when a new user comes
+	g.For("new")
+	assert.Same(t, running, g.For("running"))
+	assert.NotSame(t, idle, g.For("idle"))
+	assert.Len(t, g.users, 4)
+	g.For("in-transaction").TrackTransaction("s1", false, nil)
+}
@@ -53,6 +53,7 @@ import (
 	"vitess.io/vitess/go/vt/vtgate/logstats"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
 	"vitess.io/vitess/go/vt/vtgate/semantics"
+	"vitess.io/vitess/go/vt/vtgate/userlimits"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
 	"vitess.io/vitess/go/vt/vtgate/vschemaacl"
 	"vitess.io/vitess/go/vt/vtgate/vtgateservice"
@@ -116,6 +117,7 @@ type vcursorImpl struct {
 	// A nil value represents that no foreign_key_checks value was provided.
 	fkChecksState       *bool
 	ignoreMaxMemoryRows bool
+	spiller             *engine.Spiller
 	vschema             *vindexes.VSchema
 	vm                  VSchemaOperator
 	semTable            *semantics.SemTable
@@ -126,6 +128,14 @@ type vcursorImpl struct {
 
 	warmingReadsPercent int
 	warmingReadsChannel chan bool
+
+	// userLimits are the resource limits of the user running the query, nil when the user is not limited
+	userLimits *userlimits.User
+
+	// resultCacheTTL is how long the result of the query can be cached, as asked by the query
+	resultCacheTTL time.Duration
+	// resultCacheable is set when the result of the query only depends on the rows it reads
+	resultCacheable bool
 }
 
 // newVcursorImpl creates a vcursorImpl. Before creating this object, you have to separate out any marginComments that came with
@@ -193,6 +203,7 @@ func newVCursorImpl(
 		pv:                  pv,
 		warmingReadsPercent: warmingReadsPct,
 		warmingReadsChannel: warmingReadsChan,
+		spiller:             newSpiller(),
 	}, nil
 }
 
@@ -226,15 +237,32 @@ func (vc *vcursorImpl) SQLMode() string {
 	return config.DefaultSQLMode
 }
 
-// MaxMemoryRows returns the maxMemoryRows flag value.
+// MaxMemoryRows returns the maxMemoryRows flag value, or the row limit of the user if it is lower.
 func (vc *vcursorImpl) MaxMemoryRows() int {
-	return maxMemoryRows
+	return vc.userLimits.MaxMemoryRows(maxMemoryRows)
 }
 
 // ExceedsMaxMemoryRows returns a boolean indicating whether the maxMemoryRows value has been exceeded.
 // Returns false if the max memory rows override directive is set to true.
 func (vc *vcursorImpl) ExceedsMaxMemoryRows(numRows int) bool {
-	return !vc.ignoreMaxMemoryRows && numRows > maxMemoryRows
+	return !vc.ignoreMaxMemoryRows && numRows > vc.MaxMemoryRows()
+}
+
+// SpillToDisk returns the Spiller used by this query to write rows to disk once the
+// maxMemoryRows value has been exceeded, or nil if the spill-dir flag is not set.
+func (vc *vcursorImpl) SpillToDisk() *engine.Spiller {
+	return vc.spiller
+}
+
+func newSpiller() *engine.Spiller {
+	if spillDir == "" {
+		return nil
+	}
+	return engine.NewSpiller(engine.SpillConfig{
+		Dir:           spillDir,
+		MaxQueryBytes: spillMaxQueryDiskBytes,
+		MaxTotalBytes: spillMaxTotalDiskBytes,
+	})
 }
 
 // SetIgnoreMaxMemoryRows sets the ignoreMaxMemoryRows value.
@@ -594,6 +622,9 @@ const txRollback = "Rollback Transaction"
 // ExecuteMultiShard is part of the engine.VCursor interface.
 func (vc *vcursorImpl) ExecuteMultiShard(ctx context.Context, primitive engine.Primitive, rss []*srvtopo.ResolvedShard, queries []*querypb.BoundQuery, rollbackOnError, canAutocommit bool) (*sqltypes.Result, []error) {
 	noOfShards := len(rss)
+	if err := vc.userLimits.CheckShards(noOfShards); err != nil {
+		return nil, []error{err}
+	}
 	atomic.AddUint64(&vc.logStats.ShardQueries, uint64(noOfShards))
 	err := vc.markSavepoint(ctx, rollbackOnError && (noOfShards > 1), map[string]*querypb.BindVariable{})
 	if err != nil {
@@ -609,6 +640,9 @@ func (vc *vcursorImpl) ExecuteMultiShard(ctx context.Context, primitive engine.P
 // StreamExecuteMulti is the streaming version of ExecuteMultiShard.
 func (vc *vcursorImpl) StreamExecuteMulti(ctx context.Context, primitive engine.Primitive, query string, rss []*srvtopo.ResolvedShard, bindVars []map[string]*querypb.BindVariable, rollbackOnError bool, autocommit bool, callback func(reply *sqltypes.Result) error) []error {
 	noOfShards := len(rss)
+	if err := vc.userLimits.CheckShards(noOfShards); err != nil {
+		return []error{err}
+	}
 	atomic.AddUint64(&vc.logStats.ShardQueries, uint64(noOfShards))
 	err := vc.markSavepoint(ctx, rollbackOnError && (noOfShards > 1), map[string]*querypb.BindVariable{})
 	if err != nil {
@@ -680,6 +714,11 @@ func (vc *vcursorImpl) InTransactionAndIsDML() bool {
 	return false
 }
 
+// AfterTransaction is part of the vindexes.VCursor interface.
+func (vc *vcursorImpl) AfterTransaction(f func()) {
+	vc.safeSession.AfterTransaction(f)
+}
+
 func (vc *vcursorImpl) LookupRowLockShardSession() vtgatepb.CommitOrder {
 	switch vc.logStats.StmtType {
 	case "DELETE", "UPDATE":
@@ -870,12 +909,16 @@ func (vc *vcursorImpl) SetQueryTimeout(maxExecutionTime int64) {
 // GetQueryTimeout implements the SessionActions interface
 // The priority of adding query timeouts -
 // 1. Query timeout comment directive.
-// 2. If the comment directive is unspecified, then we use the session setting.
-// 3. If the comment directive and session settings is unspecified, then we use the global default specified by a flag.
+// 2. If the comment directive is unspecified, then we use the query attribute.
+// 3. If the query attribute is unspecified too, then we use the session setting.
+// 4. If none of the above is specified, then we use the global default specified by a flag.
 func (vc *vcursorImpl) GetQueryTimeout(queryTimeoutFromComments int) int {
 	if queryTimeoutFromComments != 0 {
 		return queryTimeoutFromComments
 	}
+	if timeout := queryTimeoutFromAttributes(vc.safeSession.GetOptions().GetQueryAttributes()); timeout != 0 {
+		return timeout
+	}
 	sessionQueryTimeout := int(vc.safeSession.GetQueryTimeout())
 	if sessionQueryTimeout != 0 {
 		return sessionQueryTimeout
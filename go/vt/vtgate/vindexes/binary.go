@@ -30,6 +30,7 @@ var (
 	_ Reversible      = (*Binary)(nil)
 	_ Hashing         = (*Binary)(nil)
 	_ ParamValidating = (*Binary)(nil)
+	_ Ordered         = (*Binary)(nil)
 )
 
 // Binary is a vindex that converts binary bits to a keyspace id.
@@ -92,6 +93,36 @@ func (vind *Binary) Map(ctx context.Context, vcursor VCursor, ids []sqltypes.Val
 	return out, nil
 }
 
+// MapRange returns the range of keyspace ids of the ids between start and end.
+// The ids are compared byte by byte, so the planner only uses it for the
+// columns with a binary collation.
+func (vind *Binary) MapRange(ctx context.Context, vcursor VCursor, start, end sqltypes.Value, startInclusive, endInclusive bool) ([]key.Destination, error) {
+	var from, to []byte
+	if !start.IsNull() {
+		if !start.IsText() && !start.IsBinary() {
+			// other types aren't compared byte by byte
+			return []key.Destination{key.DestinationAllShards{}}, nil
+		}
+		from = append([]byte{}, start.Raw()...)
+		if !startInclusive {
+			// the smallest id bigger than start
+			from = append(from, 0)
+		}
+	}
+	if !end.IsNull() {
+		if !end.IsText() && !end.IsBinary() {
+			return []key.Destination{key.DestinationAllShards{}}, nil
+		}
+		to = append([]byte{}, end.Raw()...)
+		if endInclusive {
+			to = append(to, 0)
+		} else if len(to) == 0 {
+			return []key.Destination{key.DestinationNone{}}, nil
+		}
+	}
+	return newKeyRange(from, to), nil
+}
+
 func (vind *Binary) Hash(id sqltypes.Value) ([]byte, error) {
 	return id.ToBytes()
 }
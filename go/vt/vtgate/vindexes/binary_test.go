@@ -24,10 +24,13 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/key"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
 var binOnlyVindex SingleColumn
@@ -145,3 +148,57 @@ func TestBinaryReverseMap(t *testing.T) {
 		t.Errorf("ReverseMap(): %v, want %s", err, wantErr)
 	}
 }
+
+func TestBinaryMapRange(t *testing.T) {
+	keyRange := func(start, end string) []key.Destination {
+		kr := &topodatapb.KeyRange{}
+		if start != "" {
+			kr.Start = []byte(start)
+		}
+		if end != "" {
+			kr.End = []byte(end)
+		}
+		return []key.Destination{key.DestinationKeyRange{KeyRange: kr}}
+	}
+
+	tcs := []struct {
+		name                         string
+		start, end                   sqltypes.Value
+		startInclusive, endInclusive bool
+		want                         []key.Destination
+	}{{
+		name:  "between",
+		start: sqltypes.NewVarBinary("abc"), end: sqltypes.NewVarChar("abd"),
+		startInclusive: true, endInclusive: true,
+		want: keyRange("abc", "abd\x00"),
+	}, {
+		name:  "exclusive bounds",
+		start: sqltypes.NewVarBinary("abc"), end: sqltypes.NewVarBinary("abd"),
+		want: keyRange("abc\x00", "abd"),
+	}, {
+		name:  "open start",
+		start: sqltypes.NULL, end: sqltypes.NewVarBinary("abd"),
+		want: keyRange("", "abd"),
+	}, {
+		name:  "open end",
+		start: sqltypes.NewVarBinary("abc"), end: sqltypes.NULL,
+		startInclusive: true,
+		want:           keyRange("abc", ""),
+	}, {
+		name:  "empty",
+		start: sqltypes.NewVarBinary("abd"), end: sqltypes.NewVarBinary("abd"),
+		startInclusive: true,
+		want:           []key.Destination{key.DestinationNone{}},
+	}, {
+		name:  "not a string",
+		start: sqltypes.NewInt64(10), end: sqltypes.NewVarBinary("abd"),
+		want: []key.Destination{key.DestinationAllShards{}},
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := binOnlyVindex.(Ordered).MapRange(context.Background(), nil, tc.start, tc.end, tc.startInclusive, tc.endInclusive)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -297,6 +297,20 @@ func NewKeyRangeFromPrefix(begin []byte) key.Destination {
 	}
 }
 
+// newKeyRange returns the destination of the keyspace ids from start, included, to
+// end, excluded. A nil start or end leaves the range open on that side.
+func newKeyRange(start, end []byte) []key.Destination {
+	if start != nil && end != nil && bytes.Compare(start, end) >= 0 {
+		return []key.Destination{key.DestinationNone{}}
+	}
+	return []key.Destination{key.DestinationKeyRange{
+		KeyRange: &topodatapb.KeyRange{
+			Start: start,
+			End:   end,
+		},
+	}}
+}
+
 func addOne(value []byte) []byte {
 	n := len(value)
 	overflow := true
@@ -44,11 +44,13 @@ var (
 	_ Lookup          = (*ConsistentLookupUnique)(nil)
 	_ WantOwnerInfo   = (*ConsistentLookupUnique)(nil)
 	_ LookupPlanable  = (*ConsistentLookupUnique)(nil)
+	_ LookupCached    = (*ConsistentLookupUnique)(nil)
 	_ ParamValidating = (*ConsistentLookupUnique)(nil)
 	_ SingleColumn    = (*ConsistentLookup)(nil)
 	_ Lookup          = (*ConsistentLookup)(nil)
 	_ WantOwnerInfo   = (*ConsistentLookup)(nil)
 	_ LookupPlanable  = (*ConsistentLookup)(nil)
+	_ LookupCached    = (*ConsistentLookup)(nil)
 	_ ParamValidating = (*ConsistentLookup)(nil)
 
 	consistentLookupParams = append(
@@ -167,6 +169,11 @@ func (lu *ConsistentLookup) AutoCommitEnabled() bool {
 	return lu.lkp.Autocommit
 }
 
+// LookupCacheEnabled implements the LookupCached interface.
+func (lu *ConsistentLookup) LookupCacheEnabled() bool {
+	return lu.lkp.cacheEnabled()
+}
+
 // UnknownParams implements the ParamValidating interface.
 func (lu *ConsistentLookup) UnknownParams() []string {
 	return lu.unknownParams
@@ -272,6 +279,11 @@ func (lu *ConsistentLookupUnique) AutoCommitEnabled() bool {
 	return lu.lkp.Autocommit
 }
 
+// LookupCacheEnabled implements the LookupCached interface.
+func (lu *ConsistentLookupUnique) LookupCacheEnabled() bool {
+	return lu.lkp.cacheEnabled()
+}
+
 // ====================================================================
 
 // clCommon defines a vindex that uses a lookup table.
@@ -556,6 +556,14 @@ func (vc *loggingVCursor) LookupRowLockShardSession() vtgatepb.CommitOrder {
 	return vtgatepb.CommitOrder_PRE
 }
 
+func (vc *loggingVCursor) InTransaction() bool {
+	return false
+}
+
+func (vc *loggingVCursor) AfterTransaction(f func()) {
+	f()
+}
+
 func (vc *loggingVCursor) InTransactionAndIsDML() bool {
 	return false
 }
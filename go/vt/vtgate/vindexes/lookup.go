@@ -36,10 +36,12 @@ var (
 	_ SingleColumn    = (*LookupUnique)(nil)
 	_ Lookup          = (*LookupUnique)(nil)
 	_ LookupPlanable  = (*LookupUnique)(nil)
+	_ LookupCached    = (*LookupUnique)(nil)
 	_ ParamValidating = (*LookupUnique)(nil)
 	_ SingleColumn    = (*LookupNonUnique)(nil)
 	_ Lookup          = (*LookupNonUnique)(nil)
 	_ LookupPlanable  = (*LookupNonUnique)(nil)
+	_ LookupCached    = (*LookupNonUnique)(nil)
 	_ ParamValidating = (*LookupNonUnique)(nil)
 
 	lookupParams = append(
@@ -76,6 +78,11 @@ func (ln *LookupNonUnique) AutoCommitEnabled() bool {
 	return ln.lkp.Autocommit
 }
 
+// LookupCacheEnabled implements the LookupCached interface.
+func (ln *LookupNonUnique) LookupCacheEnabled() bool {
+	return ln.lkp.cacheEnabled()
+}
+
 // String returns the name of the vindex.
 func (ln *LookupNonUnique) String() string {
 	return ln.name
@@ -208,6 +215,8 @@ func (ln *LookupNonUnique) UnknownParams() []string {
 //	autocommit: setting this to "true" will cause inserts to upsert and deletes to be ignored.
 //	write_only: in this mode, Map functions return the full keyrange causing a full scatter.
 //	no_verify: in this mode, Verify will always succeed.
+//	cache_size: the number of ids whose rows are cached at vtgate, none by default.
+//	cache_ttl: how long the rows of an id stay cached, like "30s". One minute by default.
 func newLookup(name string, m map[string]string) (Vindex, error) {
 	lookup := &LookupNonUnique{
 		name:          name,
@@ -269,6 +278,11 @@ func (lu *LookupUnique) AutoCommitEnabled() bool {
 	return lu.lkp.Autocommit
 }
 
+// LookupCacheEnabled implements the LookupCached interface.
+func (lu *LookupUnique) LookupCacheEnabled() bool {
+	return lu.lkp.cacheEnabled()
+}
+
 // newLookupUnique creates a LookupUnique vindex.
 // The supplied map has the following required fields:
 //
@@ -280,6 +294,8 @@ func (lu *LookupUnique) AutoCommitEnabled() bool {
 //
 //	autocommit: setting this to "true" will cause deletes to be ignored.
 //	write_only: in this mode, Map functions return the full keyrange causing a full scatter.
+//	cache_size: the number of ids whose rows are cached at vtgate, none by default.
+//	cache_ttl: how long the rows of an id stay cached, like "30s". One minute by default.
 func newLookupUnique(name string, m map[string]string) (Vindex, error) {
 	lu := &LookupUnique{
 		name:          name,
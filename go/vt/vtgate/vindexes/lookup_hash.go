@@ -36,10 +36,12 @@ var (
 	_ SingleColumn    = (*LookupHash)(nil)
 	_ Lookup          = (*LookupHash)(nil)
 	_ LookupPlanable  = (*LookupHash)(nil)
+	_ LookupCached    = (*LookupHash)(nil)
 	_ ParamValidating = (*LookupHash)(nil)
 	_ SingleColumn    = (*LookupHashUnique)(nil)
 	_ Lookup          = (*LookupHashUnique)(nil)
 	_ LookupPlanable  = (*LookupHashUnique)(nil)
+	_ LookupCached    = (*LookupHashUnique)(nil)
 	_ ParamValidating = (*LookupHashUnique)(nil)
 
 	lookupHashParams = append(
@@ -189,6 +191,11 @@ func (lh *LookupHash) AutoCommitEnabled() bool {
 	return lh.lkp.Autocommit
 }
 
+// LookupCacheEnabled implements the LookupCached interface.
+func (lh *LookupHash) LookupCacheEnabled() bool {
+	return lh.lkp.cacheEnabled()
+}
+
 // GetCommitOrder implements the LookupPlanable interface
 func (lh *LookupHash) GetCommitOrder() vtgatepb.CommitOrder {
 	return vtgatepb.CommitOrder_NORMAL
@@ -434,6 +441,11 @@ func (lhu *LookupHashUnique) AutoCommitEnabled() bool {
 	return lhu.lkp.Autocommit
 }
 
+// LookupCacheEnabled implements the LookupCached interface.
+func (lhu *LookupHashUnique) LookupCacheEnabled() bool {
+	return lhu.lkp.cacheEnabled()
+}
+
 func (lhu *LookupHashUnique) Query() (selQuery string, arguments []string) {
 	return lhu.lkp.query()
 }
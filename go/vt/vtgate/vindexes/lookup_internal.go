@@ -18,13 +18,17 @@ package vindexes
 
 import (
 	"bytes"
+	"container/list"
 	"context"
 	"fmt"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
@@ -46,6 +50,10 @@ const (
 	lookupInternalParamIgnoreNulls = "ignore_nulls"
 	lookupInternalParamBatchLookup = "batch_lookup"
 	lookupInternalParamReadLock    = "read_lock"
+	lookupInternalParamCacheSize   = "cache_size"
+	lookupInternalParamCacheTTL    = "cache_ttl"
+
+	defaultLookupCacheTTL = time.Minute
 )
 
 var (
@@ -71,7 +79,12 @@ var (
 		lookupInternalParamIgnoreNulls,
 		lookupInternalParamBatchLookup,
 		lookupInternalParamReadLock,
+		lookupInternalParamCacheSize,
+		lookupInternalParamCacheTTL,
 	}
+
+	lookupCacheHits   = stats.NewCountersWithSingleLabel("LookupVindexCacheHits", "Number of lookup vindex ids mapped from the cache", "Table")
+	lookupCacheMisses = stats.NewCountersWithSingleLabel("LookupVindexCacheMisses", "Number of lookup vindex ids missing from the cache", "Table")
 )
 
 // lookupInternal implements the functions for the Lookup vindexes.
@@ -86,6 +99,7 @@ type lookupInternal struct {
 	BatchLookup             bool     `json:"batch_lookup,omitempty"`
 	ReadLock                string   `json:"read_lock,omitempty"`
 	sel, selTxDml, ver, del string   // sel: map query, ver: verify query, del: delete query
+	cache                   *lookupCache
 }
 
 func (lkp *lookupInternal) Init(lookupQueryParams map[string]string, autocommit, upsert, multiShardAutocommit bool) error {
@@ -113,6 +127,22 @@ func (lkp *lookupInternal) Init(lookupQueryParams map[string]string, autocommit,
 		lkp.ReadLock = readLock
 	}
 
+	if size, ok := lookupQueryParams[lookupInternalParamCacheSize]; ok {
+		capacity, err := strconv.Atoi(size)
+		if err != nil || capacity < 0 {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid %s value: %s", lookupInternalParamCacheSize, size)
+		}
+		ttl := defaultLookupCacheTTL
+		if ttlStr, ok := lookupQueryParams[lookupInternalParamCacheTTL]; ok {
+			if ttl, err = time.ParseDuration(ttlStr); err != nil || ttl <= 0 {
+				return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid %s value: %s", lookupInternalParamCacheTTL, ttlStr)
+			}
+		}
+		if capacity > 0 {
+			lkp.cache = newLookupCache(lkp.Table, capacity, ttl)
+		}
+	}
+
 	lkp.Autocommit = autocommit
 	lkp.Upsert = upsert
 	if multiShardAutocommit {
@@ -143,16 +173,46 @@ func (lkp *lookupInternal) Lookup(ctx context.Context, vcursor VCursor, ids []sq
 	if vcursor == nil {
 		return nil, vterrors.VT13001("cannot perform lookup: no vcursor provided")
 	}
-	results := make([]*sqltypes.Result, 0, len(ids))
 	if lkp.Autocommit {
 		co = vtgatepb.CommitOrder_AUTOCOMMIT
 	}
-	var sel string
 	if vcursor.InTransactionAndIsDML() {
-		sel = lkp.selTxDml
-	} else {
-		sel = lkp.sel
+		// DMLs lock the rows they read, so they always read them from the lookup table
+		return lkp.lookup(ctx, vcursor, ids, lkp.selTxDml, co)
+	}
+	// the reads of a transaction might see its own writes, which are not committed yet
+	if lkp.cache == nil || vcursor.InTransaction() {
+		return lkp.lookup(ctx, vcursor, ids, lkp.sel, co)
+	}
+
+	generation := lkp.cache.generation()
+	results := make([]*sqltypes.Result, len(ids))
+	var missing []sqltypes.Value
+	var missingIdx []int
+	for i, id := range ids {
+		if rows, ok := lkp.cache.get(id); ok {
+			results[i] = &sqltypes.Result{Rows: rows}
+			continue
+		}
+		missing = append(missing, id)
+		missingIdx = append(missingIdx, i)
 	}
+	if len(missing) == 0 {
+		return results, nil
+	}
+	missingResults, err := lkp.lookup(ctx, vcursor, missing, lkp.sel, co)
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range missingResults {
+		results[missingIdx[i]] = result
+		lkp.cache.set(missing[i], result.Rows, generation)
+	}
+	return results, nil
+}
+
+func (lkp *lookupInternal) lookup(ctx context.Context, vcursor VCursor, ids []sqltypes.Value, sel string, co vtgatepb.CommitOrder) ([]*sqltypes.Result, error) {
+	results := make([]*sqltypes.Result, 0, len(ids))
 	if ids[0].IsIntegral() || lkp.BatchLookup {
 		// for integral types, batch query all ids and then map them back to the input order
 		vars, err := sqltypes.BuildBindVariable(ids)
@@ -280,6 +340,8 @@ func (lkp *lookupInternal) Create(ctx context.Context, vcursor VCursor, rowsColV
 }
 
 func (lkp *lookupInternal) createCustom(ctx context.Context, vcursor VCursor, rowsColValues [][]sqltypes.Value, toValues []sqltypes.Value, ignoreMode bool, co vtgatepb.CommitOrder) error {
+	lkp.invalidateCache(vcursor, rowsColValues)
+
 	// Trim rows with null values
 	trimmedRowsCols := make([][]sqltypes.Value, 0, len(rowsColValues))
 	trimmedToValues := make([]sqltypes.Value, 0, len(toValues))
@@ -368,6 +430,8 @@ nextRow:
 // A call to Delete would look like this:
 // Delete(vcursor, [[valuea, valueb]], 52CB7B1B31B2222E)
 func (lkp *lookupInternal) Delete(ctx context.Context, vcursor VCursor, rowsColValues [][]sqltypes.Value, value sqltypes.Value, co vtgatepb.CommitOrder) error {
+	lkp.invalidateCache(vcursor, rowsColValues)
+
 	// In autocommit mode, it's not safe to delete. So, it's a no-op.
 	if lkp.Autocommit {
 		return nil
@@ -420,6 +484,128 @@ func (lkp *lookupInternal) query() (selQuery string, arguments []string) {
 	return lkp.sel, lkp.FromColumns
 }
 
+// cacheEnabled returns whether the rows of the lookup table are cached.
+func (lkp *lookupInternal) cacheEnabled() bool {
+	return lkp.cache != nil
+}
+
+// invalidateCache invalidates the cached rows of the ids written by a DML. The rows are
+// invalidated again once the transaction of the DML is over, since the lookups of other
+// sessions can read and cache the previous rows until it is committed.
+func (lkp *lookupInternal) invalidateCache(vcursor VCursor, rowsColValues [][]sqltypes.Value) {
+	if lkp.cache == nil {
+		return
+	}
+	lkp.cache.invalidate(rowsColValues)
+	vcursor.AfterTransaction(func() {
+		lkp.cache.invalidate(rowsColValues)
+	})
+}
+
+// lookupCache caches the rows the lookup query returns for the ids it looks up.
+// The rows of an id are invalidated when DMLs on the owner table change them
+// through this vtgate, and expire after the ttl otherwise, since other vtgates
+// can change them as well. Ids without any rows are not cached, and neither are
+// the rows read in a transaction.
+type lookupCache struct {
+	table    string
+	ttl      time.Duration
+	capacity int
+
+	mu sync.Mutex
+	// entries and lru hold the *lookupCacheEntry of the ids, from the most to
+	// the least recently used
+	entries map[string]*list.Element
+	lru     *list.List
+	// gen is bumped by every invalidation, so that the lookups that started
+	// before it don't cache the rows they read
+	gen int64
+}
+
+type lookupCacheEntry struct {
+	id      string
+	rows    [][]sqltypes.Value
+	expires time.Time
+}
+
+func newLookupCache(table string, capacity int, ttl time.Duration) *lookupCache {
+	return &lookupCache{
+		table:    table,
+		ttl:      ttl,
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+func (c *lookupCache) generation() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+func (c *lookupCache) get(id sqltypes.Value) ([][]sqltypes.Value, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[id.ToString()]
+	if ok && time.Now().After(elem.Value.(*lookupCacheEntry).expires) {
+		c.remove(elem)
+		ok = false
+	}
+	if !ok {
+		lookupCacheMisses.Add(c.table, 1)
+		return nil, false
+	}
+	lookupCacheHits.Add(c.table, 1)
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*lookupCacheEntry).rows, true
+}
+
+// set caches the rows of an id, unless they were invalidated since the generation
+// read before looking them up.
+func (c *lookupCache) set(id sqltypes.Value, rows [][]sqltypes.Value, generation int64) {
+	if len(rows) == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gen != generation {
+		return
+	}
+	entry := &lookupCacheEntry{id: id.ToString(), rows: rows, expires: time.Now().Add(c.ttl)}
+	if elem, ok := c.entries[entry.id]; ok {
+		c.remove(elem)
+	}
+	c.entries[entry.id] = c.lru.PushFront(entry)
+	if c.lru.Len() > c.capacity {
+		c.remove(c.lru.Back())
+	}
+}
+
+// invalidate removes the rows of the ids in the first column of rowsColValues,
+// which is the column the lookup query looks up.
+func (c *lookupCache) invalidate(rowsColValues [][]sqltypes.Value) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	for _, row := range rowsColValues {
+		if len(row) == 0 {
+			continue
+		}
+		if elem, ok := c.entries[row[0].ToString()]; ok {
+			c.remove(elem)
+		}
+	}
+}
+
+func (c *lookupCache) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*lookupCacheEntry).id)
+}
+
 type commonConfig struct {
 	autocommit           bool
 	multiShardAutocommit bool
@@ -52,6 +52,11 @@ type vcursor struct {
 	autocommits int
 	pre, post   int
 	keys        []sqltypes.Value
+
+	inTransaction    bool
+	afterTransaction []func()
+	// onExecute is called before executing the queries
+	onExecute func()
 }
 
 func (vc *vcursor) LookupRowLockShardSession() vtgatepb.CommitOrder {
@@ -62,6 +67,27 @@ func (vc *vcursor) InTransactionAndIsDML() bool {
 	return false
 }
 
+func (vc *vcursor) InTransaction() bool {
+	return vc.inTransaction
+}
+
+func (vc *vcursor) AfterTransaction(f func()) {
+	if !vc.inTransaction {
+		f()
+		return
+	}
+	vc.afterTransaction = append(vc.afterTransaction, f)
+}
+
+// endTransaction calls the functions registered with AfterTransaction
+func (vc *vcursor) endTransaction() {
+	vc.inTransaction = false
+	for _, f := range vc.afterTransaction {
+		f()
+	}
+	vc.afterTransaction = nil
+}
+
 func (vc *vcursor) Execute(ctx context.Context, method string, query string, bindvars map[string]*querypb.BindVariable, rollbackOnError bool, co vtgatepb.CommitOrder) (*sqltypes.Result, error) {
 	switch co {
 	case vtgatepb.CommitOrder_PRE:
@@ -79,6 +105,9 @@ func (vc *vcursor) ExecuteKeyspaceID(ctx context.Context, keyspace string, ksid
 }
 
 func (vc *vcursor) execute(query string, bindvars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	if vc.onExecute != nil {
+		vc.onExecute()
+	}
 	vc.queries = append(vc.queries, &querypb.BoundQuery{
 		Sql:           query,
 		BindVariables: bindvars,
@@ -287,6 +316,24 @@ func testLookupCreateVindexInternalCases(t *testing.T, testCaseF func(string, ma
 			vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid read_lock value: unknown"),
 			nil,
 		),
+		testCaseF(
+			"cache_size and cache_ttl",
+			map[string]string{"cache_size": "1000", "cache_ttl": "10s"},
+			nil,
+			nil,
+		),
+		testCaseF(
+			"cache_size reject not a size",
+			map[string]string{"cache_size": "-1"},
+			vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid cache_size value: -1"),
+			nil,
+		),
+		testCaseF(
+			"cache_ttl reject not a duration",
+			map[string]string{"cache_size": "1000", "cache_ttl": "10"},
+			vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid cache_ttl value: 10"),
+			nil,
+		),
 		testCaseF(
 			"ignore_nulls reject not bool",
 			map[string]string{"ignore_nulls": "hello"},
@@ -411,6 +458,113 @@ func TestLookupNonUniqueMap(t *testing.T) {
 	require.EqualError(t, err, "lookup.Map: execute failed")
 }
 
+func TestLookupNonUniqueMapCache(t *testing.T) {
+	vindex, err := CreateVindex("lookup", "lookup", map[string]string{
+		"table":      "t",
+		"from":       "fromc",
+		"to":         "toc",
+		"cache_size": "10",
+	})
+	require.NoError(t, err)
+	lnu := vindex.(SingleColumn)
+	require.True(t, vindex.(LookupCached).LookupCacheEnabled())
+	vc := &vcursor{numRows: 2}
+	hits, misses := lookupCacheHits.Counts()["t"], lookupCacheMisses.Counts()["t"]
+
+	ids := []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewInt64(2)}
+	want := []key.Destination{
+		key.DestinationKeyspaceIDs([][]byte{[]byte("1"), []byte("2")}),
+		key.DestinationKeyspaceIDs([][]byte{[]byte("1"), []byte("2")}),
+	}
+	got, err := lnu.Map(context.Background(), vc, ids)
+	require.NoError(t, err)
+	utils.MustMatch(t, want, got)
+	require.Len(t, vc.queries, 1)
+
+	// the ids are mapped from the cache, and only the missing ones are looked up
+	got, err = lnu.Map(context.Background(), vc, append(ids, sqltypes.NewInt64(3)))
+	require.NoError(t, err)
+	utils.MustMatch(t, append(want, key.DestinationNone{}), got)
+	require.Len(t, vc.queries, 2)
+	vars, err := sqltypes.BuildBindVariable([]any{sqltypes.NewInt64(3)})
+	require.NoError(t, err)
+	utils.MustMatch(t, map[string]*querypb.BindVariable{"fromc": vars}, vc.queries[1].BindVariables)
+
+	// ids without rows are not cached
+	_, err = lnu.Map(context.Background(), vc, []sqltypes.Value{sqltypes.NewInt64(3)})
+	require.NoError(t, err)
+	require.Len(t, vc.queries, 3)
+
+	// DMLs on the owner table invalidate the rows of their ids
+	err = lnu.(Lookup).Delete(context.Background(), vc, [][]sqltypes.Value{{sqltypes.NewInt64(1)}}, []byte("1"))
+	require.NoError(t, err)
+	_, err = lnu.Map(context.Background(), vc, ids)
+	require.NoError(t, err)
+	require.Len(t, vc.queries, 5)
+	vars, err = sqltypes.BuildBindVariable([]any{sqltypes.NewInt64(1)})
+	require.NoError(t, err)
+	utils.MustMatch(t, map[string]*querypb.BindVariable{"fromc": vars}, vc.queries[4].BindVariables)
+
+	assert.EqualValues(t, 3, lookupCacheHits.Counts()["t"]-hits)
+	assert.EqualValues(t, 5, lookupCacheMisses.Counts()["t"]-misses)
+}
+
+func TestLookupNonUniqueMapCacheTransaction(t *testing.T) {
+	vindex, err := CreateVindex("lookup", "lookup", map[string]string{
+		"table":      "t",
+		"from":       "fromc",
+		"to":         "toc",
+		"cache_size": "10",
+	})
+	require.NoError(t, err)
+	lnu := vindex.(SingleColumn)
+	ids := []sqltypes.Value{sqltypes.NewInt64(1)}
+	writer := &vcursor{numRows: 1, inTransaction: true}
+	reader := &vcursor{numRows: 1}
+
+	// the reads of a transaction are not cached, as they might not be committed
+	_, err = lnu.Map(context.Background(), writer, ids)
+	require.NoError(t, err)
+	_, err = lnu.Map(context.Background(), writer, ids)
+	require.NoError(t, err)
+	require.Len(t, writer.queries, 2)
+	_, err = lnu.Map(context.Background(), reader, ids)
+	require.NoError(t, err)
+	require.Len(t, reader.queries, 1)
+
+	// another session reads and caches the previous rows until the transaction commits
+	err = lnu.(Lookup).Delete(context.Background(), writer, [][]sqltypes.Value{{sqltypes.NewInt64(1)}}, []byte("1"))
+	require.NoError(t, err)
+	_, err = lnu.Map(context.Background(), reader, ids)
+	require.NoError(t, err)
+	require.Len(t, reader.queries, 2)
+	_, err = lnu.Map(context.Background(), reader, ids)
+	require.NoError(t, err)
+	require.Len(t, reader.queries, 2)
+
+	// the commit invalidates them again
+	writer.endTransaction()
+	_, err = lnu.Map(context.Background(), reader, ids)
+	require.NoError(t, err)
+	require.Len(t, reader.queries, 3)
+
+	// the rows read while the transaction commits are not cached
+	writer.inTransaction = true
+	err = lnu.(Lookup).Delete(context.Background(), writer, [][]sqltypes.Value{{sqltypes.NewInt64(1)}}, []byte("1"))
+	require.NoError(t, err)
+	reader.onExecute = writer.endTransaction
+	_, err = lnu.Map(context.Background(), reader, ids)
+	require.NoError(t, err)
+	require.Len(t, reader.queries, 4)
+	reader.onExecute = nil
+	_, err = lnu.Map(context.Background(), reader, ids)
+	require.NoError(t, err)
+	require.Len(t, reader.queries, 5)
+	_, err = lnu.Map(context.Background(), reader, ids)
+	require.NoError(t, err)
+	require.Len(t, reader.queries, 5)
+}
+
 func TestLookupNonUniqueMapAutocommit(t *testing.T) {
 	vindex, err := CreateVindex("lookup", "lookup", map[string]string{
 		"table":      "t",
@@ -21,6 +21,7 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
+	"strconv"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/key"
@@ -31,6 +32,7 @@ var (
 	_ Reversible      = (*Numeric)(nil)
 	_ Hashing         = (*Numeric)(nil)
 	_ ParamValidating = (*Numeric)(nil)
+	_ Ordered         = (*Numeric)(nil)
 )
 
 // Numeric defines a bit-pattern mapping of a uint64 to the KeyspaceId.
@@ -95,6 +97,55 @@ func (vind *Numeric) Map(ctx context.Context, vcursor VCursor, ids []sqltypes.Va
 	return out, nil
 }
 
+// MapRange returns the range of keyspace ids of the ids between start and end.
+func (vind *Numeric) MapRange(ctx context.Context, vcursor VCursor, start, end sqltypes.Value, startInclusive, endInclusive bool) ([]key.Destination, error) {
+	var from, to []byte
+	if !start.IsNull() {
+		num, negative, ok := numericBound(start)
+		switch {
+		case !ok:
+			return []key.Destination{key.DestinationAllShards{}}, nil
+		case !negative:
+			from = binary.BigEndian.AppendUint64(nil, num)
+			if !startInclusive {
+				if from = addOne(from); from == nil {
+					return []key.Destination{key.DestinationNone{}}, nil
+				}
+			}
+		}
+	}
+	if !end.IsNull() {
+		num, negative, ok := numericBound(end)
+		switch {
+		case !ok:
+			return []key.Destination{key.DestinationAllShards{}}, nil
+		case negative, num == 0 && !endInclusive:
+			return []key.Destination{key.DestinationNone{}}, nil
+		}
+		to = binary.BigEndian.AppendUint64(nil, num)
+		if endInclusive {
+			// the end of the keyspace when num is the biggest id
+			to = addOne(to)
+		}
+	}
+	return newKeyRange(from, to), nil
+}
+
+// numericBound converts the bound of a range of ids to a uint64. The ids are never
+// negative, so a negative bound is only reported as such. Bounds that aren't integers
+// can't be converted.
+func numericBound(v sqltypes.Value) (num uint64, negative bool, ok bool) {
+	if !v.IsIntegral() && !v.IsText() && !v.IsBinary() {
+		return 0, false, false
+	}
+	s := v.ToString()
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return uint64(i), i < 0, true
+	}
+	num, err := strconv.ParseUint(s, 10, 64)
+	return num, false, err == nil
+}
+
 // ReverseMap returns the associated ids for the ksids.
 func (*Numeric) ReverseMap(_ VCursor, ksids [][]byte) ([]sqltypes.Value, error) {
 	var reverseIds = make([]sqltypes.Value, len(ksids))
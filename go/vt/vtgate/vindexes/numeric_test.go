@@ -18,13 +18,17 @@ package vindexes
 
 import (
 	"context"
+	"math"
 	"reflect"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/key"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
 var numeric SingleColumn
@@ -146,3 +150,73 @@ func TestNumericReverseMapBadData(t *testing.T) {
 		t.Errorf("numeric.Map: %v, want %v", err, want)
 	}
 }
+
+func TestNumericMapRange(t *testing.T) {
+	keyRange := func(start, end []byte) []key.Destination {
+		return []key.Destination{key.DestinationKeyRange{KeyRange: &topodatapb.KeyRange{Start: start, End: end}}}
+	}
+	none := []key.Destination{key.DestinationNone{}}
+
+	tcs := []struct {
+		name                         string
+		start, end                   sqltypes.Value
+		startInclusive, endInclusive bool
+		want                         []key.Destination
+	}{{
+		name:  "between",
+		start: sqltypes.NewInt64(10), end: sqltypes.NewInt64(20),
+		startInclusive: true, endInclusive: true,
+		want: keyRange([]byte("\x00\x00\x00\x00\x00\x00\x00\x0a"), []byte("\x00\x00\x00\x00\x00\x00\x00\x15")),
+	}, {
+		name:  "exclusive bounds",
+		start: sqltypes.NewVarChar("10"), end: sqltypes.NewUint64(0x1ff),
+		want: keyRange([]byte("\x00\x00\x00\x00\x00\x00\x00\x0b"), []byte("\x00\x00\x00\x00\x00\x00\x01\xff")),
+	}, {
+		name:  "open start",
+		start: sqltypes.NULL, end: sqltypes.NewInt64(10),
+		want: keyRange(nil, []byte("\x00\x00\x00\x00\x00\x00\x00\x0a")),
+	}, {
+		name:  "negative start",
+		start: sqltypes.NewInt64(-10), end: sqltypes.NewInt64(10),
+		want: keyRange(nil, []byte("\x00\x00\x00\x00\x00\x00\x00\x0a")),
+	}, {
+		name:  "open end",
+		start: sqltypes.NewInt64(10), end: sqltypes.NULL,
+		startInclusive: true,
+		want:           keyRange([]byte("\x00\x00\x00\x00\x00\x00\x00\x0a"), nil),
+	}, {
+		name:  "biggest end",
+		start: sqltypes.NewInt64(10), end: sqltypes.NewUint64(math.MaxUint64),
+		startInclusive: true, endInclusive: true,
+		want: keyRange([]byte("\x00\x00\x00\x00\x00\x00\x00\x0a"), nil),
+	}, {
+		name:  "empty",
+		start: sqltypes.NewInt64(20), end: sqltypes.NewInt64(10),
+		startInclusive: true, endInclusive: true,
+		want: none,
+	}, {
+		name:  "negative end",
+		start: sqltypes.NULL, end: sqltypes.NewInt64(-1),
+		endInclusive: true,
+		want:         none,
+	}, {
+		name:  "nothing below zero",
+		start: sqltypes.NULL, end: sqltypes.NewInt64(0),
+		want: none,
+	}, {
+		name:  "nothing above the biggest id",
+		start: sqltypes.NewUint64(math.MaxUint64), end: sqltypes.NULL,
+		want: none,
+	}, {
+		name:  "not an integer",
+		start: sqltypes.NewFloat64(10.5), end: sqltypes.NewInt64(20),
+		want: []key.Destination{key.DestinationAllShards{}},
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := numeric.(Ordered).MapRange(context.Background(), nil, tc.start, tc.end, tc.startInclusive, tc.endInclusive)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,348 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vindexes
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+
+	"vitess.io/vitess/go/mysql/datetime"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	rangeParamJSON      = "json"
+	rangeParamJSONPath  = "json_path"
+	rangeParamValueType = "value_type"
+
+	rangeValueTypeInt      = "int"
+	rangeValueTypeDate     = "date"
+	rangeValueTypeDatetime = "datetime"
+)
+
+var (
+	_ SingleColumn    = (*Range)(nil)
+	_ Reversible      = (*Range)(nil)
+	_ Hashing         = (*Range)(nil)
+	_ ParamValidating = (*Range)(nil)
+	_ Ordered         = (*Range)(nil)
+
+	rangeParams = []string{
+		rangeParamJSON,
+		rangeParamJSONPath,
+		rangeParamValueType,
+	}
+)
+
+// RangeDefinition is a range of ids in the configuration of a Range vindex.
+// From is included in the range and To is not; an empty From or To leaves the
+// range open on that side.
+type RangeDefinition struct {
+	From       string `json:"from,omitempty"`
+	To         string `json:"to,omitempty"`
+	KeyspaceID string `json:"keyspace_id"`
+}
+
+// Range maps ranges of ids to explicit keyspace ids, configured like:
+//
+//	[
+//	  {"to": "1000000", "keyspace_id": "00"},
+//	  {"from": "1000000", "keyspace_id": "80"}
+//	]
+//
+// The ids are integers by default, or dates or datetimes depending on the
+// value_type param. The keyspace id of an id is the keyspace id of its range
+// followed by 8 bytes encoding the id, so all the ids of a range are in the
+// shard holding the keyspace id of the range, and the vindex is Reversible.
+// Ids outside of all the ranges don't map to any keyspace id.
+type Range struct {
+	name          string
+	valueType     string
+	ranges        []rangeEntry
+	unknownParams []string
+}
+
+// rangeEntry is a range of ids, encoded as ordered int64s
+type rangeEntry struct {
+	from, to       int64
+	hasFrom, hasTo bool
+	prefix         []byte
+}
+
+func (r *rangeEntry) contains(v int64) bool {
+	return (!r.hasFrom || v >= r.from) && (!r.hasTo || v < r.to)
+}
+
+func init() {
+	Register("range", newRange)
+}
+
+// newRange creates a Range vindex.
+func newRange(name string, params map[string]string) (Vindex, error) {
+	jsonStr, jsok := params[rangeParamJSON]
+	jsonPath, jpok := params[rangeParamJSONPath]
+	if jsok == jpok {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Range: exactly one of the `json` or `json_path` params is required in vschema")
+	}
+	data := []byte(jsonStr)
+	if jpok {
+		var err error
+		if data, err = os.ReadFile(jsonPath); err != nil {
+			return nil, err
+		}
+	}
+	var definitions []RangeDefinition
+	if err := json.Unmarshal(data, &definitions); err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Range: invalid ranges: %v", err)
+	}
+
+	vind := &Range{
+		name:          name,
+		valueType:     rangeValueTypeInt,
+		unknownParams: FindUnknownParams(params, rangeParams),
+	}
+	if valueType, ok := params[rangeParamValueType]; ok {
+		switch valueType {
+		case rangeValueTypeInt, rangeValueTypeDate, rangeValueTypeDatetime:
+			vind.valueType = valueType
+		default:
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Range: invalid value_type %s, it should be one of int, date or datetime", valueType)
+		}
+	}
+
+	for _, def := range definitions {
+		r := rangeEntry{}
+		var err error
+		if def.From != "" {
+			if r.from, err = vind.encode(sqltypes.NewVarChar(def.From)); err != nil {
+				return nil, vterrors.Wrapf(err, "Range: invalid start of range %s", def.From)
+			}
+			r.hasFrom = true
+		}
+		if def.To != "" {
+			if r.to, err = vind.encode(sqltypes.NewVarChar(def.To)); err != nil {
+				return nil, vterrors.Wrapf(err, "Range: invalid end of range %s", def.To)
+			}
+			r.hasTo = true
+		}
+		if r.hasFrom && r.hasTo && r.from >= r.to {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Range: the range from %s to %s is empty", def.From, def.To)
+		}
+		if r.prefix, err = hex.DecodeString(def.KeyspaceID); err != nil || len(r.prefix) == 0 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Range: invalid keyspace_id '%s', it should be a non-empty hex string", def.KeyspaceID)
+		}
+		vind.ranges = append(vind.ranges, r)
+	}
+
+	sort.Slice(vind.ranges, func(i, j int) bool {
+		a, b := vind.ranges[i], vind.ranges[j]
+		return !a.hasFrom && b.hasFrom || a.hasFrom && b.hasFrom && a.from < b.from
+	})
+	for i := 1; i < len(vind.ranges); i++ {
+		prev, cur := vind.ranges[i-1], vind.ranges[i]
+		if !prev.hasTo || !cur.hasFrom || prev.to > cur.from {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Range: the ranges of keyspace ids %x and %x overlap", prev.prefix, cur.prefix)
+		}
+	}
+	return vind, nil
+}
+
+// String returns the name of the vindex.
+func (vind *Range) String() string {
+	return vind.name
+}
+
+// Cost returns the cost of this vindex as 1.
+func (*Range) Cost() int {
+	return 1
+}
+
+// IsUnique returns true since the Vindex is unique.
+func (*Range) IsUnique() bool {
+	return true
+}
+
+// NeedsVCursor satisfies the Vindex interface.
+func (*Range) NeedsVCursor() bool {
+	return false
+}
+
+// Verify returns true if ids and ksids match.
+func (vind *Range) Verify(ctx context.Context, vcursor VCursor, ids []sqltypes.Value, ksids [][]byte) ([]bool, error) {
+	out := make([]bool, 0, len(ids))
+	for i, id := range ids {
+		ksid, err := vind.Hash(id)
+		if err != nil {
+			out = append(out, false)
+			continue
+		}
+		out = append(out, bytes.Equal(ksid, ksids[i]))
+	}
+	return out, nil
+}
+
+// Map can map ids to key.Destination objects.
+func (vind *Range) Map(ctx context.Context, vcursor VCursor, ids []sqltypes.Value) ([]key.Destination, error) {
+	out := make([]key.Destination, 0, len(ids))
+	for _, id := range ids {
+		ksid, err := vind.Hash(id)
+		if err != nil {
+			out = append(out, key.DestinationNone{})
+			continue
+		}
+		out = append(out, key.DestinationKeyspaceID(ksid))
+	}
+	return out, nil
+}
+
+// MapRange returns the destinations of the ids between start and end. A null start or end
+// leaves the range of ids open on that side, and startInclusive and endInclusive tell
+// whether the ids equal to start and end are part of it. Bounds that can't be converted
+// to ids map to all the shards.
+func (vind *Range) MapRange(ctx context.Context, vcursor VCursor, start, end sqltypes.Value, startInclusive, endInclusive bool) ([]key.Destination, error) {
+	var from, to int64
+	var err error
+	if !start.IsNull() {
+		if from, err = vind.encode(start); err != nil {
+			return []key.Destination{key.DestinationAllShards{}}, nil
+		}
+	}
+	if !end.IsNull() {
+		if to, err = vind.encode(end); err != nil {
+			return []key.Destination{key.DestinationAllShards{}}, nil
+		}
+	}
+
+	var out []key.Destination
+	for _, r := range vind.ranges {
+		// the ids in the range are all smaller than start
+		if !start.IsNull() && r.hasTo && from >= r.to {
+			continue
+		}
+		// or all bigger than end
+		if !end.IsNull() && r.hasFrom && (to < r.from || to == r.from && !endInclusive) {
+			continue
+		}
+		out = append(out, NewKeyRangeFromPrefix(r.prefix))
+	}
+	if len(out) == 0 {
+		out = append(out, key.DestinationNone{})
+	}
+	return out, nil
+}
+
+// ReverseMap returns the associated ids for the ksids.
+func (vind *Range) ReverseMap(_ VCursor, ksids [][]byte) ([]sqltypes.Value, error) {
+	reverseIds := make([]sqltypes.Value, 0, len(ksids))
+	for _, ksid := range ksids {
+		id, ok := vind.reverse(ksid)
+		if !ok {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Range.ReverseMap: keyspace id %x is not in any range", ksid)
+		}
+		reverseIds = append(reverseIds, id)
+	}
+	return reverseIds, nil
+}
+
+func (vind *Range) reverse(ksid []byte) (sqltypes.Value, bool) {
+	for _, r := range vind.ranges {
+		if len(ksid) != len(r.prefix)+8 || !bytes.HasPrefix(ksid, r.prefix) {
+			continue
+		}
+		v := int64(binary.BigEndian.Uint64(ksid[len(r.prefix):]) ^ (1 << 63))
+		if !r.contains(v) {
+			continue
+		}
+		return vind.decode(v), true
+	}
+	return sqltypes.Value{}, false
+}
+
+// Hash returns the keyspace id of the id.
+func (vind *Range) Hash(id sqltypes.Value) ([]byte, error) {
+	v, err := vind.encode(id)
+	if err != nil {
+		return nil, err
+	}
+	i := sort.Search(len(vind.ranges), func(i int) bool {
+		r := vind.ranges[i]
+		return !r.hasTo || v < r.to
+	})
+	if i == len(vind.ranges) || !vind.ranges[i].contains(v) {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Range: %s is not in any range", id.String())
+	}
+
+	prefix := vind.ranges[i].prefix
+	ksid := make([]byte, len(prefix)+8)
+	copy(ksid, prefix)
+	// flipping the sign bit keeps the keyspace ids in the same order as the ids
+	binary.BigEndian.PutUint64(ksid[len(prefix):], uint64(v)^(1<<63))
+	return ksid, nil
+}
+
+// UnknownParams implements the ParamValidating interface.
+func (vind *Range) UnknownParams() []string {
+	return vind.unknownParams
+}
+
+// encode converts an id to an int64, which orders the ids like MySQL does
+func (vind *Range) encode(id sqltypes.Value) (int64, error) {
+	if vind.valueType == rangeValueTypeInt {
+		return id.ToCastInt64()
+	}
+	s := id.ToString()
+	dt, _, ok := datetime.ParseDateTime(s, -1)
+	if !ok {
+		var d datetime.Date
+		if d, ok = datetime.ParseDate(s); !ok {
+			return 0, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid %s %s", vind.valueType, s)
+		}
+		dt = datetime.DateTime{Date: d}
+	}
+	if vind.valueType == rangeValueTypeDate {
+		dt.Time = datetime.Time{}
+	}
+	return dt.ToStdTime(time.Time{}).UnixMicro(), nil
+}
+
+// decode converts an encoded id back into a value
+func (vind *Range) decode(v int64) sqltypes.Value {
+	switch vind.valueType {
+	case rangeValueTypeDate:
+		d := datetime.NewDateFromStd(time.UnixMicro(v).UTC())
+		return sqltypes.MakeTrusted(sqltypes.Date, d.Format())
+	case rangeValueTypeDatetime:
+		dt := datetime.NewDateTimeFromStd(time.UnixMicro(v).UTC())
+		var prec uint8
+		if v%1000000 != 0 {
+			prec = 6
+		}
+		return sqltypes.MakeTrusted(sqltypes.Datetime, dt.Format(prec))
+	default:
+		return sqltypes.NewInt64(v)
+	}
+}
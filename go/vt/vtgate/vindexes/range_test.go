@@ -0,0 +1,246 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vindexes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+const rangeTestJSON = `[
+	{"to": "1000000", "keyspace_id": "00"},
+	{"from": "1000000", "to": "2000000", "keyspace_id": "80"},
+	{"from": "3000000", "keyspace_id": "c0"}
+]`
+
+func createRangeVindex(t *testing.T, params map[string]string) *Range {
+	vindex, err := CreateVindex("range", "range", params)
+	require.NoError(t, err)
+	return vindex.(*Range)
+}
+
+func rangeCreateVindexTestCase(testName string, vindexParams map[string]string, expectErr error, expectUnknownParams []string) createVindexTestCase {
+	return createVindexTestCase{
+		testName: testName,
+
+		vindexType:   "range",
+		vindexName:   "range",
+		vindexParams: vindexParams,
+
+		expectCost:          1,
+		expectErr:           expectErr,
+		expectIsUnique:      true,
+		expectNeedsVCursor:  false,
+		expectString:        "range",
+		expectUnknownParams: expectUnknownParams,
+	}
+}
+
+func TestRangeCreateVindex(t *testing.T) {
+	cases := []createVindexTestCase{
+		rangeCreateVindexTestCase(
+			"ranges are required",
+			nil,
+			errors.New("Range: exactly one of the `json` or `json_path` params is required in vschema"),
+			nil,
+		),
+		rangeCreateVindexTestCase(
+			"valid ranges",
+			map[string]string{"json": rangeTestJSON},
+			nil,
+			nil,
+		),
+		rangeCreateVindexTestCase(
+			"unknown params",
+			map[string]string{"json": rangeTestJSON, "hello": "world"},
+			nil,
+			[]string{"hello"},
+		),
+		rangeCreateVindexTestCase(
+			"invalid value type",
+			map[string]string{"json": rangeTestJSON, "value_type": "float"},
+			errors.New("Range: invalid value_type float, it should be one of int, date or datetime"),
+			nil,
+		),
+		rangeCreateVindexTestCase(
+			"invalid keyspace id",
+			map[string]string{"json": `[{"keyspace_id": "8x"}]`},
+			errors.New("Range: invalid keyspace_id '8x', it should be a non-empty hex string"),
+			nil,
+		),
+		rangeCreateVindexTestCase(
+			"empty range",
+			map[string]string{"json": `[{"from": "10", "to": "5", "keyspace_id": "80"}]`},
+			errors.New("Range: the range from 10 to 5 is empty"),
+			nil,
+		),
+		rangeCreateVindexTestCase(
+			"overlapping ranges",
+			map[string]string{"json": `[{"from": "10", "keyspace_id": "80"}, {"from": "0", "to": "11", "keyspace_id": "40"}]`},
+			errors.New("Range: the ranges of keyspace ids 40 and 80 overlap"),
+			nil,
+		),
+		rangeCreateVindexTestCase(
+			"invalid date",
+			map[string]string{"json": `[{"from": "yesterday", "keyspace_id": "80"}]`, "value_type": "date"},
+			errors.New("Range: invalid start of range yesterday: invalid date yesterday"),
+			nil,
+		),
+	}
+
+	testCreateVindexes(t, cases)
+}
+
+func TestRangeMap(t *testing.T) {
+	vind := createRangeVindex(t, map[string]string{"json": rangeTestJSON})
+	got, err := vind.Map(context.Background(), nil, []sqltypes.Value{
+		sqltypes.NewInt64(-5),
+		sqltypes.NewInt64(999999),
+		sqltypes.NewInt64(1000000),
+		sqltypes.NewVarChar("1500000"),
+		sqltypes.NewInt64(2500000),
+		sqltypes.NewUint64(3000000),
+		sqltypes.NewVarChar("abc"),
+	})
+	require.NoError(t, err)
+	want := []key.Destination{
+		key.DestinationKeyspaceID([]byte{0x00, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfb}),
+		key.DestinationKeyspaceID([]byte{0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x0f, 0x42, 0x3f}),
+		key.DestinationKeyspaceID([]byte{0x80, 0x80, 0x00, 0x00, 0x00, 0x00, 0x0f, 0x42, 0x40}),
+		key.DestinationKeyspaceID([]byte{0x80, 0x80, 0x00, 0x00, 0x00, 0x00, 0x16, 0xe3, 0x60}),
+		key.DestinationNone{},
+		key.DestinationKeyspaceID([]byte{0xc0, 0x80, 0x00, 0x00, 0x00, 0x00, 0x2d, 0xc6, 0xc0}),
+		key.DestinationNone{},
+	}
+	assert.Equal(t, want, got)
+
+	ids := []sqltypes.Value{sqltypes.NewInt64(-5), sqltypes.NewInt64(1500000), sqltypes.NewInt64(3000000)}
+	ksids := [][]byte{got[0].(key.DestinationKeyspaceID), got[3].(key.DestinationKeyspaceID), got[5].(key.DestinationKeyspaceID)}
+	reversed, err := vind.ReverseMap(nil, ksids)
+	require.NoError(t, err)
+	assert.Equal(t, ids, reversed)
+
+	verified, err := vind.Verify(context.Background(), nil, ids, [][]byte{ksids[0], ksids[0], ksids[2]})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, true}, verified)
+
+	_, err = vind.ReverseMap(nil, [][]byte{{0x40, 0x80, 0, 0, 0, 0, 0, 0, 0}})
+	require.EqualError(t, err, "Range.ReverseMap: keyspace id 408000000000000000 is not in any range")
+}
+
+func TestRangeMapRange(t *testing.T) {
+	vind := createRangeVindex(t, map[string]string{"json": rangeTestJSON})
+	keyRange := func(start, end byte) key.Destination {
+		return key.DestinationKeyRange{KeyRange: &topodatapb.KeyRange{Start: []byte{start}, End: []byte{end}}}
+	}
+
+	tcs := []struct {
+		name                         string
+		start, end                   sqltypes.Value
+		startInclusive, endInclusive bool
+		want                         []key.Destination
+	}{{
+		name:  "inside a range",
+		start: sqltypes.NewInt64(10), end: sqltypes.NewInt64(20),
+		startInclusive: true, endInclusive: true,
+		want: []key.Destination{keyRange(0x00, 0x01)},
+	}, {
+		name:  "across ranges",
+		start: sqltypes.NewInt64(999999), end: sqltypes.NewInt64(3000000),
+		startInclusive: true, endInclusive: true,
+		want: []key.Destination{keyRange(0x00, 0x01), keyRange(0x80, 0x81), keyRange(0xc0, 0xc1)},
+	}, {
+		name:  "excluded end",
+		start: sqltypes.NewInt64(999999), end: sqltypes.NewInt64(3000000),
+		startInclusive: true, endInclusive: false,
+		want: []key.Destination{keyRange(0x00, 0x01), keyRange(0x80, 0x81)},
+	}, {
+		name:  "open start",
+		start: sqltypes.NULL, end: sqltypes.NewInt64(1000000),
+		endInclusive: false,
+		want:         []key.Destination{keyRange(0x00, 0x01)},
+	}, {
+		name:  "open end",
+		start: sqltypes.NewInt64(1000000), end: sqltypes.NULL,
+		startInclusive: true,
+		want:           []key.Destination{keyRange(0x80, 0x81), keyRange(0xc0, 0xc1)},
+	}, {
+		name:  "between ranges",
+		start: sqltypes.NewInt64(2000000), end: sqltypes.NewInt64(2999999),
+		startInclusive: true, endInclusive: true,
+		want: []key.Destination{key.DestinationNone{}},
+	}, {
+		name:  "not an id",
+		start: sqltypes.NewVarChar("abc"), end: sqltypes.NewInt64(20),
+		startInclusive: true, endInclusive: true,
+		want: []key.Destination{key.DestinationAllShards{}},
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := vind.MapRange(context.Background(), nil, tc.start, tc.end, tc.startInclusive, tc.endInclusive)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRangeDates(t *testing.T) {
+	vind := createRangeVindex(t, map[string]string{
+		"json":       `[{"from": "2023-01-01", "to": "2024-01-01", "keyspace_id": "80"}]`,
+		"value_type": "date",
+	})
+	got, err := vind.Map(context.Background(), nil, []sqltypes.Value{
+		sqltypes.NewVarChar("2023-06-15"),
+		sqltypes.MakeTrusted(sqltypes.Datetime, []byte("2023-12-31 23:59:59")),
+		sqltypes.NewVarChar("2024-01-01"),
+	})
+	require.NoError(t, err)
+	require.IsType(t, key.DestinationKeyspaceID{}, got[0])
+	require.IsType(t, key.DestinationKeyspaceID{}, got[1])
+	assert.Equal(t, key.DestinationNone{}, got[2])
+	assert.EqualValues(t, 0x80, got[0].(key.DestinationKeyspaceID)[0])
+
+	reversed, err := vind.ReverseMap(nil, [][]byte{got[0].(key.DestinationKeyspaceID), got[1].(key.DestinationKeyspaceID)})
+	require.NoError(t, err)
+	assert.Equal(t, []sqltypes.Value{
+		sqltypes.MakeTrusted(sqltypes.Date, []byte("2023-06-15")),
+		sqltypes.MakeTrusted(sqltypes.Date, []byte("2023-12-31")),
+	}, reversed)
+
+	vind = createRangeVindex(t, map[string]string{
+		"json":       `[{"from": "2023-01-01", "keyspace_id": "80"}]`,
+		"value_type": "datetime",
+	})
+	got, err = vind.Map(context.Background(), nil, []sqltypes.Value{
+		sqltypes.NewVarChar("2023-06-15 10:11:12.5"),
+		sqltypes.NewVarChar("2022-12-31 23:59:59"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, key.DestinationNone{}, got[1])
+	reversed, err = vind.ReverseMap(nil, [][]byte{got[0].(key.DestinationKeyspaceID)})
+	require.NoError(t, err)
+	assert.Equal(t, []sqltypes.Value{sqltypes.MakeTrusted(sqltypes.Datetime, []byte("2023-06-15 10:11:12.500000"))}, reversed)
+}
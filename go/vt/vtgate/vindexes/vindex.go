@@ -43,6 +43,10 @@ type (
 		Execute(ctx context.Context, method string, query string, bindvars map[string]*querypb.BindVariable, rollbackOnError bool, co vtgatepb.CommitOrder) (*sqltypes.Result, error)
 		ExecuteKeyspaceID(ctx context.Context, keyspace string, ksid []byte, query string, bindVars map[string]*querypb.BindVariable, rollbackOnError, autocommit bool) (*sqltypes.Result, error)
 		InTransactionAndIsDML() bool
+		InTransaction() bool
+		// AfterTransaction calls f once the transaction of the session is over,
+		// or once the query is done if it is not in a transaction.
+		AfterTransaction(f func())
 		LookupRowLockShardSession() vtgatepb.CommitOrder
 		ConnCollation() collations.ID
 		Environment() *vtenv.Environment
@@ -138,6 +142,18 @@ type (
 		PrefixVindex() SingleColumn
 	}
 
+	// An Ordered vindex is one that keeps the order of the ids in the keyspace
+	// ids they map to, so that a range of ids maps to ranges of keyspace ids.
+	// It's being used to reduce the fan out of range predicates like
+	// 'BETWEEN', '<' and '>='.
+	// MapRange returns the destinations of the ids between start and end. A null
+	// start or end leaves the range open on that side, and startInclusive and
+	// endInclusive tell whether the ids equal to start and end are part of it.
+	Ordered interface {
+		SingleColumn
+		MapRange(ctx context.Context, vcursor VCursor, start, end sqltypes.Value, startInclusive, endInclusive bool) ([]key.Destination, error)
+	}
+
 	// A Lookup vindex is one that needs to lookup
 	// a previously stored map to compute the keyspace
 	// id from an id. This means that the creation of
@@ -167,6 +183,13 @@ type (
 		AutoCommitEnabled() bool
 	}
 
+	// LookupCached is for lookup vindexes that can cache the rows of their lookup table,
+	// configured with the cache_size and cache_ttl params. A LookupPlanable vindex with
+	// a cache isn't planned as a lookup query, so that its lookups go through the cache.
+	LookupCached interface {
+		LookupCacheEnabled() bool
+	}
+
 	// LookupBackfill interfaces all lookup vindexes that can backfill rows, such as LookupUnique.
 	LookupBackfill interface {
 		IsBackfilling() bool
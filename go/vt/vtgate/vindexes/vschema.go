@@ -110,6 +110,8 @@ type Table struct {
 	Columns                 []Column               `json:"columns,omitempty"`
 	Pinned                  []byte                 `json:"pinned,omitempty"`
 	ColumnListAuthoritative bool                   `json:"column_list_authoritative,omitempty"`
+	// ResultCache is set if vtgate caches the results of the queries reading from the table.
+	ResultCache bool `json:"result_cache,omitempty"`
 	// ReferencedBy is an inverse mapping of tables in other keyspaces that
 	// reference this table via Source.
 	//
@@ -128,6 +130,10 @@ type Table struct {
 	// MySQL error message: ERROR 3756 (HY000): The primary key cannot be a functional index
 	PrimaryKey sqlparser.Columns `json:"primary_key,omitempty"`
 	UniqueKeys []sqlparser.Exprs `json:"unique_keys,omitempty"`
+
+	// Stats are the table statistics collected by the schema tracker, used by the planner to estimate costs.
+	// It is nil when no statistics are available for the table.
+	Stats *TableStats `json:"stats,omitempty"`
 }
 
 // GetTableName gets the sqlparser.TableName for the vindex Table.
@@ -159,6 +165,25 @@ type TableInfo struct {
 	Columns     []Column
 	ForeignKeys []*sqlparser.ForeignKeyDefinition
 	Indexes     []*sqlparser.IndexDefinition
+	Stats       *TableStats
+}
+
+// TableStats contains the statistics MySQL keeps for a table, as reported by a tablet of the keyspace.
+// The numbers are estimates for a single shard.
+type TableStats struct {
+	// Rows is the estimated number of rows in the table
+	Rows uint64 `json:"rows"`
+	// Cardinality is the estimated number of distinct values for columns that are the first column of an index,
+	// keyed by the lowercase column name
+	Cardinality map[string]uint64 `json:"cardinality,omitempty"`
+}
+
+// ColumnCardinality returns the estimated number of distinct values in the column, or 0 if it is not known
+func (ts *TableStats) ColumnCardinality(column sqlparser.IdentifierCI) uint64 {
+	if ts == nil {
+		return 0
+	}
+	return ts.Cardinality[column.Lowered()]
 }
 
 // IsUnique is used to tell whether the ColumnVindex
@@ -608,6 +633,7 @@ func buildTables(ks *vschemapb.Keyspace, vschema *VSchema, ksvschema *KeyspaceSc
 			Name:                    sqlparser.NewIdentifierCS(tname),
 			Keyspace:                keyspace,
 			ColumnListAuthoritative: table.ColumnListAuthoritative,
+			ResultCache:             table.ResultCache,
 		}
 		switch table.Type {
 		case "":
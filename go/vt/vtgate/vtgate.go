@@ -52,8 +52,11 @@ import (
 	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/queryrules"
+	"vitess.io/vitess/go/vt/vtgate/resultcache"
 	vtschema "vitess.io/vitess/go/vt/vtgate/schema"
 	"vitess.io/vitess/go/vt/vtgate/txresolver"
+	"vitess.io/vitess/go/vt/vtgate/userlimits"
 	"vitess.io/vitess/go/vt/vtgate/vtgateservice"
 )
 
@@ -73,6 +76,11 @@ var (
 	maxPayloadSize  int
 	warnPayloadSize int
 
+	// spill to disk related flags
+	spillDir               string
+	spillMaxQueryDiskBytes int64 = 1024 * 1024 * 1024 // 1gb
+	spillMaxTotalDiskBytes int64
+
 	noScatter          bool
 	enableShardRouting bool
 
@@ -100,6 +108,7 @@ var (
 	enableSchemaChangeSignal = true
 	enableViews              bool
 	enableUdfs               bool
+	tableStatsRefresh        time.Duration
 
 	// vtgate views flags
 	queryTimeout int
@@ -117,6 +126,20 @@ var (
 	warmingReadsPercent      = 0
 	warmingReadsQueryTimeout = 5 * time.Second
 	warmingReadsConcurrency  = 500
+
+	// query rules flags
+	queryRulesCell = "global"
+	queryRulesPath string
+
+	// userLimitsConfig is the file with the resource limits of the users
+	userLimitsConfig string
+
+	// result cache flags
+	resultCacheMemory int64
+	resultCacheTTL    = 10 * time.Second
+
+	// enableConsolidator consolidates identical reads from replica and rdonly tablets
+	enableConsolidator bool
 )
 
 func registerFlags(fs *pflag.FlagSet) {
@@ -127,6 +150,9 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&streamBufferSize, "stream_buffer_size", streamBufferSize, "the number of bytes sent from vtgate for each stream call. It's recommended to keep this value in sync with vttablet's query-server-config-stream-buffer-size.")
 	fs.Int64Var(&queryPlanCacheMemory, "gate_query_cache_memory", queryPlanCacheMemory, "gate server query cache size in bytes, maximum amount of memory to be cached. vtgate analyzes every incoming query and generate a query plan, these plans are being cached in a lru cache. This config controls the capacity of the lru cache.")
 	fs.IntVar(&maxMemoryRows, "max_memory_rows", maxMemoryRows, "Maximum number of rows that will be held in memory for intermediate results as well as the final result.")
+	fs.StringVar(&spillDir, "spill-dir", spillDir, "Directory where vtgate writes temporary files when sorting, hash joining or deduplicating more rows than max_memory_rows. When set, these primitives and the aggregations stream their input instead of loading it in memory, so that only the results of non-streaming queries are bound by max_memory_rows. Spilling to disk is disabled when empty.")
+	fs.Int64Var(&spillMaxQueryDiskBytes, "spill-max-query-disk-bytes", spillMaxQueryDiskBytes, "Maximum number of bytes a single query can have in the spill-dir at the same time. 0 means no limit.")
+	fs.Int64Var(&spillMaxTotalDiskBytes, "spill-max-disk-bytes", spillMaxTotalDiskBytes, "Maximum number of bytes all the queries running on this vtgate can have in the spill-dir at the same time. 0 means no limit.")
 	fs.IntVar(&warnMemoryRows, "warn_memory_rows", warnMemoryRows, "Warning threshold for in-memory results. A row count higher than this amount will cause the VtGateWarnings.ResultsExceeded counter to be incremented.")
 	fs.StringVar(&defaultDDLStrategy, "ddl_strategy", defaultDDLStrategy, "Set default strategy for DDL statements. Override with @@ddl_strategy session variable")
 	fs.StringVar(&dbDDLPlugin, "dbddl_plugin", dbDDLPlugin, "controls how to handle CREATE/DROP DATABASE. use it if you are using your own database provisioning service")
@@ -150,6 +176,13 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&messageStreamGracePeriod, "message_stream_grace_period", messageStreamGracePeriod, "the amount of time to give for a vttablet to resume if it ends a message stream, usually because of a reparent.")
 	fs.BoolVar(&enableViews, "enable-views", enableViews, "Enable views support in vtgate.")
 	fs.BoolVar(&enableUdfs, "track-udfs", enableUdfs, "Track UDFs in vtgate.")
+	fs.DurationVar(&tableStatsRefresh, "table-stats-refresh-interval", tableStatsRefresh, "How often the schema tracker reloads the table and index statistics the planner uses to estimate the cost of joins. 0 disables tracking the statistics.")
+	fs.StringVar(&queryRulesCell, "query-rules-topo-cell", queryRulesCell, "Topo cell holding the file with the vtgate query rules.")
+	fs.StringVar(&queryRulesPath, "query-rules-topo-path", queryRulesPath, "Path of the file in the topo holding the vtgate query rules, which can deny, time out, redirect or rate limit queries. The file is watched for changes. Disabled if empty.")
+	fs.StringVar(&userLimitsConfig, "user-limits-config", userLimitsConfig, "JSON file with the resource limits of the users: maximum concurrent queries, queries per second, shards per query, rows per query and transaction duration. Users are not limited if empty.")
+	fs.Int64Var(&resultCacheMemory, "result-cache-memory", resultCacheMemory, "Maximum number of bytes of query results cached by vtgate for read-only queries. The result cache is disabled if 0.")
+	fs.DurationVar(&resultCacheTTL, "result-cache-ttl", resultCacheTTL, "How long the results of queries reading from the tables that set result_cache in the VSchema are cached. Their cached results are invalidated as soon as any of their rows change. Queries on other tables can be cached with the RESULT_CACHE_TTL_MS comment directive.")
+	fs.BoolVar(&enableConsolidator, "enable-read-consolidation", enableConsolidator, "Consolidate identical reads from replica and rdonly tablets: while a read is executing, identical reads from other sessions wait for its result instead of being sent to the tablets.")
 	fs.BoolVar(&allowKillStmt, "allow-kill-statement", allowKillStmt, "Allows the execution of kill statement")
 	fs.IntVar(&warmingReadsPercent, "warming-reads-percent", 0, "Percentage of reads on the primary to forward to replicas. Useful for keeping buffer pools warm")
 	fs.IntVar(&warmingReadsConcurrency, "warming-reads-concurrency", 500, "Number of concurrent warming reads allowed")
@@ -312,6 +345,9 @@ func Init(
 	var st *vtschema.Tracker
 	if enableSchemaChangeSignal {
 		st = vtschema.NewTracker(gw.hc.Subscribe(), enableViews, enableUdfs, env.Parser())
+		if tableStatsRefresh > 0 {
+			st.EnableTableStats(tableStatsRefresh)
+		}
 		addKeyspacesToTracker(ctx, srvResolver, st, gw)
 		si = st
 	}
@@ -343,6 +379,37 @@ func Init(
 		st.RegisterSignalReceiver(executor.vm.Rebuild)
 	}
 
+	if userLimitsConfig != "" {
+		limits, err := userlimits.Load(userLimitsConfig)
+		if err != nil {
+			log.Fatalf("Unable to load the user limits: %v", err)
+		}
+		executor.SetUserLimits(limits)
+	}
+
+	if enableConsolidator {
+		executor.EnableConsolidator()
+	}
+
+	var rc *resultcache.Cache
+	if resultCacheMemory > 0 {
+		rc = resultcache.New(resultcache.Config{
+			MaxMemory:  resultCacheMemory,
+			TTL:        resultCacheTTL,
+			Doorkeeper: !servenv.TestingEndtoend,
+		})
+		executor.SetResultCache(rc)
+	}
+	rcCtx, rcCancel := context.WithCancel(context.Background())
+
+	var qrw *queryrules.TopoWatcher
+	if queryRulesPath != "" {
+		qrw, err = queryrules.NewTopoWatcher(ts, queryRulesCell, queryRulesPath, executor.SetQueryRules)
+		if err != nil {
+			log.Fatalf("Unable to watch query rules: %v", err)
+		}
+	}
+
 	// TODO: call serv.WatchSrvVSchema here
 
 	vtgateInst := newVTGate(executor, resolver, vsm, tc, gw)
@@ -362,6 +429,12 @@ func Init(
 		if st != nil && enableSchemaChangeSignal {
 			st.Start()
 		}
+		if qrw != nil {
+			qrw.Start()
+		}
+		if rc != nil {
+			rc.WatchTables(rcCtx, vsm.VStream)
+		}
 		tr.Start()
 		srv := initMySQLProtocol(vtgateInst)
 		if srv != nil {
@@ -373,6 +446,13 @@ func Init(
 		if st != nil && enableSchemaChangeSignal {
 			st.Stop()
 		}
+		if qrw != nil {
+			qrw.Stop()
+		}
+		rcCancel()
+		if rc != nil {
+			rc.Close()
+		}
 		tr.Stop()
 	})
 	vtgateInst.registerDebugHealthHandler()
@@ -24,7 +24,6 @@ import (
 	"context"
 	"fmt"
 	"reflect"
-	"sync"
 	"time"
 
 	"github.com/spf13/pflag"
@@ -63,23 +62,11 @@ type topoCustomRule struct {
 	// qsc is set at construction time.
 	qsc tabletserver.Controller
 
-	// conn is the topo connection. Set at construction time.
-	conn topo.Conn
-
-	// filePath is the file to read from.
-	filePath string
+	// watcher watches the file holding the rules.
+	watcher *topo.FileWatcher
 
 	// qrs is the current rule set that we read.
 	qrs *rules.Rules
-
-	// mu protects the following variables.
-	mu sync.Mutex
-
-	// cancel is the function to call to cancel the current watch, if any.
-	cancel func()
-
-	// stopped is set when stop() is called. It is a protection for race conditions.
-	stopped bool
 }
 
 func newTopoCustomRule(qsc tabletserver.Controller, cell, filePath string) (*topoCustomRule, error) {
@@ -87,42 +74,19 @@ func newTopoCustomRule(qsc tabletserver.Controller, cell, filePath string) (*top
 	if err != nil {
 		return nil, err
 	}
-	return &topoCustomRule{
-		qsc:      qsc,
-		conn:     conn,
-		filePath: filePath,
-	}, nil
+	cr := &topoCustomRule{
+		qsc: qsc,
+	}
+	cr.watcher = topo.NewFileWatcher(conn, filePath, sleepDuringTopoFailure, cr.apply)
+	return cr, nil
 }
 
 func (cr *topoCustomRule) start() {
-	go func() {
-		for {
-			if err := cr.oneWatch(); err != nil {
-				log.Warningf("Background watch of topo custom rule failed: %v", err)
-			}
-
-			cr.mu.Lock()
-			stopped := cr.stopped
-			cr.mu.Unlock()
-
-			if stopped {
-				log.Warningf("Topo custom rule was terminated")
-				return
-			}
-
-			log.Warningf("Sleeping for %v before trying again", sleepDuringTopoFailure)
-			time.Sleep(sleepDuringTopoFailure)
-		}
-	}()
+	cr.watcher.Start()
 }
 
 func (cr *topoCustomRule) stop() {
-	cr.mu.Lock()
-	if cr.cancel != nil {
-		cr.cancel()
-	}
-	cr.stopped = true
-	cr.mu.Unlock()
+	cr.watcher.Stop()
 }
 
 func (cr *topoCustomRule) apply(wd *topo.WatchData) error {
@@ -140,62 +104,6 @@ func (cr *topoCustomRule) apply(wd *topo.WatchData) error {
 	return nil
 }
 
-func (cr *topoCustomRule) oneWatch() error {
-	defer func() {
-		// Whatever happens, cancel() won't be valid after this function exits.
-		cr.mu.Lock()
-		cr.cancel = nil
-		cr.mu.Unlock()
-	}()
-
-	ctx, cancel := context.WithCancel(context.Background())
-	current, wdChannel, err := cr.conn.Watch(ctx, cr.filePath)
-	if err != nil {
-		cancel()
-		return err
-	}
-
-	cr.mu.Lock()
-	if cr.stopped {
-		// We're not interested in the result any more.
-		cr.mu.Unlock()
-		cancel()
-		for range wdChannel {
-		}
-		return topo.NewError(topo.Interrupted, "watch")
-	}
-	cr.cancel = cancel
-	cr.mu.Unlock()
-
-	if err := cr.apply(current); err != nil {
-		// Cancel the watch, drain channel.
-		cancel()
-		for range wdChannel {
-		}
-		return err
-	}
-
-	for wd := range wdChannel {
-		if wd.Err != nil {
-			// Last error value, we're done.
-			// wdChannel will be closed right after
-			// this, no need to do anything.
-			return wd.Err
-		}
-
-		if err := cr.apply(wd); err != nil {
-			// Cancel the watch, drain channel.
-			cancel()
-			for range wdChannel {
-			}
-			return err
-		}
-
-	}
-
-	return fmt.Errorf("watch terminated with no error")
-}
-
 // activateTopoCustomRules activates topo dynamic custom rule mechanism.
 func activateTopoCustomRules(qsc tabletserver.Controller) {
 	if rulePath != "" {
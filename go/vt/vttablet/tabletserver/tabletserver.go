@@ -810,6 +810,7 @@ func (tsv *TabletServer) execute(ctx context.Context, target *querypb.Target, sq
 			}
 			logStats.ReservedID = reservedID
 			logStats.TransactionID = transactionID
+			logStats.QueryAttributes = options.GetQueryAttributes()
 
 			var connSetting *smartconnpool.Setting
 			if len(settings) > 0 {
@@ -916,6 +917,7 @@ func (tsv *TabletServer) streamExecute(ctx context.Context, target *querypb.Targ
 			}
 			logStats.ReservedID = reservedID
 			logStats.TransactionID = transactionID
+			logStats.QueryAttributes = options.GetQueryAttributes()
 
 			var connSetting *smartconnpool.Setting
 			if len(settings) > 0 {